@@ -0,0 +1,49 @@
+package v1
+
+// Condition types reported on NamespaceClasses and NamespaceClassInventories.
+const (
+    // ConditionReady indicates all resources of the class are applied and usable.
+    ConditionReady = "Ready"
+
+    // ConditionProgressing indicates namespaces are still being synced with
+    // the current generation of the class.
+    ConditionProgressing = "Progressing"
+
+    // ConditionDegraded indicates the last sync of at least one namespace
+    // failed, or on an inventory that the namespace cannot be synced.
+    ConditionDegraded = "Degraded"
+
+    // ConditionDeprecated indicates the class is deprecated and new
+    // namespaces cannot bind to it.
+    ConditionDeprecated = "Deprecated"
+)
+
+// Condition reasons reported by the controller.
+const (
+    ReasonApplied                = "Applied"
+    ReasonGeneratedDataPending   = "GeneratedDataPending"
+    ReasonWavePending            = "WavePending"
+    ReasonDependencyPending      = "DependencyPending"
+    ReasonResourcesNotReady      = "ResourcesNotReady"
+    ReasonReadyTimeout           = "ReadyTimeout"
+    ReasonTransitionPending      = "TransitionPending"
+    ReasonRecreatePending        = "RecreatePending"
+    ReasonDeprecated             = "Deprecated"
+    ReasonClassConflict          = "ClassConflict"
+    ReasonNamespacesSynced       = "NamespacesSynced"
+    ReasonNamespacesPending      = "NamespacesPending"
+    ReasonSyncFailed             = "SyncFailed"
+    ReasonSuspended              = "Suspended"
+    ReasonKindNotInstalled       = "KindNotInstalled"
+    ReasonPermissionDenied       = "PermissionDenied"
+    ReasonKindForbidden          = "KindForbidden"
+    ReasonTooManyResources       = "TooManyResources"
+    ReasonInvalidValues          = "InvalidValues"
+    ReasonNamespacePending       = "NamespacePending"
+    ReasonNamespaceExists        = "NamespaceExists"
+    ReasonNamespaceTerminating   = "NamespaceTerminating"
+    ReasonWithinNamespaceLimit   = "WithinNamespaceLimit"
+    ReasonNamespaceLimitExceeded = "NamespaceLimitExceeded"
+    ReasonCircuitOpen            = "CircuitOpen"
+    ReasonApplyFailed            = "ApplyFailed"
+)
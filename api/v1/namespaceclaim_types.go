@@ -0,0 +1,93 @@
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=nsclaim
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.className`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// NamespaceClaim requests a namespace using a NamespaceClass. The controller
+// creates the namespace, named after the claim, labels it with the class and
+// reports in status once the resources of the class are applied. The
+// namespace is owned by the claim and deleted with it.
+type NamespaceClaim struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   NamespaceClaimSpec   `json:"spec,omitempty"`
+    Status NamespaceClaimStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type NamespaceClaimList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []NamespaceClaim `json:"items"`
+}
+
+type NamespaceClaimSpec struct {
+    // ClassName is the NamespaceClass of the namespace.
+    ClassName string `json:"className"`
+
+    // AddonClasses are add-on classes of the namespace, applied in addition
+    // to its class.
+    // +kubebuilder:validation:Optional
+    AddonClasses []string `json:"addonClasses,omitempty"`
+
+    // Labels are set on the namespace in addition to the class label.
+    // +kubebuilder:validation:Optional
+    Labels map[string]string `json:"labels,omitempty"`
+
+    // Annotations are set on the namespace.
+    // +kubebuilder:validation:Optional
+    Annotations map[string]string `json:"annotations,omitempty"`
+
+    // TTL deletes the claim, and with it the namespace, once the claim is
+    // older than the duration given, e.g. for preview environments.
+    // +kubebuilder:validation:Optional
+    TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+type NamespaceClaimStatus struct {
+    // Phase summarizes the state of the claim.
+    // +kubebuilder:validation:Optional
+    Phase ClaimPhase `json:"phase,omitempty"`
+
+    // Namespace is the namespace provisioned for the claim.
+    // +kubebuilder:validation:Optional
+    Namespace string `json:"namespace,omitempty"`
+
+    // ExpirationTime is when the claim and its namespace are deleted, for
+    // claims with a TTL.
+    // +kubebuilder:validation:Optional
+    ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+    // ObservedGeneration is the generation of the claim last reconciled.
+    // +kubebuilder:validation:Optional
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+    // Conditions represent the latest observations of the claim's state.
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ClaimPhase summarizes the state of a NamespaceClaim.
+type ClaimPhase string
+
+const (
+    // ClaimPhasePending means the namespace is being created or its class
+    // is not applied yet.
+    ClaimPhasePending ClaimPhase = "Pending"
+    // ClaimPhaseBound means the namespace exists and its class is applied.
+    ClaimPhaseBound ClaimPhase = "Bound"
+    // ClaimPhaseFailed means the namespace cannot be provisioned for the
+    // claim, e.g. because a namespace of that name already exists.
+    ClaimPhaseFailed ClaimPhase = "Failed"
+)
+
+func init() {
+    SchemeBuilder.Register(&NamespaceClaim{}, &NamespaceClaimList{})
+}
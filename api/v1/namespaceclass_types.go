@@ -1,12 +1,17 @@
 package v1
 
 import (
+    "k8s.io/apimachinery/pkg/api/resource"
     metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
     "k8s.io/apimachinery/pkg/runtime"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=nsc
+// +kubebuilder:printcolumn:name="Namespaces",type=integer,JSONPath=`.status.managedNamespaceCount`,description="Number of namespaces using this class"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type NamespaceClass struct {
     metav1.TypeMeta   `json:",inline"`
     metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -26,19 +31,553 @@ type NamespaceClassSpec struct {
     // Resources is a list of raw Kubernetes resource manifests to apply to namespaces.
     // +kubebuilder:validation:Optional
     Resources []runtime.RawExtension `json:"resources,omitempty"`
+
+    // ClusterResources are cluster-scoped resources created once for each
+    // namespace using the class. Their string values are templates that can
+    // refer to {{ .Namespace }} and {{ .Class }}; names must refer to the
+    // namespace so that each namespace gets its own objects.
+    // +kubebuilder:validation:Optional
+    ClusterResources []runtime.RawExtension `json:"clusterResources,omitempty"`
+
+    // Values are the default template values of the class, as a JSON
+    // object. When set, the string values of the resources of the class are
+    // templates that can also refer to {{ .Values.<name> }}. Namespaces can
+    // override the values the class declares here with the
+    // namespaceclass.akuity.io/values annotation.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:pruning:PreserveUnknownFields
+    Values *runtime.RawExtension `json:"values,omitempty"`
+
+    // ValuesFrom looks up values in each namespace using the class, which
+    // override the values the class declares. The
+    // namespaceclass.akuity.io/values annotation overrides them in turn.
+    // +kubebuilder:validation:Optional
+    ValuesFrom *ValuesSource `json:"valuesFrom,omitempty"`
+
+    // IgnoreFields is a list of JSON pointers (e.g. /spec/replicas) excluded from
+    // change detection and preserved from the live object on update, for fields
+    // owned by other actors such as HPAs or mutating webhooks.
+    // +kubebuilder:validation:Optional
+    IgnoreFields []string `json:"ignoreFields,omitempty"`
+
+    // ComparisonMode selects how live resources are compared with the class.
+    // Hash (the default) compares the hash recorded at the last apply; Semantic
+    // compares the live object and tolerates apiserver defaulting and normalization.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=Hash;Semantic
+    ComparisonMode ComparisonMode `json:"comparisonMode,omitempty"`
+
+    // HashAlgorithm is the algorithm used to compute resource hashes. Defaults to sha256.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=sha256;sha512;fnv64a
+    HashAlgorithm HashAlgorithm `json:"hashAlgorithm,omitempty"`
+
+    // ConflictPolicy controls what happens when a class resource already exists
+    // in the namespace but was not created by the controller. Defaults to Overwrite.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=Fail;Overwrite;Adopt;Skip
+    ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+    // ApplyPolicy controls what happens when a resource of the class fails
+    // to apply to a namespace. Defaults to StopOnError.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=StopOnError;ContinueOnError;Atomic
+    ApplyPolicy ApplyPolicy `json:"applyPolicy,omitempty"`
+
+    // ServiceAccountName is a ServiceAccount in the controller's namespace
+    // that the controller impersonates when applying the resources of the
+    // class, so that RBAC granted to it limits what the class can create.
+    // Empty applies them with the controller's own permissions.
+    // +kubebuilder:validation:Optional
+    ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+    // AdmissionWarnings are informational messages returned as admission
+    // warnings when workloads are created in namespaces using this class,
+    // e.g. "this namespace enforces default-deny egress".
+    // +kubebuilder:validation:Optional
+    AdmissionWarnings []string `json:"admissionWarnings,omitempty"`
+
+    // AllowTenantFreeze lets tenants stop updates to a single managed resource
+    // by annotating it with namespaceclass.akuity.io/frozen: "true". Frozen
+    // resources stay tracked and are still pruned when removed from the class.
+    // +kubebuilder:validation:Optional
+    AllowTenantFreeze bool `json:"allowTenantFreeze,omitempty"`
+
+    // Suspend stops the controller from applying or pruning resources of the
+    // class, e.g. during an incident. Namespaces are still checked for
+    // drift, which is reported in status.
+    // +kubebuilder:validation:Optional
+    Suspend bool `json:"suspend,omitempty"`
+
+    // UpdateWindows restricts changes to namespaces that are already
+    // provisioned to the given recurring windows. New namespaces are always
+    // provisioned immediately. Without windows, changes are applied at any time.
+    // +kubebuilder:validation:Optional
+    UpdateWindows []UpdateWindow `json:"updateWindows,omitempty"`
+
+    // WaitForReady keeps a namespace Pending after its resources are applied
+    // until they are healthy, e.g. Deployments available and Jobs complete.
+    // The health of each resource is reported in the inventory status.
+    // +kubebuilder:validation:Optional
+    WaitForReady bool `json:"waitForReady,omitempty"`
+
+    // ReadyTimeout is how long resources may stay unhealthy before the sync
+    // of the namespace is reported as failed. Defaults to 10m.
+    // +kubebuilder:validation:Optional
+    ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty"`
+
+    // Hooks are run when a namespace switches to this class from another class.
+    // +kubebuilder:validation:Optional
+    Hooks *ClassHooks `json:"hooks,omitempty"`
+
+    // Deprecated stops new namespaces from binding to the class. Namespaces
+    // already using it keep being managed.
+    // +kubebuilder:validation:Optional
+    Deprecated bool `json:"deprecated,omitempty"`
+
+    // Replacement names the class new namespaces should use instead of a
+    // deprecated class.
+    // +kubebuilder:validation:Optional
+    Replacement string `json:"replacement,omitempty"`
+
+    // NamespaceMetadata are labels and annotations set on the namespace
+    // itself, e.g. Pod Security Admission labels or cost-center annotations.
+    // +kubebuilder:validation:Optional
+    NamespaceMetadata *NamespaceMetadata `json:"namespaceMetadata,omitempty"`
+
+    // PodSecurity sets the Pod Security Admission labels of the namespaces
+    // using the class.
+    // +kubebuilder:validation:Optional
+    PodSecurity *PodSecurity `json:"podSecurity,omitempty"`
+
+    // Quota is expanded into a ResourceQuota in each namespace using the
+    // class, as an alternative to embedding one in resources.
+    // +kubebuilder:validation:Optional
+    Quota *ClassQuota `json:"quota,omitempty"`
+
+    // Limits is expanded into a LimitRange for containers in each namespace
+    // using the class, as an alternative to embedding one in resources.
+    // +kubebuilder:validation:Optional
+    Limits *ClassLimits `json:"limits,omitempty"`
+
+    // RBAC grants subjects a ClusterRole in each namespace using the class,
+    // expanded into RoleBindings.
+    // +kubebuilder:validation:Optional
+    RBAC []RoleGrant `json:"rbac,omitempty"`
+
+    // ServiceAccounts are created in each namespace using the class.
+    // +kubebuilder:validation:Optional
+    ServiceAccounts []ClassServiceAccount `json:"serviceAccounts,omitempty"`
+
+    // ConfigMaps are copied from central ConfigMaps into each namespace using
+    // the class and kept up to date when their source changes.
+    // +kubebuilder:validation:Optional
+    ConfigMaps []ConfigMapReplica `json:"configMaps,omitempty"`
+
+    // Certificates are cert-manager Certificates issued for each namespace
+    // using the class.
+    // +kubebuilder:validation:Optional
+    Certificates []ClassCertificate `json:"certificates,omitempty"`
+
+    // Namespaces are created with the class and recreated if deleted.
+    // Existing namespaces without a class are labeled with it.
+    // +kubebuilder:validation:Optional
+    Namespaces []string `json:"namespaces,omitempty"`
+
+    // NamespaceRemovalPolicy decides what happens to namespaces the class
+    // created once they are removed from namespaces. Defaults to Delete.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=Delete;Orphan
+    NamespaceRemovalPolicy NamespaceRemovalPolicy `json:"namespaceRemovalPolicy,omitempty"`
+}
+
+// NamespaceRemovalPolicy decides what happens to namespaces a class created
+// once they are removed from the class.
+type NamespaceRemovalPolicy string
+
+const (
+    // NamespaceRemovalPolicyDelete deletes the namespace.
+    NamespaceRemovalPolicyDelete NamespaceRemovalPolicy = "Delete"
+    // NamespaceRemovalPolicyOrphan keeps the namespace, still using the
+    // class, but no longer recreates it.
+    NamespaceRemovalPolicyOrphan NamespaceRemovalPolicy = "Orphan"
+)
+
+// ClassCertificate declares a cert-manager Certificate issued in each
+// namespace.
+type ClassCertificate struct {
+    Name string `json:"name"`
+
+    // SecretName is the Secret the certificate is stored in. Defaults to the
+    // name of the certificate.
+    // +kubebuilder:validation:Optional
+    SecretName string `json:"secretName,omitempty"`
+
+    // DNSNames of the certificate. Each is a template that can refer to
+    // {{ .Namespace }} and {{ .Class }}, e.g. "*.{{ .Namespace }}.example.com".
+    DNSNames []string `json:"dnsNames"`
+
+    // IssuerRef is the issuer that signs the certificate.
+    IssuerRef CertificateIssuerRef `json:"issuerRef"`
+}
+
+// CertificateIssuerKind is the kind of a cert-manager issuer.
+type CertificateIssuerKind string
+
+const (
+    CertificateIssuerKindIssuer        CertificateIssuerKind = "Issuer"
+    CertificateIssuerKindClusterIssuer CertificateIssuerKind = "ClusterIssuer"
+)
+
+// CertificateIssuerRef refers to a cert-manager issuer.
+type CertificateIssuerRef struct {
+    Name string `json:"name"`
+
+    // Kind of the issuer. Defaults to ClusterIssuer, an issuer shared by all
+    // namespaces.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+    Kind CertificateIssuerKind `json:"kind,omitempty"`
+
+    // Group of the issuer, for external issuers. Defaults to cert-manager.io.
+    // +kubebuilder:validation:Optional
+    Group string `json:"group,omitempty"`
+}
+
+// ConfigMapReplica copies a central ConfigMap into a namespace.
+type ConfigMapReplica struct {
+    // Name of the copy. Defaults to the name of the source.
+    // +kubebuilder:validation:Optional
+    Name string `json:"name,omitempty"`
+
+    // Source is the ConfigMap that is copied.
+    Source ObjectSource `json:"source"`
+}
+
+// ObjectSource refers to a namespaced object outside the namespaces of a
+// class.
+type ObjectSource struct {
+    Namespace string `json:"namespace"`
+    Name      string `json:"name"`
+}
+
+// ValuesSource is where the template values of a namespace are read from.
+type ValuesSource struct {
+    // ConfigMapRef names a ConfigMap in each namespace whose data sets
+    // values. Namespaces without it use the values of the class.
+    // +kubebuilder:validation:Optional
+    ConfigMapRef *LocalConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+// LocalConfigMapReference refers to a ConfigMap in the same namespace.
+type LocalConfigMapReference struct {
+    Name string `json:"name"`
+}
+
+// ClassServiceAccount declares a ServiceAccount created in each namespace.
+type ClassServiceAccount struct {
+    Name string `json:"name"`
+
+    // ImagePullSecret copies the central image pull secret the controller is
+    // configured with into the namespace and lists it in the imagePullSecrets
+    // of the ServiceAccount.
+    // +kubebuilder:validation:Optional
+    ImagePullSecret bool `json:"imagePullSecret,omitempty"`
 }
 
+// RoleGrant binds a ClusterRole to subjects within a namespace.
+type RoleGrant struct {
+    // Name of the RoleBinding. Defaults to the name of the ClusterRole.
+    // +kubebuilder:validation:Optional
+    Name string `json:"name,omitempty"`
+
+    // ClusterRole granted in the namespace, e.g. "edit" or "view".
+    ClusterRole string `json:"clusterRole"`
+
+    // +kubebuilder:validation:Optional
+    Groups []string `json:"groups,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Users []string `json:"users,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    ServiceAccounts []ServiceAccountSubject `json:"serviceAccounts,omitempty"`
+}
+
+// ServiceAccountSubject refers to a ServiceAccount.
+type ServiceAccountSubject struct {
+    Name string `json:"name"`
+
+    // Namespace of the ServiceAccount. Defaults to the namespace the
+    // RoleBinding is created in.
+    // +kubebuilder:validation:Optional
+    Namespace string `json:"namespace,omitempty"`
+}
+
+// ClassLimits are the defaults and bounds of the compute resources of each
+// container in a namespace.
+type ClassLimits struct {
+    // Name of the LimitRange. Defaults to "namespace-limits".
+    // +kubebuilder:validation:Optional
+    Name string `json:"name,omitempty"`
+
+    // Default are the limits of containers that set none.
+    // +kubebuilder:validation:Optional
+    Default *ResourceValues `json:"default,omitempty"`
+
+    // DefaultRequest are the requests of containers that set none.
+    // +kubebuilder:validation:Optional
+    DefaultRequest *ResourceValues `json:"defaultRequest,omitempty"`
+
+    // Max are the largest limits a container may set.
+    // +kubebuilder:validation:Optional
+    Max *ResourceValues `json:"max,omitempty"`
+
+    // Min are the smallest requests a container may set.
+    // +kubebuilder:validation:Optional
+    Min *ResourceValues `json:"min,omitempty"`
+}
+
+// ResourceValues are amounts of compute resources.
+type ResourceValues struct {
+    // +kubebuilder:validation:Optional
+    CPU *resource.Quantity `json:"cpu,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Memory *resource.Quantity `json:"memory,omitempty"`
+}
+
+// ClassQuota limits the compute resources and objects of a namespace. Only
+// the limits that are set become part of the ResourceQuota.
+type ClassQuota struct {
+    // Name of the ResourceQuota. Defaults to "namespace-quota".
+    // +kubebuilder:validation:Optional
+    Name string `json:"name,omitempty"`
+
+    // CPU limits the total CPU requests of pods (requests.cpu).
+    // +kubebuilder:validation:Optional
+    CPU *resource.Quantity `json:"cpu,omitempty"`
+
+    // Memory limits the total memory requests of pods (requests.memory).
+    // +kubebuilder:validation:Optional
+    Memory *resource.Quantity `json:"memory,omitempty"`
+
+    // LimitsCPU limits the total CPU limits of pods (limits.cpu).
+    // +kubebuilder:validation:Optional
+    LimitsCPU *resource.Quantity `json:"limitsCPU,omitempty"`
+
+    // LimitsMemory limits the total memory limits of pods (limits.memory).
+    // +kubebuilder:validation:Optional
+    LimitsMemory *resource.Quantity `json:"limitsMemory,omitempty"`
+
+    // Storage limits the total storage requested by PersistentVolumeClaims
+    // (requests.storage).
+    // +kubebuilder:validation:Optional
+    Storage *resource.Quantity `json:"storage,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Pods *int64 `json:"pods,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Services *int64 `json:"services,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    ConfigMaps *int64 `json:"configMaps,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Secrets *int64 `json:"secrets,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    PersistentVolumeClaims *int64 `json:"persistentVolumeClaims,omitempty"`
+}
+
+// PodSecurity selects the Pod Security Standards enforced, audited and
+// warned about in a namespace. Modes without a level are not set.
+type PodSecurity struct {
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=privileged;baseline;restricted
+    Enforce PodSecurityLevel `json:"enforce,omitempty"`
+
+    // EnforceVersion pins the version of the standard, e.g. "v1.30" or
+    // "latest".
+    // +kubebuilder:validation:Optional
+    EnforceVersion string `json:"enforceVersion,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=privileged;baseline;restricted
+    Audit PodSecurityLevel `json:"audit,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    AuditVersion string `json:"auditVersion,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Enum=privileged;baseline;restricted
+    Warn PodSecurityLevel `json:"warn,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    WarnVersion string `json:"warnVersion,omitempty"`
+}
+
+// PodSecurityLevel is a Pod Security Standard.
+type PodSecurityLevel string
+
+const (
+    PodSecurityPrivileged PodSecurityLevel = "privileged"
+    PodSecurityBaseline   PodSecurityLevel = "baseline"
+    PodSecurityRestricted PodSecurityLevel = "restricted"
+)
+
+// NamespaceMetadata are labels and annotations a class sets on its
+// namespaces. Keys removed from the class are removed from the namespaces.
+type NamespaceMetadata struct {
+    // +kubebuilder:validation:Optional
+    Labels map[string]string `json:"labels,omitempty"`
+
+    // +kubebuilder:validation:Optional
+    Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ClassHooks are resources, typically Jobs, created in a namespace that
+// switches classes. Each hook must complete before the next one is created
+// and the transition moves on.
+type ClassHooks struct {
+    // PreSwitch hooks run before any resource of the class is applied.
+    // +kubebuilder:validation:Optional
+    PreSwitch []runtime.RawExtension `json:"preSwitch,omitempty"`
+
+    // PostSwitch hooks run once the resources of the class are applied and
+    // ready and those of the previous class are pruned.
+    // +kubebuilder:validation:Optional
+    PostSwitch []runtime.RawExtension `json:"postSwitch,omitempty"`
+}
+
+// UpdateWindow is a recurring period during which changes may be applied.
+type UpdateWindow struct {
+    // Schedule is a cron expression for the start of the window, e.g.
+    // "0 22 * * 1-5" for 22:00 on weekdays.
+    Schedule string `json:"schedule"`
+
+    // Duration is how long the window stays open, e.g. "2h".
+    Duration metav1.Duration `json:"duration"`
+
+    // TimeZone is the IANA time zone the schedule is evaluated in. Defaults to UTC.
+    // +kubebuilder:validation:Optional
+    TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ComparisonMode is the strategy used to detect changes to managed resources.
+type ComparisonMode string
+
+const (
+    ComparisonModeHash     ComparisonMode = "Hash"
+    ComparisonModeSemantic ComparisonMode = "Semantic"
+)
+
+// ConflictPolicy decides how pre-existing resources not created by the controller are handled.
+type ConflictPolicy string
+
+const (
+    // ConflictPolicyFail reports an error and leaves the resource untouched.
+    ConflictPolicyFail ConflictPolicy = "Fail"
+    // ConflictPolicyOverwrite replaces the resource with the class definition.
+    ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+    // ConflictPolicyAdopt takes over the resource, keeping its existing labels and annotations.
+    ConflictPolicyAdopt ConflictPolicy = "Adopt"
+    // ConflictPolicySkip leaves the resource untouched and does not manage it.
+    ConflictPolicySkip ConflictPolicy = "Skip"
+)
+
+// ApplyPolicy decides how a namespace is synced when one of its resources fails to apply.
+type ApplyPolicy string
+
+const (
+    // ApplyPolicyStopOnError stops at the first resource that fails, leaving
+    // the resources after it unapplied until the namespace is retried.
+    ApplyPolicyStopOnError ApplyPolicy = "StopOnError"
+    // ApplyPolicyContinueOnError applies every resource it can and reports
+    // the resources that failed together.
+    ApplyPolicyContinueOnError ApplyPolicy = "ContinueOnError"
+    // ApplyPolicyAtomic stops at the first resource that fails and reverts
+    // the resources applied before it in the same sync.
+    ApplyPolicyAtomic ApplyPolicy = "Atomic"
+)
+
+// HashAlgorithm is the algorithm used to hash managed resources.
+type HashAlgorithm string
+
+const (
+    HashAlgorithmSHA256 HashAlgorithm = "sha256"
+    HashAlgorithmSHA512 HashAlgorithm = "sha512"
+    HashAlgorithmFNV64a HashAlgorithm = "fnv64a"
+)
+
 type NamespaceClassStatus struct {
     // Conditions represent the latest observations of the NamespaceClass's state.
     Conditions []metav1.Condition `json:"conditions,omitempty"`
 
+    // ObservedGeneration is the generation of the class the status refers to.
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
     // LastUpdateTime is the last time the NamespaceClass was updated.
     LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 
-    // ManagedNamespaces lists namespaces using this class.
+    // ManagedNamespaces lists namespaces using this class, sorted by name.
     ManagedNamespaces []string `json:"managedNamespaces,omitempty"`
+
+    // ManagedNamespaceCount is the number of namespaces using this class.
+    ManagedNamespaceCount int `json:"managedNamespaceCount"`
+
+    // Namespaces reports the result of the last sync of each namespace using
+    // this class, sorted by name.
+    Namespaces []NamespaceSyncStatus `json:"namespaces,omitempty"`
 }
 
+// NamespaceSyncStatus is the result of the last sync of a namespace.
+type NamespaceSyncStatus struct {
+    // Name is the name of the namespace.
+    Name string `json:"name"`
+
+    // Phase is the outcome of the last sync.
+    Phase SyncPhase `json:"phase"`
+
+    // LastSyncTime is the time of the last sync.
+    LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+
+    // ObservedGeneration is the generation of the class the namespace was
+    // last synced with.
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+    // DesiredResources is the number of resources the class defines.
+    DesiredResources int `json:"desiredResources"`
+
+    // ManagedResources is the number of resources managed in the namespace.
+    ManagedResources int `json:"managedResources"`
+
+    // DriftedResources is the number of resources that would be created,
+    // updated or pruned, reported while the namespace is suspended.
+    DriftedResources int `json:"driftedResources,omitempty"`
+
+    // Message describes why the last sync failed, is pending or suspended.
+    Message string `json:"message,omitempty"`
+
+    // Reason is a machine-readable reason for a failed sync, if known.
+    Reason string `json:"reason,omitempty"`
+}
+
+// SyncPhase is the outcome of syncing a namespace with its class.
+type SyncPhase string
+
+const (
+    // SyncPhaseSynced means all resources of the class are applied.
+    SyncPhaseSynced SyncPhase = "Synced"
+    // SyncPhasePending means some resources are waiting to be applied.
+    SyncPhasePending SyncPhase = "Pending"
+    // SyncPhaseFailed means the last sync returned an error.
+    SyncPhaseFailed SyncPhase = "Failed"
+    // SyncPhaseSuspended means the class is suspended or the namespace paused.
+    SyncPhaseSuspended SyncPhase = "Suspended"
+)
+
 func init() {
     SchemeBuilder.Register(&NamespaceClass{}, &NamespaceClassList{})
 }
\ No newline at end of file
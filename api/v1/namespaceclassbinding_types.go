@@ -0,0 +1,49 @@
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=nscb
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.className`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// NamespaceClassBinding allows users, groups and namespaces to use a
+// NamespaceClass. Classes without bindings can be used by anyone; once a
+// class has a binding, namespaces can only be labeled with it, or list it
+// as an add-on class, by the subjects of one of its bindings.
+type NamespaceClassBinding struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec NamespaceClassBindingSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type NamespaceClassBindingList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []NamespaceClassBinding `json:"items"`
+}
+
+type NamespaceClassBindingSpec struct {
+    // ClassName is the NamespaceClass the binding allows to use.
+    ClassName string `json:"className"`
+
+    // Users who may use the class.
+    // +kubebuilder:validation:Optional
+    Users []string `json:"users,omitempty"`
+
+    // Groups whose members may use the class.
+    // +kubebuilder:validation:Optional
+    Groups []string `json:"groups,omitempty"`
+
+    // Namespaces that may use the class, whoever labels them. Entries are
+    // names or shell patterns such as "team-a-*".
+    // +kubebuilder:validation:Optional
+    Namespaces []string `json:"namespaces,omitempty"`
+}
+
+func init() {
+    SchemeBuilder.Register(&NamespaceClassBinding{}, &NamespaceClassBindingList{})
+}
@@ -0,0 +1,194 @@
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=nscinv
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.metadata.labels.namespaceclass\.akuity\.io/name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// NamespaceClassInventory records the resources the controller manages in a
+// namespace. There is one inventory per namespace, named after it. The object
+// is written only by the controller, so status is not a separate subresource.
+type NamespaceClassInventory struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   NamespaceClassInventorySpec   `json:"spec,omitempty"`
+    Status NamespaceClassInventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type NamespaceClassInventoryList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []NamespaceClassInventory `json:"items"`
+}
+
+type NamespaceClassInventorySpec struct {
+    // Namespace is the namespace whose managed resources are tracked.
+    Namespace string `json:"namespace"`
+
+    // SchemaVersion is the version of the inventory format. Inventories
+    // without a version were written before versioning and are version 1.
+    // +kubebuilder:validation:Optional
+    SchemaVersion int `json:"schemaVersion,omitempty"`
+
+    // Resources lists the resources applied to the namespace.
+    // +kubebuilder:validation:Optional
+    Resources []InventoryEntry `json:"resources,omitempty"`
+
+    // NamespaceLabels lists the labels set on the namespace by its classes.
+    // +kubebuilder:validation:Optional
+    NamespaceLabels []MetadataEntry `json:"namespaceLabels,omitempty"`
+
+    // NamespaceAnnotations lists the annotations set on the namespace by its
+    // classes.
+    // +kubebuilder:validation:Optional
+    NamespaceAnnotations []MetadataEntry `json:"namespaceAnnotations,omitempty"`
+}
+
+// MetadataEntry identifies a label or annotation set on a namespace.
+type MetadataEntry struct {
+    Key string `json:"key"`
+
+    // Class is the NamespaceClass the label or annotation was set for.
+    Class string `json:"class,omitempty"`
+}
+
+// InventoryEntry identifies a single managed resource.
+type InventoryEntry struct {
+    APIVersion string `json:"apiVersion"`
+    Kind       string `json:"kind"`
+    Name       string `json:"name"`
+
+    // Hash is the resource hash recorded at the last apply, prefixed with
+    // the hash algorithm (e.g. "sha256:...").
+    Hash string `json:"hash,omitempty"`
+
+    // Class is the NamespaceClass the resource was applied for.
+    Class string `json:"class,omitempty"`
+
+    // Frozen is set when a tenant froze the resource against updates from the class.
+    Frozen bool `json:"frozen,omitempty"`
+
+    // ClusterScoped is set for cluster-scoped resources created for the
+    // namespace.
+    ClusterScoped bool `json:"clusterScoped,omitempty"`
+
+    // ID is the resource-id annotation of the resource in its class, which
+    // identifies it across renames.
+    ID string `json:"id,omitempty"`
+}
+
+type NamespaceClassInventoryStatus struct {
+    // Conditions represent the latest observations of the namespace's state.
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+    // Resources reports the health of each resource of the class, for
+    // classes that wait for their resources to become ready.
+    // +kubebuilder:validation:Optional
+    Resources []ResourceHealth `json:"resources,omitempty"`
+
+    // ApplyResults reports the outcome of the last apply of each resource
+    // of the classes of the namespace.
+    // +kubebuilder:validation:Optional
+    ApplyResults []ResourceApplyResult `json:"applyResults,omitempty"`
+
+    // Transition is the switch between classes in progress, if any.
+    // +kubebuilder:validation:Optional
+    Transition *ClassTransition `json:"transition,omitempty"`
+}
+
+// ClassTransition tracks a namespace switching from one class to another.
+type ClassTransition struct {
+    // From is the class the namespace is switching from.
+    From string `json:"from"`
+
+    // To is the class the namespace is switching to.
+    To string `json:"to"`
+
+    // Phase is the step of the transition in progress.
+    Phase TransitionPhase `json:"phase"`
+
+    // StartTime is when the transition started.
+    StartTime metav1.Time `json:"startTime,omitempty"`
+}
+
+// TransitionPhase is a step of a switch between classes.
+type TransitionPhase string
+
+const (
+    // TransitionPhasePreSwitch runs the pre-switch hooks of the new class.
+    TransitionPhasePreSwitch TransitionPhase = "PreSwitch"
+    // TransitionPhaseSwitching applies the new class and, once its resources
+    // are ready, prunes those of the previous class.
+    TransitionPhaseSwitching TransitionPhase = "Switching"
+    // TransitionPhasePostSwitch runs the post-switch hooks of the new class.
+    TransitionPhasePostSwitch TransitionPhase = "PostSwitch"
+)
+
+// ResourceApplyResult is the outcome of the last apply of a single resource.
+type ResourceApplyResult struct {
+    APIVersion string `json:"apiVersion"`
+    Kind       string `json:"kind"`
+    Name       string `json:"name"`
+
+    // Result is what the last apply did to the resource.
+    Result ApplyResult `json:"result"`
+
+    // Message is the error of a failed apply.
+    Message string `json:"message,omitempty"`
+
+    // LastTransitionTime is when the result or message last changed.
+    LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ApplyResult is what applying a resource did.
+type ApplyResult string
+
+const (
+    // ApplyResultCreated means the resource did not exist and was created.
+    ApplyResultCreated ApplyResult = "Created"
+    // ApplyResultUpdated means the resource was updated to match its class.
+    ApplyResultUpdated ApplyResult = "Updated"
+    // ApplyResultUnchanged means the resource already matched its class.
+    ApplyResultUnchanged ApplyResult = "Unchanged"
+    // ApplyResultSkipped means the resource was left alone under the conflict policy of its class.
+    ApplyResultSkipped ApplyResult = "Skipped"
+    // ApplyResultFrozen means a tenant froze the resource against updates.
+    ApplyResultFrozen ApplyResult = "Frozen"
+    // ApplyResultFailed means the resource could not be applied.
+    ApplyResultFailed ApplyResult = "Failed"
+)
+
+// ResourceHealth is the health of a single managed resource.
+type ResourceHealth struct {
+    APIVersion string `json:"apiVersion"`
+    Kind       string `json:"kind"`
+    Name       string `json:"name"`
+
+    // Status is the health of the resource.
+    Status HealthStatus `json:"status"`
+
+    // Message explains why the resource is not healthy yet.
+    Message string `json:"message,omitempty"`
+}
+
+// HealthStatus is the health of a resource, modelled after kstatus.
+type HealthStatus string
+
+const (
+    // HealthCurrent means the resource is fully reconciled and ready.
+    HealthCurrent HealthStatus = "Current"
+    // HealthInProgress means the resource is still being reconciled.
+    HealthInProgress HealthStatus = "InProgress"
+    // HealthFailed means the resource will not become ready without changes.
+    HealthFailed HealthStatus = "Failed"
+)
+
+func init() {
+    SchemeBuilder.Register(&NamespaceClassInventory{}, &NamespaceClassInventoryList{})
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=nscrev
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.class`
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.spec.revision`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// NamespaceClassRevision is an immutable snapshot of the spec of a
+// NamespaceClass as it was applied at one generation, named
+// <class>-<revision>. Revisions are written by the controller and used to
+// roll a class, or a single namespace, back to an earlier version.
+type NamespaceClassRevision struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec NamespaceClassRevisionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type NamespaceClassRevisionList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []NamespaceClassRevision `json:"items"`
+}
+
+type NamespaceClassRevisionSpec struct {
+    // Class is the NamespaceClass this is a revision of.
+    Class string `json:"class"`
+
+    // Revision is the generation of the class the snapshot was taken at.
+    Revision int64 `json:"revision"`
+
+    // Hash identifies the content of the template, prefixed with the hash
+    // algorithm (e.g. "sha256:...").
+    Hash string `json:"hash"`
+
+    // Template is the spec of the class at this revision, including its
+    // resource manifests.
+    Template NamespaceClassSpec `json:"template"`
+}
+
+func init() {
+    SchemeBuilder.Register(&NamespaceClassRevision{}, &NamespaceClassRevisionList{})
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.className`
+// +kubebuilder:printcolumn:name="Namespaces",type=integer,JSONPath=`.status.namespaceCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// Tenant groups the namespaces labeled with its name. It assigns them a
+// class, limits how many namespaces the tenant may have and aggregates the
+// quota of its namespaces in its status.
+type Tenant struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   TenantSpec   `json:"spec,omitempty"`
+    Status TenantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type TenantList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []Tenant `json:"items"`
+}
+
+type TenantSpec struct {
+    // ClassName is the NamespaceClass of namespaces of the tenant that do
+    // not choose a class themselves.
+    // +kubebuilder:validation:Optional
+    ClassName string `json:"className,omitempty"`
+
+    // MaxNamespaces limits how many namespaces the tenant may have. Unset
+    // allows any number.
+    // +kubebuilder:validation:Optional
+    // +kubebuilder:validation:Minimum=0
+    MaxNamespaces *int32 `json:"maxNamespaces,omitempty"`
+}
+
+type TenantStatus struct {
+    // ClassName is the class last assigned to the namespaces of the tenant.
+    // +kubebuilder:validation:Optional
+    ClassName string `json:"className,omitempty"`
+
+    // Namespaces are the namespaces of the tenant.
+    // +kubebuilder:validation:Optional
+    Namespaces []string `json:"namespaces,omitempty"`
+
+    // NamespaceCount is the number of namespaces of the tenant.
+    NamespaceCount int `json:"namespaceCount"`
+
+    // Hard is the sum of the hard limits of the ResourceQuotas in the
+    // namespaces of the tenant.
+    // +kubebuilder:validation:Optional
+    Hard corev1.ResourceList `json:"hard,omitempty"`
+
+    // Used is the sum of the usage the ResourceQuotas in the namespaces of
+    // the tenant observe.
+    // +kubebuilder:validation:Optional
+    Used corev1.ResourceList `json:"used,omitempty"`
+
+    // ObservedGeneration is the generation of the tenant last reconciled.
+    // +kubebuilder:validation:Optional
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+    // Conditions represent the latest observations of the tenant's state.
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+func init() {
+    SchemeBuilder.Register(&Tenant{}, &TenantList{})
+}
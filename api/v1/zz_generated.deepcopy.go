@@ -5,12 +5,270 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespaceClass) DeepCopyInto(out *NamespaceClass) {
+func (in *CertificateIssuerRef) DeepCopyInto(out *CertificateIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIssuerRef.
+func (in *CertificateIssuerRef) DeepCopy() *CertificateIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassCertificate) DeepCopyInto(out *ClassCertificate) {
+	*out = *in
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassCertificate.
+func (in *ClassCertificate) DeepCopy() *ClassCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassHooks) DeepCopyInto(out *ClassHooks) {
+	*out = *in
+	if in.PreSwitch != nil {
+		in, out := &in.PreSwitch, &out.PreSwitch
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostSwitch != nil {
+		in, out := &in.PostSwitch, &out.PostSwitch
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassHooks.
+func (in *ClassHooks) DeepCopy() *ClassHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassLimits) DeepCopyInto(out *ClassLimits) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(ResourceValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultRequest != nil {
+		in, out := &in.DefaultRequest, &out.DefaultRequest
+		*out = new(ResourceValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(ResourceValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(ResourceValues)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassLimits.
+func (in *ClassLimits) DeepCopy() *ClassLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassQuota) DeepCopyInto(out *ClassQuota) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LimitsCPU != nil {
+		in, out := &in.LimitsCPU, &out.LimitsCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LimitsMemory != nil {
+		in, out := &in.LimitsMemory, &out.LimitsMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassQuota.
+func (in *ClassQuota) DeepCopy() *ClassQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassServiceAccount) DeepCopyInto(out *ClassServiceAccount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassServiceAccount.
+func (in *ClassServiceAccount) DeepCopy() *ClassServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClassTransition) DeepCopyInto(out *ClassTransition) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClassTransition.
+func (in *ClassTransition) DeepCopy() *ClassTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClassTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReplica) DeepCopyInto(out *ConfigMapReplica) {
+	*out = *in
+	out.Source = in.Source
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReplica.
+func (in *ConfigMapReplica) DeepCopy() *ConfigMapReplica {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReplica)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryEntry) DeepCopyInto(out *InventoryEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryEntry.
+func (in *InventoryEntry) DeepCopy() *InventoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalConfigMapReference) DeepCopyInto(out *LocalConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalConfigMapReference.
+func (in *LocalConfigMapReference) DeepCopy() *LocalConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataEntry) DeepCopyInto(out *MetadataEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataEntry.
+func (in *MetadataEntry) DeepCopy() *MetadataEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClaim) DeepCopyInto(out *NamespaceClaim) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -18,18 +276,18 @@ func (in *NamespaceClass) DeepCopyInto(out *NamespaceClass) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClass.
-func (in *NamespaceClass) DeepCopy() *NamespaceClass {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClaim.
+func (in *NamespaceClaim) DeepCopy() *NamespaceClaim {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceClass)
+	out := new(NamespaceClaim)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NamespaceClass) DeepCopyObject() runtime.Object {
+func (in *NamespaceClaim) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -37,31 +295,31 @@ func (in *NamespaceClass) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespaceClassList) DeepCopyInto(out *NamespaceClassList) {
+func (in *NamespaceClaimList) DeepCopyInto(out *NamespaceClaimList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NamespaceClass, len(*in))
+		*out = make([]NamespaceClaim, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassList.
-func (in *NamespaceClassList) DeepCopy() *NamespaceClassList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClaimList.
+func (in *NamespaceClaimList) DeepCopy() *NamespaceClaimList {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceClassList)
+	out := new(NamespaceClaimList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NamespaceClassList) DeepCopyObject() runtime.Object {
+func (in *NamespaceClaimList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -69,30 +327,51 @@ func (in *NamespaceClassList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespaceClassSpec) DeepCopyInto(out *NamespaceClassSpec) {
+func (in *NamespaceClaimSpec) DeepCopyInto(out *NamespaceClaimSpec) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = make([]runtime.RawExtension, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.AddonClasses != nil {
+		in, out := &in.AddonClasses, &out.AddonClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassSpec.
-func (in *NamespaceClassSpec) DeepCopy() *NamespaceClassSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClaimSpec.
+func (in *NamespaceClaimSpec) DeepCopy() *NamespaceClaimSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceClassSpec)
+	out := new(NamespaceClaimSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespaceClassStatus) DeepCopyInto(out *NamespaceClassStatus) {
+func (in *NamespaceClaimStatus) DeepCopyInto(out *NamespaceClaimStatus) {
 	*out = *in
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -100,20 +379,843 @@ func (in *NamespaceClassStatus) DeepCopyInto(out *NamespaceClassStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	if in.ManagedNamespaces != nil {
-		in, out := &in.ManagedNamespaces, &out.ManagedNamespaces
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClaimStatus.
+func (in *NamespaceClaimStatus) DeepCopy() *NamespaceClaimStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(NamespaceClaimStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassStatus.
-func (in *NamespaceClassStatus) DeepCopy() *NamespaceClassStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClass) DeepCopyInto(out *NamespaceClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClass.
+func (in *NamespaceClass) DeepCopy() *NamespaceClass {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceClassStatus)
+	out := new(NamespaceClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassBinding) DeepCopyInto(out *NamespaceClassBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassBinding.
+func (in *NamespaceClassBinding) DeepCopy() *NamespaceClassBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassBindingList) DeepCopyInto(out *NamespaceClassBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceClassBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassBindingList.
+func (in *NamespaceClassBindingList) DeepCopy() *NamespaceClassBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassBindingSpec) DeepCopyInto(out *NamespaceClassBindingSpec) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassBindingSpec.
+func (in *NamespaceClassBindingSpec) DeepCopy() *NamespaceClassBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassInventory) DeepCopyInto(out *NamespaceClassInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassInventory.
+func (in *NamespaceClassInventory) DeepCopy() *NamespaceClassInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassInventoryList) DeepCopyInto(out *NamespaceClassInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceClassInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassInventoryList.
+func (in *NamespaceClassInventoryList) DeepCopy() *NamespaceClassInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassInventorySpec) DeepCopyInto(out *NamespaceClassInventorySpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]InventoryEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceLabels != nil {
+		in, out := &in.NamespaceLabels, &out.NamespaceLabels
+		*out = make([]MetadataEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceAnnotations != nil {
+		in, out := &in.NamespaceAnnotations, &out.NamespaceAnnotations
+		*out = make([]MetadataEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassInventorySpec.
+func (in *NamespaceClassInventorySpec) DeepCopy() *NamespaceClassInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassInventoryStatus) DeepCopyInto(out *NamespaceClassInventoryStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceHealth, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApplyResults != nil {
+		in, out := &in.ApplyResults, &out.ApplyResults
+		*out = make([]ResourceApplyResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Transition != nil {
+		in, out := &in.Transition, &out.Transition
+		*out = new(ClassTransition)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassInventoryStatus.
+func (in *NamespaceClassInventoryStatus) DeepCopy() *NamespaceClassInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassList) DeepCopyInto(out *NamespaceClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassList.
+func (in *NamespaceClassList) DeepCopy() *NamespaceClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassRevision) DeepCopyInto(out *NamespaceClassRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassRevision.
+func (in *NamespaceClassRevision) DeepCopy() *NamespaceClassRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassRevisionList) DeepCopyInto(out *NamespaceClassRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceClassRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassRevisionList.
+func (in *NamespaceClassRevisionList) DeepCopy() *NamespaceClassRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceClassRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassRevisionSpec) DeepCopyInto(out *NamespaceClassRevisionSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassRevisionSpec.
+func (in *NamespaceClassRevisionSpec) DeepCopy() *NamespaceClassRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassSpec) DeepCopyInto(out *NamespaceClassSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterResources != nil {
+		in, out := &in.ClusterResources, &out.ClusterResources
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = new(ValuesSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdmissionWarnings != nil {
+		in, out := &in.AdmissionWarnings, &out.AdmissionWarnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdateWindows != nil {
+		in, out := &in.UpdateWindows, &out.UpdateWindows
+		*out = make([]UpdateWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadyTimeout != nil {
+		in, out := &in.ReadyTimeout, &out.ReadyTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(ClassHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceMetadata != nil {
+		in, out := &in.NamespaceMetadata, &out.NamespaceMetadata
+		*out = new(NamespaceMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurity != nil {
+		in, out := &in.PodSecurity, &out.PodSecurity
+		*out = new(PodSecurity)
+		**out = **in
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ClassQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(ClassLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RBAC != nil {
+		in, out := &in.RBAC, &out.RBAC
+		*out = make([]RoleGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ClassServiceAccount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]ConfigMapReplica, len(*in))
+		copy(*out, *in)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]ClassCertificate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassSpec.
+func (in *NamespaceClassSpec) DeepCopy() *NamespaceClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceClassStatus) DeepCopyInto(out *NamespaceClassStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.ManagedNamespaces != nil {
+		in, out := &in.ManagedNamespaces, &out.ManagedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceSyncStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceClassStatus.
+func (in *NamespaceClassStatus) DeepCopy() *NamespaceClassStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceClassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceMetadata) DeepCopyInto(out *NamespaceMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMetadata.
+func (in *NamespaceMetadata) DeepCopy() *NamespaceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSyncStatus) DeepCopyInto(out *NamespaceSyncStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSyncStatus.
+func (in *NamespaceSyncStatus) DeepCopy() *NamespaceSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectSource) DeepCopyInto(out *ObjectSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSource.
+func (in *ObjectSource) DeepCopy() *ObjectSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSecurity) DeepCopyInto(out *PodSecurity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecurity.
+func (in *PodSecurity) DeepCopy() *PodSecurity {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSecurity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceApplyResult) DeepCopyInto(out *ResourceApplyResult) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceApplyResult.
+func (in *ResourceApplyResult) DeepCopy() *ResourceApplyResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceApplyResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceHealth) DeepCopyInto(out *ResourceHealth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealth.
+func (in *ResourceHealth) DeepCopy() *ResourceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceValues) DeepCopyInto(out *ResourceValues) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceValues.
+func (in *ResourceValues) DeepCopy() *ResourceValues {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceValues)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleGrant) DeepCopyInto(out *RoleGrant) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ServiceAccountSubject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleGrant.
+func (in *RoleGrant) DeepCopy() *RoleGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountSubject) DeepCopyInto(out *ServiceAccountSubject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountSubject.
+func (in *ServiceAccountSubject) DeepCopy() *ServiceAccountSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tenant) DeepCopyInto(out *Tenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tenant.
+func (in *Tenant) DeepCopy() *Tenant {
+	if in == nil {
+		return nil
+	}
+	out := new(Tenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Tenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantList) DeepCopyInto(out *TenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Tenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantList.
+func (in *TenantList) DeepCopy() *TenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
+	*out = *in
+	if in.MaxNamespaces != nil {
+		in, out := &in.MaxNamespaces, &out.MaxNamespaces
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantSpec.
+func (in *TenantSpec) DeepCopy() *TenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantStatus) DeepCopyInto(out *TenantStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantStatus.
+func (in *TenantStatus) DeepCopy() *TenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateWindow) DeepCopyInto(out *UpdateWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateWindow.
+func (in *UpdateWindow) DeepCopy() *UpdateWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesSource) DeepCopyInto(out *ValuesSource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(LocalConfigMapReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesSource.
+func (in *ValuesSource) DeepCopy() *ValuesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesSource)
 	in.DeepCopyInto(out)
 	return out
 }
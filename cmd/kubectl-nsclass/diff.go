@@ -0,0 +1,241 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/pmezard/go-difflib/difflib"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/yaml"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// ANSI colors of diff lines
+const (
+    colorReset = "\x1b[0m"
+    colorBold  = "\x1b[1m"
+    colorRed   = "\x1b[31m"
+    colorGreen = "\x1b[32m"
+    colorCyan  = "\x1b[36m"
+)
+
+// runDiff renders the class of a namespace and writes a unified diff for
+// every resource the controller would create, update or prune. It reports
+// whether there are any differences.
+func runDiff(ctx context.Context, c client.Client, namespace, className string, out io.Writer, color bool) (bool, error) {
+    // The namespace may not exist yet when the class is given
+    ns := &corev1.Namespace{}
+    if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+        if className == "" || !errors.IsNotFound(err) {
+            return false, err
+        }
+        ns.Name = namespace
+    }
+    if className == "" {
+        className = ns.Labels[controller.LabelKey]
+        if className == "" {
+            return false, fmt.Errorf("namespace %s has no %s label; pass --class", namespace, controller.LabelKey)
+        }
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := c.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        return false, err
+    }
+    desired, err := controller.RenderNamespaceClass(ctx, c, nsc, ns)
+    if err != nil {
+        return false, err
+    }
+
+    changed := false
+    desiredKeys := make(map[string]bool)
+    for _, res := range desired {
+        desiredKeys[resourceKey(res.GetAPIVersion(), res.GetKind(), res.GetName())] = true
+        live, err := getLive(ctx, c, res.GetAPIVersion(), res.GetKind(), res.GetNamespace(), res.GetName())
+        if err != nil {
+            return false, err
+        }
+        var before interface{}
+        if live != nil {
+            if !controller.NeedsUpdate(live, res, nsc) {
+                continue
+            }
+            // Fields only set on the live object are mostly apiserver defaults
+            before = restrictTo(controller.ComparableObject(live, res, nsc), controller.ComparableObject(res, res, nsc))
+        }
+        if err := writeDiff(out, res.GetKind(), res.GetName(), before, controller.ComparableObject(res, res, nsc), color); err != nil {
+            return false, err
+        }
+        changed = true
+    }
+
+    // Resources the inventory tracks that the class no longer renders are pruned
+    inv := &v1.NamespaceClassInventory{}
+    if err := c.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil && !errors.IsNotFound(err) {
+        return false, err
+    }
+    for _, entry := range inv.Spec.Resources {
+        if desiredKeys[resourceKey(entry.APIVersion, entry.Kind, entry.Name)] {
+            continue
+        }
+        liveNamespace := namespace
+        if entry.ClusterScoped {
+            liveNamespace = ""
+        }
+        live, err := getLive(ctx, c, entry.APIVersion, entry.Kind, liveNamespace, entry.Name)
+        if err != nil {
+            return false, err
+        }
+        if live == nil {
+            continue
+        }
+        if err := writeDiff(out, entry.Kind, entry.Name, controller.ComparableObject(live, live, nsc), nil, color); err != nil {
+            return false, err
+        }
+        changed = true
+    }
+    return changed, nil
+}
+
+func resourceKey(apiVersion, kind, name string) string {
+    return fmt.Sprintf("%s/%s/%s", apiVersion, kind, name)
+}
+
+// getLive returns the live object, or nil if it does not exist.
+func getLive(ctx context.Context, c client.Client, apiVersion, kind, namespace, name string) (*unstructured.Unstructured, error) {
+    live := &unstructured.Unstructured{}
+    live.SetAPIVersion(apiVersion)
+    live.SetKind(kind)
+    if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, live); err != nil {
+        if errors.IsNotFound(err) {
+            return nil, nil
+        }
+        return nil, err
+    }
+    return live, nil
+}
+
+// restrictTo drops the fields of live that desired does not set. Lists of
+// different length are kept whole.
+func restrictTo(live, desired interface{}) interface{} {
+    switch d := desired.(type) {
+    case map[string]interface{}:
+        l, ok := live.(map[string]interface{})
+        if !ok {
+            return live
+        }
+        restricted := make(map[string]interface{}, len(d))
+        for key, value := range d {
+            if liveValue, ok := l[key]; ok {
+                restricted[key] = restrictTo(liveValue, value)
+            }
+        }
+        return restricted
+    case []interface{}:
+        l, ok := live.([]interface{})
+        if !ok || len(l) != len(d) {
+            return live
+        }
+        restricted := make([]interface{}, len(l))
+        for i := range l {
+            restricted[i] = restrictTo(l[i], d[i])
+        }
+        return restricted
+    }
+    return live
+}
+
+// writeDiff writes a unified diff between the YAML of two objects; a nil
+// object stands for one that does not exist. The data of Secrets is masked.
+func writeDiff(out io.Writer, kind, name string, before, after interface{}, color bool) error {
+    if kind == "Secret" {
+        before, after = maskSecretData(before, after)
+    }
+    a, err := toYAML(before)
+    if err != nil {
+        return err
+    }
+    b, err := toYAML(after)
+    if err != nil {
+        return err
+    }
+    diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+        A:        difflib.SplitLines(a),
+        B:        difflib.SplitLines(b),
+        FromFile: "live/" + kind + "/" + name,
+        ToFile:   "rendered/" + kind + "/" + name,
+        Context:  3,
+    })
+    if err != nil {
+        return err
+    }
+    if color {
+        diff = colorize(diff)
+    }
+    _, err = io.WriteString(out, diff)
+    return err
+}
+
+// maskSecretData returns copies of two versions of a Secret with the values
+// of data and stringData masked, as kubectl diff does: values that are the
+// same in both are shown as "***", and values that differ as "*** (before)"
+// and "*** (after)", so the diff shows which keys change without revealing
+// them.
+func maskSecretData(before, after interface{}) (interface{}, interface{}) {
+    before, after = runtime.DeepCopyJSONValue(before), runtime.DeepCopyJSONValue(after)
+    b, _ := before.(map[string]interface{})
+    a, _ := after.(map[string]interface{})
+    for _, field := range []string{"data", "stringData"} {
+        bData, _ := b[field].(map[string]interface{})
+        aData, _ := a[field].(map[string]interface{})
+        for key, value := range bData {
+            if afterValue, ok := aData[key]; ok && afterValue != value {
+                bData[key], aData[key] = "*** (before)", "*** (after)"
+                continue
+            }
+            bData[key] = "***"
+            if _, ok := aData[key]; ok {
+                aData[key] = "***"
+            }
+        }
+        for key := range aData {
+            if _, ok := bData[key]; !ok {
+                aData[key] = "***"
+            }
+        }
+    }
+    return before, after
+}
+
+func toYAML(obj interface{}) (string, error) {
+    if obj == nil {
+        return "", nil
+    }
+    data, err := yaml.Marshal(obj)
+    return string(data), err
+}
+
+func colorize(diff string) string {
+    lines := strings.SplitAfter(diff, "\n")
+    for i, line := range lines {
+        switch {
+        case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+            lines[i] = colorBold + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+        case strings.HasPrefix(line, "@@"):
+            lines[i] = colorCyan + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+        case strings.HasPrefix(line, "-"):
+            lines[i] = colorRed + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+        case strings.HasPrefix(line, "+"):
+            lines[i] = colorGreen + strings.TrimSuffix(line, "\n") + colorReset + "\n"
+        }
+    }
+    return strings.Join(lines, "")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestDiff(t *testing.T) {
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "public"},
+        Spec: v1.NamespaceClassSpec{
+            ComparisonMode: v1.ComparisonModeSemantic,
+            Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"mode":"strict"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ResourceQuota","metadata":{"name":"limits"},"spec":{"hard":{"cpu":"1000m"}}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"deployer"}}`)},
+            },
+        },
+    }
+    managed := map[string]string{
+        controller.ManagedByAnnotation:      controller.ManagedByValue,
+        controller.CreatedByClassAnnotation: "public",
+    }
+    c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{controller.LabelKey: "public"}}},
+        nsc,
+        &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassInventorySpec{Namespace: "web", Resources: []v1.InventoryEntry{
+                {APIVersion: "v1", Kind: "ConfigMap", Name: "settings"},
+                {APIVersion: "v1", Kind: "ResourceQuota", Name: "limits"},
+                {APIVersion: "v1", Kind: "ConfigMap", Name: "legacy"},
+            }},
+        },
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "web", Annotations: managed},
+            Data:       map[string]string{"mode": "relaxed", "extra": "kept"},
+        },
+        &corev1.ResourceQuota{
+            ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "web", Annotations: managed},
+            Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+        },
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "web", Annotations: managed},
+            Data:       map[string]string{"old": "true"},
+        },
+    ).Build()
+
+    var out bytes.Buffer
+    changed, err := runDiff(context.Background(), c, "web", "", &out, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !changed {
+        t.Fatal("expected differences")
+    }
+    diff := out.String()
+    for _, want := range []string{
+        "--- live/ConfigMap/settings\n+++ rendered/ConfigMap/settings\n",
+        "-  mode: relaxed\n+  mode: strict\n",
+        "+++ rendered/ServiceAccount/deployer\n",
+        "--- live/ConfigMap/legacy\n",
+        "-  old: \"true\"\n",
+    } {
+        if !strings.Contains(diff, want) {
+            t.Errorf("diff does not contain %q:\n%s", want, diff)
+        }
+    }
+    // Semantically equal objects and fields the class does not set are not shown
+    for _, unwanted := range []string{"limits", "extra"} {
+        if strings.Contains(diff, unwanted) {
+            t.Errorf("diff unexpectedly contains %q:\n%s", unwanted, diff)
+        }
+    }
+}
+
+func TestDiffMasksSecrets(t *testing.T) {
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "public"},
+        Spec: v1.NamespaceClassSpec{
+            ComparisonMode: v1.ComparisonModeSemantic,
+            Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"registry"},"data":{"user":"YWRtaW4=","password":"bmV3LXBhc3N3b3Jk"}}`)},
+            },
+        },
+    }
+    managed := map[string]string{
+        controller.ManagedByAnnotation:      controller.ManagedByValue,
+        controller.CreatedByClassAnnotation: "public",
+    }
+    c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{controller.LabelKey: "public"}}},
+        nsc,
+        &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassInventorySpec{Namespace: "web", Resources: []v1.InventoryEntry{
+                {APIVersion: "v1", Kind: "Secret", Name: "registry"},
+                {APIVersion: "v1", Kind: "Secret", Name: "legacy-token"},
+            }},
+        },
+        &corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "web", Annotations: managed},
+            Data:       map[string][]byte{"user": []byte("admin"), "password": []byte("old-password")},
+        },
+        &corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Name: "legacy-token", Namespace: "web", Annotations: managed},
+            Data:       map[string][]byte{"token": []byte("s3cr3t")},
+        },
+    ).Build()
+
+    var out bytes.Buffer
+    changed, err := runDiff(context.Background(), c, "web", "", &out, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !changed {
+        t.Fatal("expected differences")
+    }
+    diff := out.String()
+    for _, want := range []string{
+        "--- live/Secret/registry\n+++ rendered/Secret/registry\n",
+        "-  password: '*** (before)'\n+  password: '*** (after)'\n",
+        "   user: '***'\n",
+        "--- live/Secret/legacy-token\n",
+        "-  token: '***'\n",
+    } {
+        if !strings.Contains(diff, want) {
+            t.Errorf("diff does not contain %q:\n%s", want, diff)
+        }
+    }
+    // Neither the encoded nor the decoded values are shown
+    for _, secret := range []string{"YWRtaW4=", "bmV3LXBhc3N3b3Jk", "b2xkLXBhc3N3b3Jk", "czNjcjN0", "old-password", "s3cr3t"} {
+        if strings.Contains(diff, secret) {
+            t.Errorf("diff reveals secret value %q:\n%s", secret, diff)
+        }
+    }
+}
@@ -0,0 +1,197 @@
+// kubectl-nsclass is a kubectl plugin for working with NamespaceClasses.
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+
+    "golang.org/x/term"
+    "k8s.io/apimachinery/pkg/runtime"
+    utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    _ "k8s.io/client-go/plugin/pkg/client/auth"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/backup"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+    utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+    utilruntime.Must(v1.AddToScheme(scheme))
+}
+
+const usage = `Usage: kubectl nsclass <command> [flags]
+
+Commands:
+  diff        Show what the controller would change in a namespace
+  dump        Print the resources the controller manages in a namespace
+  history     List the revisions of a class
+  missing     List namespaces that refer to classes that do not exist
+  rollback    Roll a class, or pin a namespace, back to a revision
+`
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+    switch os.Args[1] {
+    case "diff":
+        changed, err := diffCommand(os.Args[2:])
+        if err != nil {
+            fmt.Fprintln(os.Stderr, "error:", err)
+            os.Exit(2)
+        }
+        // Like kubectl diff, exit with 1 when there are differences
+        if changed {
+            os.Exit(1)
+        }
+    case "dump":
+        if err := dumpCommand(os.Args[2:]); err != nil {
+            fmt.Fprintln(os.Stderr, "error:", err)
+            os.Exit(2)
+        }
+    case "missing":
+        found, err := missingCommand(os.Args[2:])
+        if err != nil {
+            fmt.Fprintln(os.Stderr, "error:", err)
+            os.Exit(2)
+        }
+        // Exit with 1 when namespaces refer to missing classes, so CI can check for them
+        if found {
+            os.Exit(1)
+        }
+    case "history", "rollback":
+        if err := revisionCommand(os.Args[1], os.Args[2:]); err != nil {
+            fmt.Fprintln(os.Stderr, "error:", err)
+            os.Exit(2)
+        }
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+func diffCommand(args []string) (bool, error) {
+    // The --kubeconfig flag is registered on the default flag set by controller-runtime
+    fs := flag.CommandLine
+    fs.Init("diff", flag.ExitOnError)
+    var namespace, className, colorMode string
+    fs.StringVar(&namespace, "namespace", "", "Namespace to diff.")
+    fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+    fs.StringVar(&className, "class", "", "Class to render instead of the one the namespace is labeled with.")
+    fs.StringVar(&colorMode, "color", "auto", "Color the diff: auto, always or never.")
+    if err := fs.Parse(args); err != nil {
+        return false, err
+    }
+    if namespace == "" {
+        return false, fmt.Errorf("--namespace is required")
+    }
+
+    var color bool
+    switch colorMode {
+    case "auto":
+        color = term.IsTerminal(int(os.Stdout.Fd()))
+    case "always":
+        color = true
+    case "never":
+    default:
+        return false, fmt.Errorf("invalid --color %q", colorMode)
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return false, err
+    }
+    return runDiff(context.Background(), c, namespace, className, os.Stdout, color)
+}
+
+func dumpCommand(args []string) error {
+    fs := flag.CommandLine
+    fs.Init("dump", flag.ExitOnError)
+    var namespace string
+    fs.StringVar(&namespace, "namespace", "", "Namespace to dump.")
+    fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if namespace == "" {
+        return fmt.Errorf("--namespace is required")
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    state, err := backup.ExportNamespace(context.Background(), c, namespace)
+    if err != nil {
+        return err
+    }
+    for _, entry := range state.Missing {
+        fmt.Fprintf(os.Stderr, "warning: %s %s is tracked in the inventory but does not exist\n", entry.Kind, entry.Name)
+    }
+    return backup.WriteNamespace(os.Stdout, state)
+}
+
+func missingCommand(args []string) (bool, error) {
+    fs := flag.CommandLine
+    fs.Init("missing", flag.ExitOnError)
+    if err := fs.Parse(args); err != nil {
+        return false, err
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return false, err
+    }
+    return runMissing(context.Background(), c, os.Stdout)
+}
+
+func revisionCommand(command string, args []string) error {
+    fs := flag.CommandLine
+    fs.Init(command, flag.ExitOnError)
+    var className, namespace string
+    var revision int64
+    fs.StringVar(&className, "class", "", "Class to list or roll back.")
+    if command == "rollback" {
+        fs.StringVar(&namespace, "namespace", "", "Pin only this namespace to the revision instead of rolling back the class.")
+        fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+        fs.Int64Var(&revision, "to-revision", 0, "Revision to roll back to. 0 selects the previous revision of a class, or unpins a namespace.")
+    }
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if (className == "") == (namespace == "") {
+        if command == "history" {
+            return fmt.Errorf("--class is required")
+        }
+        return fmt.Errorf("exactly one of --class and --namespace is required")
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    if command == "history" {
+        return runHistory(context.Background(), c, className, os.Stdout)
+    }
+    return runRollback(context.Background(), c, className, namespace, revision, os.Stdout)
+}
+
+func newClient() (client.Client, error) {
+    cfg, err := ctrl.GetConfig()
+    if err != nil {
+        return nil, err
+    }
+    c, err := client.New(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return nil, fmt.Errorf("failed to create client: %w", err)
+    }
+    return c, nil
+}
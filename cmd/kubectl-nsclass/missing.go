@@ -0,0 +1,37 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "text/tabwriter"
+
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// runMissing lists the namespaces that refer to classes that do not exist,
+// with a similarly named class where there is one, and reports whether
+// there were any.
+func runMissing(ctx context.Context, c client.Client, out io.Writer) (bool, error) {
+    missing, err := controller.ListMissingClasses(ctx, c)
+    if err != nil {
+        return false, err
+    }
+    if len(missing) == 0 {
+        fmt.Fprintln(out, "No namespaces refer to missing classes.")
+        return false, nil
+    }
+
+    w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "NAMESPACE\tCLASS\tDID YOU MEAN")
+    for _, m := range missing {
+        suggestion := m.Suggestion
+        if suggestion == "" {
+            suggestion = "<none>"
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\n", m.Namespace, m.Class, suggestion)
+    }
+    return true, w.Flush()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/apimachinery/pkg/util/duration"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// runHistory lists the revisions of a class with the namespaces pinned to
+// each of them.
+func runHistory(ctx context.Context, c client.Client, className string, out io.Writer) error {
+    nsc := &v1.NamespaceClass{}
+    if err := c.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        return err
+    }
+    revisions, err := controller.ListRevisions(ctx, c, className)
+    if err != nil {
+        return err
+    }
+    var nsList corev1.NamespaceList
+    if err := c.List(ctx, &nsList, client.MatchingLabels{controller.LabelKey: className}); err != nil {
+        return err
+    }
+    pinned := make(map[string][]string)
+    for _, ns := range nsList.Items {
+        if revision := ns.Annotations[controller.RevisionAnnotation]; revision != "" {
+            pinned[revision] = append(pinned[revision], ns.Name)
+        }
+    }
+
+    w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "REVISION\tRESOURCES\tAGE\tPINNED NAMESPACES")
+    for _, revision := range revisions {
+        number := strconv.FormatInt(revision.Spec.Revision, 10)
+        label := number
+        if revision.Spec.Revision == nsc.Generation {
+            label += " (current)"
+        }
+        age := "<unknown>"
+        if !revision.CreationTimestamp.IsZero() {
+            age = duration.HumanDuration(time.Since(revision.CreationTimestamp.Time))
+        }
+        pins := strings.Join(pinned[number], ",")
+        if pins == "" {
+            pins = "<none>"
+        }
+        fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", label, len(revision.Spec.Template.Resources), age, pins)
+    }
+    return w.Flush()
+}
+
+// runRollback rolls a class back to a revision, or pins a namespace to one
+// when namespace is set.
+func runRollback(ctx context.Context, c client.Client, className, namespace string, revision int64, out io.Writer) error {
+    if namespace != "" {
+        if err := controller.PinNamespace(ctx, c, namespace, revision); err != nil {
+            return err
+        }
+        if revision == 0 {
+            fmt.Fprintf(out, "namespace %s follows its class again\n", namespace)
+        } else {
+            fmt.Fprintf(out, "namespace %s pinned to revision %d\n", namespace, revision)
+        }
+        return nil
+    }
+    target, err := controller.RollbackClass(ctx, c, className, revision)
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(out, "class %s rolled back to revision %d\n", className, target.Spec.Revision)
+    return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/nickleefly/namespace-class-controller/internal/backup"
+)
+
+// runBackupCommand runs the export and import subcommands, which snapshot and
+// restore classes, namespace bindings, and inventories for disaster recovery.
+func runBackupCommand(command string, args []string) error {
+    fs := flag.NewFlagSet(command, flag.ExitOnError)
+    file := fs.String("file", "-", "Bundle file to write (export) or read (import); - for stdout/stdin.")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    
+    c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+    if err != nil {
+        return fmt.Errorf("failed to create client: %w", err)
+    }
+    ctx := context.Background()
+    
+    switch command {
+    case "export":
+        bundle, err := backup.Export(ctx, c)
+        if err != nil {
+            return err
+        }
+        var w io.Writer = os.Stdout
+        if *file != "-" {
+            f, err := os.Create(*file)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            w = f
+        }
+        return backup.Write(w, bundle)
+    case "import":
+        var r io.Reader = os.Stdin
+        if *file != "-" {
+            f, err := os.Open(*file)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            r = f
+        }
+        bundle, err := backup.Read(r)
+        if err != nil {
+            return err
+        }
+        return backup.Import(ctx, c, bundle)
+    }
+    return fmt.Errorf("unknown command %q", command)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+    "context"
+    "os"
+    "os/signal"
+    "sort"
+    "syscall"
+
+    "github.com/nickleefly/namespace-class-controller/internal/config"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// Flags whose configuration file values are applied again on SIGHUP
+var reloadableFlags = map[string]bool{
+    "excluded-namespaces": true,
+}
+
+// reloadOnSIGHUP re-reads the configuration file whenever the process
+// receives SIGHUP until ctx is done. The excluded namespaces are applied
+// right away; changes to other settings are logged and take effect on the
+// next restart. Flags set on the command line or from the environment keep
+// overriding the file, and a file that fails to load leaves the running
+// configuration unchanged.
+func reloadOnSIGHUP(ctx context.Context, path string, loaded *config.Configuration, commandLine map[string]bool, scope *controller.WatchScope) {
+    signals := make(chan os.Signal, 1)
+    signal.Notify(signals, syscall.SIGHUP)
+    go func() {
+        defer signal.Stop(signals)
+        current := loaded.Values()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-signals:
+            }
+            reloaded, err := config.Load(path)
+            if err != nil {
+                setupLog.Error(err, "Failed to reload configuration, keeping the running one", "file", path)
+                continue
+            }
+            values := reloaded.Values()
+            if !commandLine["excluded-namespaces"] {
+                excluded := splitList(values["excluded-namespaces"])
+                scope.SetExcluded(excluded)
+                current["excluded-namespaces"] = values["excluded-namespaces"]
+                setupLog.Info("Reloaded configuration", "file", path, "excludedNamespaces", excluded)
+            }
+
+            var restart []string
+            for name := range union(current, values) {
+                if !reloadableFlags[name] && !commandLine[name] && current[name] != values[name] {
+                    restart = append(restart, name)
+                }
+            }
+            if len(restart) > 0 {
+                sort.Strings(restart)
+                setupLog.Info("Configuration changes take effect after a restart", "file", path, "flags", restart)
+            }
+        }
+    }()
+}
+
+// union returns the keys of two sets of flag values.
+func union(a, b map[string]string) map[string]bool {
+    keys := make(map[string]bool, len(a)+len(b))
+    for name := range a {
+        keys[name] = true
+    }
+    for name := range b {
+        keys[name] = true
+    }
+    return keys
+}
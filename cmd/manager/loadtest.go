@@ -0,0 +1,65 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "time"
+
+    "k8s.io/client-go/rest"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/envtest"
+    "sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+    "github.com/nickleefly/namespace-class-controller/internal/loadtest"
+)
+
+// runLoadTest runs the loadtest subcommand, which measures reconcile
+// throughput against envtest or a sandbox cluster.
+func runLoadTest(args []string) error {
+    var (
+        opts       loadtest.Options
+        useEnvtest bool
+        crdPath    string
+    )
+    fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+    fs.IntVar(&opts.Namespaces, "namespaces", 1000, "Number of namespaces to create.")
+    fs.IntVar(&opts.Classes, "classes", 10, "Number of classes to spread the namespaces over.")
+    fs.IntVar(&opts.ResourcesPerClass, "resources", 5, "Number of resources in each class.")
+    fs.DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "How long to wait for all namespaces to sync.")
+    fs.BoolVar(&opts.Cleanup, "cleanup", true, "Delete the generated classes and namespaces afterwards.")
+    fs.BoolVar(&useEnvtest, "envtest", false,
+        "Run against a local envtest control plane (requires KUBEBUILDER_ASSETS) instead of the current kubeconfig.")
+    fs.StringVar(&crdPath, "crd-path", "config/crd", "Directory with the CRDs installed into envtest.")
+    zapOpts := zap.Options{}
+    zapOpts.BindFlags(fs)
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
+    if opts.Namespaces <= 0 || opts.Classes <= 0 {
+        return fmt.Errorf("--namespaces and --classes must be positive")
+    }
+    
+    var cfg *rest.Config
+    if useEnvtest {
+        env := &envtest.Environment{CRDDirectoryPaths: []string{crdPath}, ErrorIfCRDPathMissing: true}
+        var err error
+        if cfg, err = env.Start(); err != nil {
+            return fmt.Errorf("failed to start envtest: %w", err)
+        }
+        defer env.Stop()
+    } else {
+        cfg = ctrl.GetConfigOrDie()
+    }
+    // Client-side throttling would measure the rate limiter instead of the controller
+    cfg.QPS, cfg.Burst = 1000, 2000
+    
+    report, err := loadtest.Run(context.Background(), cfg, scheme, opts)
+    if err != nil {
+        return err
+    }
+    report.Print(os.Stdout)
+    return nil
+}
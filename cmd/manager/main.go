@@ -1,20 +1,40 @@
 package main
 
 import (
+    "context"
+    "errors"
     "flag"
+    "fmt"
+    "net/http"
     "os"
+    "slices"
+    "strings"
+    "time"
+    // Update windows may use any time zone, whether or not the image has tzdata
+    _ "time/tzdata"
 
     "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
     utilruntime "k8s.io/apimachinery/pkg/util/runtime"
     clientgoscheme "k8s.io/client-go/kubernetes/scheme"
     _ "k8s.io/client-go/plugin/pkg/client/auth"
+    "k8s.io/client-go/rest"
     ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/cache"
+    "sigs.k8s.io/controller-runtime/pkg/client"
     "sigs.k8s.io/controller-runtime/pkg/healthz"
     "sigs.k8s.io/controller-runtime/pkg/log/zap"
     metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+    "sigs.k8s.io/controller-runtime/pkg/webhook"
 
     v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/audit"
+    "github.com/nickleefly/namespace-class-controller/internal/config"
     "github.com/nickleefly/namespace-class-controller/internal/controller"
+    "github.com/nickleefly/namespace-class-controller/internal/features"
+    "github.com/nickleefly/namespace-class-controller/internal/metricsauth"
+    "github.com/nickleefly/namespace-class-controller/internal/notify"
+    nscwebhook "github.com/nickleefly/namespace-class-controller/internal/webhook"
     // +kubebuilder:scaffold:imports
 )
 
@@ -30,10 +50,88 @@ func init() {
 }
 
 func main() {
+    if len(os.Args) > 1 && (os.Args[1] == "export" || os.Args[1] == "import") {
+        if err := runBackupCommand(os.Args[1], os.Args[2:]); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        return
+    }
+    if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+        if err := runLoadTest(os.Args[2:]); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        return
+    }
+
     var (
         metricsAddr          string
         probeAddr            string
         enableLeaderElection bool
+        metricsTeamLabel     string
+        metricsTeamValues    string
+        maintenanceConfigMap string
+        imagePullSecret      string
+        impersonateNamespace string
+        permissionPreflight  bool
+        allowedKinds         string
+        deniedKinds          string
+        maxClassResources    int
+        enableWebhooks       bool
+        webhookPort          int
+        warmUpQPS            float64
+        warmUpBurst          int
+        warmUpConcurrency    int
+        orphanScanInterval   time.Duration
+        deleteOrphans        bool
+        startupSweep         bool
+        tracing              tracingOptions
+        notifySlackURL       string
+        notifyWebhookURL     string
+        notifyCloudEventsURL string
+        notifySource         string
+        notifyThreshold      int
+        auditFile            string
+        auditURL             string
+        auditActor           string
+        revisionHistoryLimit int
+        dataProtection       bool
+        protectedKinds       string
+        shard                int
+        totalShards          int
+        watchNamespaces      string
+        kubeAPIQPS           float64
+        kubeAPIBurst         int
+        classRolloutQPS      float64
+        classRolloutBurst    int
+        classRolloutJitter   time.Duration
+        requeueJitter        float64
+        applyQPSPerKind      float64
+        applyBurstPerKind    int
+        applyRateLimits      string
+        statusBatchWindow    time.Duration
+        missingClassRetry    time.Duration
+        missingClassMaxRetry time.Duration
+        configFile           string
+        leaderElectionID     string
+        maxConcurrent        int
+        syncPeriod           time.Duration
+        excludedNamespaces   string
+        systemNamespaces     string
+        optIn                bool
+        featureGates         features.Gates
+        secureMetrics        bool
+        metricsCertDir       string
+        stallTimeout         time.Duration
+        drainTimeout         time.Duration
+        webhookCertDir       string
+        webhookCertSecret    string
+        webhookService       string
+        breakerRatio         float64
+        breakerMinReconciles int
+        breakerWindow        time.Duration
+        breakerCooldown      time.Duration
     )
     
     opts := zap.Options{
@@ -42,52 +140,575 @@ func main() {
     
     flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
     flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+    flag.Float64Var(&breakerRatio, "circuit-breaker-failure-ratio", 0.5,
+        "Share of failed reconciles of a class that pauses its applies. 0 disables the circuit breaker.")
+    flag.IntVar(&breakerMinReconciles, "circuit-breaker-min-reconciles", 20,
+        "Number of reconciles of a class in the window before its circuit breaker can open.")
+    flag.DurationVar(&breakerWindow, "circuit-breaker-window", time.Minute,
+        "How long the reconcile outcomes of a class are counted by its circuit breaker.")
+    flag.DurationVar(&breakerCooldown, "circuit-breaker-cooldown", time.Minute,
+        "How long the applies of a class are paused once its circuit breaker opens.")
+    flag.DurationVar(&stallTimeout, "stall-timeout", 10*time.Minute,
+        "How long namespaces may wait in the queue without any reconcile finishing, or a reconcile may run, before the controller is reported unhealthy. 0 disables the check.")
+    flag.DurationVar(&drainTimeout, "shutdown-drain-timeout", 20*time.Second,
+        "How long in-flight reconciles may keep applying after a shutdown signal before they are cancelled. 0 cancels them at once.")
+    flag.BoolVar(&secureMetrics, "metrics-secure", false,
+        "Serve metrics over HTTPS to clients authenticated with a bearer token and allowed to get /metrics.")
+    flag.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+        "Directory with the tls.crt and tls.key of the secure metrics endpoint. A self-signed certificate is used when empty.")
     flag.BoolVar(&enableLeaderElection, "leader-elect", false,
         "Enable leader election for controller manager. "+
             "Enabling this will ensure there is only one active controller manager.")
+    flag.StringVar(&metricsTeamLabel, "metrics-team-label", "",
+        "Namespace label exported as the team label of per-namespace metrics.")
+    flag.StringVar(&metricsTeamValues, "metrics-team-values", "",
+        "Comma-separated allowlist of team label values; other values are reported as \"other\".")
+    flag.StringVar(&maintenanceConfigMap, "maintenance-configmap", "",
+        "Namespace/name of a ConfigMap whose \"frozen: true\" entry pauses class rollouts during cluster maintenance.")
+    flag.StringVar(&imagePullSecret, "image-pull-secret", "",
+        "Namespace/name of the image pull secret copied into namespaces whose classes attach it to service accounts.")
+    flag.StringVar(&impersonateNamespace, "service-account-namespace", "default",
+        "Namespace of the ServiceAccounts classes name in spec.serviceAccountName to apply their resources as.")
+    flag.BoolVar(&permissionPreflight, "permission-preflight", true,
+        "Review the permissions needed to apply the resources of a namespace before applying any of them.")
+    flag.StringVar(&allowedKinds, "allowed-kinds", "",
+        "Comma-separated Kind, Kind.group or *.group list of the only kinds classes may create. Empty allows any kind.")
+    flag.StringVar(&deniedKinds, "denied-kinds", "",
+        "Comma-separated Kind, Kind.group or *.group list of kinds classes may not create.")
+    flag.IntVar(&maxClassResources, "max-resources-per-class", controller.DefaultMaxResourcesPerClass,
+        "Most resources a class may render for a namespace. 0 disables the limit.")
+    flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+        "Serve the admission webhooks. Requires a serving certificate in the webhook certificate directory.")
+    flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the admission webhooks are served on.")
+    flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+        "Directory the tls.crt and tls.key of the admission webhooks are read from.")
+    flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "",
+        "Namespace/name of a Secret to keep a self-signed webhook certificate in, rotated before it expires and injected into the webhook configurations. Empty expects the certificate to be provided, e.g. by cert-manager.")
+    flag.StringVar(&webhookService, "webhook-service", "default/namespaceclass-webhook",
+        "Namespace/name of the Service the webhook configurations call, used for self-signed certificates.")
+    flag.Float64Var(&warmUpQPS, "warmup-reconciles-per-second", 20,
+        "Rate of reconciles for namespaces that exist at startup. 0 disables the limit.")
+    flag.IntVar(&warmUpBurst, "warmup-burst", 20, "Number of startup reconciles allowed above the warm-up rate.")
+    flag.IntVar(&warmUpConcurrency, "warmup-concurrency", 2,
+        "Maximum parallel reconciles of namespaces that exist at startup. 0 disables the limit.")
+    flag.DurationVar(&orphanScanInterval, "orphan-scan-interval", 10*time.Minute,
+        "How often to count managed objects that no inventory tracks. 0 disables the scan.")
+    flag.BoolVar(&deleteOrphans, "delete-orphans", false,
+        "Delete managed objects that no inventory tracks once two orphan scans in a row found them, instead of only counting them.")
+    flag.BoolVar(&startupSweep, "startup-sweep", true,
+        "Reconcile every namespace with a class or tracked resources after winning the leader election.")
+    flag.StringVar(&tracing.Endpoint, "tracing-endpoint", "",
+        "host:port of an OTLP gRPC collector to send reconcile traces to. Tracing is disabled when empty.")
+    flag.BoolVar(&tracing.Insecure, "tracing-insecure", false, "Connect to the tracing collector without TLS.")
+    flag.Float64Var(&tracing.SampleRatio, "tracing-sample-ratio", 1, "Fraction of reconciles to trace.")
+    flag.StringVar(&notifySlackURL, "notify-slack-url", "", "Slack incoming webhook URL to send sync notifications to.")
+    flag.StringVar(&notifyWebhookURL, "notify-webhook-url", "", "URL to post sync notifications to as JSON.")
+    flag.StringVar(&notifyCloudEventsURL, "notify-cloudevents-url", "", "URL to post sync notifications to as CloudEvents.")
+    flag.StringVar(&notifySource, "notify-source", "namespaceclass-controller",
+        "CloudEvents source of sync notifications, e.g. the cluster name.")
+    flag.IntVar(&notifyThreshold, "notify-failure-threshold", 3,
+        "Consecutive failed reconciles of a namespace before a failure notification is sent.")
+    flag.StringVar(&auditFile, "audit-file", "", "File to append an audit record of every managed resource mutation to.")
+    flag.StringVar(&auditURL, "audit-url", "", "URL to post an audit record of every managed resource mutation to.")
+    flag.StringVar(&auditActor, "audit-actor", "namespaceclass-controller", "Actor recorded in audit records.")
+    flag.IntVar(&revisionHistoryLimit, "revision-history-limit", controller.DefaultRevisionHistoryLimit,
+        "Number of NamespaceClassRevisions kept per class for rollbacks.")
+    flag.BoolVar(&dataProtection, "data-protection", true,
+        "Keep bound PersistentVolumeClaims and Secrets with data instead of pruning them when a namespace leaves their class.")
+    flag.StringVar(&protectedKinds, "protected-kinds", "",
+        "Comma-separated Kind or Kind.group list of resources always kept when a namespace leaves their class.")
+    flag.IntVar(&shard, "shard", 0, "Shard of this controller, from 0 to --total-shards minus 1.")
+    flag.IntVar(&totalShards, "total-shards", 1,
+        "Number of controller deployments splitting classes between them by a hash of the class name.")
+    flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+        "Comma-separated list of the only namespaces to manage. Empty manages all namespaces.")
+    flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 50, "Sustained rate of requests to the API server per second.")
+    flag.IntVar(&kubeAPIBurst, "kube-api-burst", 100, "Number of requests to the API server allowed above that rate.")
+    flag.Float64Var(&classRolloutQPS, "class-rollout-reconciles-per-second", 10,
+        "Rate at which the namespaces of a changed class are queued, so large rollouts do not starve other classes. 0 disables the limit.")
+    flag.IntVar(&classRolloutBurst, "class-rollout-burst", 50,
+        "Number of namespaces of a changed class queued at once before the rollout rate applies.")
+    flag.DurationVar(&classRolloutJitter, "class-rollout-jitter", 2*time.Second,
+        "Maximum random delay added to each namespace of a changed class, smearing rollouts over time. 0 disables it.")
+    flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1,
+        "Maximum random delay added to periodic requeues, as a fraction of their interval. 0 disables it.")
+    flag.Float64Var(&applyQPSPerKind, "apply-qps-per-kind", 0,
+        "Sustained rate of creates and updates of resources of each kind. 0 leaves writes unlimited.")
+    flag.IntVar(&applyBurstPerKind, "apply-burst-per-kind", 10,
+        "Number of creates and updates of resources of each kind allowed above that rate.")
+    flag.StringVar(&applyRateLimits, "apply-rate-limits", "",
+        "Comma-separated Kind, Kind.group or *.group=rate[:burst] overrides of the apply rate of specific kinds, e.g. ConfigMap=5:10.")
+    flag.DurationVar(&statusBatchWindow, "status-batch-window", time.Second,
+        "How long the sync results of a class are collected before they are written to its status in one update. 0 writes each result immediately.")
+    flag.DurationVar(&missingClassRetry, "missing-class-retry", 30*time.Second,
+        "Delay before retrying a namespace whose class does not exist. Doubles with every retry.")
+    flag.DurationVar(&missingClassMaxRetry, "missing-class-max-retry", 30*time.Minute,
+        "Maximum delay between retries of a namespace whose class does not exist.")
+    flag.StringVar(&configFile, "config", "",
+        "ControllerConfiguration file to read settings from. Flags given on the command line override it.")
+    flag.StringVar(&leaderElectionID, "leader-election-id", "",
+        "Name of the leader election lease. Defaults to one lease per shard.")
+    flag.IntVar(&maxConcurrent, "max-concurrent-reconciles", 5, "Number of namespaces reconciled in parallel.")
+    flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour,
+        "How often the cache is resynced, reconciling every namespace again.")
+    flag.StringVar(&excludedNamespaces, "excluded-namespaces", "",
+        "Comma-separated list of namespaces the controller leaves alone.")
+    flag.StringVar(&systemNamespaces, "system-namespaces", "",
+        "Comma-separated list of namespaces the controller never manages, in addition to kube-system, kube-public, kube-node-lease and its own namespace.")
+    flag.BoolVar(&optIn, "opt-in", false,
+        "Only manage namespaces annotated with namespaceclass.akuity.io/opt-in=true, whatever their labels.")
+    flag.Var(&featureGates, "feature-gates",
+        "Comma-separated Feature=true|false list turning experimental features on or off. Features: "+features.Known())
     opts.BindFlags(flag.CommandLine)
     flag.Parse()
+
+    var (
+        loadedConfig *config.Configuration
+        commandLine  map[string]bool
+        configErr    error
+    )
+    configErr = config.ApplyEnv(flag.CommandLine)
+    if configErr == nil && configFile != "" {
+        if loadedConfig, configErr = config.Load(configFile); configErr == nil {
+            commandLine, configErr = loadedConfig.Apply(flag.CommandLine)
+        }
+    }
     
     // *** This is the critical line to ensure logging is properly initialized ***
     ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+    if configErr != nil {
+        setupLog.Error(configErr, "invalid configuration", "file", configFile)
+        os.Exit(1)
+    }
+    if maxConcurrent < 1 || syncPeriod <= 0 {
+        setupLog.Error(nil, "max-concurrent-reconciles and sync-period must be positive",
+            "maxConcurrentReconciles", maxConcurrent, "syncPeriod", syncPeriod)
+        os.Exit(1)
+    }
+    
+    if totalShards < 1 || shard < 0 || shard >= totalShards {
+        setupLog.Error(nil, "shard must be between 0 and total-shards minus 1", "shard", shard, "totalShards", totalShards)
+        os.Exit(1)
+    }
+    sharding := &controller.Sharding{Shard: shard, TotalShards: totalShards}
+    if !sharding.Primary() {
+        // Objects no inventory tracks are counted across all classes
+        orphanScanInterval = 0
+    }
+    if leaderElectionID == "" {
+        leaderElectionID = "namespaceclass-controller-leader.akuity.io"
+        if totalShards > 1 {
+            leaderElectionID = fmt.Sprintf("namespaceclass-controller-shard-%d-leader.akuity.io", shard)
+        }
+    }
+
+    scope := &controller.WatchScope{
+        Namespaces: splitList(watchNamespaces),
+        System:     systemNamespaceList(systemNamespaces),
+        OptIn:      optIn,
+    }
+    scope.SetExcluded(splitList(excludedNamespaces))
+
+    if breakerRatio < 0 || breakerRatio > 1 {
+        setupLog.Error(nil, "circuit-breaker-failure-ratio must be between 0 and 1", "value", breakerRatio)
+        os.Exit(1)
+    }
+
+    if kubeAPIQPS <= 0 || kubeAPIBurst <= 0 {
+        setupLog.Error(nil, "kube-api-qps and kube-api-burst must be positive", "qps", kubeAPIQPS, "burst", kubeAPIBurst)
+        os.Exit(1)
+    }
+
+    cfg := ctrl.GetConfigOrDie()
+    cfg.QPS = float32(kubeAPIQPS)
+    cfg.Burst = kubeAPIBurst
+    if tracing.Endpoint != "" {
+        setupLog.Info("Setting up tracing", "endpoint", tracing.Endpoint)
+        shutdown, err := setupTracing(context.Background(), cfg, tracing)
+        if err != nil {
+            setupLog.Error(err, "unable to set up tracing")
+            os.Exit(1)
+        }
+        defer func() {
+            ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+            defer cancel()
+            if err := shutdown(ctx); err != nil {
+                setupLog.Error(err, "failed to flush traces")
+            }
+        }()
+    }
     
+    // Leave time to drain reconciles and flush class status before the manager gives up
+    shutdownTimeout := drainTimeout + controller.ShutdownFlushTimeout + 5*time.Second
+
     setupLog.Info("Setting up manager")
-    mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+    mgr, err := ctrl.NewManager(cfg, ctrl.Options{
         Scheme: scheme,
-        Metrics: metricsserver.Options{
-            BindAddress: metricsAddr,
-        },
+        Metrics: metricsOptions(metricsAddr, secureMetrics, metricsCertDir),
         HealthProbeBindAddress: probeAddr,
-        LeaderElection:         enableLeaderElection,
-        LeaderElectionID:       "namespaceclass-controller-leader.akuity.io",
+        Cache: cache.Options{
+            DefaultNamespaces: scope.CacheNamespaces(),
+            SyncPeriod:        &syncPeriod,
+        },
+        LeaderElection:          enableLeaderElection,
+        LeaderElectionID:        leaderElectionID,
+        GracefulShutdownTimeout: &shutdownTimeout,
+        WebhookServer: webhook.NewServer(webhook.Options{
+            Port:    webhookPort,
+            CertDir: webhookCertDir,
+        }),
     })
     if err != nil {
         setupLog.Error(err, "unable to start manager")
         os.Exit(1)
     }
     
+    var maintenance *controller.MaintenanceSignal
+    if maintenanceConfigMap != "" {
+        namespace, name, ok := strings.Cut(maintenanceConfigMap, "/")
+        if !ok {
+            setupLog.Error(nil, "maintenance-configmap must be in namespace/name form", "value", maintenanceConfigMap)
+            os.Exit(1)
+        }
+        maintenance = &controller.MaintenanceSignal{
+            Reader:    mgr.GetAPIReader(),
+            ConfigMap: types.NamespacedName{Namespace: namespace, Name: name},
+        }
+    }
+    
+    var pullSecret *controller.ImagePullSecretSource
+    if imagePullSecret != "" {
+        namespace, name, ok := strings.Cut(imagePullSecret, "/")
+        if !ok {
+            setupLog.Error(nil, "image-pull-secret must be in namespace/name form", "value", imagePullSecret)
+            os.Exit(1)
+        }
+        pullSecret = &controller.ImagePullSecretSource{
+            Reader: mgr.GetAPIReader(),
+            Secret: types.NamespacedName{Namespace: namespace, Name: name},
+        }
+    }
+
+    kinds := kindPolicy(allowedKinds, deniedKinds)
+    resourceLimit := &controller.ResourceLimit{MaxPerClass: maxClassResources}
+    kindRateLimits, err := controller.ParseKindRateLimits(applyRateLimits)
+    if err != nil {
+        setupLog.Error(err, "invalid apply-rate-limits")
+        os.Exit(1)
+    }
+    var preflight *controller.PermissionPreflight
+    if permissionPreflight {
+        preflight = &controller.PermissionPreflight{}
+    }
+
+    c := mgr.GetClient()
+    var sinks audit.Sinks
+    if auditFile != "" {
+        sink, err := audit.NewFileSink(auditFile)
+        if err != nil {
+            setupLog.Error(err, "unable to open audit file", "file", auditFile)
+            os.Exit(1)
+        }
+        defer sink.Close()
+        sinks = append(sinks, sink)
+    }
+    if auditURL != "" {
+        sinks = append(sinks, &audit.HTTPSink{URL: auditURL, Client: &http.Client{Timeout: 5 * time.Second}})
+    }
+    if len(sinks) > 0 {
+        c = audit.NewClient(c, sinks, auditActor)
+    }
+    impersonation := &controller.Impersonation{
+        Config:    mgr.GetConfig(),
+        Options:   client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()},
+        Namespace: impersonateNamespace,
+        Wrap: func(c client.Client) client.Client {
+            if len(sinks) > 0 {
+                c = audit.NewClient(c, sinks, auditActor)
+            }
+            return controller.NewInstrumentedClient(c)
+        },
+    }
+    
+    progress := &controller.ReconcileProgress{StallTimeout: stallTimeout}
+
     setupLog.Info("Setting up controller")
     if err = (&controller.NamespaceClassReconciler{
-        Client: mgr.GetClient(),
-        Scheme: mgr.GetScheme(),
+        Client:   controller.NewInstrumentedClient(c),
+        Scheme:   mgr.GetScheme(),
+        Recorder: mgr.GetEventRecorderFor("namespaceclass-controller"),
+        TeamLabel: controller.TeamLabelMapping{
+            Label:   metricsTeamLabel,
+            Allowed: splitList(metricsTeamValues),
+        },
+        Maintenance: maintenance,
+        WarmUp: &controller.WarmUpBudget{
+            ReconcilesPerSecond: warmUpQPS,
+            Burst:               warmUpBurst,
+            MaxConcurrent:       warmUpConcurrency,
+        },
+        Fairness: &controller.ClassFairness{
+            ReconcilesPerSecond: classRolloutQPS,
+            Burst:               classRolloutBurst,
+            Jitter:              classRolloutJitter,
+        },
+        RequeueJitter: requeueJitter,
+        MaxConcurrentReconciles: maxConcurrent,
+        MissingClassBackoff: &controller.MissingClassBackoff{
+            Initial: missingClassRetry,
+            Max:     missingClassMaxRetry,
+        },
+        CircuitBreaker: &controller.CircuitBreaker{
+            Window:        breakerWindow,
+            MinReconciles: breakerMinReconciles,
+            FailureRatio:  breakerRatio,
+            Cooldown:      breakerCooldown,
+        },
+        ApplyRateLimits: &controller.ApplyRateLimits{
+            Default: controller.ApplyRateLimit{PerSecond: applyQPSPerKind, Burst: applyBurstPerKind},
+            Kinds:   kindRateLimits,
+        },
+        OrphanScanInterval: orphanScanInterval,
+        DeleteOrphans:      deleteOrphans,
+        StartupSweep:       startupSweep,
+        StatusBatchWindow:  statusBatchWindow,
+        Notifications:      notifications(notifySlackURL, notifyWebhookURL, notifyCloudEventsURL, notifySource, notifyThreshold),
+        DataProtection:     dataProtectionPolicy(dataProtection, protectedKinds),
+        ImagePullSecret:    pullSecret,
+        Impersonation:      impersonation,
+        Preflight:          preflight,
+        Kinds:              kinds,
+        ResourceLimit:      resourceLimit,
+        Shard:              sharding,
+        Scope:              scope,
+        Features:           &featureGates,
+        Progress:           progress,
+        DrainTimeout:       drainTimeout,
     }).SetupWithManager(mgr); err != nil {
         setupLog.Error(err, "unable to create controller", "controller", "NamespaceClass")
         os.Exit(1)
     }
+    if err = (&controller.RevisionReconciler{
+        Client:       mgr.GetClient(),
+        HistoryLimit: revisionHistoryLimit,
+        Shard:        sharding,
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "NamespaceClassRevision")
+        os.Exit(1)
+    }
+    // Claims and tenants are not split by class; the first shard handles them
+    if sharding.Primary() {
+        if err = (&controller.ClaimReconciler{
+            Client: mgr.GetClient(),
+            Scope:  scope,
+        }).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create controller", "controller", "NamespaceClaim")
+            os.Exit(1)
+        }
+        if err = (&controller.TenantReconciler{
+            Client: mgr.GetClient(),
+            Scope:  scope,
+        }).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create controller", "controller", "Tenant")
+            os.Exit(1)
+        }
+    }
+    if err = (&controller.ProvisionReconciler{
+        Client:   mgr.GetClient(),
+        Recorder: mgr.GetEventRecorderFor("namespaceclass-controller"),
+        Shard:    sharding,
+        Scope:    scope,
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "NamespaceProvisioning")
+        os.Exit(1)
+    }
     // +kubebuilder:scaffold:builder
 
-    if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+    if enableWebhooks && webhookCertSecret != "" {
+        setupLog.Info("Setting up webhook certificate rotation", "secret", webhookCertSecret)
+        rotator, err := certRotator(cfg, webhookCertSecret, webhookService, webhookCertDir)
+        if err != nil {
+            setupLog.Error(err, "unable to set up webhook certificate rotation")
+            os.Exit(1)
+        }
+        // The webhook server needs a certificate when it starts
+        ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+        err = rotator.Ensure(ctx)
+        cancel()
+        if err != nil {
+            setupLog.Error(err, "unable to provision webhook certificate")
+            os.Exit(1)
+        }
+        if err := mgr.Add(rotator); err != nil {
+            setupLog.Error(err, "unable to set up webhook certificate rotation")
+            os.Exit(1)
+        }
+    }
+    if enableWebhooks {
+        setupLog.Info("Setting up webhooks")
+        mgr.GetWebhookServer().Register(nscwebhook.WarningsPath, &webhook.Admission{
+            Handler: &nscwebhook.WorkloadWarner{Client: mgr.GetClient()},
+        })
+        mgr.GetWebhookServer().Register(nscwebhook.BindingPath, &webhook.Admission{
+            Handler: &nscwebhook.BindingValidator{Client: mgr.GetClient()},
+        })
+        mgr.GetWebhookServer().Register(nscwebhook.ClaimPath, &webhook.Admission{
+            Handler: &nscwebhook.ClaimValidator{Client: mgr.GetClient()},
+        })
+        if err := nscwebhook.SetupClassDefaulterWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceClass")
+            os.Exit(1)
+        }
+        if err := nscwebhook.SetupClassValidatorWithManager(mgr, kinds, resourceLimit); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceClass")
+            os.Exit(1)
+        }
+    }
+
+    // Stalled workers only recover by restarting the pod
+    if err := mgr.AddHealthzCheck("reconcile-progress", progress.Check); err != nil {
         setupLog.Error(err, "unable to set up health check")
         os.Exit(1)
     }
-    if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-        setupLog.Error(err, "unable to set up ready check")
-        os.Exit(1)
+    readyChecks := map[string]healthz.Checker{
+        "informer-sync":      cacheSyncCheck(mgr.GetCache()),
+        "reconcile-progress": progress.Check,
+    }
+    if enableWebhooks {
+        readyChecks["webhook"] = mgr.GetWebhookServer().StartedChecker()
+    }
+    for name, check := range readyChecks {
+        if err := mgr.AddReadyzCheck(name, check); err != nil {
+            setupLog.Error(err, "unable to set up ready check", "check", name)
+            os.Exit(1)
+        }
     }
     
+    ctx := ctrl.SetupSignalHandler()
+    if loadedConfig != nil {
+        reloadOnSIGHUP(ctx, configFile, loadedConfig, commandLine, scope)
+    }
+
     setupLog.Info("Starting manager")
-    if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+    if err := mgr.Start(ctx); err != nil {
         setupLog.Error(err, "problem running manager")
         os.Exit(1)
     }
-}
\ No newline at end of file
+}
+
+// certRotator builds the rotator of the self-signed webhook certificate
+// stored in a Secret.
+func certRotator(cfg *rest.Config, secret, service, certDir string) (*nscwebhook.CertRotator, error) {
+    secretNamespace, secretName, ok := strings.Cut(secret, "/")
+    if !ok {
+        return nil, fmt.Errorf("webhook-cert-secret must be in namespace/name form, got %q", secret)
+    }
+    serviceNamespace, serviceName, ok := strings.Cut(service, "/")
+    if !ok {
+        return nil, fmt.Errorf("webhook-service must be in namespace/name form, got %q", service)
+    }
+    // The cache is not running yet when the first certificate is needed
+    c, err := client.New(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return nil, err
+    }
+    return &nscwebhook.CertRotator{
+        Client:   c,
+        Secret:   types.NamespacedName{Namespace: secretNamespace, Name: secretName},
+        Service:  types.NamespacedName{Namespace: serviceNamespace, Name: serviceName},
+        CertDir:  certDir,
+        Interval: time.Hour,
+    }, nil
+}
+
+// cacheSyncCheck is a ready check failing until the informers of the
+// manager's cache have synced.
+func cacheSyncCheck(c cache.Cache) healthz.Checker {
+    return func(req *http.Request) error {
+        ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+        defer cancel()
+        if !c.WaitForCacheSync(ctx) {
+            return errors.New("informer caches are not synced")
+        }
+        return nil
+    }
+}
+
+// metricsOptions configures the metrics endpoint. A secure endpoint is
+// served over HTTPS and only to authenticated and authorized clients.
+func metricsOptions(bindAddress string, secure bool, certDir string) metricsserver.Options {
+    opts := metricsserver.Options{BindAddress: bindAddress}
+    if secure {
+        opts.SecureServing = true
+        opts.CertDir = certDir
+        opts.FilterProvider = metricsauth.FilterProvider
+    }
+    return opts
+}
+
+// notifications builds the notifier for the configured sinks, or returns nil
+// when none is configured.
+func notifications(slackURL, webhookURL, cloudEventsURL, source string, threshold int) *controller.Notifications {
+    var notifiers notify.Multi
+    if slackURL != "" {
+        notifiers = append(notifiers, &notify.Slack{URL: slackURL})
+    }
+    if webhookURL != "" {
+        notifiers = append(notifiers, &notify.Webhook{URL: webhookURL})
+    }
+    if cloudEventsURL != "" {
+        notifiers = append(notifiers, &notify.CloudEvents{URL: cloudEventsURL, Source: source})
+    }
+    if len(notifiers) == 0 {
+        return nil
+    }
+    return &controller.Notifications{Notifier: notifiers, FailureThreshold: threshold}
+}
+
+// dataProtectionPolicy builds the data protection policy from its flags, or
+// returns nil when protection is disabled.
+func dataProtectionPolicy(enabled bool, protectedKinds string) *controller.DataProtectionPolicy {
+    if !enabled {
+        return nil
+    }
+    return &controller.DataProtectionPolicy{ProtectedKinds: splitList(protectedKinds)}
+}
+
+// kindPolicy returns the kinds classes may create, or nil if they may create
+// any kind.
+func kindPolicy(allowedKinds, deniedKinds string) *controller.KindPolicy {
+    allowed, denied := splitList(allowedKinds), splitList(deniedKinds)
+    if len(allowed) == 0 && len(denied) == 0 {
+        return nil
+    }
+    return &controller.KindPolicy{Allowed: allowed, Denied: denied}
+}
+
+// systemNamespaceList returns the namespaces the controller never manages:
+// those of Kubernetes itself, the namespace the controller runs in, if it is
+// known, and the given comma-separated list.
+func systemNamespaceList(value string) []string {
+    namespaces := append(slices.Clone(controller.DefaultSystemNamespaces), splitList(value)...)
+    own := os.Getenv("POD_NAMESPACE")
+    if own == "" {
+        if data, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+            own = strings.TrimSpace(string(data))
+        }
+    }
+    if own != "" {
+        namespaces = append(namespaces, own)
+    }
+    return namespaces
+}
+
+// splitList parses a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
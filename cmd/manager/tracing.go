@@ -0,0 +1,59 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+
+    "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+    "go.opentelemetry.io/otel/trace"
+    "k8s.io/client-go/rest"
+)
+
+// tracingOptions configure OTLP export of reconcile traces.
+type tracingOptions struct {
+    // Endpoint is the host:port of the OTLP gRPC collector; tracing is off when empty
+    Endpoint string
+
+    // Insecure disables TLS to the collector
+    Insecure bool
+
+    // SampleRatio is the fraction of reconciles that are traced
+    SampleRatio float64
+}
+
+// setupTracing installs the global tracer provider and wraps the API client
+// transport so requests made during a traced reconcile become child spans.
+// It returns a function that flushes pending spans on shutdown.
+func setupTracing(ctx context.Context, cfg *rest.Config, opts tracingOptions) (func(context.Context) error, error) {
+    exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+    if opts.Insecure {
+        exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+    }
+    exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+    }
+
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+        sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("namespaceclass-controller"))),
+    )
+    otel.SetTracerProvider(provider)
+    otel.SetTextMapPropagator(propagation.TraceContext{})
+
+    // Informer list and watch requests have no parent span and are not traced
+    cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+        return otelhttp.NewTransport(rt, otelhttp.WithFilter(func(req *http.Request) bool {
+            return trace.SpanContextFromContext(req.Context()).IsValid()
+        }))
+    })
+    return provider.Shutdown, nil
+}
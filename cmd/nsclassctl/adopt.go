@@ -0,0 +1,48 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "text/tabwriter"
+
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// adoptionDescriptions explain each adoption action in the output.
+var adoptionDescriptions = map[string]string{
+    controller.AdoptionInSync:  "adopted, matches the class",
+    controller.AdoptionUpdate:  "adopted, updated in place by the controller",
+    controller.AdoptionCreate:  "missing, created by the controller",
+    controller.AdoptionManaged: "already managed by the class",
+    controller.AdoptionSkipped: "skipped",
+}
+
+// runAdopt onboards a namespace onto a class and prints what happens to
+// every class resource.
+func runAdopt(ctx context.Context, c client.Client, namespace, className string, dryRun bool, out io.Writer) (bool, error) {
+    adoptions, err := controller.AdoptNamespace(ctx, c, namespace, className, dryRun)
+    w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "KIND\tNAME\tACTION")
+    for _, a := range adoptions {
+        action := adoptionDescriptions[a.Action]
+        if a.Reason != "" {
+            action += ": " + a.Reason
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\n", a.Kind, a.Name, action)
+    }
+    if flushErr := w.Flush(); err == nil {
+        err = flushErr
+    }
+    if err != nil {
+        return false, err
+    }
+    if dryRun {
+        fmt.Fprintf(out, "namespace %s would be onboarded onto class %s (dry run)\n", namespace, className)
+    } else {
+        fmt.Fprintf(out, "namespace %s onboarded onto class %s\n", namespace, className)
+    }
+    return true, nil
+}
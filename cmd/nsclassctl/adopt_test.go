@@ -0,0 +1,104 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestAdopt(t *testing.T) {
+    ctx := context.Background()
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "public"},
+        Spec: v1.NamespaceClassSpec{
+            Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"mode":"strict"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"limits"},"data":{"cpu":"2"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"shared"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"deployer"}}`)},
+            },
+        },
+    }
+    c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "web-uid"}},
+        nsc,
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "web"},
+            Data:       map[string]string{"mode": "strict"},
+        },
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "web", Labels: map[string]string{"team": "web"}},
+            Data:       map[string]string{"cpu": "1"},
+        },
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "web", Annotations: map[string]string{
+                controller.ManagedByAnnotation:      controller.ManagedByValue,
+                controller.CreatedByClassAnnotation: "internal",
+            }},
+        },
+    ).Build()
+
+    var out bytes.Buffer
+    if _, err := runAdopt(ctx, c, "web", "public", false, &out); err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        "settings  adopted, matches the class",
+        "limits    adopted, updated in place by the controller",
+        `shared    skipped: managed by class "internal"`,
+        "deployer  missing, created by the controller",
+    } {
+        if !strings.Contains(out.String(), want) {
+            t.Errorf("output does not contain %q:\n%s", want, out.String())
+        }
+    }
+
+    // Objects matching the class are managed with the hash of the class
+    settings := &corev1.ConfigMap{}
+    if err := c.Get(ctx, types.NamespacedName{Namespace: "web", Name: "settings"}, settings); err != nil {
+        t.Fatal(err)
+    }
+    if settings.Annotations[controller.ManagedByAnnotation] != controller.ManagedByValue ||
+        settings.Annotations[controller.CreatedByClassAnnotation] != "public" ||
+        settings.Annotations[controller.ResourceHashAnnotation] == "" {
+        t.Errorf("settings not adopted: %v", settings.Annotations)
+    }
+    // The rest are left to the controller to adopt
+    limits := &corev1.ConfigMap{}
+    if err := c.Get(ctx, types.NamespacedName{Namespace: "web", Name: "limits"}, limits); err != nil {
+        t.Fatal(err)
+    }
+    if limits.Annotations[controller.AdoptAnnotation] != "true" || limits.Annotations[controller.ManagedByAnnotation] != "" {
+        t.Errorf("limits not marked for adoption: %v", limits.Annotations)
+    }
+
+    inv := &v1.NamespaceClassInventory{}
+    if err := c.Get(ctx, types.NamespacedName{Name: "web"}, inv); err != nil {
+        t.Fatal(err)
+    }
+    var names []string
+    for _, entry := range inv.Spec.Resources {
+        names = append(names, entry.Name)
+    }
+    if strings.Join(names, ",") != "settings,limits" || inv.Labels[controller.LabelKey] != "public" {
+        t.Errorf("unexpected inventory: %v %v", names, inv.Labels)
+    }
+
+    ns := &corev1.Namespace{}
+    if err := c.Get(ctx, types.NamespacedName{Name: "web"}, ns); err != nil {
+        t.Fatal(err)
+    }
+    if ns.Labels[controller.LabelKey] != "public" {
+        t.Errorf("namespace not labeled: %v", ns.Labels)
+    }
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+
+    "k8s.io/apimachinery/pkg/runtime"
+    utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+    "sigs.k8s.io/yaml"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// sourcedClass is a NamespaceClass with the file it was read from.
+type sourcedClass struct {
+    source string
+    class  *v1.NamespaceClass
+}
+
+// readClasses decodes the NamespaceClasses in a YAML or JSON stream.
+// Documents of other kinds are skipped, so whole manifest directories can
+// be passed.
+func readClasses(source string, r io.Reader) ([]sourcedClass, error) {
+    var classes []sourcedClass
+    decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+    for {
+        var raw runtime.RawExtension
+        if err := decoder.Decode(&raw); err != nil {
+            if errors.Is(err, io.EOF) {
+                return classes, nil
+            }
+            return nil, fmt.Errorf("%s: %w", source, err)
+        }
+        if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+            continue
+        }
+        var meta struct {
+            APIVersion string `json:"apiVersion"`
+            Kind       string `json:"kind"`
+        }
+        if err := json.Unmarshal(raw.Raw, &meta); err != nil {
+            return nil, fmt.Errorf("%s: %w", source, err)
+        }
+        if meta.APIVersion != v1.GroupVersion.String() || meta.Kind != "NamespaceClass" {
+            continue
+        }
+        nsc := &v1.NamespaceClass{}
+        if err := json.Unmarshal(raw.Raw, nsc); err != nil {
+            return nil, fmt.Errorf("%s: %w", source, err)
+        }
+        classes = append(classes, sourcedClass{source: source, class: nsc})
+    }
+}
+
+// runValidate writes the errors of every class to out and reports whether
+// all classes are valid.
+func runValidate(classes []sourcedClass, out io.Writer) bool {
+    valid := true
+    for _, c := range classes {
+        for _, err := range controller.ValidateClass(c.class) {
+            fmt.Fprintf(out, "%s: NamespaceClass %s: %v\n", c.source, c.class.Name, err)
+            valid = false
+        }
+    }
+    return valid
+}
+
+// runRender writes the manifests the controller would apply to a namespace
+// for the selected class as a YAML stream. Validation errors are written to
+// errOut instead, and reported by returning false.
+func runRender(classes []sourcedClass, namespace, className string, out, errOut io.Writer) (bool, error) {
+    var selected []sourcedClass
+    for _, c := range classes {
+        if className == "" || c.class.Name == className {
+            selected = append(selected, c)
+        }
+    }
+    switch {
+    case len(selected) == 0 && className != "":
+        return false, fmt.Errorf("class %s not found", className)
+    case len(selected) == 0:
+        return false, fmt.Errorf("no NamespaceClass found")
+    case len(selected) > 1:
+        return false, fmt.Errorf("found %d classes; select one with --class", len(selected))
+    }
+
+    if !runValidate(selected, errOut) {
+        return false, nil
+    }
+    resources, err := controller.RenderClass(selected[0].class, namespace)
+    if err != nil {
+        return false, err
+    }
+    for _, res := range resources {
+        data, err := yaml.Marshal(res.Object)
+        if err != nil {
+            return false, err
+        }
+        if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+            return false, err
+        }
+    }
+    return true, nil
+}
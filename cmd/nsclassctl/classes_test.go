@@ -0,0 +1,89 @@
+package main
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+const manifests = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: namespaceclass.akuity.io/v1
+kind: NamespaceClass
+metadata:
+  name: public
+spec:
+  resources:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+    data:
+      mode: strict
+---
+apiVersion: namespaceclass.akuity.io/v1
+kind: NamespaceClass
+metadata:
+  name: broken
+spec:
+  conflictPolicy: Ignore
+  resources:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+  - apiVersion: v1
+    kind: Secret
+`
+
+func TestRenderAndValidate(t *testing.T) {
+    classes, err := readClasses("classes.yaml", strings.NewReader(manifests))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(classes) != 2 {
+        t.Fatalf("got %d classes, want 2", len(classes))
+    }
+
+    var out, errOut bytes.Buffer
+    ok, err := runRender(classes, "web", "public", &out, &errOut)
+    if err != nil || !ok {
+        t.Fatalf("render failed: %v %s", err, errOut.String())
+    }
+    for _, want := range []string{"---\n", "kind: ConfigMap", "namespace: web", "mode: strict", controller.ResourceHashAnnotation} {
+        if !strings.Contains(out.String(), want) {
+            t.Errorf("rendered output does not contain %q:\n%s", want, out.String())
+        }
+    }
+
+    if _, err := runRender(classes, "web", "", &out, &errOut); err == nil {
+        t.Error("expected an error when several classes match")
+    }
+
+    errOut.Reset()
+    if runValidate(classes, &errOut) {
+        t.Fatal("expected validation to fail")
+    }
+    report := errOut.String()
+    for _, want := range []string{
+        "classes.yaml: NamespaceClass broken: spec.conflictPolicy: Unsupported value",
+        "spec.resources[1]: Duplicate value",
+        "spec.resources[2].metadata.name: Required value",
+    } {
+        if !strings.Contains(report, want) {
+            t.Errorf("report does not contain %q:\n%s", want, report)
+        }
+    }
+    if strings.Contains(report, "NamespaceClass public") {
+        t.Errorf("valid class reported:\n%s", report)
+    }
+}
@@ -0,0 +1,153 @@
+// nsclassctl works with NamespaceClass manifests offline, without a cluster,
+// so classes can be checked in CI before they are merged, and onboards
+// existing namespaces onto a class.
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+
+    "k8s.io/apimachinery/pkg/runtime"
+    utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    _ "k8s.io/client-go/plugin/pkg/client/auth"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+    utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+    utilruntime.Must(v1.AddToScheme(scheme))
+}
+
+const usage = `Usage: nsclassctl <command> [flags] [<file>...]
+
+Commands:
+  render      Print the manifests a class applies to a namespace
+  validate    Report errors in NamespaceClass manifests
+  adopt       Onboard an existing namespace onto a class
+
+Files may contain several YAML documents; "-" reads standard input.
+`
+
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+    var (
+        ok  bool
+        err error
+    )
+    switch os.Args[1] {
+    case "render":
+        ok, err = renderCommand(os.Args[2:])
+    case "validate":
+        ok, err = validateCommand(os.Args[2:])
+    case "adopt":
+        ok, err = adoptCommand(os.Args[2:])
+    default:
+        fmt.Fprint(os.Stderr, usage)
+        os.Exit(2)
+    }
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "error:", err)
+        os.Exit(2)
+    }
+    // Invalid classes have been reported on stderr
+    if !ok {
+        os.Exit(1)
+    }
+}
+
+func renderCommand(args []string) (bool, error) {
+    fs := flag.NewFlagSet("render", flag.ExitOnError)
+    var namespace, className string
+    fs.StringVar(&namespace, "namespace", "", "Namespace to render the class for.")
+    fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+    fs.StringVar(&className, "class", "", "Class to render when the files contain several.")
+    if err := fs.Parse(args); err != nil {
+        return false, err
+    }
+    if namespace == "" {
+        return false, fmt.Errorf("--namespace is required")
+    }
+    classes, err := readFiles(fs.Args())
+    if err != nil {
+        return false, err
+    }
+    return runRender(classes, namespace, className, os.Stdout, os.Stderr)
+}
+
+func validateCommand(args []string) (bool, error) {
+    fs := flag.NewFlagSet("validate", flag.ExitOnError)
+    if err := fs.Parse(args); err != nil {
+        return false, err
+    }
+    classes, err := readFiles(fs.Args())
+    if err != nil {
+        return false, err
+    }
+    return runValidate(classes, os.Stderr), nil
+}
+
+func adoptCommand(args []string) (bool, error) {
+    // The --kubeconfig flag is registered on the default flag set by controller-runtime
+    fs := flag.CommandLine
+    fs.Init("adopt", flag.ExitOnError)
+    var namespace, className string
+    var dryRun bool
+    fs.StringVar(&namespace, "namespace", "", "Namespace to adopt.")
+    fs.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+    fs.StringVar(&className, "class", "", "Class to onboard the namespace onto.")
+    fs.BoolVar(&dryRun, "dry-run", false, "Only print what would be adopted.")
+    if err := fs.Parse(args); err != nil {
+        return false, err
+    }
+    if namespace == "" || className == "" {
+        return false, fmt.Errorf("--namespace and --class are required")
+    }
+
+    cfg, err := ctrl.GetConfig()
+    if err != nil {
+        return false, err
+    }
+    c, err := client.New(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return false, fmt.Errorf("failed to create client: %w", err)
+    }
+    return runAdopt(context.Background(), c, namespace, className, dryRun, os.Stdout)
+}
+
+// readFiles reads the NamespaceClasses in the named files, or in standard
+// input when no file is named.
+func readFiles(paths []string) ([]sourcedClass, error) {
+    if len(paths) == 0 {
+        paths = []string{"-"}
+    }
+    var classes []sourcedClass
+    for _, path := range paths {
+        var r io.Reader = os.Stdin
+        if path != "-" {
+            f, err := os.Open(path)
+            if err != nil {
+                return nil, err
+            }
+            defer f.Close()
+            r = f
+        }
+        read, err := readClasses(path, r)
+        if err != nil {
+            return nil, err
+        }
+        classes = append(classes, read...)
+    }
+    return classes, nil
+}
@@ -0,0 +1,195 @@
+// internal/audit/audit.go
+package audit
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "os"
+    "sync"
+    "time"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// Operations recorded in the audit log
+const (
+    OperationCreate = "create"
+    OperationUpdate = "update"
+    OperationDelete = "delete"
+)
+
+// Record is one mutation of a managed resource.
+type Record struct {
+    Time       time.Time `json:"time"`
+    Actor      string    `json:"actor"`
+    Operation  string    `json:"operation"`
+    APIVersion string    `json:"apiVersion"`
+    Kind       string    `json:"kind"`
+    Namespace  string    `json:"namespace"`
+    Name       string    `json:"name"`
+    UID        string    `json:"uid,omitempty"`
+    Class      string    `json:"class,omitempty"`
+    Hash       string    `json:"hash,omitempty"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+    Write(ctx context.Context, record Record) error
+}
+
+// Sinks writes records to every sink, returning all errors.
+type Sinks []Sink
+
+func (s Sinks) Write(ctx context.Context, record Record) error {
+    var errs []error
+    for _, sink := range s {
+        if err := sink.Write(ctx, record); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// FileSink appends records to a file as JSON lines.
+type FileSink struct {
+    mu   sync.Mutex
+    file *os.File
+}
+
+// NewFileSink opens a file for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+    if err != nil {
+        return nil, err
+    }
+    return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, record Record) error {
+    data, err := json.Marshal(record)
+    if err != nil {
+        return err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    _, err = s.file.Write(append(data, '\n'))
+    return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+    return s.file.Close()
+}
+
+// HTTPSink posts each record as JSON to a URL.
+type HTTPSink struct {
+    URL    string
+    Client *http.Client
+}
+
+func (s *HTTPSink) Write(ctx context.Context, record Record) error {
+    data, err := json.Marshal(record)
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(data))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    c := s.Client
+    if c == nil {
+        c = http.DefaultClient
+    }
+    resp, err := c.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("audit sink %s rejected record: %s", req.URL.Host, resp.Status)
+    }
+    return nil
+}
+
+// auditClient records successful mutations of managed resources. Managed
+// resources are the unstructured objects the controller applies; writes to
+// namespaces, inventories and class status are not recorded.
+type auditClient struct {
+    client.Client
+    sink  Sink
+    actor string
+}
+
+// NewClient wraps a client so that every create, update and delete of a
+// managed resource is written to the sink as the given actor. Patches,
+// including server-side applies, are recorded as updates. The mutation
+// has already happened when the record is written, so sink errors are logged
+// rather than returned.
+func NewClient(c client.Client, sink Sink, actor string) client.Client {
+    return &auditClient{Client: c, sink: sink, actor: actor}
+}
+
+func (c *auditClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+    err := c.Client.Create(ctx, obj, opts...)
+    if err == nil {
+        c.record(ctx, OperationCreate, obj)
+    }
+    return err
+}
+
+func (c *auditClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+    err := c.Client.Update(ctx, obj, opts...)
+    if err == nil {
+        c.record(ctx, OperationUpdate, obj)
+    }
+    return err
+}
+
+func (c *auditClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+    err := c.Client.Patch(ctx, obj, patch, opts...)
+    if err == nil {
+        c.record(ctx, OperationUpdate, obj)
+    }
+    return err
+}
+
+func (c *auditClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+    err := c.Client.Delete(ctx, obj, opts...)
+    if err == nil {
+        c.record(ctx, OperationDelete, obj)
+    }
+    return err
+}
+
+func (c *auditClient) record(ctx context.Context, operation string, obj client.Object) {
+    u, ok := obj.(*unstructured.Unstructured)
+    if !ok {
+        return
+    }
+    annotations := u.GetAnnotations()
+    record := Record{
+        Time:       time.Now().UTC(),
+        Actor:      c.actor,
+        Operation:  operation,
+        APIVersion: u.GetAPIVersion(),
+        Kind:       u.GetKind(),
+        Namespace:  u.GetNamespace(),
+        Name:       u.GetName(),
+        UID:        string(u.GetUID()),
+        Class:      annotations[controller.CreatedByClassAnnotation],
+        Hash:       annotations[controller.ResourceHashAnnotation],
+    }
+    if err := c.sink.Write(ctx, record); err != nil {
+        log.FromContext(ctx).Error(err, "Failed to write audit record",
+            "operation", operation, "kind", record.Kind, "name", record.Name, "namespace", record.Namespace)
+    }
+}
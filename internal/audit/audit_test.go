@@ -0,0 +1,159 @@
+// internal/audit/audit_test.go
+package audit
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestClientRecordsManagedResourceMutations(t *testing.T) {
+    ctx := context.Background()
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    path := filepath.Join(t.TempDir(), "audit.log")
+    sink, err := NewFileSink(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer sink.Close()
+    c := NewClient(fake.NewClientBuilder().WithScheme(scheme).Build(), sink, "controller-0")
+
+    cm := &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "ConfigMap",
+        "metadata": map[string]interface{}{
+            "name":      "settings",
+            "namespace": "web",
+            "annotations": map[string]interface{}{
+                controller.CreatedByClassAnnotation: "public",
+                controller.ResourceHashAnnotation:   "abc",
+            },
+        },
+    }}
+    if err := c.Create(ctx, cm); err != nil {
+        t.Fatal(err)
+    }
+    if err := c.Update(ctx, cm); err != nil {
+        t.Fatal(err)
+    }
+    if err := c.Delete(ctx, cm); err != nil {
+        t.Fatal(err)
+    }
+    // Failed mutations and typed objects are not recorded
+    if err := c.Delete(ctx, cm); err == nil {
+        t.Fatal("expected deleting a missing object to fail")
+    }
+    if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}}); err != nil {
+        t.Fatal(err)
+    }
+
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    var records []Record
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        var record Record
+        if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+            t.Fatal(err)
+        }
+        records = append(records, record)
+    }
+
+    want := []string{OperationCreate, OperationUpdate, OperationDelete}
+    if len(records) != len(want) {
+        t.Fatalf("got %d records, want %d", len(records), len(want))
+    }
+    for i, record := range records {
+        if record.Operation != want[i] || record.Actor != "controller-0" || record.Kind != "ConfigMap" ||
+            record.Namespace != "web" || record.Name != "settings" || record.Class != "public" || record.Hash != "abc" {
+            t.Errorf("unexpected record %d: %+v", i, record)
+        }
+    }
+}
+
+// memorySink keeps the records written to it.
+type memorySink struct {
+    records []Record
+}
+
+func (s *memorySink) Write(ctx context.Context, record Record) error {
+    s.records = append(s.records, record)
+    return nil
+}
+
+func TestClientRecordsPatchesAsUpdates(t *testing.T) {
+    ctx := context.Background()
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    cm := &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "ConfigMap",
+        "metadata": map[string]interface{}{
+            "name":        "settings",
+            "namespace":   "web",
+            "annotations": map[string]interface{}{controller.CreatedByClassAnnotation: "public"},
+        },
+    }}
+    // The fake client does not implement server-side apply
+    inner := fake.NewClientBuilder().WithScheme(scheme).
+        WithObjects(cm.DeepCopy(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}}).
+        WithInterceptorFuncs(interceptor.Funcs{
+            Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+                if patch.Type() == types.ApplyPatchType {
+                    return nil
+                }
+                return c.Patch(ctx, obj, patch, opts...)
+            },
+        }).Build()
+    sink := &memorySink{}
+    c := NewClient(inner, sink, "controller-0")
+
+    if err := c.Patch(ctx, cm, client.Apply, client.FieldOwner(controller.ManagedByValue), client.ForceOwnership); err != nil {
+        t.Fatal(err)
+    }
+    live := cm.DeepCopy()
+    if err := inner.Get(ctx, client.ObjectKeyFromObject(cm), live); err != nil {
+        t.Fatal(err)
+    }
+    patch := client.MergeFrom(live.DeepCopy())
+    live.SetLabels(map[string]string{"tier": "gold"})
+    if err := c.Patch(ctx, live, patch); err != nil {
+        t.Fatal(err)
+    }
+    // Typed objects are not recorded
+    ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+    if err := c.Patch(ctx, ns, client.MergeFrom(ns.DeepCopy())); err != nil {
+        t.Fatal(err)
+    }
+
+    if len(sink.records) != 2 {
+        t.Fatalf("got %d records, want 2", len(sink.records))
+    }
+    for i, record := range sink.records {
+        if record.Operation != OperationUpdate || record.Kind != "ConfigMap" || record.Name != "settings" || record.Class != "public" {
+            t.Errorf("unexpected record %d: %+v", i, record)
+        }
+    }
+}
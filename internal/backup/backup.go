@@ -0,0 +1,335 @@
+// internal/backup/backup.go
+package backup
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/utils/ptr"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/yaml"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// BundleVersion is the version of the bundle format written by Export.
+const BundleVersion = 1
+
+// Upper bound on how many HNC ancestors are walked, as in the controller
+const maxHNCDepth = 32
+
+// Bundle is a snapshot of the provisioning state of a cluster: the classes
+// with their revisions and bindings, which namespace uses which class, and
+// what was applied to each namespace.
+type Bundle struct {
+    Version       int                          `json:"version"`
+    ExportedAt    metav1.Time                  `json:"exportedAt"`
+    Classes       []v1.NamespaceClass          `json:"classes"`
+    Revisions     []v1.NamespaceClassRevision  `json:"revisions,omitempty"`
+    ClassBindings []v1.NamespaceClassBinding   `json:"classBindings,omitempty"`
+    Bindings      []Binding                    `json:"bindings"`
+    Inventories   []v1.NamespaceClassInventory `json:"inventories"`
+}
+
+// Binding records how a namespace gets its classes: its class label, or the
+// HNC parent it inherits its class from, and the annotations selecting its
+// add-on classes, revision and template values.
+type Binding struct {
+    Namespace string `json:"namespace"`
+    Class     string `json:"class"`
+    Parent    string `json:"parent,omitempty"`
+    AddOns    string `json:"addOns,omitempty"`
+    Revision  string `json:"revision,omitempty"`
+    Values    string `json:"values,omitempty"`
+}
+
+// annotations maps the annotations of a namespace to the fields of its
+// binding.
+func (b *Binding) annotations() map[string]*string {
+    return map[string]*string{
+        controller.HNCSubnamespaceOfAnnotation: &b.Parent,
+        controller.ClassesAnnotation:           &b.AddOns,
+        controller.RevisionAnnotation:          &b.Revision,
+        controller.ValuesAnnotation:            &b.Values,
+    }
+}
+
+// Export reads the provisioning state of the cluster into a bundle.
+func Export(ctx context.Context, c client.Reader) (*Bundle, error) {
+    bundle := &Bundle{Version: BundleVersion, ExportedAt: metav1.Now()}
+
+    classes := &v1.NamespaceClassList{}
+    if err := c.List(ctx, classes); err != nil {
+        return nil, fmt.Errorf("failed to list NamespaceClasses: %w", err)
+    }
+    for _, nsc := range classes.Items {
+        stripServerFields(&nsc.ObjectMeta)
+        nsc.Status = v1.NamespaceClassStatus{}
+        bundle.Classes = append(bundle.Classes, nsc)
+    }
+
+    revisions := &v1.NamespaceClassRevisionList{}
+    if err := c.List(ctx, revisions); err != nil {
+        return nil, fmt.Errorf("failed to list NamespaceClassRevisions: %w", err)
+    }
+    for _, rev := range revisions.Items {
+        stripServerFields(&rev.ObjectMeta)
+        rev.OwnerReferences = nil
+        bundle.Revisions = append(bundle.Revisions, rev)
+    }
+
+    classBindings := &v1.NamespaceClassBindingList{}
+    if err := c.List(ctx, classBindings); err != nil {
+        return nil, fmt.Errorf("failed to list NamespaceClassBindings: %w", err)
+    }
+    for _, binding := range classBindings.Items {
+        stripServerFields(&binding.ObjectMeta)
+        bundle.ClassBindings = append(bundle.ClassBindings, binding)
+    }
+
+    // All namespaces are read, as subnamespaces inherit their class from an
+    // HNC ancestor without carrying the label themselves
+    all := make(map[string]*corev1.Namespace)
+    namespaces := &corev1.NamespaceList{}
+    err := controller.ListPages(ctx, c, namespaces, func() error {
+        for i := range namespaces.Items {
+            all[namespaces.Items[i].Name] = namespaces.Items[i].DeepCopy()
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to list namespaces: %w", err)
+    }
+    for _, ns := range all {
+        if !hasClass(all, ns) {
+            continue
+        }
+        binding := Binding{Namespace: ns.Name, Class: ns.Labels[controller.LabelKey]}
+        for key, field := range binding.annotations() {
+            *field = ns.Annotations[key]
+        }
+        bundle.Bindings = append(bundle.Bindings, binding)
+    }
+
+    inventories := &v1.NamespaceClassInventoryList{}
+    err = controller.ListPages(ctx, c, inventories, func() error {
+        for _, inv := range inventories.Items {
+            stripServerFields(&inv.ObjectMeta)
+            inv.OwnerReferences = nil
+            inv.Status = v1.NamespaceClassInventoryStatus{}
+            bundle.Inventories = append(bundle.Inventories, inv)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to list NamespaceClassInventories: %w", err)
+    }
+
+    sort.Slice(bundle.Classes, func(i, j int) bool { return bundle.Classes[i].Name < bundle.Classes[j].Name })
+    sort.Slice(bundle.Revisions, func(i, j int) bool { return bundle.Revisions[i].Name < bundle.Revisions[j].Name })
+    sort.Slice(bundle.ClassBindings, func(i, j int) bool { return bundle.ClassBindings[i].Name < bundle.ClassBindings[j].Name })
+    sort.Slice(bundle.Bindings, func(i, j int) bool { return bundle.Bindings[i].Namespace < bundle.Bindings[j].Namespace })
+    sort.Slice(bundle.Inventories, func(i, j int) bool { return bundle.Inventories[i].Name < bundle.Inventories[j].Name })
+    return bundle, nil
+}
+
+// hasClass reports whether a namespace has a class label, or inherits one
+// from an HNC ancestor among the namespaces given.
+func hasClass(namespaces map[string]*corev1.Namespace, ns *corev1.Namespace) bool {
+    for depth := 0; ns != nil && depth < maxHNCDepth; depth++ {
+        if _, ok := ns.Labels[controller.LabelKey]; ok {
+            return true
+        }
+        ns = namespaces[ns.Annotations[controller.HNCSubnamespaceOfAnnotation]]
+    }
+    return false
+}
+
+// Import restores a bundle into a cluster. Classes, their revisions and
+// bindings are created or replaced, missing namespaces are created, and
+// inventories are restored before the namespaces are labelled and annotated
+// so the controller recognizes the resources it created when it reconciles
+// them.
+func Import(ctx context.Context, c client.Client, bundle *Bundle) error {
+    if bundle.Version != BundleVersion {
+        return fmt.Errorf("unsupported bundle version %d, expected %d", bundle.Version, BundleVersion)
+    }
+
+    classes := make(map[string]*v1.NamespaceClass)
+    for i := range bundle.Classes {
+        nsc := bundle.Classes[i].DeepCopy()
+        existing := &v1.NamespaceClass{}
+        err := c.Get(ctx, types.NamespacedName{Name: nsc.Name}, existing)
+        switch {
+        case errors.IsNotFound(err):
+            err = c.Create(ctx, nsc)
+        case err == nil:
+            existing.Labels = nsc.Labels
+            existing.Annotations = nsc.Annotations
+            existing.Spec = nsc.Spec
+            err = c.Update(ctx, existing)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to restore NamespaceClass %s: %w", nsc.Name, err)
+        }
+        if existing.UID != "" {
+            nsc = existing
+        }
+        classes[nsc.Name] = nsc
+    }
+
+    for i := range bundle.Revisions {
+        rev := bundle.Revisions[i].DeepCopy()
+        if nsc, ok := classes[rev.Spec.Class]; ok {
+            // Revisions are owned by their class, as the controller records them
+            rev.OwnerReferences = []metav1.OwnerReference{{
+                APIVersion:         v1.GroupVersion.String(),
+                Kind:               "NamespaceClass",
+                Name:               nsc.Name,
+                UID:                nsc.UID,
+                BlockOwnerDeletion: ptr.To(false),
+            }}
+        }
+        existing := &v1.NamespaceClassRevision{}
+        err := c.Get(ctx, types.NamespacedName{Name: rev.Name}, existing)
+        switch {
+        case errors.IsNotFound(err):
+            err = c.Create(ctx, rev)
+        case err == nil:
+            existing.Labels = rev.Labels
+            existing.OwnerReferences = rev.OwnerReferences
+            existing.Spec = rev.Spec
+            err = c.Update(ctx, existing)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to restore NamespaceClassRevision %s: %w", rev.Name, err)
+        }
+    }
+
+    for i := range bundle.ClassBindings {
+        binding := bundle.ClassBindings[i].DeepCopy()
+        existing := &v1.NamespaceClassBinding{}
+        err := c.Get(ctx, types.NamespacedName{Name: binding.Name}, existing)
+        switch {
+        case errors.IsNotFound(err):
+            err = c.Create(ctx, binding)
+        case err == nil:
+            existing.Labels = binding.Labels
+            existing.Annotations = binding.Annotations
+            existing.Spec = binding.Spec
+            err = c.Update(ctx, existing)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to restore NamespaceClassBinding %s: %w", binding.Name, err)
+        }
+    }
+
+    namespaces := make(map[string]*corev1.Namespace)
+    for _, binding := range bundle.Bindings {
+        ns := &corev1.Namespace{}
+        err := c.Get(ctx, types.NamespacedName{Name: binding.Namespace}, ns)
+        if errors.IsNotFound(err) {
+            ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: binding.Namespace}}
+            err = c.Create(ctx, ns)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to restore namespace %s: %w", binding.Namespace, err)
+        }
+        namespaces[binding.Namespace] = ns
+    }
+
+    for i := range bundle.Inventories {
+        inv := bundle.Inventories[i].DeepCopy()
+        ns, ok := namespaces[inv.Name]
+        if !ok {
+            // The namespace no longer uses a class; nothing to restore
+            continue
+        }
+        inv.OwnerReferences = []metav1.OwnerReference{{
+            APIVersion:         "v1",
+            Kind:               "Namespace",
+            Name:               ns.Name,
+            UID:                ns.UID,
+            BlockOwnerDeletion: ptr.To(false),
+        }}
+        existing := &v1.NamespaceClassInventory{}
+        err := c.Get(ctx, types.NamespacedName{Name: inv.Name}, existing)
+        switch {
+        case errors.IsNotFound(err):
+            err = c.Create(ctx, inv)
+        case err == nil:
+            existing.Labels = inv.Labels
+            existing.OwnerReferences = inv.OwnerReferences
+            existing.Spec = inv.Spec
+            err = c.Update(ctx, existing)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to restore NamespaceClassInventory %s: %w", inv.Name, err)
+        }
+    }
+
+    for _, binding := range bundle.Bindings {
+        ns := namespaces[binding.Namespace]
+        original := ns.DeepCopy()
+        if binding.Class != "" {
+            if ns.Labels == nil {
+                ns.Labels = make(map[string]string)
+            }
+            ns.Labels[controller.LabelKey] = binding.Class
+        }
+        for key, value := range binding.annotations() {
+            if *value != "" {
+                metav1.SetMetaDataAnnotation(&ns.ObjectMeta, key, *value)
+            }
+        }
+        if equality.Semantic.DeepEqual(original.ObjectMeta, ns.ObjectMeta) {
+            continue
+        }
+        if err := c.Update(ctx, ns); err != nil {
+            return fmt.Errorf("failed to label namespace %s: %w", ns.Name, err)
+        }
+    }
+    return nil
+}
+
+// Write serializes a bundle as YAML.
+func Write(w io.Writer, bundle *Bundle) error {
+    data, err := yaml.Marshal(bundle)
+    if err != nil {
+        return err
+    }
+    _, err = w.Write(data)
+    return err
+}
+
+// Read parses a bundle written by Write.
+func Read(r io.Reader) (*Bundle, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    bundle := &Bundle{}
+    if err := yaml.UnmarshalStrict(data, bundle); err != nil {
+        return nil, fmt.Errorf("failed to parse bundle: %w", err)
+    }
+    return bundle, nil
+}
+
+// stripServerFields clears metadata assigned by the apiserver, which cannot
+// be restored into another cluster.
+func stripServerFields(meta *metav1.ObjectMeta) {
+    meta.UID = ""
+    meta.ResourceVersion = ""
+    meta.Generation = 0
+    meta.CreationTimestamp = metav1.Time{}
+    meta.ManagedFields = nil
+    meta.Finalizers = nil
+}
@@ -0,0 +1,197 @@
+// internal/backup/backup_test.go
+package backup
+
+import (
+    "bytes"
+    "context"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+    ctx := context.Background()
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    source := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "public"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+            }},
+        },
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{controller.LabelKey: "public"}}},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unbound"}},
+        &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{controller.LabelKey: "public"}},
+            Spec: v1.NamespaceClassInventorySpec{
+                Namespace: "web",
+                Resources: []v1.InventoryEntry{{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", Class: "public"}},
+            },
+        },
+    ).Build()
+
+    bundle, err := Export(ctx, source)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var buf bytes.Buffer
+    if err := Write(&buf, bundle); err != nil {
+        t.Fatal(err)
+    }
+    restored, err := Read(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(restored.Classes) != 1 || len(restored.Bindings) != 1 || len(restored.Inventories) != 1 {
+        t.Fatalf("unexpected bundle contents: %+v", restored)
+    }
+
+    target := fake.NewClientBuilder().WithScheme(scheme).Build()
+    if err := Import(ctx, target, restored); err != nil {
+        t.Fatal(err)
+    }
+    ns := &corev1.Namespace{}
+    if err := target.Get(ctx, types.NamespacedName{Name: "web"}, ns); err != nil {
+        t.Fatal(err)
+    }
+    if ns.Labels[controller.LabelKey] != "public" {
+        t.Errorf("namespace label = %q, want public", ns.Labels[controller.LabelKey])
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := target.Get(ctx, types.NamespacedName{Name: "public"}, nsc); err != nil {
+        t.Fatal(err)
+    }
+    if len(nsc.Spec.Resources) != 1 {
+        t.Errorf("restored class has %d resources, want 1", len(nsc.Spec.Resources))
+    }
+    inv := &v1.NamespaceClassInventory{}
+    if err := target.Get(ctx, types.NamespacedName{Name: "web"}, inv); err != nil {
+        t.Fatal(err)
+    }
+    if len(inv.OwnerReferences) != 1 || inv.OwnerReferences[0].Name != "web" {
+        t.Errorf("inventory owner references = %+v", inv.OwnerReferences)
+    }
+}
+
+func TestExportImportClassState(t *testing.T) {
+    ctx := context.Background()
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    source := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "public", UID: "public-uid"}},
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+        &v1.NamespaceClassRevision{
+            ObjectMeta: metav1.ObjectMeta{
+                Name:   controller.RevisionName("public", 3),
+                Labels: map[string]string{controller.LabelKey: "public"},
+                OwnerReferences: []metav1.OwnerReference{{
+                    APIVersion: v1.GroupVersion.String(), Kind: "NamespaceClass", Name: "public", UID: "public-uid",
+                }},
+            },
+            Spec: v1.NamespaceClassRevisionSpec{Class: "public", Revision: 3, Hash: "sha256:abc"},
+        },
+        &v1.NamespaceClassBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: "team-a-public"},
+            Spec:       v1.NamespaceClassBindingSpec{ClassName: "public", Groups: []string{"team-a"}},
+        },
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:        "web",
+            Labels:      map[string]string{controller.LabelKey: "public"},
+            Annotations: map[string]string{
+                controller.ClassesAnnotation:  "monitoring",
+                controller.RevisionAnnotation: "3",
+                controller.ValuesAnnotation:   `{"tier":"large"}`,
+            },
+        }},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:        "web-preview",
+            Annotations: map[string]string{controller.HNCSubnamespaceOfAnnotation: "web"},
+        }},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:        "scratch",
+            Annotations: map[string]string{controller.HNCSubnamespaceOfAnnotation: "unbound"},
+        }},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unbound"}},
+        &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "web-preview", Labels: map[string]string{controller.LabelKey: "public"}},
+            Spec:       v1.NamespaceClassInventorySpec{Namespace: "web-preview"},
+        },
+    ).Build()
+
+    bundle, err := Export(ctx, source)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(bundle.Bindings) != 2 || len(bundle.Revisions) != 1 || len(bundle.ClassBindings) != 1 {
+        t.Fatalf("unexpected bundle contents: %+v", bundle)
+    }
+    if rev := bundle.Revisions[0]; len(rev.OwnerReferences) != 0 || rev.UID != "" {
+        t.Errorf("revision keeps cluster specific metadata: %+v", rev.ObjectMeta)
+    }
+
+    target := fake.NewClientBuilder().WithScheme(scheme).Build()
+    if err := Import(ctx, target, bundle); err != nil {
+        t.Fatal(err)
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := target.Get(ctx, types.NamespacedName{Name: "public"}, nsc); err != nil {
+        t.Fatal(err)
+    }
+    rev := &v1.NamespaceClassRevision{}
+    if err := target.Get(ctx, types.NamespacedName{Name: controller.RevisionName("public", 3)}, rev); err != nil {
+        t.Fatal(err)
+    }
+    if len(rev.OwnerReferences) != 1 || rev.OwnerReferences[0].UID != nsc.UID {
+        t.Errorf("revision owner references = %+v, want the restored class", rev.OwnerReferences)
+    }
+    if err := target.Get(ctx, types.NamespacedName{Name: "team-a-public"}, &v1.NamespaceClassBinding{}); err != nil {
+        t.Errorf("class binding not restored: %v", err)
+    }
+
+    ns := &corev1.Namespace{}
+    if err := target.Get(ctx, types.NamespacedName{Name: "web"}, ns); err != nil {
+        t.Fatal(err)
+    }
+    for key, want := range map[string]string{
+        controller.ClassesAnnotation:  "monitoring",
+        controller.RevisionAnnotation: "3",
+        controller.ValuesAnnotation:   `{"tier":"large"}`,
+    } {
+        if got := ns.Annotations[key]; got != want {
+            t.Errorf("annotation %s = %q, want %q", key, got, want)
+        }
+    }
+    if err := target.Get(ctx, types.NamespacedName{Name: "web-preview"}, ns); err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := ns.Labels[controller.LabelKey]; ok {
+        t.Errorf("subnamespace was labelled instead of inheriting its class: %v", ns.Labels)
+    }
+    if ns.Annotations[controller.HNCSubnamespaceOfAnnotation] != "web" {
+        t.Errorf("subnamespace parent = %q, want web", ns.Annotations[controller.HNCSubnamespaceOfAnnotation])
+    }
+    if err := target.Get(ctx, types.NamespacedName{Name: "web-preview"}, &v1.NamespaceClassInventory{}); err != nil {
+        t.Errorf("inventory of subnamespace not restored: %v", err)
+    }
+    if err := target.Get(ctx, types.NamespacedName{Name: "scratch"}, ns); err == nil {
+        t.Errorf("namespace without a class was restored")
+    }
+}
@@ -0,0 +1,84 @@
+// internal/backup/namespace.go
+package backup
+
+import (
+    "context"
+    "fmt"
+    "io"
+
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/yaml"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// NamespaceState is the content of the resources the controller manages in
+// a namespace, as they are applied.
+type NamespaceState struct {
+    Namespace string
+    Class     string
+    // Resources are the live objects tracked by the inventory, in inventory order
+    Resources []*unstructured.Unstructured
+    // Missing are inventory entries whose objects no longer exist
+    Missing []v1.InventoryEntry
+}
+
+// ExportNamespace reads every object tracked in the inventory of a
+// namespace. The inventory only records what is managed; this returns the
+// objects themselves, with their management annotations and hashes, and
+// without the fields assigned by the apiserver.
+func ExportNamespace(ctx context.Context, c client.Reader, namespace string) (*NamespaceState, error) {
+    inv := &v1.NamespaceClassInventory{}
+    if err := c.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+        if errors.IsNotFound(err) {
+            return nil, fmt.Errorf("namespace %s has no NamespaceClassInventory", namespace)
+        }
+        return nil, err
+    }
+
+    state := &NamespaceState{Namespace: namespace, Class: inv.Labels[controller.LabelKey]}
+    for _, entry := range inv.Spec.Resources {
+        obj := &unstructured.Unstructured{}
+        obj.SetAPIVersion(entry.APIVersion)
+        obj.SetKind(entry.Kind)
+        if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: entry.Name}, obj); err != nil {
+            if errors.IsNotFound(err) {
+                state.Missing = append(state.Missing, entry)
+                continue
+            }
+            return nil, fmt.Errorf("failed to get %s %s: %w", entry.Kind, entry.Name, err)
+        }
+        stripUnstructuredServerFields(obj)
+        state.Resources = append(state.Resources, obj)
+    }
+    return state, nil
+}
+
+// WriteNamespace serializes the resources of a namespace as a YAML stream.
+func WriteNamespace(w io.Writer, state *NamespaceState) error {
+    for _, obj := range state.Resources {
+        data, err := yaml.Marshal(obj.Object)
+        if err != nil {
+            return err
+        }
+        if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// stripUnstructuredServerFields is stripServerFields for unstructured
+// objects; status is dropped as well, since it is not applied.
+func stripUnstructuredServerFields(obj *unstructured.Unstructured) {
+    obj.SetUID("")
+    obj.SetResourceVersion("")
+    obj.SetGeneration(0)
+    obj.SetManagedFields(nil)
+    unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+    unstructured.RemoveNestedField(obj.Object, "status")
+}
@@ -0,0 +1,74 @@
+// internal/backup/namespace_test.go
+package backup
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestExportNamespace(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{controller.LabelKey: "public"}},
+            Spec: v1.NamespaceClassInventorySpec{
+                Namespace: "web",
+                Resources: []v1.InventoryEntry{
+                    {APIVersion: "v1", Kind: "ConfigMap", Name: "settings", Class: "public"},
+                    {APIVersion: "v1", Kind: "ServiceAccount", Name: "deployer", Class: "public"},
+                },
+            },
+        },
+        &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "web", UID: "uid-1", Annotations: map[string]string{
+                controller.ManagedByAnnotation:    controller.ManagedByValue,
+                controller.ResourceHashAnnotation: "abc",
+            }},
+            Data: map[string]string{"mode": "strict"},
+        },
+    ).Build()
+
+    state, err := ExportNamespace(context.Background(), c, "web")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if state.Class != "public" || len(state.Resources) != 1 || len(state.Missing) != 1 || state.Missing[0].Name != "deployer" {
+        t.Fatalf("unexpected state: %+v", state)
+    }
+
+    var buf bytes.Buffer
+    if err := WriteNamespace(&buf, state); err != nil {
+        t.Fatal(err)
+    }
+    out := buf.String()
+    for _, want := range []string{"---\n", "kind: ConfigMap", "mode: strict", controller.ResourceHashAnnotation + ": abc"} {
+        if !strings.Contains(out, want) {
+            t.Errorf("output does not contain %q:\n%s", want, out)
+        }
+    }
+    for _, unwanted := range []string{"uid:", "resourceVersion:"} {
+        if strings.Contains(out, unwanted) {
+            t.Errorf("output contains server field %q:\n%s", unwanted, out)
+        }
+    }
+
+    if _, err := ExportNamespace(context.Background(), c, "unmanaged"); err == nil {
+        t.Error("expected an error for a namespace without inventory")
+    }
+}
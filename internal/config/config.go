@@ -0,0 +1,147 @@
+// internal/config/config.go
+package config
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/yaml"
+)
+
+const (
+    // APIVersion is the version of the configuration file format
+    APIVersion = "config.namespaceclass.akuity.io/v1alpha1"
+
+    // Kind is the kind of the configuration file
+    Kind = "ControllerConfiguration"
+)
+
+// Configuration is the typed configuration file of the controller manager,
+// loaded with --config. Every setting corresponds to a command-line flag and
+// sets that flag unless it is also given on the command line or in the
+// environment, so the file holds the settings a deployment shares and flags
+// stay available for one-off overrides.
+type Configuration struct {
+    APIVersion string `json:"apiVersion"`
+    Kind       string `json:"kind"`
+
+    // LeaderElection configures leader election between replicas.
+    LeaderElection LeaderElection `json:"leaderElection,omitempty"`
+
+    // Concurrency is the number of namespaces reconciled in parallel
+    // (--max-concurrent-reconciles).
+    Concurrency *int `json:"concurrency,omitempty"`
+
+    // SyncPeriod is how often the cache is resynced, reconciling every
+    // namespace again (--sync-period).
+    SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+
+    // ExcludedNamespaces are namespaces the controller leaves alone
+    // (--excluded-namespaces). They are reloaded on SIGHUP.
+    ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+
+    // FeatureGates turns experimental features on or off (--feature-gates).
+    FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+    // Flags sets any other flag by name, e.g. "orphan-scan-interval": "5m".
+    Flags map[string]string `json:"flags,omitempty"`
+}
+
+// LeaderElection configures leader election between replicas.
+type LeaderElection struct {
+    // LeaderElect enables leader election (--leader-elect).
+    LeaderElect *bool `json:"leaderElect,omitempty"`
+
+    // ResourceName is the name of the lease (--leader-election-id).
+    ResourceName string `json:"resourceName,omitempty"`
+}
+
+// Load reads and validates a configuration file. Unknown fields are
+// rejected so that typos do not silently leave a setting at its default.
+func Load(path string) (*Configuration, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read configuration: %w", err)
+    }
+    c := &Configuration{}
+    if err := yaml.UnmarshalStrict(data, c); err != nil {
+        return nil, fmt.Errorf("failed to parse configuration %s: %w", path, err)
+    }
+    if c.APIVersion != APIVersion || c.Kind != Kind {
+        return nil, fmt.Errorf("configuration %s must have apiVersion %s and kind %s", path, APIVersion, Kind)
+    }
+    if c.Concurrency != nil && *c.Concurrency < 1 {
+        return nil, fmt.Errorf("configuration %s: concurrency must be positive", path)
+    }
+    if c.SyncPeriod != nil && c.SyncPeriod.Duration <= 0 {
+        return nil, fmt.Errorf("configuration %s: syncPeriod must be positive", path)
+    }
+    return c, nil
+}
+
+// Values returns the flag values the configuration sets, by flag name.
+// Typed settings take precedence over the same flag in Flags.
+func (c *Configuration) Values() map[string]string {
+    values := make(map[string]string, len(c.Flags)+6)
+    for name, value := range c.Flags {
+        values[name] = value
+    }
+    if c.LeaderElection.LeaderElect != nil {
+        values["leader-elect"] = strconv.FormatBool(*c.LeaderElection.LeaderElect)
+    }
+    if c.LeaderElection.ResourceName != "" {
+        values["leader-election-id"] = c.LeaderElection.ResourceName
+    }
+    if c.Concurrency != nil {
+        values["max-concurrent-reconciles"] = strconv.Itoa(*c.Concurrency)
+    }
+    if c.SyncPeriod != nil {
+        values["sync-period"] = c.SyncPeriod.Duration.String()
+    }
+    if c.ExcludedNamespaces != nil {
+        values["excluded-namespaces"] = strings.Join(c.ExcludedNamespaces, ",")
+    }
+    if c.FeatureGates != nil {
+        gates := make([]string, 0, len(c.FeatureGates))
+        for name, enabled := range c.FeatureGates {
+            gates = append(gates, fmt.Sprintf("%s=%t", name, enabled))
+        }
+        sort.Strings(gates)
+        values["feature-gates"] = strings.Join(gates, ",")
+    }
+    return values
+}
+
+// Apply sets the flags of a parsed flag set to the values of the
+// configuration, except for flags already set on the command line or from
+// the environment. It returns the names of those flags.
+func (c *Configuration) Apply(fs *flag.FlagSet) (map[string]bool, error) {
+    explicit := make(map[string]bool)
+    fs.Visit(func(f *flag.Flag) {
+        explicit[f.Name] = true
+    })
+
+    values := c.Values()
+    names := make([]string, 0, len(values))
+    for name := range values {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        if name == "config" || fs.Lookup(name) == nil {
+            return nil, fmt.Errorf("configuration sets unknown flag %q", name)
+        }
+        if explicit[name] {
+            continue
+        }
+        if err := fs.Set(name, values[name]); err != nil {
+            return nil, fmt.Errorf("configuration sets invalid value for flag %q: %w", name, err)
+        }
+    }
+    return explicit, nil
+}
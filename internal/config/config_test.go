@@ -0,0 +1,94 @@
+// internal/config/config_test.go
+package config
+
+import (
+    "flag"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestApply(t *testing.T) {
+    path := writeConfig(t, `
+apiVersion: config.namespaceclass.akuity.io/v1alpha1
+kind: ControllerConfiguration
+leaderElection:
+  leaderElect: true
+  resourceName: nscc-leader
+concurrency: 10
+syncPeriod: 1h
+excludedNamespaces: [kube-system, kube-public]
+featureGates:
+  ServerSideApply: true
+  DriftWatches: false
+flags:
+  orphan-scan-interval: 5m
+  leader-election-id: ignored
+`)
+    c, err := Load(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+    leaderElect := fs.Bool("leader-elect", false, "")
+    leaderElectionID := fs.String("leader-election-id", "", "")
+    concurrency := fs.Int("max-concurrent-reconciles", 5, "")
+    syncPeriod := fs.Duration("sync-period", 10*time.Hour, "")
+    excluded := fs.String("excluded-namespaces", "", "")
+    orphanScan := fs.Duration("orphan-scan-interval", 10*time.Minute, "")
+    featureGates := fs.String("feature-gates", "", "")
+    if err := fs.Parse([]string{"--max-concurrent-reconciles=3"}); err != nil {
+        t.Fatal(err)
+    }
+
+    explicit, err := c.Apply(fs)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !explicit["max-concurrent-reconciles"] || len(explicit) != 1 {
+        t.Errorf("unexpected command-line flags %v", explicit)
+    }
+    if !*leaderElect || *leaderElectionID != "nscc-leader" {
+        t.Errorf("unexpected leader election %v %q", *leaderElect, *leaderElectionID)
+    }
+    if *concurrency != 3 {
+        t.Errorf("command-line concurrency overridden: got %d", *concurrency)
+    }
+    if *syncPeriod != time.Hour || *orphanScan != 5*time.Minute {
+        t.Errorf("unexpected durations %s %s", *syncPeriod, *orphanScan)
+    }
+    if *excluded != "kube-system,kube-public" {
+        t.Errorf("unexpected excluded namespaces %q", *excluded)
+    }
+    if *featureGates != "DriftWatches=false,ServerSideApply=true" {
+        t.Errorf("unexpected feature gates %q", *featureGates)
+    }
+}
+
+func TestLoadRejectsInvalidConfiguration(t *testing.T) {
+    for name, content := range map[string]string{
+        "wrong kind":    "apiVersion: config.namespaceclass.akuity.io/v1alpha1\nkind: Other\n",
+        "unknown field": "apiVersion: config.namespaceclass.akuity.io/v1alpha1\nkind: ControllerConfiguration\nconcurency: 3\n",
+        "concurrency":   "apiVersion: config.namespaceclass.akuity.io/v1alpha1\nkind: ControllerConfiguration\nconcurrency: 0\n",
+    } {
+        if _, err := Load(writeConfig(t, content)); err == nil {
+            t.Errorf("%s: expected an error", name)
+        }
+    }
+
+    c := &Configuration{Flags: map[string]string{"no-such-flag": "1"}}
+    if _, err := c.Apply(flag.NewFlagSet("manager", flag.ContinueOnError)); err == nil {
+        t.Error("expected an error for an unknown flag")
+    }
+}
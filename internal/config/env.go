@@ -0,0 +1,41 @@
+// internal/config/env.go
+package config
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// Prefix of the environment variables that set flags
+const EnvPrefix = "NSCC_"
+
+// EnvName returns the environment variable that sets a flag, e.g.
+// NSCC_METRICS_BIND_ADDRESS for --metrics-bind-address.
+func EnvName(flagName string) string {
+    return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flagName))
+}
+
+// ApplyEnv sets the flags of a parsed flag set from their environment
+// variables, except for flags given on the command line. Flags set this way
+// count as set, so a configuration file applied afterwards does not override
+// them.
+func ApplyEnv(fs *flag.FlagSet) error {
+    explicit := make(map[string]bool)
+    fs.Visit(func(f *flag.Flag) {
+        explicit[f.Name] = true
+    })
+
+    var err error
+    fs.VisitAll(func(f *flag.Flag) {
+        value, ok := os.LookupEnv(EnvName(f.Name))
+        if err != nil || !ok || explicit[f.Name] {
+            return
+        }
+        if setErr := fs.Set(f.Name, value); setErr != nil {
+            err = fmt.Errorf("invalid value of %s: %w", EnvName(f.Name), setErr)
+        }
+    })
+    return err
+}
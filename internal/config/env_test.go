@@ -0,0 +1,50 @@
+// internal/config/env_test.go
+package config
+
+import (
+    "flag"
+    "testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+    t.Setenv("NSCC_METRICS_BIND_ADDRESS", ":9090")
+    t.Setenv("NSCC_MAX_CONCURRENT_RECONCILES", "8")
+    t.Setenv("NSCC_LEADER_ELECT", "true")
+
+    fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+    metricsAddr := fs.String("metrics-bind-address", ":8080", "")
+    concurrency := fs.Int("max-concurrent-reconciles", 5, "")
+    leaderElect := fs.Bool("leader-elect", false, "")
+    if err := fs.Parse([]string{"--max-concurrent-reconciles=3"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := ApplyEnv(fs); err != nil {
+        t.Fatal(err)
+    }
+    if *metricsAddr != ":9090" || !*leaderElect {
+        t.Errorf("environment not applied: %q %v", *metricsAddr, *leaderElect)
+    }
+    if *concurrency != 3 {
+        t.Errorf("command-line flag overridden by environment: got %d", *concurrency)
+    }
+
+    // The environment takes precedence over the configuration file
+    c := &Configuration{Flags: map[string]string{"metrics-bind-address": ":7070"}}
+    explicit, err := c.Apply(fs)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if *metricsAddr != ":9090" || !explicit["metrics-bind-address"] {
+        t.Errorf("environment overridden by configuration: %q", *metricsAddr)
+    }
+
+    t.Setenv("NSCC_LEADER_ELECT", "maybe")
+    if err := ApplyEnv(flag.NewFlagSet("manager", flag.ContinueOnError)); err != nil {
+        t.Errorf("unexpected error for unknown flags: %v", err)
+    }
+    fs = flag.NewFlagSet("manager", flag.ContinueOnError)
+    fs.Bool("leader-elect", false, "")
+    if err := ApplyEnv(fs); err == nil {
+        t.Error("expected an error for an invalid value")
+    }
+}
@@ -0,0 +1,152 @@
+// internal/controller/addons.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation on a namespace listing add-on classes, comma-separated, whose
+// resources are applied in addition to those of the class in its label
+const ClassesAnnotation = "namespaceclass.akuity.io/classes"
+
+// addonClassNames returns the add-on classes of a namespace in the order they
+// are listed, without duplicates and without its primary class.
+func addonClassNames(ns *corev1.Namespace, primary string) []string {
+    var names []string
+    seen := map[string]bool{primary: true}
+    for _, name := range strings.Split(ns.Annotations[ClassesAnnotation], ",") {
+        name = strings.TrimSpace(name)
+        if name == "" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        names = append(names, name)
+    }
+    return names
+}
+
+// NamespaceClasses returns the class in the label of a namespace followed by
+// its add-on classes, or nil if it has no class label.
+func NamespaceClasses(ns *corev1.Namespace) []string {
+    primary := ns.Labels[LabelKey]
+    if primary == "" {
+        return nil
+    }
+    return append([]string{primary}, addonClassNames(ns, primary)...)
+}
+
+// resourceClasses maps the resources of a namespace to the add-on class they
+// come from. Resources that are not in the map come from the primary class.
+type resourceClasses map[*unstructured.Unstructured]*v1.NamespaceClass
+
+// of returns the class a resource is applied for.
+func (c resourceClasses) of(res *unstructured.Unstructured, primary *v1.NamespaceClass) *v1.NamespaceClass {
+    if nsc, ok := c[res]; ok {
+        return nsc
+    }
+    return primary
+}
+
+// classConflictError reports resources that more than one class of a
+// namespace defines.
+type classConflictError struct {
+    conflicts []string
+}
+
+func (e *classConflictError) Error() string {
+    return fmt.Sprintf("resources are defined by more than one class: %s", strings.Join(e.conflicts, "; "))
+}
+
+// classResources merges the resources of the primary class of a namespace
+// with those of its add-on classes, the primary class first and add-ons in
+// the order they are listed. Classes may not define the same resource: which
+// class manages it would be ambiguous, so a collision is returned as a
+// classConflictError instead of letting one class silently win.
+func (r *NamespaceClassReconciler) classResources(ctx context.Context, primary *v1.NamespaceClass, addons []*v1.NamespaceClass) ([]*unstructured.Unstructured, resourceClasses, error) {
+    resources, err := r.parseResources(ctx, primary)
+    if err != nil {
+        return nil, nil, err
+    }
+    sources := make(map[string]string, len(resources))
+    for _, res := range resources {
+        sources[resourceKey(res)] = primary.Name
+    }
+
+    classes := make(resourceClasses)
+    var conflicts []string
+    for _, addon := range addons {
+        addonResources, err := r.parseResources(ctx, addon)
+        if err != nil {
+            return nil, nil, err
+        }
+        for _, res := range addonResources {
+            key := resourceKey(res)
+            if _, ok := sources[key]; ok && isImagePullSecretCopy(res) {
+                // Classes share the copy of the central image pull secret
+                continue
+            }
+            if source, ok := sources[key]; ok {
+                conflicts = append(conflicts, fmt.Sprintf("%s %s in classes %s and %s", res.GetKind(), res.GetName(), source, addon.Name))
+                continue
+            }
+            sources[key] = addon.Name
+            classes[res] = addon
+            resources = append(resources, res)
+        }
+    }
+    if len(conflicts) > 0 {
+        return nil, nil, &classConflictError{conflicts: conflicts}
+    }
+    return resources, classes, nil
+}
+
+// reportClassConflict records that the classes of a namespace collide, with
+// a warning event and a Degraded condition on its inventory. Namespaces that
+// have no inventory yet get an empty one so the condition can be reported.
+func (r *NamespaceClassReconciler) reportClassConflict(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, currentManaged []ManagedResource, previousClass string, conflict *classConflictError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonClassConflict,
+        "Not applying any resources: %s", strings.Join(conflict.conflicts, "; "))
+    if previousClass == "" && len(currentManaged) == 0 {
+        if err := r.updateManagedResources(ctx, ns, nsc.Name, nil); err != nil {
+            return err
+        }
+    }
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonClassConflict,
+        Message: conflict.Error(),
+    })
+}
+
+// addonClasses fetches the add-on classes of a namespace. It returns the name
+// of the first class that does not exist, if any.
+func (r *NamespaceClassReconciler) addonClasses(ctx context.Context, ns *corev1.Namespace, primary string) ([]*v1.NamespaceClass, string, error) {
+    var addons []*v1.NamespaceClass
+    for _, name := range addonClassNames(ns, primary) {
+        nsc := &v1.NamespaceClass{}
+        if err := r.Get(ctx, types.NamespacedName{Name: name}, nsc); err != nil {
+            if errors.IsNotFound(err) {
+                return nil, name, nil
+            }
+            return nil, "", err
+        }
+        addons = append(addons, nsc)
+    }
+    return addons, "", nil
+}
+
+// resourceKey identifies a resource within a namespace.
+func resourceKey(res *unstructured.Unstructured) string {
+    return res.GetAPIVersion() + "/" + res.GetKind() + "/" + res.GetName()
+}
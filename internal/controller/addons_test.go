@@ -0,0 +1,97 @@
+// internal/controller/addons_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Add-on classes", func() {
+    It("should merge the resources of add-on classes and prune them per class", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        observability := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"owner":"observability"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"collector"}}`)},
+            }},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:        "team",
+                Labels:      map[string]string{LabelKey: "baseline"},
+                Annotations: map[string]string{ClassesAnnotation: "observability, baseline"},
+                Finalizers:  []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"owner":"baseline"}}`)},
+                }},
+            },
+            observability,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        recorder := record.NewFakeRecorder(50)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        key := func(name string) types.NamespacedName {
+            return types.NamespacedName{Namespace: "team", Name: name}
+        }
+
+        // Both classes define the ConfigMap, so nothing is applied
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).To(MatchError(ContainSubstring("ConfigMap settings in classes baseline and observability")))
+        Expect(errors.IsNotFound(cl.Get(ctx, key("settings"), &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(errors.IsNotFound(cl.Get(ctx, key("collector"), &corev1.ServiceAccount{}))).To(BeTrue())
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        degraded := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionDegraded)
+        Expect(degraded).NotTo(BeNil())
+        Expect(degraded.Reason).To(Equal(v1.ReasonClassConflict))
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring("Warning ClassConflict Not applying any resources")))
+
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "observability"}, observability)).To(Succeed())
+        observability.Spec.Resources = observability.Spec.Resources[1:]
+        Expect(cl.Update(ctx, observability)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, key("settings"), &corev1.ConfigMap{})).To(Succeed())
+        sa := &corev1.ServiceAccount{}
+        Expect(cl.Get(ctx, key("collector"), sa)).To(Succeed())
+        Expect(sa.Annotations[CreatedByClassAnnotation]).To(Equal("observability"))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionDegraded)).To(BeNil())
+
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        managed, err := reconciler.getManagedResources(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        classes := map[string]string{}
+        for _, res := range managed {
+            classes[res.Name] = res.Class
+        }
+        Expect(classes).To(Equal(map[string]string{"settings": "baseline", "collector": "observability"}))
+
+        delete(ns.Annotations, ClassesAnnotation)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, key("collector"), &corev1.ServiceAccount{}))).To(BeTrue())
+        Expect(cl.Get(ctx, key("settings"), &corev1.ConfigMap{})).To(Succeed())
+    })
+})
@@ -0,0 +1,177 @@
+// internal/controller/adopt.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Adoption actions, describing what happens to a class resource when an
+// existing namespace is onboarded.
+const (
+    // AdoptionInSync resources already match the class and are taken over as they are
+    AdoptionInSync = "InSync"
+    // AdoptionUpdate resources are adopted and updated in place on the next reconcile
+    AdoptionUpdate = "Update"
+    // AdoptionCreate resources do not exist yet and are created on the next reconcile
+    AdoptionCreate = "Create"
+    // AdoptionManaged resources are already managed for the class
+    AdoptionManaged = "Managed"
+    // AdoptionSkipped resources belong to another class or are propagated by HNC
+    AdoptionSkipped = "Skipped"
+)
+
+// Adoption is the outcome of adopting a single class resource.
+type Adoption struct {
+    Kind   string
+    Name   string
+    Action string
+    // Reason explains skipped resources
+    Reason string
+}
+
+// AdoptNamespace onboards an existing namespace onto a class without
+// recreating the resources it already has. Live objects matching class
+// resources are taken over: objects that already match the class are
+// annotated as managed with the hash of the class, so the controller leaves
+// them untouched, and the rest are marked for adoption so the controller
+// updates them in place. The inventory is written before the namespace is
+// labeled, so the first reconcile already knows every adopted object. With
+// dryRun nothing is written.
+func AdoptNamespace(ctx context.Context, c client.Client, namespace, className string, dryRun bool) ([]Adoption, error) {
+    ns := &corev1.Namespace{}
+    if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+        return nil, err
+    }
+    if current := ns.Labels[LabelKey]; current != "" && current != className {
+        return nil, fmt.Errorf("namespace %s already uses class %s", namespace, current)
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := c.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        return nil, err
+    }
+    desired, err := RenderNamespaceClass(ctx, c, nsc, ns)
+    if err != nil {
+        return nil, err
+    }
+
+    // Keep what is already tracked, so re-running adopt never orphans resources
+    r := &NamespaceClassReconciler{Client: c}
+    managed, err := r.getManagedResources(ctx, ns)
+    if err != nil {
+        return nil, err
+    }
+    tracked := make(map[string]int, len(managed))
+    for i, res := range managed {
+        tracked[res.key()] = i
+    }
+
+    var adoptions []Adoption
+    for _, res := range desired {
+        adoption, hash, err := adoptResource(ctx, c, res, nsc, dryRun)
+        if err != nil {
+            return adoptions, err
+        }
+        adoptions = append(adoptions, adoption)
+        if adoption.Action == AdoptionCreate || adoption.Action == AdoptionSkipped {
+            continue
+        }
+        entry := ManagedResource{
+            APIVersion:    res.GetAPIVersion(),
+            Kind:          res.GetKind(),
+            Name:          res.GetName(),
+            Hash:          hash,
+            Class:         className,
+            ClusterScoped: isClusterScoped(res),
+            ID:            res.GetAnnotations()[ResourceIDAnnotation],
+        }
+        if i, ok := tracked[entry.key()]; ok {
+            managed[i] = entry
+            continue
+        }
+        tracked[entry.key()] = len(managed)
+        managed = append(managed, entry)
+    }
+    if dryRun {
+        return adoptions, nil
+    }
+
+    if err := r.updateManagedResources(ctx, ns, className, managed); err != nil {
+        return adoptions, fmt.Errorf("failed to write inventory: %w", err)
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    if ns.Labels == nil {
+        ns.Labels = make(map[string]string)
+    }
+    ns.Labels[LabelKey] = className
+    if err := c.Patch(ctx, ns, patch); err != nil {
+        return adoptions, fmt.Errorf("failed to label namespace: %w", err)
+    }
+    return adoptions, nil
+}
+
+// adoptResource annotates the live object of a rendered class resource and
+// returns the action taken with the hash to record in the inventory.
+func adoptResource(ctx context.Context, c client.Client, desired *unstructured.Unstructured, nsc *v1.NamespaceClass, dryRun bool) (Adoption, string, error) {
+    adoption := Adoption{Kind: desired.GetKind(), Name: desired.GetName()}
+    live := &unstructured.Unstructured{}
+    live.SetGroupVersionKind(desired.GroupVersionKind())
+    if err := c.Get(ctx, client.ObjectKeyFromObject(desired), live); err != nil {
+        if errors.IsNotFound(err) {
+            adoption.Action = AdoptionCreate
+            return adoption, "", nil
+        }
+        return adoption, "", err
+    }
+
+    switch {
+    case isHNCPropagated(live):
+        adoption.Action = AdoptionSkipped
+        adoption.Reason = fmt.Sprintf("propagated by HNC from %s", live.GetLabels()[HNCInheritedFromLabel])
+        return adoption, "", nil
+    case isManagedByController(live) && live.GetAnnotations()[CreatedByClassAnnotation] != nsc.Name:
+        adoption.Action = AdoptionSkipped
+        adoption.Reason = fmt.Sprintf("managed by class %q", live.GetAnnotations()[CreatedByClassAnnotation])
+        return adoption, "", nil
+    case isManagedByController(live):
+        adoption.Action = AdoptionManaged
+        return adoption, live.GetAnnotations()[ResourceHashAnnotation], nil
+    }
+
+    patch := client.MergeFrom(live.DeepCopy())
+    annotations := live.GetAnnotations()
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    annotations[ManagedByAnnotation] = ManagedByValue
+    annotations[CreatedByClassAnnotation] = nsc.Name
+    live.SetAnnotations(annotations)
+
+    var hash string
+    if semanticEqual(comparableObject(desired, renderOptions(desired, nsc).unmanagedFields()), live.Object) {
+        hash = desired.GetAnnotations()[ResourceHashAnnotation]
+        annotations[ResourceHashAnnotation] = hash
+        adoption.Action = AdoptionInSync
+    } else {
+        // Let the controller adopt it, which keeps labels and annotations
+        // the class does not set
+        delete(annotations, ManagedByAnnotation)
+        delete(annotations, CreatedByClassAnnotation)
+        annotations[AdoptAnnotation] = "true"
+        adoption.Action = AdoptionUpdate
+    }
+    live.SetAnnotations(annotations)
+
+    if dryRun {
+        return adoption, hash, nil
+    }
+    return adoption, hash, c.Patch(ctx, live, patch)
+}
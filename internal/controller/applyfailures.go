@@ -0,0 +1,71 @@
+// internal/controller/applyfailures.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// applyFailure is a resource that could not be applied to a namespace.
+type applyFailure struct {
+    resource *unstructured.Unstructured
+    err      error
+}
+
+// applyFailuresError reports the resources of a class with the
+// ContinueOnError apply policy that failed to apply, after the remaining
+// resources were applied. Returning it requeues the namespace with the usual
+// backoff.
+type applyFailuresError struct {
+    failures []applyFailure
+    total    int
+}
+
+func (e *applyFailuresError) Error() string {
+    messages := make([]string, 0, len(e.failures))
+    for _, f := range e.failures {
+        messages = append(messages, fmt.Sprintf("%s %s: %v", f.resource.GetKind(), f.resource.GetName(), f.err))
+    }
+    return fmt.Sprintf("failed to apply %d of %d resources: %s", len(e.failures), e.total, strings.Join(messages, "; "))
+}
+
+// transient reports whether every failure is worth retrying without marking
+// the namespace as failed.
+func (e *applyFailuresError) transient() bool {
+    for _, f := range e.failures {
+        if !isTransientError(f.err) {
+            return false
+        }
+    }
+    return true
+}
+
+// continuesOnError reports whether a namespace of a class is applied past
+// resources that fail.
+func continuesOnError(nsc *v1.NamespaceClass) bool {
+    return nsc.Spec.ApplyPolicy == v1.ApplyPolicyContinueOnError
+}
+
+// reportApplyFailures records the resources that failed to apply with a
+// Degraded condition on the inventory of the namespace, listing one resource
+// per line.
+func (r *NamespaceClassReconciler) reportApplyFailures(ctx context.Context, ns *corev1.Namespace, failuresErr *applyFailuresError) error {
+    lines := make([]string, 0, len(failuresErr.failures))
+    for _, f := range failuresErr.failures {
+        lines = append(lines, fmt.Sprintf("%s %s (%s): %v", f.resource.GetKind(), f.resource.GetName(), f.resource.GetAPIVersion(), f.err))
+    }
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonApplyFailed,
+        Message: fmt.Sprintf("%d of %d resources failed to apply:\n%s",
+            len(failuresErr.failures), failuresErr.total, strings.Join(lines, "\n")),
+    })
+}
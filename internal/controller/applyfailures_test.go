@@ -0,0 +1,147 @@
+// internal/controller/applyfailures_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Apply policy", func() {
+    It("should apply the remaining resources and report each failure with ContinueOnError", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}}`)},
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}`)},
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c"}}`)},
+                },
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+                if obj.GetName() == "b" {
+                    return errors.NewForbidden(corev1.Resource("configmaps"), "b", fmt.Errorf("denied by admission webhook"))
+                }
+                return c.Create(ctx, obj, opts...)
+            },
+        }).Build()
+        recorder := record.NewFakeRecorder(20)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        exists := func(name string) bool {
+            return cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, &corev1.ConfigMap{}) == nil
+        }
+
+        // By default the first failure stops the sync
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).To(HaveOccurred())
+        Expect(exists("a")).To(BeTrue())
+        Expect(exists("c")).To(BeFalse())
+
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.ApplyPolicy = v1.ApplyPolicyContinueOnError
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).To(BeAssignableToTypeOf(&applyFailuresError{}))
+        Expect(err.Error()).To(ContainSubstring("failed to apply 1 of 3 resources: ConfigMap b"))
+        Expect(exists("c")).To(BeTrue())
+
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        var names []string
+        for _, res := range inv.Spec.Resources {
+            names = append(names, res.Name)
+        }
+        Expect(names).To(ConsistOf("a", "c"))
+        degraded := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionDegraded)
+        Expect(degraded).NotTo(BeNil())
+        Expect(degraded.Reason).To(Equal(v1.ReasonApplyFailed))
+        Expect(degraded.Message).To(ContainSubstring("ConfigMap b (v1): "))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseFailed))
+    })
+
+    It("should revert the resources applied before a failure with Atomic", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        configMap := func(name, value string) runtime.RawExtension {
+            return runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":%q},"data":{"value":%q}}`, name, value))}
+        }
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 1},
+            Spec: v1.NamespaceClassSpec{
+                ApplyPolicy: v1.ApplyPolicyAtomic,
+                Resources:   []runtime.RawExtension{configMap("a", "1"), configMap("b", "1")},
+            },
+        }
+        revision := &v1.NamespaceClassRevision{
+            ObjectMeta: metav1.ObjectMeta{Name: RevisionName("web", 1), Labels: map[string]string{LabelKey: "web"}},
+            Spec:       v1.NamespaceClassRevisionSpec{Class: "web", Revision: 1, Template: *nsc.Spec.DeepCopy()},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc, revision,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+                if obj.GetName() == "e" {
+                    return errors.NewForbidden(corev1.Resource("configmaps"), "e", fmt.Errorf("denied by admission webhook"))
+                }
+                return c.Create(ctx, obj, opts...)
+            },
+        }).Build()
+        recorder := record.NewFakeRecorder(20)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        // Update one resource and add two, the last of which fails
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.Resources = []runtime.RawExtension{configMap("a", "2"), configMap("b", "1"), configMap("d", "2"), configMap("e", "2")}
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).To(HaveOccurred())
+        Expect(err.Error()).To(ContainSubstring("rolled back 2 resources"))
+        Expect(err.Error()).NotTo(ContainSubstring("could not restore"))
+
+        cm := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "a"}, cm)).To(Succeed())
+        Expect(cm.Data["value"]).To(Equal("1"))
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "d"}, cm))).To(BeTrue())
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(inv.Spec.Resources).To(HaveLen(2))
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring(ReasonRolledBack)))
+    })
+})
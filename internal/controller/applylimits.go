@@ -0,0 +1,117 @@
+// internal/controller/applylimits.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "sync"
+
+    "golang.org/x/time/rate"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ApplyRateLimit is a token bucket for the writes of one kind.
+type ApplyRateLimit struct {
+    // PerSecond is the sustained rate of creates and updates; zero leaves the kind unlimited
+    PerSecond float64
+
+    // Burst is the number of writes allowed above that rate; it defaults to one
+    Burst int
+}
+
+// KindRateLimit is the apply rate limit of the kinds matching a Kind,
+// Kind.group or *.group pattern.
+type KindRateLimit struct {
+    Pattern string
+    ApplyRateLimit
+}
+
+// ApplyRateLimits limits how fast the controller creates and updates the
+// resources of each kind, so that a class with heavy objects, such as large
+// ConfigMaps, cannot saturate the API server when many namespaces sync at
+// once. Every GroupVersionKind has its own token bucket; reconciles wait for
+// a token before each write.
+type ApplyRateLimits struct {
+    // Default is the limit of kinds no rule in Kinds matches
+    Default ApplyRateLimit
+
+    // Kinds are the limits of specific kinds; the first matching rule applies
+    Kinds []KindRateLimit
+
+    mu       sync.Mutex
+    limiters map[schema.GroupVersionKind]*rate.Limiter
+}
+
+// wait blocks until a resource of a kind may be written. A nil limit or a
+// kind without a rate returns immediately.
+func (l *ApplyRateLimits) wait(ctx context.Context, gvk schema.GroupVersionKind) error {
+    if l == nil {
+        return nil
+    }
+    limiter := l.limiter(gvk)
+    if limiter == nil {
+        return nil
+    }
+    return limiter.Wait(ctx)
+}
+
+// limiter returns the token bucket of a kind, or nil if it is unlimited.
+func (l *ApplyRateLimits) limiter(gvk schema.GroupVersionKind) *rate.Limiter {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if limiter, ok := l.limiters[gvk]; ok {
+        return limiter
+    }
+    limit := l.Default
+    for _, rule := range l.Kinds {
+        if kindMatches(rule.Pattern, gvk.GroupKind()) {
+            limit = rule.ApplyRateLimit
+            break
+        }
+    }
+    var limiter *rate.Limiter
+    if limit.PerSecond > 0 {
+        burst := limit.Burst
+        if burst < 1 {
+            burst = 1
+        }
+        limiter = rate.NewLimiter(rate.Limit(limit.PerSecond), burst)
+    }
+    if l.limiters == nil {
+        l.limiters = make(map[schema.GroupVersionKind]*rate.Limiter)
+    }
+    l.limiters[gvk] = limiter
+    return limiter
+}
+
+// ParseKindRateLimits parses a comma-separated list of pattern=rate or
+// pattern=rate:burst rules, e.g. "ConfigMap=5:10,*.apps=20".
+func ParseKindRateLimits(value string) ([]KindRateLimit, error) {
+    var rules []KindRateLimit
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        pattern, limit, ok := strings.Cut(item, "=")
+        if !ok || pattern == "" {
+            return nil, fmt.Errorf("invalid rate limit %q: expected kind=rate or kind=rate:burst", item)
+        }
+        perSecond, burst, hasBurst := strings.Cut(limit, ":")
+        rule := KindRateLimit{Pattern: pattern}
+        var err error
+        if rule.PerSecond, err = strconv.ParseFloat(perSecond, 64); err != nil || rule.PerSecond < 0 {
+            return nil, fmt.Errorf("invalid rate in rate limit %q", item)
+        }
+        if hasBurst {
+            if rule.Burst, err = strconv.Atoi(burst); err != nil || rule.Burst < 0 {
+                return nil, fmt.Errorf("invalid burst in rate limit %q", item)
+            }
+        }
+        rules = append(rules, rule)
+    }
+    return rules, nil
+}
@@ -0,0 +1,48 @@
+// internal/controller/applylimits_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Apply rate limits", func() {
+    It("should parse per-kind rate limits", func() {
+        rules, err := ParseKindRateLimits("ConfigMap=5:10, *.apps=20")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(rules).To(Equal([]KindRateLimit{
+            {Pattern: "ConfigMap", ApplyRateLimit: ApplyRateLimit{PerSecond: 5, Burst: 10}},
+            {Pattern: "*.apps", ApplyRateLimit: ApplyRateLimit{PerSecond: 20}},
+        }))
+        for _, invalid := range []string{"ConfigMap", "ConfigMap=fast", "ConfigMap=5:many", "=5"} {
+            _, err := ParseKindRateLimits(invalid)
+            Expect(err).To(HaveOccurred(), invalid)
+        }
+    })
+
+    It("should throttle writes of each kind separately", func() {
+        ctx := context.Background()
+        limits := &ApplyRateLimits{
+            Kinds: []KindRateLimit{{Pattern: "ConfigMap", ApplyRateLimit: ApplyRateLimit{PerSecond: 10, Burst: 2}}},
+        }
+        configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+        secret := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+        start := time.Now()
+        for i := 0; i < 4; i++ {
+            Expect(limits.wait(ctx, configMap)).To(Succeed())
+        }
+        Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+
+        // Kinds without a rule are not limited
+        start = time.Now()
+        for i := 0; i < 100; i++ {
+            Expect(limits.wait(ctx, secret)).To(Succeed())
+        }
+        Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+    })
+})
@@ -0,0 +1,98 @@
+// internal/controller/applyresults.go
+package controller
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// applyResults collects what the current sync of a namespace did to each
+// resource it applied, by resource key.
+type applyResults map[string]v1.ResourceApplyResult
+
+// record sets the outcome of applying a resource; a non-nil error records a
+// failure with the error as message.
+func (a applyResults) record(res *unstructured.Unstructured, result v1.ApplyResult, err error) {
+    entry := v1.ResourceApplyResult{
+        APIVersion: res.GetAPIVersion(),
+        Kind:       res.GetKind(),
+        Name:       res.GetName(),
+        Result:     result,
+    }
+    if err != nil {
+        entry.Result = v1.ApplyResultFailed
+        entry.Message = err.Error()
+    }
+    a[resourceKey(res)] = entry
+}
+
+// mergeApplyResults returns the apply results of the desired resources of a
+// namespace, in their order: the results of the current sync where there are
+// any, and the previous results of resources the sync did not reach. Results
+// that did not change keep the time they were first reported, so syncs that
+// change nothing do not rewrite the inventory.
+func mergeApplyResults(previous []v1.ResourceApplyResult, desired []*unstructured.Unstructured, results applyResults, now metav1.Time) []v1.ResourceApplyResult {
+    byKey := make(map[string]v1.ResourceApplyResult, len(previous))
+    for _, entry := range previous {
+        byKey[entry.APIVersion+"/"+entry.Kind+"/"+entry.Name] = entry
+    }
+    var merged []v1.ResourceApplyResult
+    for _, res := range desired {
+        key := resourceKey(res)
+        last, hasLast := byKey[key]
+        current, ok := results[key]
+        switch {
+        case ok && hasLast && last.Result == current.Result && last.Message == current.Message:
+            merged = append(merged, last)
+        case ok:
+            current.LastTransitionTime = now
+            merged = append(merged, current)
+        case hasLast:
+            merged = append(merged, last)
+        }
+    }
+    return merged
+}
+
+// setInventoryApplyResults records the apply results of the current sync in
+// the inventory status of a namespace. Namespaces without an inventory have
+// nothing to report.
+func (r *NamespaceClassReconciler) setInventoryApplyResults(ctx context.Context, namespace string, desired []*unstructured.Unstructured, results applyResults) error {
+    now := metav1.Now()
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        merged := mergeApplyResults(inv.Status.ApplyResults, desired, results, now)
+        if equality.Semantic.DeepEqual(inv.Status.ApplyResults, merged) {
+            return nil
+        }
+        inv.Status.ApplyResults = merged
+        return r.Update(ctx, inv)
+    })
+}
+
+// reportApplyResults records the apply results of a sync that stopped at a
+// failing resource. Namespaces that have no inventory yet get an empty one
+// so the results can be reported.
+func (r *NamespaceClassReconciler) reportApplyResults(ctx context.Context, ns *corev1.Namespace, className string, desired []*unstructured.Unstructured, results applyResults) error {
+    err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, &v1.NamespaceClassInventory{})
+    if errors.IsNotFound(err) {
+        err = r.updateManagedResources(ctx, ns, className, nil)
+    }
+    if err != nil {
+        return err
+    }
+    return r.setInventoryApplyResults(ctx, ns.Name, desired, results)
+}
@@ -0,0 +1,105 @@
+// internal/controller/applyresults_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Apply results", func() {
+    It("should record the outcome of the last apply of each resource in the inventory", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}}`)},
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}`)},
+                },
+            },
+        }
+        denied := true
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+                if obj.GetName() == "b" && denied {
+                    return errors.NewForbidden(corev1.Resource("configmaps"), "b", fmt.Errorf("denied by admission webhook"))
+                }
+                return c.Create(ctx, obj, opts...)
+            },
+        }).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: record.NewFakeRecorder(20)}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        inv := &v1.NamespaceClassInventory{}
+        outcomes := func() map[string]v1.ResourceApplyResult {
+            Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+            byName := make(map[string]v1.ResourceApplyResult)
+            for _, result := range inv.Status.ApplyResults {
+                byName[result.Name] = result
+            }
+            return byName
+        }
+
+        // A new namespace whose sync fails still reports its results
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).To(HaveOccurred())
+        results := outcomes()
+        Expect(results["a"].Result).To(Equal(v1.ApplyResultCreated))
+        Expect(results["b"].Result).To(Equal(v1.ApplyResultFailed))
+        Expect(results["b"].Message).To(ContainSubstring("denied by admission webhook"))
+        Expect(results["b"].LastTransitionTime.Time.IsZero()).To(BeFalse())
+
+        denied = false
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        results = outcomes()
+        Expect(results["a"].Result).To(Equal(v1.ApplyResultUnchanged))
+        Expect(results["b"].Result).To(Equal(v1.ApplyResultCreated))
+        Expect(results["b"].Message).To(BeEmpty())
+
+        // Results that did not change keep the time they were first reported
+        later := metav1.NewTime(results["a"].LastTransitionTime.Add(time.Hour))
+        desired := []*unstructured.Unstructured{}
+        for _, name := range []string{"a", "b"} {
+            res := &unstructured.Unstructured{}
+            res.SetAPIVersion("v1")
+            res.SetKind("ConfigMap")
+            res.SetName(name)
+            desired = append(desired, res)
+        }
+        unchanged := make(applyResults)
+        unchanged.record(desired[0], v1.ApplyResultUnchanged, nil)
+        unchanged.record(desired[1], v1.ApplyResultUnchanged, nil)
+        merged := mergeApplyResults(inv.Status.ApplyResults, desired, unchanged, later)
+        Expect(merged[0].LastTransitionTime).To(Equal(results["a"].LastTransitionTime))
+        Expect(merged[1].Result).To(Equal(v1.ApplyResultUnchanged))
+        Expect(merged[1].LastTransitionTime).To(Equal(later))
+
+        // Resources no longer in the class are dropped
+        Expect(mergeApplyResults(inv.Status.ApplyResults, desired[:1], nil, later)).To(HaveLen(1))
+    })
+})
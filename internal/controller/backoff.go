@@ -0,0 +1,72 @@
+// internal/controller/backoff.go
+package controller
+
+import (
+    "sync"
+    "time"
+
+    "k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Retry delay for namespaces whose class is missing when no backoff is configured
+const defaultMissingClassRetry = time.Minute
+
+// MissingClassBackoff spaces out the retries of namespaces whose class, or
+// one of whose add-on classes, does not exist. The delay doubles with every
+// retry of a namespace up to Max, so thousands of namespaces pointing at a
+// deleted class do not keep requeueing at the same pace. Namespaces are
+// reconciled as soon as the class is created regardless of the backoff.
+type MissingClassBackoff struct {
+    // Initial is the delay before the first retry
+    Initial time.Duration
+
+    // Max caps the delay between retries; below Initial the delay stays at Initial
+    Max time.Duration
+
+    mu       sync.Mutex
+    attempts map[string]int
+}
+
+// next returns the delay before the next retry of a namespace and counts the
+// retry. A nil backoff retries after a minute.
+func (b *MissingClassBackoff) next(namespace string) time.Duration {
+    if b == nil || b.Initial <= 0 {
+        return defaultMissingClassRetry
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if b.attempts == nil {
+        b.attempts = make(map[string]int)
+    }
+    delay := b.Initial
+    for i := 0; i < b.attempts[namespace] && delay < b.Max; i++ {
+        delay *= 2
+    }
+    if delay > b.Max && b.Max > b.Initial {
+        delay = b.Max
+    }
+    b.attempts[namespace]++
+    return delay
+}
+
+// reset forgets the retries of a namespace once its classes exist or it is
+// deleted.
+func (b *MissingClassBackoff) reset(namespace string) {
+    if b == nil {
+        return
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    delete(b.attempts, namespace)
+}
+
+// jittered adds a random delay of up to the reconciler's requeue jitter to a
+// requeue interval, so that namespaces reconciled together do not all come
+// back at the same moment.
+func (r *NamespaceClassReconciler) jittered(d time.Duration) time.Duration {
+    if r.RequeueJitter <= 0 || d <= 0 {
+        return d
+    }
+    return wait.Jitter(d, r.RequeueJitter)
+}
@@ -0,0 +1,54 @@
+// internal/controller/backoff_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Missing class backoff", func() {
+    It("should back off exponentially while the class is missing", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:       "web",
+            Labels:     map[string]string{LabelKey: "deleted"},
+            Finalizers: []string{NamespaceFinalizer},
+        }}).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        recorder := record.NewFakeRecorder(10)
+        reconciler := &NamespaceClassReconciler{
+            Client:              cl,
+            Scheme:              scheme,
+            Recorder:            recorder,
+            MissingClassBackoff: &MissingClassBackoff{Initial: 30 * time.Second, Max: 2 * time.Minute},
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}}
+
+        var delays []time.Duration
+        for i := 0; i < 4; i++ {
+            result, err := reconciler.Reconcile(ctx, request)
+            Expect(err).NotTo(HaveOccurred())
+            delays = append(delays, result.RequeueAfter)
+        }
+        Expect(delays).To(Equal([]time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}))
+        Expect(recorder.Events).To(Receive(ContainSubstring("retrying in 30s")))
+
+        // The backoff starts over once the class exists
+        Expect(cl.Create(ctx, &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "deleted"}})).To(Succeed())
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(reconciler.MissingClassBackoff.next("web")).To(Equal(30 * time.Second))
+    })
+})
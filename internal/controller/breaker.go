@@ -0,0 +1,141 @@
+// internal/controller/breaker.go
+package controller
+
+import (
+    "fmt"
+    "sync"
+    "time"
+)
+
+// CircuitBreaker pauses the applies of a class whose reconciles start
+// failing at a high rate, e.g. because an admission webhook or the API
+// server is struggling, instead of retrying every namespace of the class
+// indefinitely. Reconcile outcomes of each class are counted over Window;
+// once at least MinReconciles were counted and the share of failures
+// reaches FailureRatio, the breaker opens and the namespaces of the class
+// apply nothing for Cooldown. After the cooldown a single namespace is
+// reconciled as a probe: if it succeeds the breaker closes, otherwise it
+// opens for another cooldown. Failures the class reports with their own
+// reason, such as missing kinds or permissions, are not counted, since
+// pausing does not help them.
+type CircuitBreaker struct {
+    // Window is how long reconcile outcomes are counted
+    Window time.Duration
+
+    // MinReconciles is the number of reconciles in the window before the breaker can open
+    MinReconciles int
+
+    // FailureRatio is the share of failed reconciles that opens the breaker; zero disables it
+    FailureRatio float64
+
+    // Cooldown is how long applies are paused once the breaker opens
+    Cooldown time.Duration
+
+    mu      sync.Mutex
+    classes map[string]*classCircuit
+}
+
+// classCircuit is the state of the breaker of one class.
+type classCircuit struct {
+    outcomes  []reconcileOutcome
+    openUntil time.Time
+    probing   time.Time
+}
+
+// reconcileOutcome is the result of one reconcile of a namespace of a class.
+type reconcileOutcome struct {
+    at     time.Time
+    failed bool
+}
+
+// circuitOpenError is reported for namespaces whose class's breaker is open.
+type circuitOpenError struct {
+    className string
+    retryIn   time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+    return fmt.Sprintf("applies of class %s are paused after a spike of failed reconciles; retrying in %s",
+        e.className, e.retryIn.Round(time.Second))
+}
+
+// allow returns nil if a namespace of a class may apply its resources, and
+// otherwise the error to report with the time until it may retry. A nil or
+// disabled breaker allows everything.
+func (b *CircuitBreaker) allow(className string) *circuitOpenError {
+    if b == nil || b.FailureRatio <= 0 {
+        return nil
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    c := b.classes[className]
+    now := time.Now()
+    if c == nil || c.openUntil.IsZero() {
+        return nil
+    }
+    if now.Before(c.openUntil) {
+        return &circuitOpenError{className: className, retryIn: c.openUntil.Sub(now)}
+    }
+    // Half-open: one probe at a time, replaced if it never reported back
+    if !c.probing.IsZero() && now.Sub(c.probing) < b.Cooldown {
+        return &circuitOpenError{className: className, retryIn: b.Cooldown / 4}
+    }
+    c.probing = now
+    return nil
+}
+
+// record counts the outcome of a reconcile of a namespace of a class that
+// was allowed to apply. Failures the class reports on its own are ignored.
+func (b *CircuitBreaker) record(className string, err error) {
+    if b == nil || b.FailureRatio <= 0 || (err != nil && syncFailureReason(err) != "") {
+        return
+    }
+    failed := err != nil
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if b.classes == nil {
+        b.classes = make(map[string]*classCircuit)
+    }
+    c := b.classes[className]
+    if c == nil {
+        c = &classCircuit{}
+        b.classes[className] = c
+    }
+    now := time.Now()
+
+    // The outcome of a probe decides whether the breaker closes
+    if !c.openUntil.IsZero() {
+        if now.Before(c.openUntil) {
+            return
+        }
+        c.probing = time.Time{}
+        if failed {
+            c.openUntil = now.Add(b.Cooldown)
+        } else {
+            c.openUntil = time.Time{}
+            c.outcomes = nil
+        }
+        return
+    }
+
+    kept := c.outcomes[:0]
+    for _, outcome := range c.outcomes {
+        if now.Sub(outcome.at) < b.Window {
+            kept = append(kept, outcome)
+        }
+    }
+    c.outcomes = append(kept, reconcileOutcome{at: now, failed: failed})
+
+    failures := 0
+    for _, outcome := range c.outcomes {
+        if outcome.failed {
+            failures++
+        }
+    }
+    if len(c.outcomes) >= b.MinReconciles && float64(failures) >= b.FailureRatio*float64(len(c.outcomes)) {
+        c.openUntil = now.Add(b.Cooldown)
+        c.outcomes = nil
+    }
+}
@@ -0,0 +1,70 @@
+// internal/controller/breaker_test.go
+package controller
+
+import (
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Circuit breaker", func() {
+    It("should pause the applies of a class whose reconciles fail at a high rate", func() {
+        breaker := &CircuitBreaker{Window: time.Minute, MinReconciles: 4, FailureRatio: 0.5, Cooldown: 50 * time.Millisecond}
+        failure := errors.NewServiceUnavailable("webhook overloaded")
+
+        breaker.record("team", nil)
+        breaker.record("team", failure)
+        breaker.record("team", nil)
+        Expect(breaker.allow("team")).To(BeNil())
+
+        // Failures the class explains do not count
+        breaker.record("team", &kindNotInstalledError{gvk: schema.GroupVersionKind{Kind: "Widget"}})
+        Expect(breaker.allow("team")).To(BeNil())
+
+        breaker.record("team", failure)
+        open := breaker.allow("team")
+        Expect(open).NotTo(BeNil())
+        Expect(syncFailureReason(open)).To(Equal(v1.ReasonCircuitOpen))
+        Expect(breaker.allow("other")).To(BeNil())
+
+        // After the cooldown one probe is let through at a time
+        time.Sleep(60 * time.Millisecond)
+        Expect(breaker.allow("team")).To(BeNil())
+        Expect(breaker.allow("team")).NotTo(BeNil())
+
+        // A failed probe opens the breaker again, a successful one closes it
+        breaker.record("team", failure)
+        Expect(breaker.allow("team")).NotTo(BeNil())
+        time.Sleep(60 * time.Millisecond)
+        Expect(breaker.allow("team")).To(BeNil())
+        breaker.record("team", nil)
+        Expect(breaker.allow("team")).To(BeNil())
+        Expect(breaker.allow("team")).To(BeNil())
+    })
+
+    It("should report classes with an open breaker as degraded", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team", Generation: 1},
+            Status: v1.NamespaceClassStatus{
+                ManagedNamespaces: []string{"web"},
+                Namespaces: []v1.NamespaceSyncStatus{{
+                    Name:    "web",
+                    Phase:   v1.SyncPhaseFailed,
+                    Reason:  v1.ReasonCircuitOpen,
+                    Message: (&circuitOpenError{className: "team", retryIn: time.Minute}).Error(),
+                }},
+            },
+        }
+        setClassConditions(nsc)
+        degraded := meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDegraded)
+        Expect(degraded).NotTo(BeNil())
+        Expect(degraded.Reason).To(Equal(v1.ReasonCircuitOpen))
+    })
+})
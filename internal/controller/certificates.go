@@ -0,0 +1,74 @@
+// internal/controller/certificates.go
+package controller
+
+import (
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// API group of cert-manager
+const certManagerGroup = "cert-manager.io"
+
+// expandCertificateNames expands the DNS names and common name of a
+// cert-manager Certificate for the namespace it is rendered in. Names that
+// fail to expand are left as they are; class validation rejects them.
+func expandCertificateNames(res *unstructured.Unstructured, namespace, className string) {
+    if res.GroupVersionKind().Group != certManagerGroup || res.GetKind() != "Certificate" {
+        return
+    }
+    data := templateData{Namespace: namespace, Class: className}
+    if names, ok, _ := unstructured.NestedStringSlice(res.Object, "spec", "dnsNames"); ok {
+        for i, name := range names {
+            if expanded, err := expandTemplate(name, data); err == nil {
+                names[i] = expanded
+            }
+        }
+        _ = unstructured.SetNestedStringSlice(res.Object, names, "spec", "dnsNames")
+    }
+    if name, ok, _ := unstructured.NestedString(res.Object, "spec", "commonName"); ok {
+        if expanded, err := expandTemplate(name, data); err == nil {
+            _ = unstructured.SetNestedField(res.Object, expanded, "spec", "commonName")
+        }
+    }
+}
+
+// certificateResources expands the certificates of a class into cert-manager
+// Certificates. Their DNS names are expanded when they are rendered for a
+// namespace.
+func certificateResources(certificates []v1.ClassCertificate) []*unstructured.Unstructured {
+    var resources []*unstructured.Unstructured
+    for _, cert := range certificates {
+        secretName := cert.SecretName
+        if secretName == "" {
+            secretName = cert.Name
+        }
+        kind := cert.IssuerRef.Kind
+        if kind == "" {
+            kind = v1.CertificateIssuerKindClusterIssuer
+        }
+        group := cert.IssuerRef.Group
+        if group == "" {
+            group = certManagerGroup
+        }
+        dnsNames := make([]interface{}, 0, len(cert.DNSNames))
+        for _, name := range cert.DNSNames {
+            dnsNames = append(dnsNames, name)
+        }
+        resources = append(resources, &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": certManagerGroup + "/v1",
+            "kind":       "Certificate",
+            "metadata":   map[string]interface{}{"name": cert.Name},
+            "spec": map[string]interface{}{
+                "secretName": secretName,
+                "dnsNames":   dnsNames,
+                "issuerRef": map[string]interface{}{
+                    "name":  cert.IssuerRef.Name,
+                    "kind":  string(kind),
+                    "group": group,
+                },
+            },
+        }})
+    }
+    return resources
+}
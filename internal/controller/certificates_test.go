@@ -0,0 +1,38 @@
+// internal/controller/certificates_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class certificates", func() {
+    It("should issue a Certificate with DNS names for each namespace", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{Certificates: []v1.ClassCertificate{{
+                Name:      "ingress-tls",
+                DNSNames:  []string{"{{ .Namespace }}.example.com", "*.{{ .Namespace }}.{{ .Class }}.example.com"},
+                IssuerRef: v1.CertificateIssuerRef{Name: "letsencrypt"},
+            }}},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        resources, err := RenderClass(nsc, "team-a")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(1))
+        Expect(resources[0].GetAPIVersion()).To(Equal("cert-manager.io/v1"))
+        dnsNames, _, _ := unstructured.NestedStringSlice(resources[0].Object, "spec", "dnsNames")
+        Expect(dnsNames).To(Equal([]string{"team-a.example.com", "*.team-a.web.example.com"}))
+        secretName, _, _ := unstructured.NestedString(resources[0].Object, "spec", "secretName")
+        Expect(secretName).To(Equal("ingress-tls"))
+        issuer, _, _ := unstructured.NestedStringMap(resources[0].Object, "spec", "issuerRef")
+        Expect(issuer).To(Equal(map[string]string{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"}))
+
+        nsc.Spec.Certificates[0].DNSNames = []string{"{{ .Tenant }}.example.com"}
+        Expect(ValidateClass(nsc)).To(HaveLen(1))
+    })
+})
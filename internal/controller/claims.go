@@ -0,0 +1,230 @@
+// internal/controller/claims.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/utils/ptr"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// ClaimReconciler provisions the namespaces NamespaceClaims request. It
+// creates a namespace named after each claim, owned by the claim and labeled
+// with its class, keeps the class, add-on classes, labels and annotations of
+// the namespace in line with the claim, and reports in the claim's status
+// whether the class is applied.
+type ClaimReconciler struct {
+    client.Client
+
+    // Scope limits the namespaces claims may request; claims for other
+    // namespaces are left to another controller. Nil allows all namespaces.
+    Scope *WatchScope
+}
+
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclaims,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclaims/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=create
+
+// Reconcile creates or updates the namespace of a claim and reports its
+// state.
+func (r *ClaimReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
+    logger := log.FromContext(ctx).WithValues("claim", req.Name, "controller", "ClaimReconciler")
+    if !r.Scope.includes(req.Name) {
+        return reconcile.Result{}, nil
+    }
+
+    claim := &v1.NamespaceClaim{}
+    if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+        return reconcile.Result{}, client.IgnoreNotFound(err)
+    }
+    // The namespace is garbage collected with the claim
+    if !claim.DeletionTimestamp.IsZero() {
+        return reconcile.Result{}, nil
+    }
+    if expiry := claimExpiry(claim); expiry != nil {
+        remaining := time.Until(expiry.Time)
+        if remaining <= 0 {
+            logger.Info("Deleting claim whose TTL has passed", "ttl", claim.Spec.TTL.Duration)
+            return reconcile.Result{}, client.IgnoreNotFound(r.Delete(ctx, claim))
+        }
+        defer func() {
+            result = requeueWithin(result, remaining)
+        }()
+    }
+
+    ns := &corev1.Namespace{}
+    err = r.Get(ctx, types.NamespacedName{Name: claim.Name}, ns)
+    switch {
+    case errors.IsNotFound(err):
+        ns = &corev1.Namespace{
+            ObjectMeta: metav1.ObjectMeta{
+                Name: claim.Name,
+                OwnerReferences: []metav1.OwnerReference{{
+                    APIVersion:         v1.GroupVersion.String(),
+                    Kind:               "NamespaceClaim",
+                    Name:               claim.Name,
+                    UID:                claim.UID,
+                    Controller:         ptr.To(true),
+                    BlockOwnerDeletion: ptr.To(false),
+                }},
+            },
+        }
+        claimNamespaceMetadata(claim, ns)
+        r.Scope.optIn(ns)
+        if err := r.Create(ctx, ns); err != nil {
+            logger.Error(err, "Failed to create namespace")
+            return reconcile.Result{}, err
+        }
+        logger.Info("Created namespace for claim", "class", claim.Spec.ClassName)
+    case err != nil:
+        return reconcile.Result{}, err
+    case !ns.DeletionTimestamp.IsZero():
+        // Reconciled again once the namespace is gone
+        return reconcile.Result{}, r.setClaimStatus(ctx, claim, v1.ClaimPhasePending, metav1.Condition{
+            Type:    v1.ConditionReady,
+            Status:  metav1.ConditionFalse,
+            Reason:  v1.ReasonNamespaceTerminating,
+            Message: fmt.Sprintf("Namespace %s is being deleted", ns.Name),
+        })
+    case !metav1.IsControlledBy(ns, claim):
+        return reconcile.Result{}, r.setClaimStatus(ctx, claim, v1.ClaimPhaseFailed, metav1.Condition{
+            Type:    v1.ConditionReady,
+            Status:  metav1.ConditionFalse,
+            Reason:  v1.ReasonNamespaceExists,
+            Message: fmt.Sprintf("Namespace %s already exists and was not created for this claim", ns.Name),
+        })
+    default:
+        patch := client.MergeFrom(ns.DeepCopy())
+        if claimNamespaceMetadata(claim, ns) {
+            if err := r.Patch(ctx, ns, patch); err != nil {
+                logger.Error(err, "Failed to update namespace")
+                return reconcile.Result{}, err
+            }
+        }
+    }
+
+    ready, err := r.claimReadiness(ctx, claim)
+    if err != nil {
+        return reconcile.Result{}, err
+    }
+    phase := v1.ClaimPhasePending
+    if ready.Status == metav1.ConditionTrue {
+        phase = v1.ClaimPhaseBound
+    }
+    return reconcile.Result{}, r.setClaimStatus(ctx, claim, phase, ready)
+}
+
+// ClaimNamespace returns the namespace a claim asks for: named after the
+// claim, with its class, add-on classes, labels and annotations.
+func ClaimNamespace(claim *v1.NamespaceClaim) *corev1.Namespace {
+    ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: claim.Name}}
+    claimNamespaceMetadata(claim, ns)
+    return ns
+}
+
+// claimNamespaceMetadata sets the class label, add-on classes annotation,
+// labels and annotations a claim asks for on its namespace, and reports
+// whether anything changed. Labels and annotations later removed from the
+// claim are left on the namespace.
+func claimNamespaceMetadata(claim *v1.NamespaceClaim, ns *corev1.Namespace) bool {
+    changed := false
+    set := func(m *map[string]string, key, value string) {
+        if current, ok := (*m)[key]; ok && current == value {
+            return
+        }
+        if *m == nil {
+            *m = make(map[string]string)
+        }
+        (*m)[key] = value
+        changed = true
+    }
+
+    for key, value := range claim.Spec.Labels {
+        set(&ns.Labels, key, value)
+    }
+    set(&ns.Labels, LabelKey, claim.Spec.ClassName)
+    for key, value := range claim.Spec.Annotations {
+        set(&ns.Annotations, key, value)
+    }
+    if len(claim.Spec.AddonClasses) > 0 {
+        set(&ns.Annotations, ClassesAnnotation, strings.Join(claim.Spec.AddonClasses, ","))
+    } else if _, ok := ns.Annotations[ClassesAnnotation]; ok {
+        delete(ns.Annotations, ClassesAnnotation)
+        changed = true
+    }
+    return changed
+}
+
+// claimReadiness derives the Ready condition of a claim from the inventory
+// of its namespace: Ready once the class is applied, and otherwise carrying
+// the reason the namespace is degraded or not ready yet.
+func (r *ClaimReconciler) claimReadiness(ctx context.Context, claim *v1.NamespaceClaim) (metav1.Condition, error) {
+    ready := metav1.Condition{
+        Type:    v1.ConditionReady,
+        Status:  metav1.ConditionFalse,
+        Reason:  v1.ReasonNamespacePending,
+        Message: fmt.Sprintf("Class %s is not applied to namespace %s yet", claim.Spec.ClassName, claim.Name),
+    }
+    inv := &v1.NamespaceClassInventory{}
+    if err := r.Get(ctx, types.NamespacedName{Name: claim.Name}, inv); err != nil {
+        return ready, client.IgnoreNotFound(err)
+    }
+    if degraded := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionDegraded); degraded != nil && degraded.Status == metav1.ConditionTrue {
+        ready.Reason, ready.Message = degraded.Reason, degraded.Message
+        return ready, nil
+    }
+    if current := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionReady); current != nil {
+        ready.Status, ready.Reason, ready.Message = current.Status, current.Reason, current.Message
+    }
+    return ready, nil
+}
+
+// setClaimStatus records the phase, Ready condition and expiration time of
+// a claim.
+func (r *ClaimReconciler) setClaimStatus(ctx context.Context, claim *v1.NamespaceClaim, phase v1.ClaimPhase, ready metav1.Condition) error {
+    namespace := claim.Name
+    if phase == v1.ClaimPhaseFailed {
+        namespace = ""
+    }
+    expiry := claimExpiry(claim)
+    ready.ObservedGeneration = claim.Generation
+    changed := meta.SetStatusCondition(&claim.Status.Conditions, ready)
+    if !changed && claim.Status.Phase == phase && claim.Status.Namespace == namespace &&
+        claim.Status.ObservedGeneration == claim.Generation && expiry.Equal(claim.Status.ExpirationTime) {
+        return nil
+    }
+    claim.Status.Phase = phase
+    claim.Status.Namespace = namespace
+    claim.Status.ExpirationTime = expiry
+    claim.Status.ObservedGeneration = claim.Generation
+    return r.Status().Update(ctx, claim)
+}
+
+// SetupWithManager sets up the claim controller with the Manager. Claims are
+// reconciled when their namespace changes and when the inventory of their
+// namespace, which is named after it, reports progress.
+func (r *ClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        Named("namespaceclaim").
+        For(&v1.NamespaceClaim{}).
+        Owns(&corev1.Namespace{}).
+        Watches(&v1.NamespaceClassInventory{}, handler.EnqueueRequestsFromMapFunc(
+            func(ctx context.Context, obj client.Object) []reconcile.Request {
+                return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetName()}}}
+            })).
+        Complete(r)
+}
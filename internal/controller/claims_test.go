@@ -0,0 +1,99 @@
+// internal/controller/claims_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Namespace claims", func() {
+    It("should create the namespace of a claim and report when its class is applied", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        claim := &v1.NamespaceClaim{
+            ObjectMeta: metav1.ObjectMeta{Name: "team-a", UID: "claim-uid"},
+            Spec: v1.NamespaceClaimSpec{
+                ClassName:    "team",
+                AddonClasses: []string{"monitoring"},
+                Labels:       map[string]string{"owner": "team-a"},
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).
+            WithStatusSubresource(&v1.NamespaceClaim{}).Build()
+        reconciler := &ClaimReconciler{Client: cl}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{LabelKey: "team", "owner": "team-a"}))
+        Expect(ns.Annotations).To(HaveKeyWithValue(ClassesAnnotation, "monitoring"))
+        Expect(metav1.IsControlledBy(ns, claim)).To(BeTrue())
+        Expect(cl.Get(ctx, request.NamespacedName, claim)).To(Succeed())
+        Expect(claim.Status.Phase).To(Equal(v1.ClaimPhasePending))
+        Expect(meta.FindStatusCondition(claim.Status.Conditions, v1.ConditionReady).Reason).To(Equal(v1.ReasonNamespacePending))
+
+        // Bound once the inventory of the namespace reports the class applied
+        Expect(cl.Create(ctx, &v1.NamespaceClassInventory{
+            ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+            Status: v1.NamespaceClassInventoryStatus{Conditions: []metav1.Condition{{
+                Type:               v1.ConditionReady,
+                Status:             metav1.ConditionTrue,
+                Reason:             v1.ReasonApplied,
+                Message:            "All resources of class team are applied",
+                LastTransitionTime: metav1.Now(),
+            }}},
+        })).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, request.NamespacedName, claim)).To(Succeed())
+        Expect(claim.Status.Phase).To(Equal(v1.ClaimPhaseBound))
+        Expect(claim.Status.Namespace).To(Equal("team-a"))
+
+        // Changing the class of the claim relabels the namespace
+        claim.Spec.ClassName = "restricted"
+        claim.Spec.AddonClasses = nil
+        Expect(cl.Update(ctx, claim)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue(LabelKey, "restricted"))
+        Expect(ns.Annotations).NotTo(HaveKey(ClassesAnnotation))
+    })
+
+    It("should not take over an existing namespace", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        claim := &v1.NamespaceClaim{
+            ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "claim-uid"},
+            Spec:       v1.NamespaceClaimSpec{ClassName: "team"},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            claim,
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+        ).WithStatusSubresource(&v1.NamespaceClaim{}).Build()
+        reconciler := &ClaimReconciler{Client: cl}
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kube-system"}})
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "kube-system"}, ns)).To(Succeed())
+        Expect(ns.Labels).NotTo(HaveKey(LabelKey))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "kube-system"}, claim)).To(Succeed())
+        Expect(claim.Status.Phase).To(Equal(v1.ClaimPhaseFailed))
+        Expect(meta.FindStatusCondition(claim.Status.Conditions, v1.ConditionReady).Reason).To(Equal(v1.ReasonNamespaceExists))
+    })
+})
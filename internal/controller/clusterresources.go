@@ -0,0 +1,73 @@
+// internal/controller/clusterresources.go
+package controller
+
+import (
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation on cluster-scoped resources naming the namespace they were
+// created for. A namespace never updates or prunes cluster-scoped resources
+// created for another namespace.
+const OwnerNamespaceAnnotation = "namespaceclass.akuity.io/namespace"
+
+// clusterResources decodes the cluster-scoped resources of a class. They are
+// marked with an empty OwnerNamespaceAnnotation until scopeClusterResources
+// expands them for a namespace.
+func clusterResources(nsc *v1.NamespaceClass) ([]*unstructured.Unstructured, error) {
+    resources, err := parseClassResources(nsc.Spec.ClusterResources, nsc.Name)
+    if err != nil {
+        return nil, err
+    }
+    for _, res := range resources {
+        annotations := res.GetAnnotations()
+        if annotations == nil {
+            annotations = make(map[string]string)
+        }
+        annotations[OwnerNamespaceAnnotation] = ""
+        res.SetAnnotations(annotations)
+    }
+    return resources, nil
+}
+
+// isClusterScoped reports whether a resource comes from the cluster-scoped
+// resources of a class.
+func isClusterScoped(res *unstructured.Unstructured) bool {
+    _, ok := res.GetAnnotations()[OwnerNamespaceAnnotation]
+    return ok
+}
+
+// scopeClusterResources expands the templates of the cluster-scoped resources
+// among the resources of a namespace and records the namespace on them. It
+// runs before resources are compared with the inventory, which tracks them by
+// their expanded names. Values that fail to expand are left as they are;
+// class validation rejects them.
+func scopeClusterResources(resources []*unstructured.Unstructured, namespace string, sources resourceClasses, primary *v1.NamespaceClass) {
+    for _, res := range resources {
+        if !isClusterScoped(res) || res.GetAnnotations()[OwnerNamespaceAnnotation] != "" {
+            continue
+        }
+        _ = expandObjectTemplates(res.Object, templateData{Namespace: namespace, Class: sources.of(res, primary).Name})
+        annotations := res.GetAnnotations()
+        annotations[OwnerNamespaceAnnotation] = namespace
+        res.SetAnnotations(annotations)
+        res.SetNamespace("")
+    }
+}
+
+// ownedByNamespace reports whether a live object may be managed for the
+// namespace a desired resource is rendered for. Namespaced resources always
+// are; cluster-scoped resources must have been created for that namespace.
+func ownedByNamespace(live, desired *unstructured.Unstructured) bool {
+    return live.GetAnnotations()[OwnerNamespaceAnnotation] == desired.GetAnnotations()[OwnerNamespaceAnnotation]
+}
+
+// objectKey returns the key of a tracked resource of a namespace.
+func (m ManagedResource) objectKey(namespace string) client.ObjectKey {
+    if m.ClusterScoped {
+        return client.ObjectKey{Name: m.Name}
+    }
+    return client.ObjectKey{Namespace: namespace, Name: m.Name}
+}
@@ -0,0 +1,78 @@
+// internal/controller/clusterresources_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Cluster-scoped resources", func() {
+    It("should create cluster-scoped resources per namespace and prune them with the class", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{ClusterResources: []runtime.RawExtension{{Raw: []byte(`{
+                "apiVersion": "rbac.authorization.k8s.io/v1",
+                "kind": "ClusterRoleBinding",
+                "metadata": {"name": "{{ .Namespace }}-node-readers"},
+                "roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "node-reader"},
+                "subjects": [{"kind": "ServiceAccount", "name": "default", "namespace": "{{ .Namespace }}"}]
+            }`)}}},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team-a",
+                Labels:     map[string]string{LabelKey: "team"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        binding := &rbacv1.ClusterRoleBinding{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-node-readers"}, binding)).To(Succeed())
+        Expect(binding.Annotations).To(HaveKeyWithValue(OwnerNamespaceAnnotation, "team-a"))
+        Expect(binding.Subjects[0].Namespace).To(Equal("team-a"))
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a"}, inv)).To(Succeed())
+        Expect(inv.Spec.Resources).To(HaveLen(1))
+        Expect(inv.Spec.Resources[0].ClusterScoped).To(BeTrue())
+
+        // Removing the resources from the class prunes them
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, nsc)).To(Succeed())
+        nsc.Spec.ClusterResources = nil
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "team-a-node-readers"}, binding))).To(BeTrue())
+
+        nsc.Spec.ClusterResources = []runtime.RawExtension{{Raw: []byte(`{
+            "apiVersion": "rbac.authorization.k8s.io/v1",
+            "kind": "ClusterRoleBinding",
+            "metadata": {"name": "node-readers", "namespace": "team-a"}
+        }`)}}
+        var fields []string
+        for _, err := range ValidateClass(nsc) {
+            fields = append(fields, err.Field)
+        }
+        Expect(fields).To(ConsistOf("spec.clusterResources[0].metadata.namespace", "spec.clusterResources[0].metadata.name"))
+    })
+})
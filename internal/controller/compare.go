@@ -0,0 +1,507 @@
+// internal/controller/compare.go
+package controller
+
+import (
+    "crypto/sha256"
+    "crypto/sha512"
+    "encoding/json"
+    "fmt"
+    "hash"
+    "hash/fnv"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "k8s.io/apimachinery/pkg/api/resource"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // Annotation on an embedded resource listing additional fields to ignore
+    IgnoreFieldsAnnotation = "namespaceclass.akuity.io/ignore-fields"
+
+    // Annotation on an embedded resource listing fields that are only set when
+    // the resource is created and never reconciled afterwards
+    CreateOnlyFieldsAnnotation = "namespaceclass.akuity.io/create-only-fields"
+
+    // Annotation on an embedded resource selecting how updates are applied
+    UpdateStrategyAnnotation = "namespaceclass.akuity.io/update-strategy"
+
+    // UpdateStrategyRecreate deletes and recreates a resource when an update
+    // is rejected because it changes an immutable field.
+    UpdateStrategyRecreate = "Recreate"
+
+    // Annotation on a namespace or a pre-existing resource requesting that
+    // matching resources are adopted into management
+    AdoptAnnotation = "namespaceclass.akuity.io/adopt"
+
+    // Annotation a tenant sets on a managed resource to stop the controller
+    // from updating it, honored when the class sets allowTenantFreeze
+    FrozenAnnotation = "namespaceclass.akuity.io/frozen"
+)
+
+// applyOptions carries the per-class settings that affect how a single
+// resource is compared and applied.
+type applyOptions struct {
+    ignoreFields     []string
+    createOnlyFields []string
+    comparisonMode v1.ComparisonMode
+    updateStrategy string
+    conflictPolicy v1.ConflictPolicy
+    allowFreeze    bool
+
+    // className is the class the resource is applied for
+    className string
+    // trackedClass is the class recorded in the inventory, if the resource is tracked
+    trackedClass *string
+    // drifted counts managed resources found drifted from the class
+    drifted *int
+    // client applies the resource in place of the controller's own client,
+    // e.g. one impersonating the ServiceAccount of the class
+    client client.Client
+}
+
+// applyResult describes what happened when a resource was applied.
+type applyResult string
+
+const (
+    applyResultCreated   applyResult = "Created"
+    applyResultUpdated   applyResult = "Updated"
+    applyResultUnchanged applyResult = "Unchanged"
+    applyResultSkipped   applyResult = "Skipped"
+    applyResultFrozen    applyResult = "Frozen"
+)
+
+// unmanagedFields returns the fields excluded from change detection.
+func (o applyOptions) unmanagedFields() []string {
+    return append(append([]string{}, o.ignoreFields...), o.createOnlyFields...)
+}
+
+// needsUpdate reports whether the live object has to be updated to match desired.
+// When the class hash algorithm changed since the object was applied, the
+// desired object is hashed again with the live object's algorithm, so
+// switching algorithms does not update every resource of the class.
+func needsUpdate(existing, desired *unstructured.Unstructured, opts applyOptions) bool {
+    if opts.comparisonMode == v1.ComparisonModeSemantic {
+        // Fields removed from the class are still set on the live object, so
+        // only the hash it was last applied with tells that they have to be
+        // removed
+        applied := existing.GetAnnotations()[ResourceHashAnnotation] != ""
+        return (applied && hashChanged(existing, desired, opts)) ||
+            !semanticEqual(comparableObject(desired, opts.unmanagedFields()), existing.Object)
+    }
+    return hashChanged(existing, desired, opts)
+}
+
+// hashChanged reports whether the desired content differs from the content
+// the live object was last applied with.
+func hashChanged(existing, desired *unstructured.Unstructured, opts applyOptions) bool {
+    liveHash := existing.GetAnnotations()[ResourceHashAnnotation]
+    desiredHash := desired.GetAnnotations()[ResourceHashAnnotation]
+    if liveHash == desiredHash || hasLegacyHash(existing, desired, opts) {
+        return false
+    }
+    if algorithm := hashAlgorithmOf(liveHash); liveHash != "" && algorithm != hashAlgorithmOf(desiredHash) {
+        return calculateResourceHash(desired, opts.unmanagedFields(), algorithm) != liveHash
+    }
+    return true
+}
+
+// isDrifted reports whether a live object was changed outside the controller:
+// the class still wants the content it was last applied with, but the live
+// object no longer matches it.
+func isDrifted(existing, desired *unstructured.Unstructured, opts applyOptions) bool {
+    return !hashChanged(existing, desired, opts) &&
+        !semanticEqual(comparableObject(desired, opts.unmanagedFields()), existing.Object)
+}
+
+// hashAlgorithmOf returns the algorithm a hash was computed with.
+func hashAlgorithmOf(hash string) v1.HashAlgorithm {
+    if algorithm, _, ok := strings.Cut(hash, ":"); ok {
+        return v1.HashAlgorithm(algorithm)
+    }
+    return v1.HashAlgorithmSHA256
+}
+
+// hasLegacyHash reports whether a live object was last applied by a release
+// that hashed spec alone (see legacyResourceHash) and still has the content
+// the class wants, so that upgrading does not update every object at once.
+// Objects whose content differs are updated as usual, since the legacy hash
+// does not cover data and metadata.
+func hasLegacyHash(existing, desired *unstructured.Unstructured, opts applyOptions) bool {
+    liveHash := existing.GetAnnotations()[ResourceHashAnnotation]
+    return liveHash != "" && liveHash == legacyResourceHash(desired) &&
+        semanticEqual(comparableObject(desired, opts.unmanagedFields()), existing.Object)
+}
+
+// legacyResourceHash returns the hash the first releases annotated objects
+// with: a bare SHA-256 of spec alone.
+func legacyResourceHash(obj *unstructured.Unstructured) string {
+    data, err := json.Marshal(obj.Object["spec"])
+    if err != nil {
+        return ""
+    }
+    return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// calculateResourceHash hashes the desired content of an object with the
+// given algorithm. SHA-256 hashes are stored bare like the hashes of the
+// first releases, which covered spec alone and are recognized by
+// hasLegacyHash; other algorithms are prefixed with their name.
+func calculateResourceHash(obj *unstructured.Unstructured, ignoreFields []string, algorithm v1.HashAlgorithm) string {
+    // Serialize the object for hashing
+    data, err := json.Marshal(comparableObject(obj, ignoreFields))
+    if err != nil {
+        return ""
+    }
+
+    var h hash.Hash
+    switch algorithm {
+    case v1.HashAlgorithmSHA512:
+        h = sha512.New()
+    case v1.HashAlgorithmFNV64a:
+        h = fnv.New64a()
+    default:
+        sum := sha256.Sum256(data)
+        return fmt.Sprintf("%x", sum)
+    }
+    h.Write(data)
+    return fmt.Sprintf("%s:%x", algorithm, h.Sum(nil))
+}
+
+// comparableObject returns a copy of the object without status, ignored
+// fields, and metadata that is set by the apiserver or the controller.
+func comparableObject(obj *unstructured.Unstructured, ignoreFields []string) map[string]interface{} {
+    stripped := obj.DeepCopy().Object
+    delete(stripped, "status")
+
+    // Remove fields that are managed outside of the class
+    stripIgnoredFields(stripped, ignoreFields)
+
+    // Remove metadata fields that change frequently
+    if metaMap, ok := stripped["metadata"].(map[string]interface{}); ok {
+        delete(metaMap, "resourceVersion")
+        delete(metaMap, "generation")
+        delete(metaMap, "creationTimestamp")
+        delete(metaMap, "uid")
+        delete(metaMap, "managedFields")
+        if annotations, ok := metaMap["annotations"].(map[string]interface{}); ok {
+            delete(annotations, ResourceHashAnnotation)
+        }
+    }
+    return stripped
+}
+
+// semanticEqual reports whether every field of desired is present in live
+// with an equivalent value. Fields that only exist in live (defaults filled in
+// by the apiserver) are ignored, empty and missing values are equivalent,
+// numbers are compared by value, and resource quantities by amount
+// ("1000m" == "1").
+func semanticEqual(desired, live interface{}) bool {
+    return semanticEqualAt(nil, desired, live)
+}
+
+// semanticEqualAt compares the values at the given field path, see
+// semanticEqual.
+func semanticEqualAt(path []string, desired, live interface{}) bool {
+    if isEmptyValue(desired) {
+        return isEmptyValue(live)
+    }
+
+    switch d := desired.(type) {
+    case map[string]interface{}:
+        l, ok := live.(map[string]interface{})
+        if !ok {
+            return false
+        }
+        for key, value := range d {
+            if !semanticEqualAt(append(path[:len(path):len(path)], key), value, l[key]) {
+                return false
+            }
+        }
+        return true
+    case []interface{}:
+        l, ok := live.([]interface{})
+        if !ok || len(l) != len(d) {
+            return false
+        }
+        for i := range d {
+            if !semanticEqualAt(path, d[i], l[i]) {
+                return false
+            }
+        }
+        return true
+    }
+
+    if reflect.DeepEqual(desired, live) {
+        return true
+    }
+    if dn, ok := toFloat(desired); ok {
+        if ln, ok := toFloat(live); ok {
+            return dn == ln
+        }
+    }
+    if !isQuantityPath(path) {
+        return false
+    }
+    dq, ok := toQuantity(desired)
+    if !ok {
+        return false
+    }
+    lq, ok := toQuantity(live)
+    return ok && dq.Cmp(lq) == 0
+}
+
+// quantityFields are the fields whose entries are resource quantities: the
+// limits and requests of container and PVC resources, the hard limits of a
+// ResourceQuota and the bounds of a LimitRange.
+var quantityFields = map[string]bool{
+    "limits":               true,
+    "requests":             true,
+    "hard":                 true,
+    "max":                  true,
+    "min":                  true,
+    "default":              true,
+    "defaultRequest":       true,
+    "maxLimitRequestRatio": true,
+}
+
+// isQuantityPath reports whether the value at a field path is a resource
+// quantity, so that e.g. ConfigMap data "1000m" and "1" stay different.
+func isQuantityPath(path []string) bool {
+    return len(path) >= 2 && quantityFields[path[len(path)-2]]
+}
+
+// isEmptyValue treats nil, empty maps, and empty lists as the same value.
+func isEmptyValue(value interface{}) bool {
+    switch v := value.(type) {
+    case nil:
+        return true
+    case map[string]interface{}:
+        return len(v) == 0
+    case []interface{}:
+        return len(v) == 0
+    }
+    return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+    switch v := value.(type) {
+    case int64:
+        return float64(v), true
+    case int:
+        return float64(v), true
+    case float64:
+        return v, true
+    }
+    return 0, false
+}
+
+func toQuantity(value interface{}) (resource.Quantity, bool) {
+    var s string
+    switch v := value.(type) {
+    case string:
+        s = v
+    case int64, int, float64:
+        s = fmt.Sprint(v)
+    default:
+        return resource.Quantity{}, false
+    }
+    q, err := resource.ParseQuantity(s)
+    if err != nil {
+        return resource.Quantity{}, false
+    }
+    return q, true
+}
+
+// resourceIgnoreFields merges the class-wide ignore list with the
+// comma-separated list from the resource's ignore-fields annotation.
+func resourceIgnoreFields(obj *unstructured.Unstructured, classFields []string) []string {
+    return append(append([]string{}, classFields...), annotationFields(obj, IgnoreFieldsAnnotation)...)
+}
+
+// annotationFields parses a comma-separated list of field paths from an annotation.
+func annotationFields(obj *unstructured.Unstructured, annotation string) []string {
+    var fields []string
+    if value := obj.GetAnnotations()[annotation]; value != "" {
+        for _, f := range strings.Split(value, ",") {
+            if f = strings.TrimSpace(f); f != "" {
+                fields = append(fields, f)
+            }
+        }
+    }
+    return fields
+}
+
+// parseFieldPath splits a JSON pointer ("/spec/replicas") or a dotted
+// path ("spec.replicas") into its unescaped segments.
+func parseFieldPath(path string) []string {
+    if path == "" {
+        return nil
+    }
+    if !strings.HasPrefix(path, "/") {
+        return strings.Split(path, ".")
+    }
+    segments := strings.Split(path[1:], "/")
+    for i, s := range segments {
+        s = strings.ReplaceAll(s, "~1", "/")
+        segments[i] = strings.ReplaceAll(s, "~0", "~")
+    }
+    return segments
+}
+
+// getField returns the value stored at the given path segments.
+func getField(obj interface{}, segments []string) (interface{}, bool) {
+    current := obj
+    for _, s := range segments {
+        switch node := current.(type) {
+        case map[string]interface{}:
+            value, ok := node[s]
+            if !ok {
+                return nil, false
+            }
+            current = value
+        case []interface{}:
+            idx, err := strconv.Atoi(s)
+            if err != nil || idx < 0 || idx >= len(node) {
+                return nil, false
+            }
+            current = node[idx]
+        default:
+            return nil, false
+        }
+    }
+    return current, true
+}
+
+// setField stores value at the given path, creating intermediate maps as needed.
+// Paths that traverse a missing list element are left untouched.
+func setField(obj map[string]interface{}, segments []string, value interface{}) {
+    if len(segments) == 0 {
+        return
+    }
+    var current interface{} = obj
+    for i, s := range segments {
+        last := i == len(segments)-1
+        switch node := current.(type) {
+        case map[string]interface{}:
+            if last {
+                node[s] = value
+                return
+            }
+            next, ok := node[s]
+            if !ok || next == nil {
+                next = map[string]interface{}{}
+                node[s] = next
+            }
+            current = next
+        case []interface{}:
+            idx, err := strconv.Atoi(s)
+            if err != nil || idx < 0 || idx >= len(node) {
+                return
+            }
+            if last {
+                node[idx] = value
+                return
+            }
+            current = node[idx]
+        default:
+            return
+        }
+    }
+}
+
+// removeField deletes the value at the given path if present.
+func removeField(obj map[string]interface{}, segments []string) {
+    if len(segments) == 0 {
+        return
+    }
+    parent, ok := getField(obj, segments[:len(segments)-1])
+    if !ok {
+        return
+    }
+    if m, ok := parent.(map[string]interface{}); ok {
+        delete(m, segments[len(segments)-1])
+    }
+}
+
+// stripIgnoredFields removes every ignored field from the object.
+func stripIgnoredFields(obj map[string]interface{}, ignoreFields []string) {
+    for _, f := range ignoreFields {
+        removeField(obj, parseFieldPath(f))
+    }
+}
+
+// preserveIgnoredFields copies the live value of every ignored field onto
+// the desired object, so fields owned by other actors (HPAs, webhooks) are
+// left as they are on update.
+func preserveIgnoredFields(existing, desired *unstructured.Unstructured, ignoreFields []string) {
+    for _, f := range ignoreFields {
+        segments := parseFieldPath(f)
+        if value, ok := getField(existing.Object, segments); ok {
+            setField(desired.Object, segments, runtime.DeepCopyJSONValue(value))
+        }
+    }
+}
+
+// preserveCreateOnlyFields replaces the desired value of every create-only
+// field with its live value, dropping it if the live object does not have it.
+// Secret stringData is write-only, so the live value of a create-only
+// stringData key is read from data instead.
+func preserveCreateOnlyFields(existing, desired *unstructured.Unstructured, createOnlyFields []string) {
+    isSecret := desired.GetAPIVersion() == "v1" && desired.GetKind() == "Secret"
+    for _, f := range createOnlyFields {
+        segments := parseFieldPath(f)
+        removeField(desired.Object, segments)
+        if isSecret && len(segments) == 2 && segments[0] == "stringData" {
+            segments = []string{"data", segments[1]}
+        }
+        if value, ok := getField(existing.Object, segments); ok {
+            setField(desired.Object, segments, runtime.DeepCopyJSONValue(value))
+        }
+    }
+}
+
+// isManagedByController reports whether the object was created by this controller.
+func isManagedByController(obj *unstructured.Unstructured) bool {
+    return obj.GetAnnotations()[ManagedByAnnotation] == ManagedByValue
+}
+
+// ownsResource reports whether a managed object may be modified on behalf of
+// className. The object must have been created for that class, or be tracked
+// in the inventory for the class that created it (e.g. during a class switch).
+// Inventory entries written before classes were recorded match any class.
+func ownsResource(obj *unstructured.Unstructured, className string, trackedClass *string) bool {
+    createdBy := obj.GetAnnotations()[CreatedByClassAnnotation]
+    if className == "" || createdBy == className {
+        return true
+    }
+    return trackedClass != nil && (*trackedClass == "" || *trackedClass == createdBy)
+}
+
+// mergeExistingMetadata carries the labels and annotations of an adopted
+// object over to the desired object; values from the class take precedence.
+func mergeExistingMetadata(existing, desired *unstructured.Unstructured) {
+    labels := existing.GetLabels()
+    if labels == nil {
+        labels = make(map[string]string)
+    }
+    for k, v := range desired.GetLabels() {
+        labels[k] = v
+    }
+    desired.SetLabels(labels)
+
+    annotations := existing.GetAnnotations()
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    for k, v := range desired.GetAnnotations() {
+        annotations[k] = v
+    }
+    delete(annotations, AdoptAnnotation)
+    desired.SetAnnotations(annotations)
+}
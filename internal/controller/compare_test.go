@@ -0,0 +1,422 @@
+// internal/controller/compare_test.go
+package controller
+
+import (
+    "context"
+    "encoding/json"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Legacy resource hashes", func() {
+    // rendered returns the first resource of a class as the controller
+    // applies it to namespace team, with its hash
+    rendered := func(nsc *v1.NamespaceClass) *unstructured.Unstructured {
+        obj := &unstructured.Unstructured{}
+        Expect(json.Unmarshal(nsc.Spec.Resources[0].Raw, &obj.Object)).To(Succeed())
+        obj.SetNamespace("team")
+        obj.SetAnnotations(map[string]string{
+            ManagedByAnnotation:      "namespaceclass-controller",
+            CreatedByClassAnnotation: nsc.Name,
+            ResourceHashAnnotation:   calculateResourceHash(obj, nil, nsc.Spec.HashAlgorithm),
+        })
+        return obj
+    }
+
+    It("should rewrite the hash of unchanged objects applied by the first releases without updating them", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        Expect(networkingv1.AddToScheme(scheme)).To(Succeed())
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec:       v1.NamespaceClassSpec{Resources: []runtime.RawExtension{createNetworkPolicyRaw("allow-all", "0.0.0.0/0")}},
+        }
+
+        // The object as the first releases applied it, hashing spec alone
+        desired := rendered(nsc)
+        live := desired.DeepCopy()
+        annotations := live.GetAnnotations()
+        annotations[ResourceHashAnnotation] = legacyResourceHash(live)
+        live.SetAnnotations(annotations)
+
+        updates := 0
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc, live,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+                if _, isNamespace := obj.(*corev1.Namespace); !isNamespace && obj.GetName() == "allow-all" {
+                    updates++
+                }
+                return c.Update(ctx, obj, opts...)
+            },
+        }).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(updates).To(BeZero())
+        policy := &networkingv1.NetworkPolicy{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "allow-all"}, policy)).To(Succeed())
+        Expect(policy.Annotations[ResourceHashAnnotation]).To(Equal(desired.GetAnnotations()[ResourceHashAnnotation]))
+    })
+
+    It("should update objects with a legacy hash whose content differs", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"mode":"strict"}}`)},
+            }},
+        }
+        desired := rendered(nsc)
+        live := desired.DeepCopy()
+        live.Object["data"] = map[string]interface{}{"mode": "relaxed"}
+        annotations := live.GetAnnotations()
+        annotations[ResourceHashAnnotation] = legacyResourceHash(live)
+        live.SetAnnotations(annotations)
+
+        // Every ConfigMap has the same legacy hash, as it has no spec
+        Expect(legacyResourceHash(live)).To(Equal(legacyResourceHash(desired)))
+        Expect(needsUpdate(live, desired, applyOptions{})).To(BeTrue())
+    })
+})
+
+var _ = Describe("Ignored fields", func() {
+    It("should exclude ignored fields from the resource hash", func() {
+        obj := &unstructured.Unstructured{}
+        Expect(json.Unmarshal(createNetworkPolicyRaw("policy", "10.0.0.0/8").Raw, &obj.Object)).To(Succeed())
+        hash := calculateResourceHash(obj, []string{"/spec/ingress"}, v1.HashAlgorithmSHA256)
+
+        changed := obj.DeepCopy()
+        Expect(unstructured.SetNestedField(changed.Object, "changed", "spec", "ingress")).To(Succeed())
+        Expect(calculateResourceHash(changed, []string{"spec.ingress"}, v1.HashAlgorithmSHA256)).To(Equal(hash))
+        Expect(calculateResourceHash(changed, nil, "")).NotTo(Equal(calculateResourceHash(obj, nil, "")))
+    })
+
+    It("should preserve live values of ignored fields on update", func() {
+        existing := &unstructured.Unstructured{Object: map[string]interface{}{
+            "spec": map[string]interface{}{"replicas": int64(5), "paused": false},
+        }}
+        desired := &unstructured.Unstructured{Object: map[string]interface{}{
+            "spec": map[string]interface{}{"replicas": int64(1), "paused": true},
+        }}
+        preserveIgnoredFields(existing, desired, resourceIgnoreFields(desired, []string{"/spec/replicas"}))
+        Expect(desired.Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(5), "paused": true}))
+    })
+})
+
+var _ = Describe("Semantic comparison", func() {
+    It("should tolerate apiserver defaulting and normalization", func() {
+        desired := map[string]interface{}{
+            "spec": map[string]interface{}{
+                "limits":   map[string]interface{}{"cpu": "1000m", "memory": "1Gi"},
+                "replicas": int64(2),
+                "selector": map[string]interface{}{},
+            },
+        }
+        live := map[string]interface{}{
+            "spec": map[string]interface{}{
+                "limits":          map[string]interface{}{"cpu": "1", "memory": "1024Mi"},
+                "replicas":        float64(2),
+                "revisionHistory": int64(10),
+            },
+            "status": map[string]interface{}{"ready": true},
+        }
+        Expect(semanticEqual(desired, live)).To(BeTrue())
+
+        live["spec"].(map[string]interface{})["replicas"] = int64(3)
+        Expect(semanticEqual(desired, live)).To(BeFalse())
+    })
+
+    It("should only compare resource quantities by amount", func() {
+        Expect(semanticEqual(
+            map[string]interface{}{"data": map[string]interface{}{"cpu": "1000m", "port": "80", "size": "1e3"}},
+            map[string]interface{}{"data": map[string]interface{}{"cpu": "1", "port": int64(80), "size": "1000"}},
+        )).To(BeFalse())
+        Expect(semanticEqual(
+            map[string]interface{}{"spec": map[string]interface{}{"hard": map[string]interface{}{"requests.storage": "1e3"}}},
+            map[string]interface{}{"spec": map[string]interface{}{"hard": map[string]interface{}{"requests.storage": "1k"}}},
+        )).To(BeTrue())
+    })
+
+    It("should update objects when fields are removed from the class", func() {
+        opts := applyOptions{comparisonMode: v1.ComparisonModeSemantic}
+        withHash := func(data map[string]interface{}) *unstructured.Unstructured {
+            obj := &unstructured.Unstructured{Object: map[string]interface{}{
+                "apiVersion": "v1",
+                "kind":       "ConfigMap",
+                "metadata":   map[string]interface{}{"name": "settings"},
+                "data":       data,
+            }}
+            obj.SetAnnotations(map[string]string{
+                ManagedByAnnotation:    "namespaceclass-controller",
+                ResourceHashAnnotation: calculateResourceHash(obj, nil, v1.HashAlgorithmSHA256),
+            })
+            return obj
+        }
+        live := withHash(map[string]interface{}{"mode": "strict", "level": "2"})
+        live.SetResourceVersion("7")
+
+        Expect(needsUpdate(live, withHash(map[string]interface{}{"mode": "strict", "level": "2"}), opts)).To(BeFalse())
+        Expect(needsUpdate(live, withHash(map[string]interface{}{"mode": "strict"}), opts)).To(BeTrue())
+    })
+
+    It("should prefix non-default hash algorithms", func() {
+        obj := &unstructured.Unstructured{}
+        Expect(json.Unmarshal(createNetworkPolicyRaw("policy", "10.0.0.0/8").Raw, &obj.Object)).To(Succeed())
+        Expect(calculateResourceHash(obj, nil, v1.HashAlgorithmSHA256)).To(HaveLen(64))
+        Expect(calculateResourceHash(obj, nil, v1.HashAlgorithmFNV64a)).To(HavePrefix("fnv64a:"))
+    })
+})
+
+var _ = Describe("Conflict policy", func() {
+    var (
+        reconciler *NamespaceClassReconciler
+        ctx        context.Context
+        desired    *unstructured.Unstructured
+    )
+
+    BeforeEach(func() {
+        ctx = context.Background()
+        scheme := newScheme()
+        reconciler = &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+                ObjectMeta: metav1.ObjectMeta{
+                    Name:      "settings",
+                    Namespace: "team",
+                    Labels:    map[string]string{"owner": "team"},
+                },
+                Data: map[string]string{"mode": "manual"},
+            }).Build(),
+            Scheme: scheme,
+        }
+        desired = &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": "v1",
+            "kind":       "ConfigMap",
+            "metadata": map[string]interface{}{
+                "name":        "settings",
+                "namespace":   "team",
+                "annotations": map[string]interface{}{ManagedByAnnotation: ManagedByValue},
+            },
+            "data": map[string]interface{}{"mode": "managed"},
+        }}
+    })
+
+    getConfigMap := func() *corev1.ConfigMap {
+        cm := &corev1.ConfigMap{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, cm)).To(Succeed())
+        return cm
+    }
+
+    It("should refuse to touch unmanaged resources with Fail", func() {
+        _, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{conflictPolicy: v1.ConflictPolicyFail})
+        Expect(err).To(HaveOccurred())
+        Expect(getConfigMap().Data["mode"]).To(Equal("manual"))
+    })
+
+    It("should leave unmanaged resources alone with Skip", func() {
+        result, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{conflictPolicy: v1.ConflictPolicySkip})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result).To(Equal(applyResultSkipped))
+        Expect(getConfigMap().Data["mode"]).To(Equal("manual"))
+    })
+
+    It("should keep existing metadata when adopting", func() {
+        result, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{conflictPolicy: v1.ConflictPolicyAdopt})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result).To(Equal(applyResultUpdated))
+        cm := getConfigMap()
+        Expect(cm.Data["mode"]).To(Equal("managed"))
+        Expect(cm.Labels).To(HaveKeyWithValue("owner", "team"))
+        Expect(cm.Annotations).To(HaveKeyWithValue(ManagedByAnnotation, ManagedByValue))
+    })
+
+    It("should adopt resources annotated for adoption regardless of policy", func() {
+        cm := getConfigMap()
+        cm.Annotations = map[string]string{AdoptAnnotation: "true"}
+        Expect(reconciler.Update(ctx, cm)).To(Succeed())
+
+        _, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{conflictPolicy: v1.ConflictPolicyFail})
+        Expect(err).NotTo(HaveOccurred())
+        cm = getConfigMap()
+        Expect(cm.Annotations).To(HaveKeyWithValue(ManagedByAnnotation, ManagedByValue))
+        Expect(cm.Annotations).NotTo(HaveKey(AdoptAnnotation))
+    })
+})
+
+var _ = Describe("Ownership verification", func() {
+    var (
+        reconciler *NamespaceClassReconciler
+        ctx        context.Context
+    )
+
+    configMap := func(name string, annotations map[string]string) *corev1.ConfigMap {
+        return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+            Name:        name,
+            Namespace:   "team",
+            Annotations: annotations,
+        }}
+    }
+
+    BeforeEach(func() {
+        ctx = context.Background()
+        scheme := newScheme()
+        reconciler = &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+                configMap("user-owned", nil),
+                configMap("other-class", map[string]string{
+                    ManagedByAnnotation:      ManagedByValue,
+                    CreatedByClassAnnotation: "other",
+                }),
+                configMap("owned", map[string]string{
+                    ManagedByAnnotation:      ManagedByValue,
+                    CreatedByClassAnnotation: "web",
+                }),
+            ).Build(),
+            Scheme: scheme,
+        }
+    })
+
+    exists := func(name string) bool {
+        err := reconciler.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, &corev1.ConfigMap{})
+        return err == nil
+    }
+
+    It("should only prune resources created for the expected class", func() {
+        for _, name := range []string{"user-owned", "other-class", "owned"} {
+            Expect(reconciler.deleteResource(ctx, "team", ManagedResource{
+                APIVersion: "v1", Kind: "ConfigMap", Name: name, Class: "web",
+            })).To(Succeed())
+        }
+        Expect(exists("user-owned")).To(BeTrue())
+        Expect(exists("other-class")).To(BeTrue())
+        Expect(exists("owned")).To(BeFalse())
+    })
+
+    It("should refuse to update resources created by another class", func() {
+        desired := &unstructured.Unstructured{}
+        desired.SetAPIVersion("v1")
+        desired.SetKind("ConfigMap")
+        desired.SetNamespace("team")
+        desired.SetName("other-class")
+        _, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{className: "web"})
+        Expect(err).To(HaveOccurred())
+
+        previous := "other"
+        _, err = reconciler.createOrUpdateResource(ctx, desired, applyOptions{className: "web", trackedClass: &previous})
+        Expect(err).NotTo(HaveOccurred())
+    })
+})
+
+var _ = Describe("Tenant freeze", func() {
+    It("should only leave frozen resources alone when the class allows it", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        reconciler := &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+                ObjectMeta: metav1.ObjectMeta{
+                    Name:      "settings",
+                    Namespace: "team",
+                    Annotations: map[string]string{
+                        ManagedByAnnotation:    ManagedByValue,
+                        ResourceHashAnnotation: "old",
+                        FrozenAnnotation:       "true",
+                    },
+                },
+                Data: map[string]string{"mode": "tenant"},
+            }).Build(),
+            Scheme: scheme,
+        }
+        desired := func() *unstructured.Unstructured {
+            return &unstructured.Unstructured{Object: map[string]interface{}{
+                "apiVersion": "v1",
+                "kind":       "ConfigMap",
+                "metadata": map[string]interface{}{
+                    "name":      "settings",
+                    "namespace": "team",
+                    "annotations": map[string]interface{}{
+                        ManagedByAnnotation:    ManagedByValue,
+                        ResourceHashAnnotation: "new",
+                    },
+                },
+                "data": map[string]interface{}{"mode": "class"},
+            }}
+        }
+        getMode := func() string {
+            cm := &corev1.ConfigMap{}
+            Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, cm)).To(Succeed())
+            return cm.Data["mode"]
+        }
+
+        result, err := reconciler.createOrUpdateResource(ctx, desired(), applyOptions{allowFreeze: true})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result).To(Equal(applyResultFrozen))
+        Expect(getMode()).To(Equal("tenant"))
+
+        result, err = reconciler.createOrUpdateResource(ctx, desired(), applyOptions{})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result).To(Equal(applyResultUpdated))
+        Expect(getMode()).To(Equal("class"))
+    })
+})
+
+var _ = Describe("Create-only fields", func() {
+    It("should keep rotated values of create-only fields on update", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        reconciler := &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+                ObjectMeta: metav1.ObjectMeta{
+                    Name:        "db",
+                    Namespace:   "team",
+                    Annotations: map[string]string{ManagedByAnnotation: ManagedByValue, ResourceHashAnnotation: "old"},
+                },
+                Data: map[string][]byte{"password": []byte("rotated"), "user": []byte("app")},
+            }).Build(),
+            Scheme: scheme,
+        }
+        desired := &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": "v1",
+            "kind":       "Secret",
+            "metadata": map[string]interface{}{
+                "name":      "db",
+                "namespace": "team",
+                "annotations": map[string]interface{}{
+                    ManagedByAnnotation:        ManagedByValue,
+                    CreateOnlyFieldsAnnotation: "/stringData/password",
+                },
+            },
+            "stringData": map[string]interface{}{"password": "initial", "user": "admin"},
+        }}
+        opts := applyOptions{createOnlyFields: annotationFields(desired, CreateOnlyFieldsAnnotation)}
+        withoutPassword := desired.DeepCopy()
+        unstructured.RemoveNestedField(withoutPassword.Object, "stringData", "password")
+        Expect(calculateResourceHash(desired, opts.unmanagedFields(), "")).To(Equal(calculateResourceHash(withoutPassword, nil, "")))
+
+        _, err := reconciler.createOrUpdateResource(ctx, desired, opts)
+        Expect(err).NotTo(HaveOccurred())
+
+        secret := &corev1.Secret{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "team", Name: "db"}, secret)).To(Succeed())
+        Expect(secret.StringData).To(HaveKeyWithValue("user", "admin"))
+        Expect(secret.StringData).NotTo(HaveKey("password"))
+        Expect(string(secret.Data["password"])).To(Equal("rotated"))
+    })
+})
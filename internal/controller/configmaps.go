@@ -0,0 +1,100 @@
+// internal/controller/configmaps.go
+package controller
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation on replicated ConfigMaps holding a hash of the data of their
+// source, so that workloads can roll when it changes
+const SourceHashAnnotation = "namespaceclass.akuity.io/source-hash"
+
+// configMapReplicaName returns the name of the copy of a replicated
+// ConfigMap.
+func configMapReplicaName(replica v1.ConfigMapReplica) string {
+    if replica.Name != "" {
+        return replica.Name
+    }
+    return replica.Source.Name
+}
+
+// replicatesConfigMap reports whether a class copies a ConfigMap.
+func replicatesConfigMap(nsc *v1.NamespaceClass, source types.NamespacedName) bool {
+    for _, replica := range nsc.Spec.ConfigMaps {
+        if replica.Source.Namespace == source.Namespace && replica.Source.Name == source.Name {
+            return true
+        }
+    }
+    return false
+}
+
+// replicateConfigMaps adds copies of the central ConfigMaps of a class to its
+// resources. Sources are read as unstructured objects, which the manager does
+// not cache, so that ConfigMaps are not cached cluster-wide; only their
+// metadata is watched.
+func (r *NamespaceClassReconciler) replicateConfigMaps(ctx context.Context, nsc *v1.NamespaceClass, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+    for _, replica := range nsc.Spec.ConfigMaps {
+        source := &unstructured.Unstructured{}
+        source.SetAPIVersion("v1")
+        source.SetKind("ConfigMap")
+        key := types.NamespacedName{Namespace: replica.Source.Namespace, Name: replica.Source.Name}
+        if err := r.Get(ctx, key, source); err != nil {
+            return nil, fmt.Errorf("failed to get source ConfigMap %s of class %s: %w", key, nsc.Name, err)
+        }
+        replicated, err := configMapReplica(source, configMapReplicaName(replica))
+        if err != nil {
+            return nil, err
+        }
+        resources = append(resources, replicated)
+    }
+    return resources, nil
+}
+
+// configMapReplica returns a copy of a source ConfigMap to apply in a
+// namespace, annotated with its source and a hash of its data.
+func configMapReplica(source *unstructured.Unstructured, name string) (*unstructured.Unstructured, error) {
+    data, _, _ := unstructured.NestedMap(source.Object, "data")
+    binaryData, _, _ := unstructured.NestedMap(source.Object, "binaryData")
+    hash, err := configMapDataHash(data, binaryData)
+    if err != nil {
+        return nil, err
+    }
+
+    replicated := &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "ConfigMap",
+        "metadata": map[string]interface{}{
+            "name": name,
+            "annotations": map[string]interface{}{
+                CopiedFromAnnotation: source.GetNamespace() + "/" + source.GetName(),
+                SourceHashAnnotation: hash,
+            },
+        },
+    }}
+    if len(data) > 0 {
+        replicated.Object["data"] = data
+    }
+    if len(binaryData) > 0 {
+        replicated.Object["binaryData"] = binaryData
+    }
+    return replicated, nil
+}
+
+// configMapDataHash identifies the content of a ConfigMap.
+func configMapDataHash(data, binaryData map[string]interface{}) (string, error) {
+    content, err := json.Marshal([]map[string]interface{}{data, binaryData})
+    if err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(content)
+    return fmt.Sprintf("%s:%s", v1.HashAlgorithmSHA256, hex.EncodeToString(sum[:])), nil
+}
@@ -0,0 +1,62 @@
+// internal/controller/configmaps_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("ConfigMap replication", func() {
+    It("should copy central ConfigMaps with a hash of their data", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        source := &corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "platform"},
+            Data:       map[string]string{"ca.crt": "first"},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{ConfigMaps: []v1.ConfigMapReplica{
+                {Name: "trusted-ca", Source: v1.ObjectSource{Namespace: "platform", Name: "ca-bundle"}},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        Expect(replicatesConfigMap(nsc, types.NamespacedName{Namespace: "platform", Name: "ca-bundle"})).To(BeTrue())
+        Expect(replicatesConfigMap(nsc, types.NamespacedName{Namespace: "team", Name: "ca-bundle"})).To(BeFalse())
+
+        r := &NamespaceClassReconciler{Client: cl}
+        resources, err := r.parseResources(ctx, nsc)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(1))
+        Expect(resources[0].GetName()).To(Equal("trusted-ca"))
+        Expect(resources[0].Object["data"]).To(Equal(map[string]interface{}{"ca.crt": "first"}))
+        Expect(resources[0].GetAnnotations()).To(HaveKeyWithValue(CopiedFromAnnotation, "platform/ca-bundle"))
+        firstHash := resources[0].GetAnnotations()[SourceHashAnnotation]
+
+        // Metadata changes do not change the copy; data changes do
+        source.Labels = map[string]string{"owner": "platform"}
+        Expect(cl.Update(ctx, source)).To(Succeed())
+        resources, err = r.parseResources(ctx, nsc)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources[0].GetAnnotations()[SourceHashAnnotation]).To(Equal(firstHash))
+        source.Data["ca.crt"] = "second"
+        Expect(cl.Update(ctx, source)).To(Succeed())
+        resources, err = r.parseResources(ctx, nsc)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources[0].GetAnnotations()[SourceHashAnnotation]).NotTo(Equal(firstHash))
+        Expect(resources[0].Object["data"]).To(Equal(map[string]interface{}{"ca.crt": "second"}))
+
+        Expect(cl.Delete(ctx, source)).To(Succeed())
+        _, err = r.parseResources(ctx, nsc)
+        Expect(err).To(MatchError(ContainSubstring("failed to get source ConfigMap platform/ca-bundle")))
+    })
+})
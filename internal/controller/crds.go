@@ -0,0 +1,52 @@
+// internal/controller/crds.go
+package controller
+
+import (
+    "k8s.io/apimachinery/pkg/api/meta"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupKind of CustomResourceDefinitions
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+// definedGroupKind returns the group and kind a CustomResourceDefinition
+// defines, or false if the resource is not a CustomResourceDefinition.
+func definedGroupKind(res *unstructured.Unstructured) (schema.GroupKind, bool) {
+    if res.GroupVersionKind().GroupKind() != crdGroupKind {
+        return schema.GroupKind{}, false
+    }
+    group, _, _ := unstructured.NestedString(res.Object, "spec", "group")
+    kind, _, _ := unstructured.NestedString(res.Object, "spec", "names", "kind")
+    if kind == "" {
+        return schema.GroupKind{}, false
+    }
+    return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+// classDefinitions indexes the CustomResourceDefinitions among the resources
+// of a class by the group and kind they define.
+func classDefinitions(resources []*unstructured.Unstructured) map[schema.GroupKind]*unstructured.Unstructured {
+    definitions := make(map[schema.GroupKind]*unstructured.Unstructured)
+    for _, res := range resources {
+        if gk, ok := definedGroupKind(res); ok {
+            definitions[gk] = res
+        }
+    }
+    return definitions
+}
+
+// crdEstablished reports whether a live CustomResourceDefinition is served.
+// A definition that was just created has no Established condition yet.
+func crdEstablished(live *unstructured.Unstructured) bool {
+    c := findCondition(live, "Established")
+    return c != nil && c["status"] == "True"
+}
+
+// resetRESTMapper drops what the mapper knows about served kinds, so that
+// kinds of newly established CustomResourceDefinitions are discovered.
+func resetRESTMapper(mapper meta.RESTMapper) {
+    if resettable, ok := mapper.(meta.ResettableRESTMapper); ok {
+        resettable.Reset()
+    }
+}
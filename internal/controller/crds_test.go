@@ -0,0 +1,59 @@
+// internal/controller/crds_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("CustomResourceDefinitions in classes", func() {
+    It("should apply custom resources only once their definition is established", func() {
+        ctx := context.Background()
+        crd := &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": "apiextensions.k8s.io/v1",
+            "kind":       "CustomResourceDefinition",
+            "metadata":   map[string]interface{}{"name": "widgets.example.com"},
+            "spec": map[string]interface{}{
+                "group": "example.com",
+                "names": map[string]interface{}{"kind": "Widget", "plural": "widgets"},
+            },
+        }}
+        widget := &unstructured.Unstructured{}
+        widget.SetAPIVersion("example.com/v1")
+        widget.SetKind("Widget")
+        widget.SetName("default")
+        resources := []*unstructured.Unstructured{widget, crd}
+        Expect(orderResources(resources)).To(Succeed())
+        Expect(resources[0].GetKind()).To(Equal("CustomResourceDefinition"))
+
+        // A definition must not come after the resources of its kind
+        late := crd.DeepCopy()
+        late.SetAnnotations(map[string]string{WaveAnnotation: "1"})
+        Expect(orderResources([]*unstructured.Unstructured{widget, late})).NotTo(Succeed())
+
+        reconciler := &NamespaceClassReconciler{Client: fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(crd.DeepCopy()).Build()}
+        pending, err := reconciler.pendingResource(ctx, []*unstructured.Unstructured{crd})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(pending).To(Equal(crd))
+        status, _ := resourceHealth(crd)
+        Expect(status).To(Equal(v1.HealthInProgress))
+
+        established := crd.DeepCopy()
+        Expect(unstructured.SetNestedSlice(established.Object, []interface{}{
+            map[string]interface{}{"type": "Established", "status": "True"},
+        }, "status", "conditions")).To(Succeed())
+        reconciler = &NamespaceClassReconciler{Client: fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(established).Build()}
+        pending, err = reconciler.pendingResource(ctx, []*unstructured.Unstructured{crd})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(pending).To(BeNil())
+        status, _ = resourceHealth(established)
+        Expect(status).To(Equal(v1.HealthCurrent))
+    })
+})
@@ -0,0 +1,138 @@
+// internal/controller/dependencies.go
+package controller
+
+import (
+    "fmt"
+    "strings"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Annotation on an embedded resource listing the resources of the class it
+// depends on, as comma separated Kind/name references. A resource is only
+// applied after its dependencies exist and are ready.
+const DependsOnAnnotation = "namespaceclass.akuity.io/depends-on"
+
+// resourceRef identifies a resource within a class for dependency
+// references.
+func resourceRef(obj *unstructured.Unstructured) string {
+    return obj.GetKind() + "/" + obj.GetName()
+}
+
+// resourceDependencies returns the references of the depends-on annotation
+// of a resource.
+func resourceDependencies(obj *unstructured.Unstructured) []string {
+    var refs []string
+    for _, ref := range strings.Split(obj.GetAnnotations()[DependsOnAnnotation], ",") {
+        if ref = strings.TrimSpace(ref); ref != "" {
+            refs = append(refs, ref)
+        }
+    }
+    return refs
+}
+
+// dependencyGraph holds the dependencies between the resources of a class
+// as indexes into the resource list.
+type dependencyGraph struct {
+    resources []*unstructured.Unstructured
+    waves     []int
+    deps      [][]int
+}
+
+// buildDependencyGraph resolves the depends-on references of the resources.
+// Custom resources also depend on the CustomResourceDefinition of their kind
+// if the class has it. A resource may only depend on resources of the class
+// in the same or an earlier sync wave.
+func buildDependencyGraph(resources []*unstructured.Unstructured) (*dependencyGraph, error) {
+    g := &dependencyGraph{
+        resources: resources,
+        waves:     make([]int, len(resources)),
+        deps:      make([][]int, len(resources)),
+    }
+    index := make(map[string]int, len(resources))
+    for i, res := range resources {
+        wave, err := resourceWave(res)
+        if err != nil {
+            return nil, err
+        }
+        g.waves[i] = wave
+        index[resourceRef(res)] = i
+    }
+    definitions := classDefinitions(resources)
+    for i, res := range resources {
+        if crd, ok := definitions[res.GroupVersionKind().GroupKind()]; ok {
+            j := index[resourceRef(crd)]
+            if g.waves[j] > g.waves[i] {
+                return nil, fmt.Errorf("%s in wave %d is defined by %s in the later wave %d", resourceRef(res), g.waves[i], resourceRef(crd), g.waves[j])
+            }
+            g.deps[i] = append(g.deps[i], j)
+        }
+        for _, ref := range resourceDependencies(res) {
+            j, ok := index[ref]
+            if !ok {
+                return nil, fmt.Errorf("%s depends on %s, which is not part of the class", resourceRef(res), ref)
+            }
+            if g.waves[j] > g.waves[i] {
+                return nil, fmt.Errorf("%s in wave %d depends on %s in the later wave %d", resourceRef(res), g.waves[i], ref, g.waves[j])
+            }
+            g.deps[i] = append(g.deps[i], j)
+        }
+    }
+    return g, nil
+}
+
+// order returns the resource indexes in the order they are applied: by sync
+// wave, with every resource after its dependencies, and otherwise in the
+// order of the class.
+func (g *dependencyGraph) order() ([]int, error) {
+    applied := make([]bool, len(g.resources))
+    order := make([]int, 0, len(g.resources))
+    for len(order) < len(g.resources) {
+        next := -1
+        for i := range g.resources {
+            if applied[i] || (next >= 0 && g.waves[i] >= g.waves[next]) {
+                continue
+            }
+            ready := true
+            for _, j := range g.deps[i] {
+                if !applied[j] {
+                    ready = false
+                    break
+                }
+            }
+            if ready {
+                next = i
+            }
+        }
+        if next < 0 {
+            var cycle []string
+            for i, res := range g.resources {
+                if !applied[i] {
+                    cycle = append(cycle, resourceRef(res))
+                }
+            }
+            return nil, fmt.Errorf("dependency cycle involving %s", strings.Join(cycle, ", "))
+        }
+        applied[next] = true
+        order = append(order, next)
+    }
+    return order, nil
+}
+
+// orderResources sorts resources into the order they are applied in.
+func orderResources(resources []*unstructured.Unstructured) error {
+    g, err := buildDependencyGraph(resources)
+    if err != nil {
+        return err
+    }
+    order, err := g.order()
+    if err != nil {
+        return err
+    }
+    sorted := make([]*unstructured.Unstructured, len(order))
+    for i, j := range order {
+        sorted[i] = resources[j]
+    }
+    copy(resources, sorted)
+    return nil
+}
@@ -0,0 +1,78 @@
+// internal/controller/dependencies_test.go
+package controller
+
+import (
+    "encoding/json"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Resource dependencies", func() {
+    resource := func(kind, name, wave, dependsOn string) runtime.RawExtension {
+        u := &unstructured.Unstructured{}
+        u.SetAPIVersion("v1")
+        u.SetKind(kind)
+        u.SetName(name)
+        annotations := map[string]string{}
+        if wave != "" {
+            annotations[WaveAnnotation] = wave
+        }
+        if dependsOn != "" {
+            annotations[DependsOnAnnotation] = dependsOn
+        }
+        u.SetAnnotations(annotations)
+        raw, err := json.Marshal(u.Object)
+        Expect(err).NotTo(HaveOccurred())
+        return runtime.RawExtension{Raw: raw}
+    }
+
+    It("should apply resources after the resources they depend on", func() {
+        nsc := &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "deps"}}
+        nsc.Spec.Resources = []runtime.RawExtension{
+            resource("RoleBinding", "deployer", "", "ServiceAccount/deployer, Role/deployer"),
+            resource("Role", "deployer", "", ""),
+            resource("ConfigMap", "settings", "1", "RoleBinding/deployer"),
+            resource("ServiceAccount", "deployer", "", ""),
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+
+        rendered, err := RenderClass(nsc, "team")
+        Expect(err).NotTo(HaveOccurred())
+        var refs []string
+        for _, res := range rendered {
+            refs = append(refs, resourceRef(res))
+        }
+        Expect(refs).To(Equal([]string{"Role/deployer", "ServiceAccount/deployer", "RoleBinding/deployer", "ConfigMap/settings"}))
+    })
+
+    It("should reject unknown references, later waves and cycles", func() {
+        nsc := &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "deps"}}
+        nsc.Spec.Resources = []runtime.RawExtension{
+            resource("ConfigMap", "a", "", "Secret/missing"),
+            resource("ConfigMap", "b", "", "ConfigMap/c"),
+            resource("ConfigMap", "c", "2", ""),
+        }
+        errs := ValidateClass(nsc)
+        Expect(errs).To(HaveLen(2))
+        Expect(errs[0].Field).To(Equal("spec.resources[0].metadata.annotations[" + DependsOnAnnotation + "]"))
+        Expect(errs[1].Detail).To(ContainSubstring("later wave 2"))
+
+        nsc.Spec.Resources = []runtime.RawExtension{
+            resource("ConfigMap", "a", "", "ConfigMap/b"),
+            resource("ConfigMap", "b", "", "ConfigMap/a"),
+            resource("ConfigMap", "c", "", ""),
+        }
+        errs = ValidateClass(nsc)
+        Expect(errs).To(HaveLen(1))
+        Expect(errs[0].Field).To(Equal("spec.resources"))
+        Expect(errs[0].Detail).To(Equal("dependency cycle involving ConfigMap/a, ConfigMap/b"))
+        _, err := RenderClass(nsc, "team")
+        Expect(err).To(HaveOccurred())
+    })
+})
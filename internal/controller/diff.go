@@ -0,0 +1,58 @@
+// internal/controller/diff.go
+package controller
+
+import (
+    "fmt"
+    "sort"
+)
+
+// fieldChange is a single field the controller is about to change.
+type fieldChange struct {
+    Path string      `json:"path"`
+    Old  interface{} `json:"old,omitempty"`
+    New  interface{} `json:"new,omitempty"`
+}
+
+// diffObjects lists the fields of desired whose values differ from live,
+// using the same equivalence as semantic comparison. Fields only present in
+// live are usually apiserver defaults and are not reported. Lists of
+// different length are reported as a whole.
+func diffObjects(live, desired map[string]interface{}) []fieldChange {
+    var changes []fieldChange
+    diffValues("", nil, live, desired, &changes)
+    return changes
+}
+
+func diffValues(path string, segments []string, live, desired interface{}, changes *[]fieldChange) {
+    switch d := desired.(type) {
+    case map[string]interface{}:
+        if l, ok := live.(map[string]interface{}); ok && !isEmptyValue(d) {
+            keys := make([]string, 0, len(d))
+            for key := range d {
+                keys = append(keys, key)
+            }
+            sort.Strings(keys)
+            for _, key := range keys {
+                diffValues(joinPath(path, key), append(segments[:len(segments):len(segments)], key), l[key], d[key], changes)
+            }
+            return
+        }
+    case []interface{}:
+        if l, ok := live.([]interface{}); ok && len(l) == len(d) {
+            for i := range d {
+                diffValues(fmt.Sprintf("%s[%d]", path, i), segments, l[i], d[i], changes)
+            }
+            return
+        }
+    }
+    if !semanticEqualAt(segments, desired, live) {
+        *changes = append(*changes, fieldChange{Path: path, Old: live, New: desired})
+    }
+}
+
+func joinPath(path, key string) string {
+    if path == "" {
+        return key
+    }
+    return path + "." + key
+}
@@ -0,0 +1,33 @@
+// internal/controller/diff_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+)
+
+var _ = Describe("Update diffs", func() {
+    It("should list the fields that differ from the live object", func() {
+        live := map[string]interface{}{
+            "data": map[string]interface{}{"mode": "relaxed", "level": "3"},
+            "spec": map[string]interface{}{
+                "replicas":  int64(2),
+                "ports":     []interface{}{map[string]interface{}{"port": int64(80), "protocol": "TCP"}},
+                "selectors": []interface{}{"a"},
+            },
+        }
+        desired := map[string]interface{}{
+            "data": map[string]interface{}{"mode": "strict", "level": "3"},
+            "spec": map[string]interface{}{
+                "replicas":  float64(2),
+                "ports":     []interface{}{map[string]interface{}{"port": int64(8080)}},
+                "selectors": []interface{}{"a", "b"},
+            },
+        }
+        Expect(diffObjects(live, desired)).To(Equal([]fieldChange{
+            {Path: "data.mode", Old: "relaxed", New: "strict"},
+            {Path: "spec.ports[0].port", Old: int64(80), New: int64(8080)},
+            {Path: "spec.selectors", Old: []interface{}{"a"}, New: []interface{}{"a", "b"}},
+        }))
+    })
+})
@@ -0,0 +1,70 @@
+// internal/controller/driftwatch.go
+package controller
+
+import (
+    "context"
+    "sync"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/cache"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/controller"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+    "sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// driftWatches watches every kind the controller has applied, so that a
+// namespace is reconciled as soon as one of its managed resources is changed
+// or deleted outside the controller instead of on the next resync. Watches
+// are started the first time a kind is applied and cache all objects of the
+// kind, which is why they sit behind the DriftWatches feature gate.
+type driftWatches struct {
+    controller controller.Controller
+    cache      cache.Cache
+
+    mu      sync.Mutex
+    watched map[schema.GroupVersionKind]bool
+}
+
+// watch starts watching a kind unless it is watched already. A nil
+// driftWatches does nothing.
+func (w *driftWatches) watch(gvk schema.GroupVersionKind) error {
+    if w == nil {
+        return nil
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if w.watched[gvk] {
+        return nil
+    }
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(gvk)
+    if err := w.controller.Watch(source.Kind[client.Object](w.cache, obj, handler.EnqueueRequestsFromMapFunc(driftedNamespace))); err != nil {
+        return err
+    }
+    if w.watched == nil {
+        w.watched = make(map[schema.GroupVersionKind]bool)
+    }
+    w.watched[gvk] = true
+    return nil
+}
+
+// driftedNamespace maps a managed resource to the namespace it was applied
+// for. Objects the controller does not manage are ignored.
+func driftedNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+    if obj.GetAnnotations()[ManagedByAnnotation] != ManagedByValue {
+        return nil
+    }
+    namespace := obj.GetNamespace()
+    if namespace == "" {
+        namespace = obj.GetAnnotations()[OwnerNamespaceAnnotation]
+    }
+    if namespace == "" {
+        return nil
+    }
+    return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: namespace}}}
+}
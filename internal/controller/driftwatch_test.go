@@ -0,0 +1,35 @@
+// internal/controller/driftwatch_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Drift watches", func() {
+    It("should reconcile the namespace a changed managed resource was applied for", func() {
+        ctx := context.Background()
+        cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "web"}}
+        Expect(driftedNamespace(ctx, cm)).To(BeEmpty())
+
+        cm.Annotations = map[string]string{ManagedByAnnotation: ManagedByValue}
+        Expect(driftedNamespace(ctx, cm)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}}))
+
+        role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "web-admin", Annotations: map[string]string{
+            ManagedByAnnotation:      ManagedByValue,
+            OwnerNamespaceAnnotation: "web",
+        }}}
+        Expect(driftedNamespace(ctx, role)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}}))
+
+        // Without the feature gate no kinds are watched
+        var watches *driftWatches
+        Expect(watches.watch(corev1.SchemeGroupVersion.WithKind("ConfigMap"))).To(Succeed())
+    })
+})
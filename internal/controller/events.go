@@ -0,0 +1,96 @@
+// internal/controller/events.go
+package controller
+
+import (
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/runtime"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Event reasons emitted by the controller.
+const (
+    ReasonResourceRecreated    = "ResourceRecreated"
+    ReasonWaitingForRecreate   = "WaitingForRecreate"
+    ReasonResourceConflict     = "ResourceConflict"
+    ReasonResourceAdopted      = "ResourceAdopted"
+    ReasonOwnershipConflict    = "OwnershipConflict"
+    ReasonResourceCreated      = "ResourceCreated"
+    ReasonResourceUpdated      = "ResourceUpdated"
+    ReasonResourcePruned       = "ResourcePruned"
+    ReasonApplyFailed          = "ApplyFailed"
+    ReasonPruneFailed          = "PruneFailed"
+    ReasonClassNotFound        = "ClassNotFound"
+    ReasonRolloutPaused        = "RolloutPaused"
+    ReasonWaitingForData       = "WaitingForGeneratedData"
+    ReasonWaitingForWave       = "WaitingForWave"
+    ReasonWaitingForDependency = "WaitingForDependency"
+    ReasonRevisionNotFound     = "RevisionNotFound"
+    ReasonOutsideUpdateWindow  = "OutsideUpdateWindow"
+    ReasonReadyTimeout         = "ReadyTimeout"
+    ReasonHookFailed           = "HookFailed"
+    ReasonResourceRetained     = "ResourceRetained"
+    ReasonClassConflict        = "ClassConflict"
+    ReasonMetadataUpdated      = "NamespaceMetadataUpdated"
+    ReasonKindNotInstalled     = "KindNotInstalled"
+    ReasonPermissionDenied     = "PermissionDenied"
+    ReasonKindForbidden        = "KindForbidden"
+    ReasonNamespaceExpired     = "NamespaceExpired"
+    ReasonInvalidTTL           = "InvalidTTL"
+    ReasonNamespaceProvisioned = "NamespaceProvisioned"
+    ReasonNamespaceRemoved     = "NamespaceRemoved"
+    ReasonNamespaceOrphaned    = "NamespaceOrphaned"
+    ReasonNamespaceConflict    = "NamespaceConflict"
+    ReasonCircuitOpen          = "CircuitOpen"
+    ReasonRolledBack           = "RolledBack"
+    ReasonOrphanDeleted        = "OrphanDeleted"
+    ReasonResourceRenamed      = "ResourceRenamed"
+    ReasonTooManyResources     = "TooManyResources"
+    ReasonInvalidValues        = "InvalidValues"
+)
+
+// recordEvent emits an event if the reconciler has a recorder configured.
+func (r *NamespaceClassReconciler) recordEvent(obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+    if r.Recorder == nil {
+        return
+    }
+    r.Recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
+
+// recordSyncEvent emits an event about syncing a namespace in the namespace
+// itself and, if known, on the class it is applied for.
+func (r *NamespaceClassReconciler) recordSyncEvent(ns *corev1.Namespace, nsc *v1.NamespaceClass, eventType, reason, messageFmt string, args ...interface{}) {
+    message := fmt.Sprintf(messageFmt, args...)
+    r.recordEvent(tenantRef(ns), eventType, reason, "%s", message)
+    if nsc != nil {
+        r.recordEvent(nsc, eventType, reason, "Namespace %s: %s", ns.Name, message)
+    }
+}
+
+// tenantRef refers to a namespace from within the namespace. Events about
+// cluster-scoped objects are stored in the default namespace, where tenants
+// cannot see them; events for this reference are stored in the tenant's
+// namespace, so `kubectl get events` there shows what the controller did.
+func tenantRef(ns *corev1.Namespace) *corev1.ObjectReference {
+    return &corev1.ObjectReference{
+        APIVersion: "v1",
+        Kind:       "Namespace",
+        Name:       ns.Name,
+        Namespace:  ns.Name,
+        UID:        ns.UID,
+    }
+}
+
+// isImmutableFieldError reports whether an update was rejected because it
+// tried to change a field that cannot be modified after creation.
+func isImmutableFieldError(err error) bool {
+    if !errors.IsInvalid(err) {
+        return false
+    }
+    msg := err.Error()
+    return strings.Contains(msg, "field is immutable") || strings.Contains(msg, "may not change once set")
+}
@@ -0,0 +1,48 @@
+// internal/controller/events_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Events", func() {
+    It("should record applied resources on the namespace and the class", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        recorder := record.NewFakeRecorder(10)
+        reconciler := &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:       "team",
+                    Labels:     map[string]string{LabelKey: "web"},
+                    Finalizers: []string{NamespaceFinalizer},
+                }},
+                &v1.NamespaceClass{
+                    ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                    Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                        {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                    }},
+                },
+            ).WithStatusSubresource(&v1.NamespaceClass{}).Build(),
+            Scheme:   scheme,
+            Recorder: recorder,
+        }
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(recorder.Events).To(Receive(Equal("Normal ResourceCreated Created ConfigMap settings")))
+        Expect(recorder.Events).To(Receive(Equal("Normal ResourceCreated Namespace team: Created ConfigMap settings")))
+    })
+})
@@ -0,0 +1,119 @@
+// internal/controller/fairness.go
+package controller
+
+import (
+    "context"
+    "math/rand"
+    "sync"
+    "time"
+
+    "golang.org/x/time/rate"
+    "k8s.io/client-go/util/workqueue"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// ClassFairness spreads out the reconciles a class change queues for the
+// namespaces using it, so that a rollout of a class with many namespaces
+// does not starve the namespaces of other classes. Each class has its own
+// token bucket: namespaces of a changed class beyond the burst are queued
+// with a delay, while changes to other classes and to namespaces themselves
+// are queued immediately. Jitter additionally smears the namespaces of a
+// rollout over time, so that their applies do not reach the API server in
+// synchronized waves.
+type ClassFairness struct {
+    // ReconcilesPerSecond is the sustained rate at which the namespaces of one class are queued
+    ReconcilesPerSecond float64
+
+    // Burst is the number of namespaces of one class queued at once before the rate applies
+    Burst int
+
+    // Jitter delays each namespace of a changed class by a random time of up to this long, smearing rollouts
+    Jitter time.Duration
+
+    mu       sync.Mutex
+    limiters map[string]*rate.Limiter
+}
+
+// limiter returns the token bucket of a class.
+func (f *ClassFairness) limiter(className string) *rate.Limiter {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    if f.limiters == nil {
+        f.limiters = make(map[string]*rate.Limiter)
+    }
+    limiter, ok := f.limiters[className]
+    if !ok {
+        burst := f.Burst
+        if burst < 1 {
+            burst = 1
+        }
+        limiter = rate.NewLimiter(rate.Limit(f.ReconcilesPerSecond), burst)
+        f.limiters[className] = limiter
+    }
+    return limiter
+}
+
+// forget drops the token bucket of a deleted class.
+func (f *ClassFairness) forget(className string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    delete(f.limiters, className)
+}
+
+// handler wraps the event handler of class events so that the requests it
+// queues for the class take tokens from the class's bucket and are delayed
+// by the jitter. A nil fairness or one without a rate or jitter returns the
+// handler unchanged.
+func (f *ClassFairness) handler(h handler.EventHandler) handler.EventHandler {
+    if f == nil || (f.ReconcilesPerSecond <= 0 && f.Jitter <= 0) {
+        return h
+    }
+    queue := func(className string, q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+        fq := &fairQueue{RateLimitingInterface: q, jitter: f.Jitter}
+        if f.ReconcilesPerSecond > 0 {
+            fq.limiter = f.limiter(className)
+        }
+        return fq
+    }
+    return handler.Funcs{
+        CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+            h.Create(ctx, e, queue(e.Object.GetName(), q))
+        },
+        UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+            h.Update(ctx, e, queue(e.ObjectNew.GetName(), q))
+        },
+        DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+            h.Delete(ctx, e, q)
+            f.forget(e.Object.GetName())
+        },
+        GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+            h.Generic(ctx, e, queue(e.Object.GetName(), q))
+        },
+    }
+}
+
+// fairQueue delays the requests added to a work queue by the time their
+// class's token bucket needs to refill, plus a random jitter.
+type fairQueue struct {
+    workqueue.RateLimitingInterface
+    limiter *rate.Limiter
+    jitter  time.Duration
+}
+
+// Add queues the request once a token is available and the jitter passed.
+func (q *fairQueue) Add(item interface{}) {
+    var delay time.Duration
+    if q.limiter != nil {
+        delay = q.limiter.Reserve().Delay()
+    }
+    if q.jitter > 0 {
+        delay += time.Duration(rand.Int63n(int64(q.jitter)))
+    }
+    if delay > 0 {
+        q.RateLimitingInterface.AddAfter(item, delay)
+        return
+    }
+    q.RateLimitingInterface.Add(item)
+}
@@ -0,0 +1,71 @@
+// internal/controller/fairness_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/workqueue"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class fairness", func() {
+    It("should spread out the namespaces of a large class rollout", func() {
+        ctx := context.Background()
+        namespaces := map[string]int{"big": 5, "small": 1}
+        fairness := &ClassFairness{ReconcilesPerSecond: 1, Burst: 2}
+        h := fairness.handler(handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+            var requests []reconcile.Request
+            for i := 0; i < namespaces[obj.GetName()]; i++ {
+                name := fmt.Sprintf("%s-%d", obj.GetName(), i)
+                requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+            }
+            return requests
+        }))
+        q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+        defer q.ShutDown()
+
+        update := func(className string) {
+            nsc := &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: className}}
+            h.Update(ctx, event.UpdateEvent{ObjectOld: nsc, ObjectNew: nsc}, q)
+        }
+        update("big")
+        Expect(q.Len()).To(Equal(2))
+
+        // Other classes are not held up by the rollout
+        update("small")
+        Expect(q.Len()).To(Equal(3))
+        Eventually(q.Len, 3*time.Second, 100*time.Millisecond).Should(BeNumerically(">", 3))
+    })
+
+    It("should smear rollouts and periodic requeues with jitter", func() {
+        ctx := context.Background()
+        h := (&ClassFairness{Jitter: 200 * time.Millisecond}).handler(handler.EnqueueRequestsFromMapFunc(
+            func(ctx context.Context, obj client.Object) []reconcile.Request {
+                return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "web"}}}
+            }))
+        q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+        defer q.ShutDown()
+
+        nsc := &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "team"}}
+        h.Update(ctx, event.UpdateEvent{ObjectOld: nsc, ObjectNew: nsc}, q)
+        Eventually(q.Len, time.Second, 10*time.Millisecond).Should(Equal(1))
+
+        reconciler := &NamespaceClassReconciler{RequeueJitter: 0.5}
+        for i := 0; i < 10; i++ {
+            Expect(reconciler.jittered(10 * time.Second)).To(And(
+                BeNumerically(">=", 10*time.Second), BeNumerically("<=", 15*time.Second)))
+        }
+        Expect((&NamespaceClassReconciler{}).jittered(10 * time.Second)).To(Equal(10 * time.Second))
+    })
+})
@@ -0,0 +1,66 @@
+// internal/controller/freeze.go
+package controller
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+    // Data key of the maintenance ConfigMap that freezes class rollouts
+    MaintenanceFrozenKey = "frozen"
+
+    // How long a read of the maintenance ConfigMap is reused
+    maintenanceCacheTTL = 10 * time.Second
+
+    // How often frozen namespaces are retried
+    freezeRequeueInterval = time.Minute
+)
+
+// MaintenanceSignal reads a cluster-maintenance ConfigMap that pauses class
+// rollouts to already provisioned namespaces while the cluster is upgraded.
+// Provisioning of new namespaces is never paused. The ConfigMap is read
+// through an uncached reader and the result reused for a short time, so no
+// ConfigMap informer is needed.
+type MaintenanceSignal struct {
+    // Reader is used to read the ConfigMap, typically the manager's API reader
+    Reader client.Reader
+
+    // ConfigMap identifies the maintenance ConfigMap
+    ConfigMap types.NamespacedName
+
+    mu        sync.Mutex
+    frozen    bool
+    checkedAt time.Time
+}
+
+// Frozen reports whether rollouts are currently frozen. A missing ConfigMap
+// means no maintenance is in progress.
+func (m *MaintenanceSignal) Frozen(ctx context.Context) (bool, error) {
+    if m == nil || m.ConfigMap.Name == "" {
+        return false, nil
+    }
+    
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if time.Since(m.checkedAt) < maintenanceCacheTTL {
+        return m.frozen, nil
+    }
+    
+    cm := &corev1.ConfigMap{}
+    if err := m.Reader.Get(ctx, m.ConfigMap, cm); err != nil {
+        if !errors.IsNotFound(err) {
+            return false, err
+        }
+        cm.Data = nil
+    }
+    m.frozen = cm.Data[MaintenanceFrozenKey] == "true"
+    m.checkedAt = time.Now()
+    return m.frozen, nil
+}
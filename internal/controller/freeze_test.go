@@ -0,0 +1,36 @@
+// internal/controller/freeze_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Maintenance freeze", func() {
+    It("should pause rollouts only while the maintenance ConfigMap is frozen", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        key := types.NamespacedName{Namespace: "kube-system", Name: "cluster-maintenance"}
+
+        missing := &MaintenanceSignal{Reader: fake.NewClientBuilder().WithScheme(scheme).Build(), ConfigMap: key}
+        Expect(missing.Frozen(ctx)).To(BeFalse())
+
+        frozen := &MaintenanceSignal{
+            Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+                ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+                Data:       map[string]string{MaintenanceFrozenKey: "true"},
+            }).Build(),
+            ConfigMap: key,
+        }
+        Expect(frozen.Frozen(ctx)).To(BeTrue())
+
+        var disabled *MaintenanceSignal
+        Expect(disabled.Frozen(ctx)).To(BeFalse())
+    })
+})
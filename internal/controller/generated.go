@@ -0,0 +1,60 @@
+// internal/controller/generated.go
+package controller
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+    // Annotation on an embedded resource listing data keys that are generated
+    // after creation and must be populated before dependent resources are applied
+    WaitForDataAnnotation = "namespaceclass.akuity.io/wait-for-data"
+
+    // How often resources waiting for generated data are rechecked
+    generatedDataRequeueInterval = 2 * time.Second
+)
+
+// generatedDataKeys returns the data keys that are filled in asynchronously
+// for a resource. Service account token Secrets always wait for their token.
+func generatedDataKeys(obj *unstructured.Unstructured) []string {
+    var keys []string
+    if obj.GetKind() == "Secret" && obj.GetAPIVersion() == "v1" {
+        if secretType, _, _ := unstructured.NestedString(obj.Object, "type"); secretType == string(corev1.SecretTypeServiceAccountToken) {
+            keys = append(keys, corev1.ServiceAccountTokenKey)
+        }
+    }
+    for _, key := range strings.Split(obj.GetAnnotations()[WaitForDataAnnotation], ",") {
+        if key = strings.TrimSpace(key); key != "" && !containsString(keys, key) {
+            keys = append(keys, key)
+        }
+    }
+    return keys
+}
+
+// generatedDataReady reports whether all generated data keys of a resource
+// have been populated in the live object.
+func (r *NamespaceClassReconciler) generatedDataReady(ctx context.Context, desired *unstructured.Unstructured) (bool, error) {
+    keys := generatedDataKeys(desired)
+    if len(keys) == 0 {
+        return true, nil
+    }
+    
+    live := &unstructured.Unstructured{}
+    live.SetGroupVersionKind(desired.GroupVersionKind())
+    if err := r.Get(ctx, client.ObjectKeyFromObject(desired), live); err != nil {
+        return false, client.IgnoreNotFound(err)
+    }
+    data, _, _ := unstructured.NestedMap(live.Object, "data")
+    for _, key := range keys {
+        if value, ok := data[key].(string); !ok || value == "" {
+            return false, nil
+        }
+    }
+    return true, nil
+}
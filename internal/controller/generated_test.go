@@ -0,0 +1,43 @@
+// internal/controller/generated_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Generated data", func() {
+    It("should wait until a service account token has been populated", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        secret := &corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "ci-token"},
+            Type:       corev1.SecretTypeServiceAccountToken,
+        }
+        c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+        reconciler := &NamespaceClassReconciler{Client: c, Scheme: scheme}
+
+        desired := &unstructured.Unstructured{}
+        desired.SetAPIVersion("v1")
+        desired.SetKind("Secret")
+        desired.SetNamespace("team")
+        desired.SetName("ci-token")
+        Expect(unstructured.SetNestedField(desired.Object, string(corev1.SecretTypeServiceAccountToken), "type")).To(Succeed())
+
+        ready, err := reconciler.generatedDataReady(ctx, desired)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(ready).To(BeFalse())
+
+        secret.Data = map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token")}
+        Expect(c.Update(ctx, secret)).To(Succeed())
+        ready, err = reconciler.generatedDataReady(ctx, desired)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(ready).To(BeTrue())
+    })
+})
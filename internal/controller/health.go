@@ -0,0 +1,222 @@
+// internal/controller/health.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // How long resources may stay unhealthy when the class sets no timeout
+    defaultReadyTimeout = 10 * time.Minute
+
+    // How often unhealthy resources are rechecked
+    healthRequeueInterval = 15 * time.Second
+)
+
+// readyTimeout returns how long the resources of a class may stay unhealthy.
+func readyTimeout(nsc *v1.NamespaceClass) time.Duration {
+    if nsc.Spec.ReadyTimeout != nil && nsc.Spec.ReadyTimeout.Duration > 0 {
+        return nsc.Spec.ReadyTimeout.Duration
+    }
+    return defaultReadyTimeout
+}
+
+// checkHealth reports the health of the applied resources of a class and the
+// number of resources that are not Current.
+func (r *NamespaceClassReconciler) checkHealth(ctx context.Context, resources []*unstructured.Unstructured) ([]v1.ResourceHealth, int, error) {
+    health := make([]v1.ResourceHealth, 0, len(resources))
+    unhealthy := 0
+    for _, res := range resources {
+        entry := v1.ResourceHealth{APIVersion: res.GetAPIVersion(), Kind: res.GetKind(), Name: res.GetName()}
+        live := &unstructured.Unstructured{}
+        live.SetGroupVersionKind(res.GroupVersionKind())
+        if err := r.Get(ctx, client.ObjectKeyFromObject(res), live); err != nil {
+            if !errors.IsNotFound(err) {
+                return nil, 0, err
+            }
+            entry.Status, entry.Message = v1.HealthInProgress, "Resource does not exist yet"
+        } else {
+            entry.Status, entry.Message = resourceHealth(live)
+        }
+        if entry.Status != v1.HealthCurrent {
+            unhealthy++
+        }
+        health = append(health, entry)
+    }
+    return health, unhealthy, nil
+}
+
+// resourceHealth computes the health of a live object the way kstatus does:
+// the controller of the object must have observed its latest generation,
+// workloads must have all replicas updated and available, Jobs must be
+// complete, and other objects must not report a Ready condition that is not
+// true.
+func resourceHealth(obj *unstructured.Unstructured) (v1.HealthStatus, string) {
+    observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+    if found && observed < obj.GetGeneration() {
+        return v1.HealthInProgress, fmt.Sprintf("Generation %d has not been observed yet", obj.GetGeneration())
+    }
+
+    switch obj.GroupVersionKind().GroupKind().String() {
+    case "Deployment.apps":
+        if c := findCondition(obj, "Progressing"); c != nil && c["reason"] == "ProgressDeadlineExceeded" {
+            return v1.HealthFailed, fmt.Sprintf("Deployment exceeded its progress deadline: %v", c["message"])
+        }
+        return replicaHealth(obj, "updatedReplicas", "availableReplicas")
+    case "StatefulSet.apps":
+        if status, message := replicaHealth(obj, "updatedReplicas", "readyReplicas"); status != v1.HealthCurrent {
+            return status, message
+        }
+        current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+        update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+        if update != "" && current != update {
+            return v1.HealthInProgress, fmt.Sprintf("Rolling out revision %s", update)
+        }
+        return v1.HealthCurrent, ""
+    case "DaemonSet.apps":
+        desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+        updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedNumberScheduled")
+        available, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberAvailable")
+        if updated < desired {
+            return v1.HealthInProgress, fmt.Sprintf("Updated %d of %d pods", updated, desired)
+        }
+        if available < desired {
+            return v1.HealthInProgress, fmt.Sprintf("Available %d of %d pods", available, desired)
+        }
+        return v1.HealthCurrent, ""
+    case "Job.batch":
+        if c := findCondition(obj, "Failed"); c != nil && c["status"] == "True" {
+            return v1.HealthFailed, fmt.Sprintf("Job failed: %v", c["message"])
+        }
+        if c := findCondition(obj, "Complete"); c != nil && c["status"] == "True" {
+            return v1.HealthCurrent, ""
+        }
+        return v1.HealthInProgress, "Job has not completed"
+    case "Pod":
+        phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+        switch phase {
+        case "Succeeded":
+            return v1.HealthCurrent, ""
+        case "Failed":
+            return v1.HealthFailed, "Pod failed"
+        }
+        if c := findCondition(obj, "Ready"); c != nil && c["status"] == "True" {
+            return v1.HealthCurrent, ""
+        }
+        return v1.HealthInProgress, "Pod is not ready"
+    case "PersistentVolumeClaim":
+        if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "Bound" {
+            return v1.HealthInProgress, "PersistentVolumeClaim is not bound"
+        }
+        return v1.HealthCurrent, ""
+    case "CustomResourceDefinition.apiextensions.k8s.io":
+        if !crdEstablished(obj) {
+            return v1.HealthInProgress, "CustomResourceDefinition is not established"
+        }
+        return v1.HealthCurrent, ""
+    case "Service":
+        serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+        ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+        if serviceType == "LoadBalancer" && len(ingress) == 0 {
+            return v1.HealthInProgress, "Waiting for a load balancer address"
+        }
+        return v1.HealthCurrent, ""
+    }
+
+    if c := findCondition(obj, "Stalled"); c != nil && c["status"] == "True" {
+        return v1.HealthFailed, fmt.Sprintf("%v", c["message"])
+    }
+    for _, conditionType := range []string{"Ready", "Established"} {
+        if c := findCondition(obj, conditionType); c != nil && c["status"] != "True" {
+            return v1.HealthInProgress, fmt.Sprintf("%s condition is %v: %v", conditionType, c["status"], c["message"])
+        }
+    }
+    return v1.HealthCurrent, ""
+}
+
+// replicaHealth checks that the updated and ready or available replicas of
+// a workload match its desired replicas.
+func replicaHealth(obj *unstructured.Unstructured, updatedField, readyField string) (v1.HealthStatus, string) {
+    replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+    if !found {
+        replicas = 1
+    }
+    updated, _, _ := unstructured.NestedInt64(obj.Object, "status", updatedField)
+    ready, _, _ := unstructured.NestedInt64(obj.Object, "status", readyField)
+    if updated < replicas {
+        return v1.HealthInProgress, fmt.Sprintf("Updated %d of %d replicas", updated, replicas)
+    }
+    if ready < replicas {
+        return v1.HealthInProgress, fmt.Sprintf("%d of %d replicas are ready", ready, replicas)
+    }
+    return v1.HealthCurrent, ""
+}
+
+// findCondition returns the status condition of the given type, or nil.
+func findCondition(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+    conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+    for _, c := range conditions {
+        if condition, ok := c.(map[string]interface{}); ok && condition["type"] == conditionType {
+            return condition
+        }
+    }
+    return nil
+}
+
+// unhealthyReason returns the reason and message of the Ready condition of a
+// namespace whose resources are not healthy yet. Once they have been
+// unhealthy for longer than the ready timeout, the reason is ReadyTimeout
+// and a warning event is recorded.
+func (r *NamespaceClassReconciler) unhealthyReason(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, unhealthy, total int) (string, string, error) {
+    inv := &v1.NamespaceClassInventory{}
+    if err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, inv); client.IgnoreNotFound(err) != nil {
+        return "", "", err
+    }
+
+    // Ready stays False while resources are pending, so its last
+    // transition is when the namespace stopped being ready
+    since := time.Now()
+    ready := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionReady)
+    if ready != nil && ready.Status == metav1.ConditionFalse {
+        since = ready.LastTransitionTime.Time
+    }
+    timeout := readyTimeout(nsc)
+    if time.Since(since) < timeout {
+        return v1.ReasonResourcesNotReady, fmt.Sprintf("%d of %d resources are not ready", unhealthy, total), nil
+    }
+    message := fmt.Sprintf("%d of %d resources are not ready after %s", unhealthy, total, timeout)
+    if ready == nil || ready.Reason != v1.ReasonReadyTimeout {
+        r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonReadyTimeout, "%s", message)
+    }
+    return v1.ReasonReadyTimeout, message, nil
+}
+
+// setInventoryHealth records the health of the resources of a namespace in
+// its inventory status.
+func (r *NamespaceClassReconciler) setInventoryHealth(ctx context.Context, namespace string, health []v1.ResourceHealth) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        if equality.Semantic.DeepEqual(inv.Status.Resources, health) {
+            return nil
+        }
+        inv.Status.Resources = health
+        return r.Update(ctx, inv)
+    })
+}
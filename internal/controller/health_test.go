@@ -0,0 +1,111 @@
+// internal/controller/health_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Waiting for ready resources", func() {
+    It("should compute the health of resources", func() {
+        object := func(manifest string) *unstructured.Unstructured {
+            u := &unstructured.Unstructured{}
+            Expect(u.UnmarshalJSON([]byte(manifest))).To(Succeed())
+            return u
+        }
+        status, message := resourceHealth(object(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"generation":2},
+            "spec":{"replicas":3},"status":{"observedGeneration":2,"updatedReplicas":3,"availableReplicas":1}}`))
+        Expect(status).To(Equal(v1.HealthInProgress))
+        Expect(message).To(Equal("1 of 3 replicas are ready"))
+        status, _ = resourceHealth(object(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"generation":3},
+            "spec":{"replicas":1},"status":{"observedGeneration":2,"updatedReplicas":1,"availableReplicas":1}}`))
+        Expect(status).To(Equal(v1.HealthInProgress))
+        status, _ = resourceHealth(object(`{"apiVersion":"batch/v1","kind":"Job",
+            "status":{"conditions":[{"type":"Complete","status":"True"}]}}`))
+        Expect(status).To(Equal(v1.HealthCurrent))
+        status, _ = resourceHealth(object(`{"apiVersion":"batch/v1","kind":"Job",
+            "status":{"conditions":[{"type":"Failed","status":"True","message":"BackoffLimitExceeded"}]}}`))
+        Expect(status).To(Equal(v1.HealthFailed))
+        status, _ = resourceHealth(object(`{"apiVersion":"example.com/v1","kind":"Database",
+            "status":{"conditions":[{"type":"Ready","status":"False","message":"provisioning"}]}}`))
+        Expect(status).To(Equal(v1.HealthInProgress))
+        status, _ = resourceHealth(object(`{"apiVersion":"v1","kind":"ConfigMap"}`))
+        Expect(status).To(Equal(v1.HealthCurrent))
+    })
+
+    It("should keep namespaces pending until resources are healthy", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{
+                WaitForReady: true,
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"init"},"spec":{"containers":[{"name":"init","image":"busybox"}]}}`)},
+                },
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}, &corev1.Pod{}).Build()
+        recorder := record.NewFakeRecorder(20)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        inv := &v1.NamespaceClassInventory{}
+
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(Equal(healthRequeueInterval))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhasePending))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(inv.Status.Resources).To(Equal([]v1.ResourceHealth{{
+            APIVersion: "v1", Kind: "Pod", Name: "init", Status: v1.HealthInProgress, Message: "Pod is not ready",
+        }}))
+        Expect(meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionReady).Reason).To(Equal(v1.ReasonResourcesNotReady))
+
+        // Resources that stay unhealthy past the timeout fail the sync
+        nsc.Spec.ReadyTimeout = &metav1.Duration{Duration: time.Nanosecond}
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseFailed))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionReady).Reason).To(Equal(v1.ReasonReadyTimeout))
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring(ReasonReadyTimeout)))
+
+        pod := &corev1.Pod{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "init"}, pod)).To(Succeed())
+        pod.Status.Phase = corev1.PodSucceeded
+        Expect(cl.Status().Update(ctx, pod)).To(Succeed())
+        result, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeZero())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseSynced))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(inv.Status.Resources[0].Status).To(Equal(v1.HealthCurrent))
+        Expect(meta.IsStatusConditionTrue(inv.Status.Conditions, v1.ConditionReady)).To(BeTrue())
+    })
+})
@@ -0,0 +1,77 @@
+// internal/controller/hnc.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+    // Annotation set by HNC on namespaces created from a subnamespace anchor
+    HNCSubnamespaceOfAnnotation = "hnc.x-k8s.io/subnamespace-of"
+
+    // Label set by HNC on objects it propagated from an ancestor namespace
+    HNCInheritedFromLabel = "hnc.x-k8s.io/inherited-from"
+
+    // Suffix of the labels HNC sets on every descendant of a namespace
+    HNCTreeDepthLabelSuffix = ".tree.hnc.x-k8s.io/depth"
+
+    // Upper bound on how many ancestors are walked when inheriting a class
+    maxHNCDepth = 32
+)
+
+// resolveClass returns the class a namespace belongs to. A class label on the
+// namespace always wins; otherwise subnamespaces created from an HNC anchor
+// inherit the class of their closest labelled ancestor.
+func (r *NamespaceClassReconciler) resolveClass(ctx context.Context, ns *corev1.Namespace) (string, bool, error) {
+    current := ns
+    for depth := 0; depth < maxHNCDepth; depth++ {
+        if className, ok := current.Labels[LabelKey]; ok {
+            return className, true, nil
+        }
+        parentName := current.Annotations[HNCSubnamespaceOfAnnotation]
+        if parentName == "" {
+            return "", false, nil
+        }
+        parent := &corev1.Namespace{}
+        if err := r.Get(ctx, types.NamespacedName{Name: parentName}, parent); err != nil {
+            if errors.IsNotFound(err) {
+                return "", false, nil
+            }
+            return "", false, fmt.Errorf("failed to get parent namespace %s: %w", parentName, err)
+        }
+        current = parent
+    }
+    return "", false, nil
+}
+
+// isHNCPropagated reports whether HNC copied the object from an ancestor namespace.
+func isHNCPropagated(obj client.Object) bool {
+    _, ok := obj.GetLabels()[HNCInheritedFromLabel]
+    return ok
+}
+
+// hncDescendantRequests returns reconcile requests for every HNC descendant of
+// the namespace, so subnamespaces follow class changes of their ancestors.
+func hncDescendantRequests(ctx context.Context, c client.Client, namespace string) ([]reconcile.Request, error) {
+    var nsList corev1.NamespaceList
+    if err := c.List(ctx, &nsList, client.HasLabels{namespace + HNCTreeDepthLabelSuffix}); err != nil {
+        return nil, err
+    }
+    var requests []reconcile.Request
+    for _, ns := range nsList.Items {
+        if ns.Name == namespace {
+            continue
+        }
+        requests = append(requests, reconcile.Request{
+            NamespacedName: types.NamespacedName{Name: ns.Name},
+        })
+    }
+    return requests, nil
+}
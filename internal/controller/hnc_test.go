@@ -0,0 +1,60 @@
+// internal/controller/hnc_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("HNC subnamespaces", func() {
+    var (
+        reconciler *NamespaceClassReconciler
+        ctx        context.Context
+    )
+
+    BeforeEach(func() {
+        ctx = context.Background()
+        scheme := newScheme()
+        reconciler = &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:   "team",
+                    Labels: map[string]string{LabelKey: "public-network"},
+                }},
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:        "team-dev",
+                    Annotations: map[string]string{HNCSubnamespaceOfAnnotation: "team"},
+                }},
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:        "team-dev-feature",
+                    Labels:      map[string]string{LabelKey: "internal-network"},
+                    Annotations: map[string]string{HNCSubnamespaceOfAnnotation: "team-dev"},
+                }},
+            ).Build(),
+            Scheme: scheme,
+        }
+    })
+
+    It("should inherit the class of the parent namespace", func() {
+        ns := &corev1.Namespace{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "team-dev"}, ns)).To(Succeed())
+        className, hasClass, err := reconciler.resolveClass(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(hasClass).To(BeTrue())
+        Expect(className).To(Equal("public-network"))
+    })
+
+    It("should let a subnamespace override the inherited class", func() {
+        ns := &corev1.Namespace{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "team-dev-feature"}, ns)).To(Succeed())
+        className, _, err := reconciler.resolveClass(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(className).To(Equal("internal-network"))
+    })
+})
@@ -0,0 +1,76 @@
+// internal/controller/impersonation.go
+package controller
+
+import (
+    "fmt"
+    "sync"
+
+    "k8s.io/client-go/rest"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Impersonation builds clients that act as the ServiceAccounts classes name
+// in spec.serviceAccountName, so that what a class can create is limited by
+// the RBAC granted to its ServiceAccount rather than by the controller's own
+// permissions.
+type Impersonation struct {
+    // Config is the controller's own REST config
+    Config *rest.Config
+
+    // Options are used to create the clients, typically with the manager's
+    // scheme and RESTMapper
+    Options client.Options
+
+    // Namespace holds the ServiceAccounts classes can impersonate
+    Namespace string
+
+    // Wrap, if set, wraps each client the way the controller's own client
+    // is wrapped, e.g. for auditing and metrics
+    Wrap func(client.Client) client.Client
+
+    mu      sync.Mutex
+    clients map[string]client.Client
+}
+
+// clientFor returns a client impersonating a ServiceAccount. Clients are
+// created once per ServiceAccount and reused. Reads through them are not
+// cached, and the ServiceAccount needs permission to get the resources too.
+func (i *Impersonation) clientFor(serviceAccountName string) (client.Client, error) {
+    if i == nil {
+        return nil, fmt.Errorf("cannot apply resources as ServiceAccount %s: impersonation is not configured", serviceAccountName)
+    }
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    if c, ok := i.clients[serviceAccountName]; ok {
+        return c, nil
+    }
+
+    config := rest.CopyConfig(i.Config)
+    config.Impersonate = rest.ImpersonationConfig{
+        UserName: fmt.Sprintf("system:serviceaccount:%s:%s", i.Namespace, serviceAccountName),
+    }
+    c, err := client.New(config, i.Options)
+    if err != nil {
+        return nil, err
+    }
+    if i.Wrap != nil {
+        c = i.Wrap(c)
+    }
+    if i.clients == nil {
+        i.clients = make(map[string]client.Client)
+    }
+    i.clients[serviceAccountName] = c
+    return c, nil
+}
+
+// applyClient returns the client the resources of a class are applied with:
+// one impersonating its ServiceAccount, if it names one, or the controller's
+// own client.
+func (r *NamespaceClassReconciler) applyClient(nsc *v1.NamespaceClass) (client.Client, error) {
+    if nsc.Spec.ServiceAccountName == "" {
+        return r.Client, nil
+    }
+    return r.Impersonation.clientFor(nsc.Spec.ServiceAccountName)
+}
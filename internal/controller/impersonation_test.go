@@ -0,0 +1,49 @@
+// internal/controller/impersonation_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Apply impersonation", func() {
+    It("should apply resources with the client of the class ServiceAccount", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        own := fake.NewClientBuilder().WithScheme(scheme).Build()
+        impersonated := fake.NewClientBuilder().WithScheme(scheme).Build()
+        reconciler := &NamespaceClassReconciler{Client: own, Scheme: scheme}
+
+        desired := &unstructured.Unstructured{}
+        desired.SetAPIVersion("v1")
+        desired.SetKind("ConfigMap")
+        desired.SetName("settings")
+        desired.SetNamespace("team")
+        result, err := reconciler.createOrUpdateResource(ctx, desired, applyOptions{client: impersonated})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result).To(Equal(applyResultCreated))
+        Expect(impersonated.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, &corev1.ConfigMap{})).To(Succeed())
+        Expect(errors.IsNotFound(own.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, &corev1.ConfigMap{}))).To(BeTrue())
+
+        _, err = reconciler.Impersonation.clientFor("team-applier")
+        Expect(err).To(MatchError(ContainSubstring("impersonation is not configured")))
+
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec:       v1.NamespaceClassSpec{ServiceAccountName: "team-applier"},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        nsc.Spec.ServiceAccountName = "Team_Applier"
+        Expect(ValidateClass(nsc)).To(HaveLen(1))
+    })
+})
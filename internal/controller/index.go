@@ -0,0 +1,58 @@
+// internal/controller/index.go
+package controller
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Cache index of namespaces by the classes they use, both the class in their
+// label and their add-on classes
+const classIndexField = "namespaceclass.akuity.io/classes"
+
+// indexNamespaceClasses returns the classes a namespace is indexed under in
+// classIndexField.
+func indexNamespaceClasses(obj client.Object) []string {
+    ns, ok := obj.(*corev1.Namespace)
+    if !ok {
+        return nil
+    }
+    primary := ns.Labels[LabelKey]
+    classes := addonClassNames(ns, primary)
+    if primary != "" {
+        classes = append([]string{primary}, classes...)
+    }
+    return classes
+}
+
+// classNamespaceRequests returns reconcile requests for the namespaces using
+// a class, from the cache index rather than by listing every namespace.
+// HNC subnamespaces inheriting the class from a namespace labeled with it
+// are included.
+func classNamespaceRequests(ctx context.Context, c client.Client, className string) ([]reconcile.Request, error) {
+    var nsList corev1.NamespaceList
+    if err := c.List(ctx, &nsList, client.MatchingFields{classIndexField: className}); err != nil {
+        return nil, err
+    }
+    var requests []reconcile.Request
+    for _, ns := range nsList.Items {
+        requests = append(requests, reconcile.Request{
+            NamespacedName: types.NamespacedName{Name: ns.Name},
+        })
+        if ns.Labels[LabelKey] != className {
+            continue
+        }
+        descendants, err := hncDescendantRequests(ctx, c, ns.Name)
+        if err != nil {
+            log.FromContext(ctx).Error(err, "Failed to list HNC descendants", "namespace", ns.Name)
+            continue
+        }
+        requests = append(requests, descendants...)
+    }
+    return requests, nil
+}
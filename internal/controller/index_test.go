@@ -0,0 +1,40 @@
+// internal/controller/index_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Class index", func() {
+    It("should find the namespaces of a class in the cache index", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        cl := fake.NewClientBuilder().WithScheme(scheme).
+            WithIndex(&corev1.Namespace{}, classIndexField, indexNamespaceClasses).
+            WithObjects(
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public-network"}}},
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:        "api",
+                    Labels:      map[string]string{LabelKey: "internal-network"},
+                    Annotations: map[string]string{ClassesAnnotation: "public-network"},
+                }},
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch", Labels: map[string]string{LabelKey: "internal-network"}}},
+            ).Build()
+
+        requests, err := classNamespaceRequests(ctx, cl, "public-network")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(requests).To(ConsistOf(
+            reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}},
+            reconcile.Request{NamespacedName: types.NamespacedName{Name: "api"}},
+        ))
+    })
+})
@@ -0,0 +1,98 @@
+// internal/controller/instrumented_client.go
+package controller
+
+import (
+    "context"
+    stderrors "errors"
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var applyRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+    Name: "namespaceclass_apply_request_duration_seconds",
+    Help: "Duration of API requests made while applying managed resources, by target kind and verb. " +
+        "Mutating verbs include admission webhook time; compare them with get to isolate admission latency.",
+    Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+}, []string{"group", "version", "kind", "verb", "code"})
+
+func init() {
+    metrics.Registry.MustRegister(applyRequestDuration)
+}
+
+// instrumentedClient records the latency of every API request made for a
+// managed resource. Managed resources are handled as unstructured objects,
+// which are never served from the informer cache, so each observation is a
+// real round trip to the apiserver including admission.
+type instrumentedClient struct {
+    client.Client
+}
+
+// NewInstrumentedClient wraps a client so that requests for managed resources
+// are exported in the namespaceclass_apply_request_duration_seconds histogram.
+func NewInstrumentedClient(c client.Client) client.Client {
+    return &instrumentedClient{Client: c}
+}
+
+func (c *instrumentedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+    start := time.Now()
+    err := c.Client.Get(ctx, key, obj, opts...)
+    observeApplyRequest(obj, "get", start, err)
+    return err
+}
+
+func (c *instrumentedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+    start := time.Now()
+    err := c.Client.Create(ctx, obj, opts...)
+    observeApplyRequest(obj, "create", start, err)
+    return err
+}
+
+func (c *instrumentedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+    start := time.Now()
+    err := c.Client.Update(ctx, obj, opts...)
+    observeApplyRequest(obj, "update", start, err)
+    return err
+}
+
+func (c *instrumentedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+    start := time.Now()
+    err := c.Client.Patch(ctx, obj, patch, opts...)
+    observeApplyRequest(obj, "patch", start, err)
+    return err
+}
+
+func (c *instrumentedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+    start := time.Now()
+    err := c.Client.Delete(ctx, obj, opts...)
+    observeApplyRequest(obj, "delete", start, err)
+    return err
+}
+
+// observeApplyRequest records a request for a managed (unstructured) resource.
+func observeApplyRequest(obj client.Object, verb string, start time.Time, err error) {
+    u, ok := obj.(*unstructured.Unstructured)
+    if !ok {
+        return
+    }
+    gvk := u.GroupVersionKind()
+    applyRequestDuration.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, verb, statusCode(err)).
+        Observe(time.Since(start).Seconds())
+}
+
+// statusCode returns the HTTP status code of a request outcome.
+func statusCode(err error) string {
+    if err == nil {
+        return "200"
+    }
+    var status errors.APIStatus
+    if stderrors.As(err, &status) && status.Status().Code != 0 {
+        return strconv.Itoa(int(status.Status().Code))
+    }
+    return "error"
+}
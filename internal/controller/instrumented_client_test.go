@@ -0,0 +1,57 @@
+// internal/controller/instrumented_client_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    dto "github.com/prometheus/client_model/go"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Instrumented client", func() {
+    It("should record the duration of requests for managed resources by kind, verb and code", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        applyRequestDuration.Reset()
+        DeferCleanup(applyRequestDuration.Reset)
+        c := NewInstrumentedClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+        cm := &unstructured.Unstructured{}
+        cm.SetAPIVersion("v1")
+        cm.SetKind("ConfigMap")
+        cm.SetNamespace("team")
+        cm.SetName("settings")
+        Expect(c.Create(ctx, cm)).To(Succeed())
+        Expect(unstructured.SetNestedField(cm.Object, "strict", "data", "mode")).To(Succeed())
+        Expect(c.Update(ctx, cm)).To(Succeed())
+        patch := client.MergeFrom(cm.DeepCopy())
+        Expect(unstructured.SetNestedField(cm.Object, "relaxed", "data", "mode")).To(Succeed())
+        Expect(c.Patch(ctx, cm, patch)).To(Succeed())
+        Expect(c.Delete(ctx, cm)).To(Succeed())
+        Expect(c.Delete(ctx, cm)).NotTo(Succeed())
+
+        // Typed objects are not managed resources and are not recorded
+        Expect(c.Get(ctx, types.NamespacedName{Name: "team"}, &corev1.Namespace{})).NotTo(Succeed())
+
+        Expect(testutil.CollectAndCount(applyRequestDuration)).To(Equal(5))
+        for _, labels := range [][]string{
+            {"", "v1", "ConfigMap", "create", "200"},
+            {"", "v1", "ConfigMap", "update", "200"},
+            {"", "v1", "ConfigMap", "patch", "200"},
+            {"", "v1", "ConfigMap", "delete", "200"},
+            {"", "v1", "ConfigMap", "delete", "404"},
+        } {
+            metric := &dto.Metric{}
+            Expect(applyRequestDuration.WithLabelValues(labels...).(prometheus.Histogram).Write(metric)).To(Succeed())
+            Expect(metric.GetHistogram().GetSampleCount()).To(Equal(uint64(1)), "requests with labels %v", labels)
+        }
+    })
+})
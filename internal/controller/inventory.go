@@ -0,0 +1,271 @@
+// internal/controller/inventory.go
+package controller
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "k8s.io/utils/ptr"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// InventorySchemaVersion is the inventory format written by this controller.
+// Bump it and register a converter in inventoryConverters whenever the format
+// of the tracked data changes, so older inventories keep driving cleanup.
+const InventorySchemaVersion = 2
+
+// inventoryConverters upgrade an inventory from the schema version they are
+// registered for to the next one.
+var inventoryConverters = map[int]func(inv *v1.NamespaceClassInventory){
+    // Version 1 stored SHA-256 hashes without an algorithm prefix
+    1: func(inv *v1.NamespaceClassInventory) {
+        for i := range inv.Spec.Resources {
+            inv.Spec.Resources[i].Hash = qualifiedHash(inv.Spec.Resources[i].Hash)
+        }
+    },
+}
+
+// getManagedResources returns the resources tracked for a namespace. The
+// NamespaceClassInventory is authoritative; namespaces that were provisioned
+// before inventories existed are read from the legacy annotation and migrated
+// on the next inventory update.
+func (r *NamespaceClassReconciler) getManagedResources(ctx context.Context, ns *corev1.Namespace) ([]ManagedResource, error) {
+    inv := &v1.NamespaceClassInventory{}
+    err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, inv)
+    if err != nil && !errors.IsNotFound(err) {
+        return nil, err
+    }
+    if errors.IsNotFound(err) {
+        legacy, err := legacyManagedResources(ns)
+        if err != nil {
+            return nil, err
+        }
+        // The legacy annotation has the same layout as the first inventory format
+        inv = &v1.NamespaceClassInventory{Spec: v1.NamespaceClassInventorySpec{
+            SchemaVersion: 1,
+            Resources:     toInventoryEntries(legacy, false),
+        }}
+    }
+    
+    if err := migrateInventory(inv); err != nil {
+        return nil, err
+    }
+    return fromInventoryEntries(inv.Spec.Resources), nil
+}
+
+// migrateInventory upgrades an inventory to InventorySchemaVersion in memory.
+// Inventories written by a newer controller are rejected rather than misread,
+// since pruning from a misread inventory could delete the wrong resources.
+func migrateInventory(inv *v1.NamespaceClassInventory) error {
+    version := inv.Spec.SchemaVersion
+    if version == 0 {
+        version = 1
+    }
+    if version > InventorySchemaVersion {
+        return fmt.Errorf("inventory %s has schema version %d, newer than supported version %d", 
+            inv.Name, version, InventorySchemaVersion)
+    }
+    for ; version < InventorySchemaVersion; version++ {
+        convert, ok := inventoryConverters[version]
+        if !ok {
+            return fmt.Errorf("no converter for inventory schema version %d", version)
+        }
+        convert(inv)
+    }
+    inv.Spec.SchemaVersion = version
+    return nil
+}
+
+// inventoryClass returns the class recorded on the inventory of a namespace,
+// or an empty string if the namespace has no inventory.
+func (r *NamespaceClassReconciler) inventoryClass(ctx context.Context, namespace string) (string, error) {
+    inv := &v1.NamespaceClassInventory{}
+    if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+        return "", client.IgnoreNotFound(err)
+    }
+    return inv.Labels[LabelKey], nil
+}
+
+// legacyManagedResources parses the inventory stored in the namespace annotation.
+func legacyManagedResources(ns *corev1.Namespace) ([]ManagedResource, error) {
+    if ns.Annotations == nil || ns.Annotations[AnnotationKey] == "" {
+        return nil, nil
+    }
+    var managed []ManagedResource
+    if err := json.Unmarshal([]byte(ns.Annotations[AnnotationKey]), &managed); err != nil {
+        return nil, fmt.Errorf("failed to parse %s annotation: %w", AnnotationKey, err)
+    }
+    return managed, nil
+}
+
+// updateManagedResources writes the inventory of a namespace, deleting it when
+// the namespace has no class, and drops the legacy annotation once migrated.
+// Namespaces using a class always have an inventory, even if the class has no
+// resources, since the inventories determine the namespaces listed in the
+// class status.
+func (r *NamespaceClassReconciler) updateManagedResources(ctx context.Context, ns *corev1.Namespace, className string, managed []ManagedResource) error {
+    err := retry.OnError(retry.DefaultRetry, isConflictOrExists, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, inv)
+        if err != nil && !errors.IsNotFound(err) {
+            return err
+        }
+        exists := err == nil
+        
+        if className == "" {
+            if !exists {
+                return nil
+            }
+            return client.IgnoreNotFound(r.Delete(ctx, inv))
+        }
+        
+        inv.Name = ns.Name
+        inv.Spec.Namespace = ns.Name
+        inv.Spec.SchemaVersion = InventorySchemaVersion
+        inv.Spec.Resources = toInventoryEntries(managed, true)
+        setInventoryClass(inv, className)
+        inv.OwnerReferences = []metav1.OwnerReference{{
+            APIVersion:         "v1",
+            Kind:               "Namespace",
+            Name:               ns.Name,
+            UID:                ns.UID,
+            BlockOwnerDeletion: ptr.To(false),
+        }}
+        
+        if !exists {
+            return r.Create(ctx, inv)
+        }
+        return r.Update(ctx, inv)
+    })
+    if err != nil {
+        return err
+    }
+    
+    return r.removeLegacyInventory(ctx, ns)
+}
+
+// removeLegacyInventory deletes the legacy inventory annotation from the namespace.
+func (r *NamespaceClassReconciler) removeLegacyInventory(ctx context.Context, ns *corev1.Namespace) error {
+    if _, ok := ns.Annotations[AnnotationKey]; !ok {
+        return nil
+    }
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        // Get latest namespace
+        if err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, ns); err != nil {
+            return err
+        }
+        if _, ok := ns.Annotations[AnnotationKey]; !ok {
+            return nil
+        }
+        delete(ns.Annotations, AnnotationKey)
+        return r.Update(ctx, ns)
+    })
+}
+
+// setInventoryClass labels the inventory with the class of the namespace so
+// inventories can be listed per class.
+func setInventoryClass(inv *v1.NamespaceClassInventory, className string) {
+    if inv.Labels == nil {
+        inv.Labels = make(map[string]string)
+    }
+    if className == "" {
+        delete(inv.Labels, LabelKey)
+        return
+    }
+    inv.Labels[LabelKey] = className
+}
+
+// toInventoryEntries converts managed resources to inventory entries,
+// qualifying hashes with their algorithm if requested.
+func toInventoryEntries(managed []ManagedResource, qualify bool) []v1.InventoryEntry {
+    entries := make([]v1.InventoryEntry, 0, len(managed))
+    for _, res := range managed {
+        hash := res.Hash
+        if qualify {
+            hash = qualifiedHash(hash)
+        }
+        entries = append(entries, v1.InventoryEntry{
+            APIVersion:    res.APIVersion,
+            Kind:          res.Kind,
+            Name:          res.Name,
+            Hash:          hash,
+            Class:         res.Class,
+            Frozen:        res.Frozen,
+            ClusterScoped: res.ClusterScoped,
+            ID:            res.ID,
+        })
+    }
+    return entries
+}
+
+// qualifiedHash prefixes bare SHA-256 hashes with the algorithm name.
+func qualifiedHash(hash string) string {
+    if hash == "" || strings.Contains(hash, ":") {
+        return hash
+    }
+    return fmt.Sprintf("%s:%s", v1.HashAlgorithmSHA256, hash)
+}
+
+func fromInventoryEntries(entries []v1.InventoryEntry) []ManagedResource {
+    managed := make([]ManagedResource, 0, len(entries))
+    for _, entry := range entries {
+        managed = append(managed, ManagedResource{
+            APIVersion:    entry.APIVersion,
+            Kind:          entry.Kind,
+            Name:          entry.Name,
+            Hash:          entry.Hash,
+            Class:         entry.Class,
+            Frozen:        entry.Frozen,
+            ClusterScoped: entry.ClusterScoped,
+            ID:            entry.ID,
+        })
+    }
+    return managed
+}
+
+// isConflictOrExists reports errors caused by racing with another writer or
+// a stale cache, which are resolved by retrying with fresh state.
+func isConflictOrExists(err error) bool {
+    return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+}
+
+// setInventoryCondition records a condition on the inventory of a namespace.
+// Namespaces without an inventory have nothing to report.
+func (r *NamespaceClassReconciler) setInventoryCondition(ctx context.Context, namespace string, condition metav1.Condition) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        condition.ObservedGeneration = inv.Generation
+        if !meta.SetStatusCondition(&inv.Status.Conditions, condition) {
+            return nil
+        }
+        return r.Update(ctx, inv)
+    })
+}
+
+// removeInventoryCondition removes a condition from the inventory of a
+// namespace once it no longer applies.
+func (r *NamespaceClassReconciler) removeInventoryCondition(ctx context.Context, namespace, conditionType string) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        if !meta.RemoveStatusCondition(&inv.Status.Conditions, conditionType) {
+            return nil
+        }
+        return r.Update(ctx, inv)
+    })
+}
@@ -0,0 +1,100 @@
+// internal/controller/inventory_test.go
+package controller
+
+import (
+    "context"
+    "encoding/json"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Inventory", func() {
+    var (
+        reconciler *NamespaceClassReconciler
+        ctx        context.Context
+        ns         *corev1.Namespace
+    )
+
+    BeforeEach(func() {
+        ctx = context.Background()
+        scheme := newScheme()
+        legacy, err := json.Marshal([]ManagedResource{{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"}})
+        Expect(err).NotTo(HaveOccurred())
+        ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:        "team",
+            Annotations: map[string]string{AnnotationKey: string(legacy)},
+        }}
+        reconciler = &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build(),
+            Scheme: scheme,
+        }
+    })
+
+    It("should migrate the legacy annotation into an inventory object", func() {
+        managed, err := reconciler.getManagedResources(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(managed).To(HaveLen(1))
+
+        Expect(reconciler.updateManagedResources(ctx, ns, "web", managed)).To(Succeed())
+
+        inv := &v1.NamespaceClassInventory{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(inv.Spec.Resources).To(HaveLen(1))
+        Expect(inv.Labels).To(HaveKeyWithValue(LabelKey, "web"))
+
+        updated := &corev1.Namespace{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "team"}, updated)).To(Succeed())
+        Expect(updated.Annotations).NotTo(HaveKey(AnnotationKey))
+    })
+
+    It("should delete the inventory when nothing is managed", func() {
+        Expect(reconciler.updateManagedResources(ctx, ns, "web", []ManagedResource{{APIVersion: "v1", Kind: "ConfigMap", Name: "a"}})).To(Succeed())
+        Expect(reconciler.updateManagedResources(ctx, ns, "", nil)).To(Succeed())
+        err := reconciler.Get(ctx, types.NamespacedName{Name: "team"}, &v1.NamespaceClassInventory{})
+        Expect(errors.IsNotFound(err)).To(BeTrue())
+    })
+})
+
+var _ = Describe("Inventory schema versions", func() {
+    It("should upgrade version 1 inventories and reject newer ones", func() {
+        inv := &v1.NamespaceClassInventory{Spec: v1.NamespaceClassInventorySpec{
+            Resources: []v1.InventoryEntry{{APIVersion: "v1", Kind: "ConfigMap", Name: "a", Hash: "abc"}},
+        }}
+        Expect(migrateInventory(inv)).To(Succeed())
+        Expect(inv.Spec.SchemaVersion).To(Equal(InventorySchemaVersion))
+        Expect(inv.Spec.Resources[0].Hash).To(Equal("sha256:abc"))
+
+        inv.Spec.SchemaVersion = InventorySchemaVersion + 1
+        Expect(migrateInventory(inv)).NotTo(Succeed())
+    })
+
+    It("should not update resources when only the hash algorithm changed", func() {
+        desired := &unstructured.Unstructured{}
+        desired.SetAPIVersion("v1")
+        desired.SetKind("ConfigMap")
+        desired.SetName("settings")
+        desired.SetAnnotations(map[string]string{ManagedByAnnotation: ManagedByValue})
+        Expect(unstructured.SetNestedField(desired.Object, "value", "data", "key")).To(Succeed())
+
+        existing := desired.DeepCopy()
+        Expect(unstructured.SetNestedField(existing.Object, calculateResourceHash(desired, nil, v1.HashAlgorithmSHA256),
+            "metadata", "annotations", ResourceHashAnnotation)).To(Succeed())
+        Expect(unstructured.SetNestedField(desired.Object, calculateResourceHash(desired, nil, v1.HashAlgorithmFNV64a),
+            "metadata", "annotations", ResourceHashAnnotation)).To(Succeed())
+        Expect(needsUpdate(existing, desired, applyOptions{})).To(BeFalse())
+
+        Expect(unstructured.SetNestedField(desired.Object, "changed", "data", "key")).To(Succeed())
+        Expect(unstructured.SetNestedField(desired.Object, calculateResourceHash(desired, nil, v1.HashAlgorithmFNV64a),
+            "metadata", "annotations", ResourceHashAnnotation)).To(Succeed())
+        Expect(needsUpdate(existing, desired, applyOptions{})).To(BeTrue())
+    })
+})
@@ -0,0 +1,144 @@
+// internal/controller/kindpolicy.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/util/validation/field"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// KindPolicy restricts the kinds classes may create. Kinds are given as Kind
+// or Kind.group, e.g. "ClusterRoleBinding.rbac.authorization.k8s.io", or as
+// *.group for every kind of an API group, e.g.
+// "*.admissionregistration.k8s.io".
+type KindPolicy struct {
+    // Allowed are the only kinds classes may create; empty allows any kind
+    Allowed []string
+
+    // Denied are kinds classes may not create, even if allowed
+    Denied []string
+}
+
+// forbids describes why the policy does not let classes create a kind, or
+// returns an empty string if it does. A nil policy allows every kind.
+func (p *KindPolicy) forbids(gk schema.GroupKind) string {
+    if p == nil {
+        return ""
+    }
+    for _, pattern := range p.Denied {
+        if kindMatches(pattern, gk) {
+            return fmt.Sprintf("kind %s is denied", gk)
+        }
+    }
+    if len(p.Allowed) == 0 {
+        return ""
+    }
+    for _, pattern := range p.Allowed {
+        if kindMatches(pattern, gk) {
+            return ""
+        }
+    }
+    return fmt.Sprintf("kind %s is not allowed", gk)
+}
+
+// kindMatches reports whether a Kind, Kind.group or *.group pattern matches
+// a kind.
+func kindMatches(pattern string, gk schema.GroupKind) bool {
+    if group, ok := strings.CutPrefix(pattern, "*."); ok {
+        return strings.EqualFold(group, gk.Group)
+    }
+    return strings.EqualFold(pattern, gk.Kind) || strings.EqualFold(pattern, gk.String())
+}
+
+// ValidateClass checks that the policy lets a class create all of its
+// resources and hooks, including those it generates. Resources that cannot
+// be decoded are left to ValidateClass.
+func (p *KindPolicy) ValidateClass(nsc *v1.NamespaceClass) field.ErrorList {
+    if p == nil {
+        return nil
+    }
+    var errs field.ErrorList
+    spec := field.NewPath("spec")
+    embedded := make(map[string]bool)
+    check := func(path *field.Path, raw []runtime.RawExtension) {
+        for i, r := range raw {
+            u := &unstructured.Unstructured{}
+            if err := u.UnmarshalJSON(r.Raw); err != nil {
+                continue
+            }
+            embedded[resourceKey(u)] = true
+            if reason := p.forbids(u.GroupVersionKind().GroupKind()); reason != "" {
+                errs = append(errs, field.Forbidden(path.Index(i), reason))
+            }
+        }
+    }
+    check(spec.Child("resources"), nsc.Spec.Resources)
+    check(spec.Child("clusterResources"), nsc.Spec.ClusterResources)
+    if hooks := nsc.Spec.Hooks; hooks != nil {
+        check(spec.Child("hooks", "preSwitch"), hooks.PreSwitch)
+        check(spec.Child("hooks", "postSwitch"), hooks.PostSwitch)
+    }
+
+    // Resources generated from other fields of the spec
+    resources, err := classSpecResources(nsc)
+    if err != nil {
+        return errs
+    }
+    for _, res := range resources {
+        if embedded[resourceKey(res)] {
+            continue
+        }
+        if reason := p.forbids(res.GroupVersionKind().GroupKind()); reason != "" {
+            errs = append(errs, field.Forbidden(spec, fmt.Sprintf("generates %s %s: %s", res.GetKind(), res.GetName(), reason)))
+        }
+    }
+    return errs
+}
+
+// forbiddenKindsError lists the resources of the classes of a namespace the
+// kind policy does not let them create.
+type forbiddenKindsError struct {
+    forbidden []string
+}
+
+func (e *forbiddenKindsError) Error() string {
+    return fmt.Sprintf("classes create forbidden kinds: %s", strings.Join(e.forbidden, "; "))
+}
+
+// checkKinds returns a forbiddenKindsError if the kind policy does not let
+// the classes of a namespace create some of their resources.
+func (r *NamespaceClassReconciler) checkKinds(resources []*unstructured.Unstructured) error {
+    var forbidden []string
+    for _, res := range resources {
+        if reason := r.Kinds.forbids(res.GroupVersionKind().GroupKind()); reason != "" {
+            forbidden = append(forbidden, fmt.Sprintf("%s %s: %s", res.GetKind(), res.GetName(), reason))
+        }
+    }
+    if len(forbidden) > 0 {
+        return &forbiddenKindsError{forbidden: forbidden}
+    }
+    return nil
+}
+
+// reportForbiddenKinds records that the classes of a namespace create kinds
+// the kind policy forbids, with a warning event and a Degraded condition on
+// the inventory of the namespace.
+func (r *NamespaceClassReconciler) reportForbiddenKinds(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, kindsErr *forbiddenKindsError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonKindForbidden,
+        "Not applying any resources: %s", strings.Join(kindsErr.forbidden, "; "))
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonKindForbidden,
+        Message: kindsErr.Error(),
+    })
+}
@@ -0,0 +1,55 @@
+// internal/controller/kindpolicy_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Kind policy", func() {
+    It("should apply nothing while classes create forbidden kinds", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        policy := &KindPolicy{Allowed: []string{"ConfigMap", "*.rbac.authorization.k8s.io"}, Denied: []string{"ClusterRoleBinding"}}
+        Expect(policy.forbids(schema.GroupKind{Kind: "ConfigMap"})).To(BeEmpty())
+        Expect(policy.forbids(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"})).To(BeEmpty())
+        Expect(policy.forbids(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"})).To(Equal("kind ClusterRoleBinding.rbac.authorization.k8s.io is denied"))
+        Expect(policy.forbids(schema.GroupKind{Kind: "Secret"})).To(Equal("kind Secret is not allowed"))
+
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}}`)},
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "token"}}`)},
+            }},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team-a",
+                Labels:     map[string]string{LabelKey: "team"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Kinds: policy}
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+        Expect(err).To(MatchError("classes create forbidden kinds: Secret token: kind Secret is not allowed"))
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "settings"}, &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, nsc)).To(Succeed())
+        Expect(meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDegraded).Reason).To(Equal(v1.ReasonKindForbidden))
+    })
+})
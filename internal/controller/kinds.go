@@ -0,0 +1,39 @@
+// internal/controller/kinds.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// kindNotInstalledError reports a resource of a class whose kind the API
+// server does not serve, typically because the CRD or the operator that
+// installs it is missing from the cluster. Returning it requeues the
+// namespace with the usual backoff until the kind is installed.
+type kindNotInstalledError struct {
+    gvk schema.GroupVersionKind
+}
+
+func (e *kindNotInstalledError) Error() string {
+    return fmt.Sprintf("kind %s (%s) is not installed", e.gvk.Kind, e.gvk.GroupVersion())
+}
+
+// reportKindNotInstalled records that a resource of a class cannot be applied
+// because its kind is not installed, with a warning event and a Degraded
+// condition on the inventory of the namespace.
+func (r *NamespaceClassReconciler) reportKindNotInstalled(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, name string, kindErr *kindNotInstalledError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonKindNotInstalled,
+        "Cannot apply %s %s: %v. Install it in the cluster or remove it from the class", kindErr.gvk.Kind, name, kindErr)
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonKindNotInstalled,
+        Message: kindErr.Error(),
+    })
+}
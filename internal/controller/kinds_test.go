@@ -0,0 +1,66 @@
+// internal/controller/kinds_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Unknown kinds", func() {
+    It("should report kinds that are not installed on the class and retry", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{{Raw: []byte(`{
+                "apiVersion": "example.com/v1",
+                "kind": "Widget",
+                "metadata": {"name": "default"}
+            }`)}}},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team-a",
+                Labels:     map[string]string{LabelKey: "team"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            // The fake client serves any kind; fail like an API server without the CRD
+            Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+                if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind == "Widget" {
+                    return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+                }
+                return c.Get(ctx, key, obj, opts...)
+            },
+        }).Build()
+        recorder := record.NewFakeRecorder(10)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+        Expect(err).To(MatchError("kind Widget (example.com/v1) is not installed"))
+        Expect(recorder.Events).To(Receive(ContainSubstring("KindNotInstalled")))
+
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces).To(HaveLen(1))
+        Expect(nsc.Status.Namespaces[0].Reason).To(Equal(v1.ReasonKindNotInstalled))
+        degraded := meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDegraded)
+        Expect(degraded).NotTo(BeNil())
+        Expect(degraded.Reason).To(Equal(v1.ReasonKindNotInstalled))
+        Expect(degraded.Message).To(HavePrefix("kind Widget (example.com/v1) is not installed; 1 namespace(s) failed to sync"))
+    })
+})
@@ -0,0 +1,64 @@
+// internal/controller/limits.go
+package controller
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// DefaultLimitsName is the name of the LimitRange generated from class
+// limits that do not set one.
+const DefaultLimitsName = "namespace-limits"
+
+// limitsName returns the name of the LimitRange generated from limits.
+func limitsName(limits *v1.ClassLimits) string {
+    if limits.Name != "" {
+        return limits.Name
+    }
+    return DefaultLimitsName
+}
+
+// resourceValueList converts resource values to a LimitRange resource list.
+func resourceValueList(values *v1.ResourceValues) map[string]interface{} {
+    if values == nil {
+        return nil
+    }
+    list := make(map[string]interface{})
+    if values.CPU != nil {
+        list[string(corev1.ResourceCPU)] = values.CPU.String()
+    }
+    if values.Memory != nil {
+        list[string(corev1.ResourceMemory)] = values.Memory.String()
+    }
+    if len(list) == 0 {
+        return nil
+    }
+    return list
+}
+
+// limitsResource expands the limits of a class into a LimitRange for
+// containers, or returns nil if the class has none.
+func limitsResource(limits *v1.ClassLimits) *unstructured.Unstructured {
+    if limits == nil {
+        return nil
+    }
+    item := map[string]interface{}{"type": string(corev1.LimitTypeContainer)}
+    for field, values := range map[string]*v1.ResourceValues{
+        "default":        limits.Default,
+        "defaultRequest": limits.DefaultRequest,
+        "max":            limits.Max,
+        "min":            limits.Min,
+    } {
+        if list := resourceValueList(values); list != nil {
+            item[field] = list
+        }
+    }
+    return &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "LimitRange",
+        "metadata":   map[string]interface{}{"name": limitsName(limits)},
+        "spec":       map[string]interface{}{"limits": []interface{}{item}},
+    }}
+}
@@ -0,0 +1,42 @@
+// internal/controller/limits_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class limits", func() {
+    It("should expand spec.limits into a LimitRange for containers", func() {
+        request, limit, max := resource.MustParse("100m"), resource.MustParse("500m"), resource.MustParse("512Mi")
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+            Spec: v1.NamespaceClassSpec{Limits: &v1.ClassLimits{
+                DefaultRequest: &v1.ResourceValues{CPU: &request},
+                Default:        &v1.ResourceValues{CPU: &limit},
+                Max:            &v1.ResourceValues{Memory: &max},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        resources, err := RenderClass(nsc, "team")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(1))
+        Expect(resources[0].GetKind()).To(Equal("LimitRange"))
+        Expect(resources[0].GetName()).To(Equal(DefaultLimitsName))
+        limits, _, _ := unstructured.NestedSlice(resources[0].Object, "spec", "limits")
+        Expect(limits).To(Equal([]interface{}{map[string]interface{}{
+            "type":           "Container",
+            "defaultRequest": map[string]interface{}{"cpu": "100m"},
+            "default":        map[string]interface{}{"cpu": "500m"},
+            "max":            map[string]interface{}{"memory": "512Mi"},
+        }}))
+
+        nsc.Spec.Limits = &v1.ClassLimits{}
+        Expect(ValidateClass(nsc)).To(HaveLen(1))
+    })
+})
@@ -0,0 +1,166 @@
+// internal/controller/metadata.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Prefix of the labels and annotations the controller uses itself, which
+// classes cannot set on namespaces
+const reservedMetadataPrefix = "namespaceclass.akuity.io/"
+
+// metadataSet holds labels or annotations set on a namespace and the class
+// each one is set for.
+type metadataSet struct {
+    values  map[string]string
+    classes map[string]string
+}
+
+// add merges the labels or annotations of a class into the set, recording a
+// conflict for keys an earlier class sets to another value.
+func (m *metadataSet) add(className, kind string, values map[string]string, conflicts *[]string) {
+    keys := make([]string, 0, len(values))
+    for key := range values {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    for _, key := range keys {
+        if strings.HasPrefix(key, reservedMetadataPrefix) {
+            continue
+        }
+        if existing, ok := m.values[key]; ok {
+            if existing != values[key] {
+                *conflicts = append(*conflicts, fmt.Sprintf("namespace %s %s in classes %s and %s", kind, key, m.classes[key], className))
+            }
+            continue
+        }
+        if m.values == nil {
+            m.values = make(map[string]string)
+            m.classes = make(map[string]string)
+        }
+        m.values[key] = values[key]
+        m.classes[key] = className
+    }
+}
+
+// entries returns the inventory entries of the set, sorted by key.
+func (m metadataSet) entries() []v1.MetadataEntry {
+    entries := make([]v1.MetadataEntry, 0, len(m.values))
+    for key := range m.values {
+        entries = append(entries, v1.MetadataEntry{Key: key, Class: m.classes[key]})
+    }
+    sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+    return entries
+}
+
+// namespaceMetadata are the labels and annotations the classes of a namespace
+// set on it.
+type namespaceMetadata struct {
+    labels      metadataSet
+    annotations metadataSet
+}
+
+// classNamespaceMetadata merges the namespace metadata and Pod Security
+// labels of the classes of a namespace, the primary class first. Classes may not set the same key to
+// different values; a collision is returned as a classConflictError.
+func classNamespaceMetadata(classes []*v1.NamespaceClass) (*namespaceMetadata, error) {
+    metadata := &namespaceMetadata{}
+    var conflicts []string
+    for _, nsc := range classes {
+        if md := nsc.Spec.NamespaceMetadata; md != nil {
+            metadata.labels.add(nsc.Name, "label", md.Labels, &conflicts)
+            metadata.annotations.add(nsc.Name, "annotation", md.Annotations, &conflicts)
+        }
+        metadata.labels.add(nsc.Name, "label", podSecurityLabels(nsc.Spec.PodSecurity), &conflicts)
+    }
+    if len(conflicts) > 0 {
+        return nil, &classConflictError{conflicts: conflicts}
+    }
+    return metadata, nil
+}
+
+// syncNamespaceMetadata sets the labels and annotations the classes of a
+// namespace declare on it and removes those set for the classes before that
+// are no longer declared, as recorded in the inventory. A nil metadata
+// removes all of them. Labels and annotations set by others are left alone.
+func (r *NamespaceClassReconciler) syncNamespaceMetadata(ctx context.Context, ns *corev1.Namespace, metadata *namespaceMetadata) error {
+    inv := &v1.NamespaceClassInventory{}
+    if err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, inv); client.IgnoreNotFound(err) != nil {
+        return err
+    }
+    if metadata == nil {
+        metadata = &namespaceMetadata{}
+    }
+
+    patch := client.MergeFrom(ns.DeepCopy())
+    labels, labelsChanged := applyMetadata(ns.GetLabels(), metadata.labels, inv.Spec.NamespaceLabels)
+    annotations, annotationsChanged := applyMetadata(ns.GetAnnotations(), metadata.annotations, inv.Spec.NamespaceAnnotations)
+    if !labelsChanged && !annotationsChanged {
+        return nil
+    }
+    ns.SetLabels(labels)
+    ns.SetAnnotations(annotations)
+    if err := r.Patch(ctx, ns, patch); err != nil {
+        return err
+    }
+    log.FromContext(ctx).Info("Updated namespace metadata")
+    r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonMetadataUpdated,
+        "Updated the labels and annotations set on the namespace by its classes")
+    return nil
+}
+
+// applyMetadata removes the tracked keys that are no longer desired from the
+// live labels or annotations of a namespace and sets the desired ones. It
+// reports whether anything changed.
+func applyMetadata(live map[string]string, desired metadataSet, tracked []v1.MetadataEntry) (map[string]string, bool) {
+    if live == nil {
+        live = make(map[string]string)
+    }
+    changed := false
+    for _, entry := range tracked {
+        if _, ok := desired.values[entry.Key]; ok || strings.HasPrefix(entry.Key, reservedMetadataPrefix) {
+            continue
+        }
+        if _, ok := live[entry.Key]; ok {
+            delete(live, entry.Key)
+            changed = true
+        }
+    }
+    for key, value := range desired.values {
+        if current, ok := live[key]; !ok || current != value {
+            live[key] = value
+            changed = true
+        }
+    }
+    return live, changed
+}
+
+// setInventoryMetadata records the labels and annotations set on a namespace
+// in its inventory.
+func (r *NamespaceClassReconciler) setInventoryMetadata(ctx context.Context, namespace string, metadata *namespaceMetadata) error {
+    labels, annotations := metadata.labels.entries(), metadata.annotations.entries()
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        if equality.Semantic.DeepEqual(inv.Spec.NamespaceLabels, labels) && equality.Semantic.DeepEqual(inv.Spec.NamespaceAnnotations, annotations) {
+            return nil
+        }
+        inv.Spec.NamespaceLabels = labels
+        inv.Spec.NamespaceAnnotations = annotations
+        return r.Update(ctx, inv)
+    })
+}
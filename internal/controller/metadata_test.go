@@ -0,0 +1,87 @@
+// internal/controller/metadata_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Namespace metadata", func() {
+    It("should set and prune the labels and annotations a class declares", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+            Spec: v1.NamespaceClassSpec{NamespaceMetadata: &v1.NamespaceMetadata{
+                Labels: map[string]string{
+                    "pod-security.kubernetes.io/enforce": "restricted",
+                    "pod-security.kubernetes.io/warn":    "restricted",
+                },
+                Annotations: map[string]string{"example.com/cost-center": "platform"},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "restricted", "team": "payments"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+        Expect(ns.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/warn", "restricted"))
+        Expect(ns.Annotations).To(HaveKeyWithValue("example.com/cost-center", "platform"))
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        Expect(inv.Spec.NamespaceLabels).To(Equal([]v1.MetadataEntry{
+            {Key: "pod-security.kubernetes.io/enforce", Class: "restricted"},
+            {Key: "pod-security.kubernetes.io/warn", Class: "restricted"},
+        }))
+
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "restricted"}, nsc)).To(Succeed())
+        delete(nsc.Spec.NamespaceMetadata.Labels, "pod-security.kubernetes.io/warn")
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        Expect(ns.Labels).NotTo(HaveKey("pod-security.kubernetes.io/warn"))
+        Expect(ns.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+
+        delete(ns.Labels, LabelKey)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{"team": "payments"}))
+        Expect(ns.Annotations).NotTo(HaveKey("example.com/cost-center"))
+    })
+
+    It("should reject labels reserved for the controller", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+            Spec: v1.NamespaceClassSpec{NamespaceMetadata: &v1.NamespaceMetadata{
+                Labels: map[string]string{LabelKey: "other"},
+            }},
+        }
+        errs := ValidateClass(nsc)
+        Expect(errs).To(HaveLen(1))
+        Expect(errs[0].Field).To(Equal("spec.namespaceMetadata.labels[namespaceclass.akuity.io/name]"))
+    })
+})
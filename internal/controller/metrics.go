@@ -0,0 +1,195 @@
+// internal/controller/metrics.go
+package controller
+
+import (
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+    // Team label value for namespaces without the configured label
+    teamNone = "none"
+
+    // Team label value for namespaces whose label value is not allowlisted
+    teamOther = "other"
+
+    // Operations counted by namespaceclass_resource_operations_total
+    operationCreated = "created"
+    operationUpdated = "updated"
+    operationDeleted = "deleted"
+)
+
+var (
+    namespaceReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "namespaceclass_namespace_reconcile_total",
+        Help: "Number of namespace reconciliations by class, team and result.",
+    }, []string{"class", "team", "result"})
+
+    namespaceManagedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "namespaceclass_namespace_managed_resources",
+        Help: "Number of resources managed in a namespace by its class.",
+    }, []string{"namespace", "class", "team"})
+
+    resourceOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "namespaceclass_resource_operations_total",
+        Help: "Number of managed resources created, updated and deleted by class and kind.",
+    }, []string{"class", "group", "version", "kind", "operation"})
+
+    reconcileErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "namespaceclass_reconcile_errors_total",
+        Help: "Number of failed namespace reconciliations by API error reason.",
+    }, []string{"reason"})
+
+    classNamespaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "namespaceclass_class_namespaces",
+        Help: "Number of namespaces using a class.",
+    }, []string{"class"})
+
+    driftedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "namespaceclass_drifted_resources",
+        Help: "Number of managed resources found changed outside the controller at the last reconcile of a namespace.",
+    }, []string{"namespace", "class"})
+
+    orphanedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "namespaceclass_orphaned_resources",
+        Help: "Number of objects marked as managed by the controller that no inventory tracks.",
+    }, []string{"namespace", "class"})
+
+    reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "namespaceclass_reconcile_duration_seconds",
+        Help:    "Duration of namespace reconciliations by class.",
+        Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+    }, []string{"class"})
+
+    missingClassNamespaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "namespaceclass_missing_class_namespaces",
+        Help: "Namespaces whose class label or add-on classes refer to a class that does not exist.",
+    }, []string{"namespace", "class"})
+
+    warmUpPendingNamespaces = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "namespaceclass_warmup_pending_namespaces",
+        Help: "Number of namespaces from the startup listing not yet reconciled.",
+    })
+)
+
+func init() {
+    metrics.Registry.MustRegister(
+        namespaceReconcileTotal,
+        namespaceManagedResources,
+        resourceOperationsTotal,
+        reconcileErrorsTotal,
+        classNamespaces,
+        reconcileDuration,
+        driftedResources,
+        orphanedResources,
+        missingClassNamespaces,
+        warmUpPendingNamespaces,
+    )
+}
+
+// TeamLabelMapping exports the value of a namespace label as the "team"
+// label of per-namespace metrics. Only allowlisted values are exported
+// verbatim, so a typo'd or user-controlled label cannot explode cardinality.
+type TeamLabelMapping struct {
+    // Label is the namespace label to read, e.g. "team". Empty disables the mapping.
+    Label string
+
+    // Allowed lists the label values exported as-is; others are reported as "other".
+    Allowed []string
+}
+
+// teamFor returns the bounded team label value for a namespace.
+func (m TeamLabelMapping) teamFor(ns *corev1.Namespace) string {
+    if m.Label == "" {
+        return teamNone
+    }
+    value, ok := ns.Labels[m.Label]
+    if !ok || value == "" {
+        return teamNone
+    }
+    if containsString(m.Allowed, value) {
+        return value
+    }
+    return teamOther
+}
+
+// recordReconcileMetrics counts a finished namespace reconciliation and
+// observes how long it took.
+func (r *NamespaceClassReconciler) recordReconcileMetrics(ns *corev1.Namespace, className string, duration time.Duration, err error) {
+    result := "success"
+    if err != nil {
+        result = "error"
+        reconcileErrorsTotal.WithLabelValues(errorReason(err)).Inc()
+    }
+    namespaceReconcileTotal.WithLabelValues(classLabel(className), r.TeamLabel.teamFor(ns), result).Inc()
+    reconcileDuration.WithLabelValues(classLabel(className)).Observe(duration.Seconds())
+}
+
+// errorReason returns the API status reason of an error, e.g. "Conflict" or
+// "Forbidden", or "Unknown" for errors not returned by the apiserver.
+func errorReason(err error) string {
+    if reason := errors.ReasonForError(err); reason != "" {
+        return string(reason)
+    }
+    return "Unknown"
+}
+
+// recordResourceOperation counts a change made to a managed resource.
+func recordResourceOperation(className string, gvk schema.GroupVersionKind, operation string) {
+    resourceOperationsTotal.WithLabelValues(classLabel(className), gvk.Group, gvk.Version, gvk.Kind, operation).Inc()
+}
+
+// recordClassNamespaces records how many namespaces use a class.
+func recordClassNamespaces(className string, count int) {
+    classNamespaces.WithLabelValues(className).Set(float64(count))
+}
+
+// forgetClassMetrics drops the series of a deleted class.
+func forgetClassMetrics(className string) {
+    classNamespaces.DeleteLabelValues(className)
+}
+
+// recordManagedResources records how many resources a namespace's class manages.
+func (r *NamespaceClassReconciler) recordManagedResources(ns *corev1.Namespace, className string, count int) {
+    forgetNamespaceMetrics(ns.Name)
+    namespaceManagedResources.WithLabelValues(ns.Name, className, r.TeamLabel.teamFor(ns)).Set(float64(count))
+}
+
+// forgetNamespaceMetrics drops the per-namespace series of a namespace that
+// left its class or is being deleted.
+func forgetNamespaceMetrics(namespace string) {
+    namespaceManagedResources.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+    driftedResources.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+    forgetMissingClass(namespace)
+}
+
+// recordMissingClass records that a namespace refers to a class that does
+// not exist, replacing any missing class recorded for it before.
+func recordMissingClass(namespace, className string) {
+    forgetMissingClass(namespace)
+    missingClassNamespaces.WithLabelValues(namespace, className).Set(1)
+}
+
+// forgetMissingClass drops the missing class series of a namespace whose
+// classes resolve again.
+func forgetMissingClass(namespace string) {
+    missingClassNamespaces.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+}
+
+// recordDriftedResources records how many resources of a namespace were
+// found drifted from its class.
+func recordDriftedResources(namespace, className string, count int) {
+    driftedResources.WithLabelValues(namespace, className).Set(float64(count))
+}
+
+func classLabel(className string) string {
+    if className == "" {
+        return teamNone
+    }
+    return className
+}
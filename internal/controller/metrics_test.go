@@ -0,0 +1,34 @@
+// internal/controller/metrics_test.go
+package controller
+
+import (
+    "fmt"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Team metric label", func() {
+    It("should only export allowlisted team values", func() {
+        mapping := TeamLabelMapping{Label: "team", Allowed: []string{"payments"}}
+        withTeam := func(team string) *corev1.Namespace {
+            return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": team}}}
+        }
+        Expect(mapping.teamFor(withTeam("payments"))).To(Equal("payments"))
+        Expect(mapping.teamFor(withTeam("paymnets"))).To(Equal("other"))
+        Expect(mapping.teamFor(&corev1.Namespace{})).To(Equal("none"))
+        Expect(TeamLabelMapping{}.teamFor(withTeam("payments"))).To(Equal("none"))
+    })
+
+    It("should label reconcile errors by API reason", func() {
+        conflict := errors.NewConflict(v1.GroupVersion.WithResource("namespaceclasses").GroupResource(), "web", fmt.Errorf("stale"))
+        Expect(errorReason(conflict)).To(Equal("Conflict"))
+        Expect(errorReason(fmt.Errorf("wrapped: %w", conflict))).To(Equal("Conflict"))
+        Expect(errorReason(fmt.Errorf("boom"))).To(Equal("Unknown"))
+    })
+})
@@ -0,0 +1,117 @@
+// internal/controller/missingclasses.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Classes whose name is at most this many edits away from a missing class
+// are suggested as what the label meant
+const maxSuggestionDistance = 2
+
+// MissingClass is a namespace whose label or add-on annotation refers to a
+// class that does not exist.
+type MissingClass struct {
+    Namespace string
+    Class     string
+
+    // Suggestion is an existing class with a similar name, if any
+    Suggestion string
+}
+
+// ListMissingClasses returns the namespaces referring to classes that do not
+// exist, sorted by namespace, so typo'd labels can be found without waiting
+// for the controller to retry them.
+func ListMissingClasses(ctx context.Context, c client.Reader) ([]MissingClass, error) {
+    var classes v1.NamespaceClassList
+    if err := c.List(ctx, &classes); err != nil {
+        return nil, err
+    }
+    names := make([]string, 0, len(classes.Items))
+    exists := make(map[string]bool, len(classes.Items))
+    for _, nsc := range classes.Items {
+        names = append(names, nsc.Name)
+        exists[nsc.Name] = true
+    }
+
+    var missing []MissingClass
+    var nsList corev1.NamespaceList
+    err := ListPages(ctx, c, &nsList, func() error {
+        for i := range nsList.Items {
+            for _, className := range NamespaceClasses(&nsList.Items[i]) {
+                if !exists[className] {
+                    missing = append(missing, MissingClass{
+                        Namespace:  nsList.Items[i].Name,
+                        Class:      className,
+                        Suggestion: suggestClass(className, names),
+                    })
+                }
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    sort.SliceStable(missing, func(i, j int) bool {
+        return missing[i].Namespace < missing[j].Namespace
+    })
+    return missing, nil
+}
+
+// missingClassMessage describes a missing class in the events of a
+// namespace, suggesting an existing class with a similar name.
+func (r *NamespaceClassReconciler) missingClassMessage(ctx context.Context, className string) string {
+    var classes v1.NamespaceClassList
+    if err := r.List(ctx, &classes); err != nil {
+        return fmt.Sprintf("NamespaceClass %s does not exist", className)
+    }
+    names := make([]string, 0, len(classes.Items))
+    for _, nsc := range classes.Items {
+        names = append(names, nsc.Name)
+    }
+    if suggestion := suggestClass(className, names); suggestion != "" {
+        return fmt.Sprintf("NamespaceClass %s does not exist (did you mean %s?)", className, suggestion)
+    }
+    return fmt.Sprintf("NamespaceClass %s does not exist", className)
+}
+
+// suggestClass returns the class closest to a missing class name, or "" if
+// none is close enough to be a likely typo.
+func suggestClass(name string, classes []string) string {
+    best, bestDistance := "", maxSuggestionDistance+1
+    for _, candidate := range classes {
+        if d := editDistance(name, candidate); d < bestDistance {
+            best, bestDistance = candidate, d
+        }
+    }
+    return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+    previous := make([]int, len(b)+1)
+    current := make([]int, len(b)+1)
+    for j := range previous {
+        previous[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        current[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+        }
+        previous, current = current, previous
+    }
+    return previous[len(b)]
+}
@@ -0,0 +1,47 @@
+// internal/controller/missingclasses_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Missing classes", func() {
+    It("should list namespaces referring to missing classes with a suggestion", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "public-network"}},
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:   "web",
+                Labels: map[string]string{LabelKey: "public-netwrk"},
+            }},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:        "api",
+                Labels:      map[string]string{LabelKey: "public-network"},
+                Annotations: map[string]string{ClassesAnnotation: "monitoring,logging"},
+            }},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}},
+        ).Build()
+
+        missing, err := ListMissingClasses(ctx, cl)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(missing).To(Equal([]MissingClass{
+            {Namespace: "api", Class: "logging"},
+            {Namespace: "web", Class: "public-netwrk", Suggestion: "public-network"},
+        }))
+
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+        Expect(reconciler.missingClassMessage(ctx, "monitorng")).To(ContainSubstring("did you mean monitoring?"))
+        Expect(reconciler.missingClassMessage(ctx, "database")).NotTo(ContainSubstring("did you mean"))
+    })
+})
@@ -3,20 +3,23 @@ package controller
 
 import (
     "context"
-    "crypto/sha256"
-    "encoding/json"
+    stderrors "errors"
     "fmt"
     "reflect"
     "strings"
     "time"
 
+    "go.opentelemetry.io/otel/attribute"
     corev1 "k8s.io/api/core/v1"
     "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
     metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
     "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
     "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
     "k8s.io/apimachinery/pkg/types"
-    "k8s.io/client-go/util/retry"
+    "k8s.io/client-go/tools/record"
+    "k8s.io/utils/ptr"
     "sigs.k8s.io/controller-runtime/pkg/client"
     "sigs.k8s.io/controller-runtime/pkg/controller"
     "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -27,15 +30,18 @@ import (
     "sigs.k8s.io/controller-runtime/pkg/predicate"
     "sigs.k8s.io/controller-runtime/pkg/reconcile"
     "sigs.k8s.io/controller-runtime/pkg/builder"
+    "sigs.k8s.io/controller-runtime/pkg/source"
 
     v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/features"
 )
 
 const (
     // Label key to identify which NamespaceClass a Namespace belongs to
     LabelKey                 = "namespaceclass.akuity.io/name"
     
-    // Annotation to track resources managed by the controller
+    // Legacy annotation that tracked resources managed by the controller,
+    // migrated to NamespaceClassInventory objects
     AnnotationKey            = "namespaceclass.akuity.io/managed-resources"
     
     // Annotation to mark resources as managed by this controller
@@ -49,42 +55,187 @@ const (
     
     // Finalizer to ensure cleanup of resources when namespace is deleted
     NamespaceFinalizer       = "namespaceclass.akuity.io/finalizer"
+    
+    // Value of the managed-by annotation on resources created by this controller
+    ManagedByValue           = "namespaceclass-controller"
 )
 
 // ManagedResource tracks resources applied to a namespace.
 type ManagedResource struct {
-    APIVersion string `json:"apiVersion"`
-    Kind       string `json:"kind"`
-    Name       string `json:"name"`
-    Hash       string `json:"hash,omitempty"` // Store hash for change detection
+    APIVersion    string `json:"apiVersion"`
+    Kind          string `json:"kind"`
+    Name          string `json:"name"`
+    Hash          string `json:"hash,omitempty"` // Store hash for change detection
+    Class         string `json:"class,omitempty"` // Class the resource was applied for
+    Frozen        bool   `json:"frozen,omitempty"` // Tenant froze the resource against updates
+    ClusterScoped bool   `json:"clusterScoped,omitempty"` // Cluster-scoped resource created for the namespace
+    ID            string `json:"id,omitempty"` // Identifier that stays the same when the resource is renamed
+}
+
+// key identifies the resource within its namespace.
+func (m ManagedResource) key() string {
+    return fmt.Sprintf("%s/%s/%s", m.APIVersion, m.Kind, m.Name)
+}
+
+func (m ManagedResource) groupVersionKind() schema.GroupVersionKind {
+    return schema.FromAPIVersionAndKind(m.APIVersion, m.Kind)
 }
 
 // NamespaceClassReconciler reconciles Namespaces based on NamespaceClass.
 type NamespaceClassReconciler struct {
     client.Client
-    Scheme *runtime.Scheme
+    Scheme   *runtime.Scheme
+    Recorder record.EventRecorder
+
+    // TeamLabel maps a namespace label onto the team dimension of per-namespace metrics
+    TeamLabel TeamLabelMapping
+
+    // Maintenance pauses rollouts to provisioned namespaces during cluster upgrades
+    Maintenance *MaintenanceSignal
+
+    // WarmUp limits the initial pass over existing namespaces after a restart
+    WarmUp *WarmUpBudget
+
+    // OrphanScanInterval is how often objects no inventory tracks are counted; zero disables the scan
+    OrphanScanInterval time.Duration
+
+    // DeleteOrphans deletes the objects the orphan scan finds instead of only counting them
+    DeleteOrphans bool
+
+    // StartupSweep queues every namespace with a class or tracked resources after winning the leader election
+    StartupSweep bool
+
+    // Notifications sends onboarding, drift and failure notifications
+    Notifications *Notifications
+
+    // DataProtection keeps resources holding data when a namespace leaves their class; nil prunes them
+    DataProtection *DataProtectionPolicy
+
+    // ImagePullSecret is copied into namespaces whose classes attach it to service accounts; nil disables it
+    ImagePullSecret *ImagePullSecretSource
+
+    // Impersonation applies the resources of classes with a ServiceAccount as that ServiceAccount; nil rejects such classes
+    Impersonation *Impersonation
+
+    // Preflight reviews the permissions needed to apply the resources of a namespace before applying any; nil skips the review
+    Preflight *PermissionPreflight
+
+    // Kinds restricts the kinds classes may create; nil allows any kind
+    Kinds *KindPolicy
+
+    // ResourceLimit caps the resources a class may render per namespace; nil allows any number
+    ResourceLimit *ResourceLimit
+
+    // ApplyRateLimits limits how fast resources of each kind are created and updated; nil leaves writes unlimited
+    ApplyRateLimits *ApplyRateLimits
+
+    // Shard limits the controller to the namespaces of the classes of its shard; nil reconciles all namespaces
+    Shard *Sharding
+
+    // Scope limits the controller to a fixed set of namespaces; nil reconciles all namespaces
+    Scope *WatchScope
+
+    // Fairness spreads out the reconciles of large class rollouts; nil queues all namespaces of a changed class at once
+    Fairness *ClassFairness
+
+    // RequeueJitter adds up to this fraction of random delay to periodic requeues; zero requeues at fixed intervals
+    RequeueJitter float64
+
+    // MissingClassBackoff spaces out the retries of namespaces whose class is missing; nil retries every minute
+    MissingClassBackoff *MissingClassBackoff
+
+    // CircuitBreaker pauses the applies of classes whose reconciles fail at a high rate; nil never pauses them
+    CircuitBreaker *CircuitBreaker
+
+    // StatusBatchWindow aggregates the sync results written to the status of a class; zero writes each result immediately
+    StatusBatchWindow time.Duration
+
+    // MaxConcurrentReconciles is the number of namespaces reconciled in parallel; zero reconciles 5 at a time
+    MaxConcurrentReconciles int
+
+    // Features turns experimental behaviors on; nil leaves every feature at its default
+    Features *features.Gates
+
+    // Progress detects stalled reconciles for health checks; nil does not track them
+    Progress *ReconcileProgress
+
+    // DrainTimeout is how long in-flight reconciles may keep applying after shutdown starts; zero cancels them at once
+    DrainTimeout time.Duration
+
+    statusBatch  statusBatch
+    driftWatches *driftWatches
+    drain        shutdownDrain
 }
 
 // +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclasses,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclasses/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclassinventories,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;update;patch;delete
 // +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=impersonate
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
 
 // Reconcile ensures a namespace's resources match its NamespaceClass.
-func (r *NamespaceClassReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+func (r *NamespaceClassReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
     logger := log.FromContext(ctx).WithValues(
         "namespace", req.Name, 
         "controller", "NamespaceClassReconciler",
     )
     
+    ns := &corev1.Namespace{}
+    var className string
+    var applying bool
+
+    // Do not start applying once the controller is shutting down
+    if ctx.Err() != nil || !r.drain.enter() {
+        return reconcile.Result{}, nil
+    }
+    defer r.drain.leave()
+    ctx, cancel := r.drainContext(ctx)
+    defer cancel()
+
+    defer r.Progress.track(req.Name)()
+
+    // Namespaces outside the scope are left alone, apart from releasing the
+    // finalizer so they can always be deleted
+    if !r.Scope.includes(req.Name) {
+        if err := r.releaseExcluded(ctx, req.Name); err != nil {
+            logger.Error(err, "Failed to remove finalizer from excluded namespace")
+            return reconcile.Result{}, err
+        }
+        return reconcile.Result{}, nil
+    }
+    release, err := r.WarmUp.acquire(ctx, r.Client, req.Name)
+    if err != nil {
+        return reconcile.Result{}, err
+    }
+    defer release()
+    
+    tr := startReconcileTrace(ctx, req.Name)
     startTime := time.Now()
     logger.Info("Starting reconciliation")
     defer func() {
-        logger.Info("Completed reconciliation", "durationSeconds", time.Since(startTime).Seconds())
+        duration := time.Since(startTime)
+        logger.Info("Completed reconciliation", "durationSeconds", duration.Seconds())
+        r.recordReconcileMetrics(ns, className, duration, err)
+        if applying {
+            r.CircuitBreaker.record(className, err)
+        }
+        // Transient errors are retried with the work queue's backoff
+        // without marking the namespace as failed
+        if err != nil && className != "" && !isTransientError(err) {
+            r.recordSyncFailure(tr.ctx, ns.Name, className, err)
+            r.Notifications.failed(tr.ctx, ns.Name, className, err)
+        } else if err != nil {
+            logger.Info("Transient error, retrying with backoff", "error", err.Error())
+        }
+        tr.end(className, err)
     }()
+    ctx = tr.startPhase("fetch")
 
     // Fetch the namespace
-    ns := &corev1.Namespace{}
     if err := r.Get(ctx, req.NamespacedName, ns); err != nil {
         if errors.IsNotFound(err) {
             logger.Info("Namespace not found, ignoring")
@@ -94,30 +245,115 @@ func (r *NamespaceClassReconciler) Reconcile(ctx context.Context, req reconcile.
         return reconcile.Result{}, err
     }
 
+    // With sharding, another controller may be responsible for the namespace
+    if owned, err := r.ownsNamespace(ctx, ns); err != nil {
+        logger.Error(err, "Failed to resolve the shard of namespace")
+        return reconcile.Result{}, err
+    } else if !owned {
+        return reconcile.Result{}, nil
+    }
+
+    // Namespaces excluded from management, or that did not opt in to it in
+    // opt-in mode, are left entirely alone, apart from releasing the
+    // finalizer so they can always be deleted
+    if isSkipped(ns) || !r.Scope.admits(ns) {
+        logger.Info("Namespace is excluded from management, skipping", "skipped", isSkipped(ns))
+        forgetNamespaceMetrics(ns.Name)
+        r.Notifications.forget(ns.Name)
+        r.MissingClassBackoff.reset(ns.Name)
+        if err := r.releaseSkipped(ctx, ns); err != nil {
+            logger.Error(err, "Failed to remove finalizer")
+            return reconcile.Result{}, err
+        }
+        return reconcile.Result{}, nil
+    }
+
     // Handle namespace deletion with finalizer
     if !ns.DeletionTimestamp.IsZero() {
-        return r.handleNamespaceDeletion(ctx, ns)
+        forgetNamespaceMetrics(ns.Name)
+        r.Notifications.forget(ns.Name)
+        r.MissingClassBackoff.reset(ns.Name)
+        return r.handleNamespaceDeletion(tr.startPhase("cleanup"), ns)
+    }
+
+    // Delete ephemeral namespaces once their TTL has passed, and otherwise
+    // reconcile them again when it does
+    expired, expiresIn, err := r.expireNamespace(ctx, ns)
+    if err != nil {
+        logger.Error(err, "Failed to delete expired namespace")
+        return reconcile.Result{}, err
+    }
+    if expired {
+        return reconcile.Result{}, nil
+    }
+    if expiresIn > 0 {
+        defer func() {
+            result = requeueWithin(result, expiresIn)
+        }()
     }
 
-    // Get the current class label
-    className, hasClass := ns.Labels[LabelKey]
-    currentManaged, err := r.getManagedResources(ns)
+    // Get the current class, either from the label or inherited from an HNC parent
+    className, hasClass, err := r.resolveClass(ctx, ns)
+    if err != nil {
+        logger.Error(err, "Failed to resolve namespace class")
+        return reconcile.Result{}, err
+    }
+    currentManaged, err := r.getManagedResources(ctx, ns)
     if err != nil {
         logger.Error(err, "Failed to parse managed resources")
         return reconcile.Result{}, err
     }
+    previousClass, err := r.inventoryClass(ctx, ns.Name)
+    if err != nil {
+        logger.Error(err, "Failed to get inventory")
+        return reconcile.Result{}, err
+    }
 
     // If no class, clean up and exit
     if !hasClass {
+        if isPaused(ns) {
+            logger.Info("Namespace has no class label but is paused, leaving managed resources in place")
+            return reconcile.Result{}, nil
+        }
+        if suspended, err := r.classSuspended(ctx, previousClass); err != nil {
+            logger.Error(err, "Failed to get previous class", "class", previousClass)
+            return reconcile.Result{}, err
+        } else if suspended {
+            // Resuming the class does not requeue namespaces that left it
+            logger.Info("Namespace left a suspended class, leaving managed resources in place", "class", previousClass)
+            return reconcile.Result{RequeueAfter: r.jittered(suspendedRequeueInterval)}, nil
+        }
         logger.Info("Namespace has no class label, cleaning up managed resources")
+        forgetNamespaceMetrics(ns.Name)
+        r.Notifications.forget(ns.Name)
+        r.MissingClassBackoff.reset(ns.Name)
+        ctx = tr.startPhase("prune")
         for _, res := range currentManaged {
-            if err := r.deleteResource(ctx, ns.Name, res); err != nil {
-                if !errors.IsNotFound(err) {
-                    logger.Error(err, "Failed to delete resource", "resource", fmt.Sprintf("%s/%s", res.Kind, res.Name))
-                }
+            retained, err := r.retainProtected(ctx, ns, res)
+            if err != nil {
+                logger.Error(err, "Failed to check data protection", "resource", fmt.Sprintf("%s/%s", res.Kind, res.Name))
+                return reconcile.Result{}, err
+            }
+            if retained {
+                continue
+            }
+            deleted, err := r.pruneResource(ctx, ns.Name, res)
+            if err != nil {
+                logger.Error(err, "Failed to delete resource", "resource", fmt.Sprintf("%s/%s", res.Kind, res.Name))
+                r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonPruneFailed,
+                    "Failed to delete %s %s: %v", res.Kind, res.Name, err)
+            } else if deleted {
+                recordResourceOperation(res.Class, res.groupVersionKind(), operationDeleted)
+                r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonResourcePruned,
+                    "Deleted %s %s after the namespace left class %s", res.Kind, res.Name, res.Class)
             }
         }
 
+        if err := r.syncNamespaceMetadata(ctx, ns, nil); err != nil {
+            logger.Error(err, "Failed to remove namespace metadata")
+            return reconcile.Result{}, err
+        }
+
         // Remove finalizer if exists
         if containsString(ns.Finalizers, NamespaceFinalizer) {
             ns.Finalizers = removeString(ns.Finalizers, NamespaceFinalizer)
@@ -127,12 +363,13 @@ func (r *NamespaceClassReconciler) Reconcile(ctx context.Context, req reconcile.
             }
         }
 
-        // Clear managed resources annotation
-        if err := r.updateManagedResources(ctx, ns, nil); err != nil {
+        // Clear managed resources inventory
+        ctx = tr.startPhase("status-update")
+        if err := r.updateManagedResources(ctx, ns, "", nil); err != nil {
             logger.Error(err, "Failed to clear managed resources")
             return reconcile.Result{}, err
         }
-        return reconcile.Result{}, nil
+        return reconcile.Result{}, r.syncClassMembership(ctx, ns.Name, previousClass, "", nil)
     }
 
     // Add finalizer if needed
@@ -150,83 +387,708 @@ func (r *NamespaceClassReconciler) Reconcile(ctx context.Context, req reconcile.
     nsc := &v1.NamespaceClass{}
     if err := r.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
         if errors.IsNotFound(err) {
-            logger.Error(err, "NamespaceClass not found", "class", className)
-            return reconcile.Result{RequeueAfter: time.Minute}, nil // Requeue in case class is created later
+            retry := r.MissingClassBackoff.next(ns.Name)
+            logger.Error(err, "NamespaceClass not found", "class", className, "retryAfter", retry)
+            recordMissingClass(ns.Name, className)
+            r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonClassNotFound,
+                "%s; resources will be applied once it is created, retrying in %s", r.missingClassMessage(ctx, className), retry)
+            return reconcile.Result{RequeueAfter: r.jittered(retry)}, nil // Requeue in case the class watch missed its creation
         }
         logger.Error(err, "Failed to get NamespaceClass", "class", className)
         return reconcile.Result{}, err
     }
 
-    // Parse desired resources from the NamespaceClass
-    desiredResources, err := r.parseResources(ctx, nsc.Spec.Resources, className)
+    // Namespaces pinned to an earlier revision are rendered from that revision
+    if pin := ns.Annotations[RevisionAnnotation]; pin != "" {
+        nsc, err = r.pinnedClass(ctx, nsc, pin)
+        if err != nil {
+            logger.Error(err, "Failed to get pinned revision", "class", className, "revision", pin)
+            r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonRevisionNotFound,
+                "Cannot apply pinned revision %s of class %s: %v", pin, className, err)
+            return reconcile.Result{}, err
+        }
+    }
+
+    // Add-on classes listed on the namespace contribute their resources too
+    addons, missing, err := r.addonClasses(ctx, ns, className)
+    if err != nil {
+        logger.Error(err, "Failed to get add-on classes")
+        return reconcile.Result{}, err
+    }
+    if missing != "" {
+        retry := r.MissingClassBackoff.next(ns.Name)
+        logger.Info("Add-on NamespaceClass not found", "class", missing, "retryAfter", retry)
+        recordMissingClass(ns.Name, missing)
+        r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonClassNotFound,
+            "%s; resources will be applied once it is created, retrying in %s", r.missingClassMessage(ctx, missing), retry)
+        return reconcile.Result{RequeueAfter: r.jittered(retry)}, nil
+    }
+    r.MissingClassBackoff.reset(ns.Name)
+    forgetMissingClass(ns.Name)
+
+    // Parse desired resources from the NamespaceClass and its add-ons, and
+    // refuse to touch the namespace while they collide
+    desiredResources, sources, err := r.classResources(ctx, nsc, addons)
+    var metadata *namespaceMetadata
+    if err == nil {
+        err = expandValues(ctx, r.Client, desiredResources, ns, sources, nsc)
+    }
+    if err == nil {
+        scopeClusterResources(desiredResources, ns.Name, sources, nsc)
+        metadata, err = classNamespaceMetadata(append([]*v1.NamespaceClass{nsc}, addons...))
+    }
+    var conflict *classConflictError
+    if stderrors.As(err, &conflict) {
+        logger.Error(err, "Classes of the namespace define the same resources")
+        if reportErr := r.reportClassConflict(ctx, ns, nsc, currentManaged, previousClass, conflict); reportErr != nil {
+            logger.Error(reportErr, "Failed to update inventory status")
+        }
+        return reconcile.Result{}, err
+    }
+    var valuesErr *invalidValuesError
+    if stderrors.As(err, &valuesErr) {
+        logger.Info("Values of the namespace do not render its classes", "error", valuesErr.Error())
+        if reportErr := r.reportInvalidValues(ctx, ns, nsc, valuesErr); reportErr != nil {
+            logger.Error(reportErr, "Failed to update inventory status")
+        }
+        return reconcile.Result{}, err
+    }
     if err != nil {
         logger.Error(err, "Failed to parse resources")
         return reconcile.Result{}, err
     }
+    if err := r.removeInventoryCondition(ctx, ns.Name, v1.ConditionDegraded); err != nil {
+        logger.Error(err, "Failed to update inventory status")
+        return reconcile.Result{}, err
+    }
+
+    // Suspended classes and paused namespaces are only checked for drift
+    if reason := suspension(ns, nsc); reason != "" {
+        return r.reportSuspended(tr.startPhase("drift-check"), ns, nsc, desiredResources, sources, currentManaged, previousClass, reason)
+    }
+
+    // Pause rollouts to already provisioned namespaces during cluster maintenance
+    if len(currentManaged) > 0 {
+        frozen, err := r.Maintenance.Frozen(ctx)
+        if err != nil {
+            logger.Error(err, "Failed to read maintenance state")
+            return reconcile.Result{}, err
+        }
+        if frozen {
+            logger.Info("Class rollouts are frozen for cluster maintenance, requeueing")
+            r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonRolloutPaused,
+                "Changes to class %s are paused during cluster maintenance", className)
+            return reconcile.Result{RequeueAfter: r.jittered(freezeRequeueInterval)}, nil
+        }
+
+        // Hold changes back until one of the update windows of the class opens
+        open, next, err := updateWindowState(nsc.Spec.UpdateWindows, time.Now())
+        if err != nil {
+            logger.Error(err, "Invalid update windows", "class", className)
+            return reconcile.Result{}, err
+        }
+        if !open {
+            logger.Info("Outside the update windows of the class, requeueing", "nextWindow", next)
+            r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonOutsideUpdateWindow,
+                "Changes to class %s are deferred until the next update window at %s", className, next.Format(time.RFC3339))
+            return reconcile.Result{RequeueAfter: time.Until(next)}, nil
+        }
+    }
+
+    // Pause applies while the class's reconciles fail at a high rate
+    if open := r.CircuitBreaker.allow(className); open != nil {
+        logger.Info("Applies of the class are paused after a spike of failures", "retryIn", open.retryIn)
+        r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonCircuitOpen, "%v", open)
+        r.recordSyncFailure(ctx, ns.Name, className, open)
+        return reconcile.Result{RequeueAfter: r.jittered(open.retryIn)}, nil
+    }
+    applying = true
+
+    ctx = tr.startPhase("render")
+    if err := orderResources(desiredResources); err != nil {
+        logger.Error(err, "Failed to order resources")
+        return reconcile.Result{}, err
+    }
+
+    // Apply nothing unless the classes may create every kind they use
+    if err := r.checkKinds(desiredResources); err != nil {
+        var kindsErr *forbiddenKindsError
+        if stderrors.As(err, &kindsErr) {
+            logger.Info("Classes create forbidden kinds", "forbidden", kindsErr.forbidden)
+            if reportErr := r.reportForbiddenKinds(ctx, ns, nsc, kindsErr); reportErr != nil {
+                logger.Error(reportErr, "Failed to update inventory status")
+            }
+        }
+        return reconcile.Result{}, err
+    }
+
+    // Apply nothing if a class renders more resources than allowed
+    if err := r.checkResourceLimit(desiredResources, sources, nsc); err != nil {
+        var limitErr *tooManyResourcesError
+        if stderrors.As(err, &limitErr) {
+            logger.Info("Classes render too many resources", "classes", limitErr.classes)
+            if reportErr := r.reportTooManyResources(ctx, ns, nsc, limitErr); reportErr != nil {
+                logger.Error(reportErr, "Failed to update inventory status")
+            }
+        }
+        return reconcile.Result{}, err
+    }
+
+    // Apply nothing unless every resource can be applied
+    if err := r.checkPermissions(ctx, ns, desiredResources, sources, nsc); err != nil {
+        var permissionErr *missingPermissionsError
+        if stderrors.As(err, &permissionErr) {
+            logger.Info("Missing permissions to apply class resources, retrying later", "missing", permissionErr.missing)
+            if reportErr := r.reportMissingPermissions(ctx, ns, nsc, permissionErr); reportErr != nil {
+                logger.Error(reportErr, "Failed to update inventory status")
+            }
+        } else {
+            logger.Error(err, "Failed to review permissions")
+        }
+        return reconcile.Result{}, err
+    }
+
+    // A namespace switching classes runs the pre-switch hooks of the new
+    // class before any of its resources are applied
+    transition, err := r.classTransition(ctx, ns.Name, previousClass, className)
+    if err != nil {
+        logger.Error(err, "Failed to get class transition")
+        return reconcile.Result{}, err
+    }
+    if transition != nil && transition.Phase == v1.TransitionPhasePreSwitch {
+        hook, err := r.runHooks(ctx, ns, nsc, transition.Phase, classHooks(nsc, transition.Phase))
+        if err != nil {
+            logger.Error(err, "Pre-switch hook failed", "from", transition.From)
+            r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonHookFailed, "%v", err)
+            return reconcile.Result{}, err
+        }
+        if hook != nil {
+            logger.Info("Waiting for pre-switch hook", "from", transition.From, "kind", hook.GetKind(), "name", hook.GetName())
+            return r.waitForPreSwitchHooks(ctx, ns, nsc, transition, hook, len(desiredResources), len(currentManaged))
+        }
+        transition.Phase = v1.TransitionPhaseSwitching
+        if err := r.setInventoryTransition(ctx, ns.Name, transition); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+    }
+
+    // Set the labels and annotations the classes declare on the namespace
+    // before any resource that may depend on them, e.g. Pod Security labels
+    if err := r.syncNamespaceMetadata(ctx, ns, metadata); err != nil {
+        logger.Error(err, "Failed to update namespace metadata")
+        return reconcile.Result{}, err
+    }
+
+    // Index the current inventory so ownership can be verified on update
+    tracked := make(map[string]ManagedResource)
+    for _, res := range currentManaged {
+        tracked[res.key()] = res
+    }
+
+    // Get keys of desired resources for cleanup
+    desiredKeys := make(map[string]bool)
+    for _, res := range desiredResources {
+        desiredKeys[fmt.Sprintf("%s/%s/%s", res.GetAPIVersion(), res.GetKind(), res.GetName())] = true
+    }
+
+    // Renamed resources are created under their new name before the old
+    // object is pruned, which only happens once the new one is applied
+    renames := resourceRenames(desiredResources, sources, nsc, currentManaged, desiredKeys)
+    migrated := make(map[string]string)
 
     // Create or update desired resources
+    ctx = tr.startPhase("apply")
     var managed []ManagedResource
+    var waitingFor *unstructured.Unstructured
+    var waitingMessage, waitingReason string
+    var drifted int
+    var wave []*unstructured.Unstructured
+    var failures []applyFailure
+    var applied []appliedResource
+    results := make(applyResults)
+    byRef := make(map[string]*unstructured.Unstructured, len(desiredResources))
     for _, res := range desiredResources {
-        // Set namespace and add management annotations
-        res.SetNamespace(ns.Name)
-        annotations := res.GetAnnotations()
-        if annotations == nil {
-            annotations = make(map[string]string)
+        byRef[resourceRef(res)] = res
+    }
+    definitions := classDefinitions(desiredResources)
+    for _, res := range desiredResources {
+        key := fmt.Sprintf("%s/%s/%s", res.GetAPIVersion(), res.GetKind(), res.GetName())
+
+        // Start the next sync wave only once the previous one is ready
+        if waitingFor == nil && len(wave) > 0 {
+            previous, _ := resourceWave(wave[0])
+            if current, _ := resourceWave(res); current != previous {
+                pending, err := r.pendingResource(ctx, wave)
+                if err != nil {
+                    logger.Error(err, "Failed to check sync wave", "wave", previous)
+                    return reconcile.Result{}, err
+                }
+                if pending != nil {
+                    logger.Info("Waiting for sync wave before applying remaining resources",
+                        "wave", previous, "kind", pending.GetKind(), "name", pending.GetName())
+                    r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonWaitingForWave,
+                        "Waiting for %s %s of wave %d to be ready before applying the next wave", pending.GetKind(), pending.GetName(), previous)
+                    waitingFor = pending
+                    waitingMessage = fmt.Sprintf("Waiting for %s %s of wave %d to be ready", pending.GetKind(), pending.GetName(), previous)
+                    waitingReason = v1.ReasonWavePending
+                }
+                wave = nil
+            }
         }
-        annotations[ManagedByAnnotation] = "namespaceclass-controller"
-        annotations[CreatedByClassAnnotation] = className
 
-        // Calculate resource hash
-        resourceHash := calculateResourceHash(res)
-        annotations[ResourceHashAnnotation] = resourceHash
-        res.SetAnnotations(annotations)
+        // Apply a resource only once the resources it depends on are ready
+        if waitingFor == nil {
+            var deps []*unstructured.Unstructured
+            if crd, ok := definitions[res.GroupVersionKind().GroupKind()]; ok {
+                deps = append(deps, crd)
+            }
+            for _, ref := range resourceDependencies(res) {
+                deps = append(deps, byRef[ref])
+            }
+            pending, err := r.pendingResource(ctx, deps)
+            if err != nil {
+                logger.Error(err, "Failed to check dependencies", "kind", res.GetKind(), "name", res.GetName())
+                return reconcile.Result{}, err
+            }
+            if pending != nil {
+                logger.Info("Waiting for dependency before applying remaining resources",
+                    "kind", res.GetKind(), "name", res.GetName(), "dependency", resourceRef(pending))
+                r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonWaitingForDependency,
+                    "Waiting for %s %s to be ready before applying %s %s", pending.GetKind(), pending.GetName(), res.GetKind(), res.GetName())
+                waitingFor = pending
+                waitingMessage = fmt.Sprintf("Waiting for %s %s to be ready before applying %s %s", pending.GetKind(), pending.GetName(), res.GetKind(), res.GetName())
+                waitingReason = v1.ReasonDependencyPending
+            }
+        }
+
+        // Once a resource is waiting for generated data, a wave or a dependency,
+        // resources after it are not applied yet; keep tracking the ones applied
+        // in earlier passes
+        if waitingFor != nil {
+            if entry, ok := tracked[key]; ok {
+                managed = append(managed, entry)
+            }
+            continue
+        }
+        wave = append(wave, res)
+
+        // Set namespace, management annotations and resource hash for the
+        // class the resource comes from
+        source := sources.of(res, nsc)
+        resourceHash := renderResource(res, ns.Name, source)
+        opts := renderOptions(res, source)
+        opts.drifted = &drifted
+        if entry, ok := tracked[key]; ok {
+            opts.trackedClass = &entry.Class
+        }
+        if ns.Annotations[AdoptAnnotation] == "true" {
+            opts.conflictPolicy = v1.ConflictPolicyAdopt
+        }
+        if opts.client, err = r.applyClient(source); err != nil {
+            logger.Error(err, "Failed to impersonate ServiceAccount of class", "class", source.Name)
+            return reconcile.Result{}, err
+        }
+
+        // A resource created under a new name may take over the data of the
+        // object it was renamed from
+        applyRes := res
+        if old, renamed := renames[key]; renamed {
+            if _, exists := tracked[key]; !exists {
+                if applyRes, err = r.copyRenamedData(ctx, ns.Name, res, old); err != nil {
+                    logger.Error(err, "Failed to copy data of renamed resource", "kind", res.GetKind(), "name", res.GetName(), "from", old.Name)
+                    return reconcile.Result{}, err
+                }
+            }
+        }
 
         // Create or update the resource
-        if err := r.createOrUpdateResource(ctx, res); err != nil {
-            logger.Error(err, "Failed to apply resource", 
+        applyCtx, span := startSpan(ctx, "apply "+res.GetKind(),
+            attribute.String("apiVersion", res.GetAPIVersion()),
+            attribute.String("kind", res.GetKind()),
+            attribute.String("name", res.GetName()))
+        result, err := r.createOrUpdateResource(applyCtx, applyRes, opts)
+        err = redactError(res, err)
+        span.SetAttributes(attribute.String("result", string(result)))
+        endSpan(span, err)
+
+        // The API server may serve the kind of an established definition of
+        // the class a moment later; forget what the mapper cached and retry
+        if _, ok := definitions[res.GroupVersionKind().GroupKind()]; ok && meta.IsNoMatchError(err) {
+            resetRESTMapper(r.RESTMapper())
+            logger.Info("Waiting for the API server to serve kind before applying remaining resources",
                 "kind", res.GetKind(), "name", res.GetName())
+            waitingFor = res
+            waitingMessage = fmt.Sprintf("Waiting for the API server to serve %s", res.GroupVersionKind())
+            waitingReason = v1.ReasonDependencyPending
+            if entry, ok := tracked[key]; ok {
+                managed = append(managed, entry)
+            }
+            continue
+        }
+        // A resource being recreated is created again once its old object
+        // is gone
+        var recreateErr *recreatePendingError
+        if stderrors.As(err, &recreateErr) {
+            logger.Info("Waiting for old object to be deleted before recreating resource",
+                "kind", res.GetKind(), "name", res.GetName())
+            r.recordSyncEvent(ns, source, corev1.EventTypeNormal, ReasonWaitingForRecreate,
+                "Waiting for %s %s to be deleted before recreating it", res.GetKind(), res.GetName())
+            waitingFor = res
+            waitingMessage = fmt.Sprintf("Waiting for %s %s to be deleted before recreating it", res.GetKind(), res.GetName())
+            waitingReason = v1.ReasonRecreatePending
+            if entry, ok := tracked[key]; ok {
+                managed = append(managed, entry)
+            }
+            continue
+        }
+        if meta.IsNoMatchError(err) && !continuesOnError(nsc) {
+            kindErr := &kindNotInstalledError{gvk: res.GroupVersionKind()}
+            logger.Info("Kind of resource is not installed, retrying later",
+                "kind", res.GetKind(), "apiVersion", res.GetAPIVersion(), "name", res.GetName())
+            results.record(res, "", kindErr)
+            err := r.rollBack(ctx, ns, nsc, previousClass, applied, currentManaged, results, kindErr)
+            if reportErr := r.reportApplyResults(ctx, ns, className, desiredResources, results); reportErr != nil {
+                logger.Error(reportErr, "Failed to update inventory status")
+            }
+            if reportErr := r.reportKindNotInstalled(ctx, ns, source, res.GetName(), kindErr); reportErr != nil {
+                logger.Error(reportErr, "Failed to update inventory status")
+                return reconcile.Result{}, reportErr
+            }
             return reconcile.Result{}, err
         }
+        if meta.IsNoMatchError(err) {
+            err = &kindNotInstalledError{gvk: res.GroupVersionKind()}
+        }
+        if err != nil {
+            logger.Error(err, "Failed to apply resource", 
+                "kind", res.GetKind(), "name", res.GetName())
+            r.recordSyncEvent(ns, source, corev1.EventTypeWarning, ReasonApplyFailed,
+                "Failed to apply %s %s: %v", res.GetKind(), res.GetName(), err)
+            results.record(res, "", err)
+            if !continuesOnError(nsc) {
+                err = r.rollBack(ctx, ns, nsc, previousClass, applied, currentManaged, results, err)
+                if reportErr := r.reportApplyResults(ctx, ns, className, desiredResources, results); reportErr != nil {
+                    logger.Error(reportErr, "Failed to update inventory status")
+                }
+                return reconcile.Result{}, err
+            }
+
+            // Apply the remaining resources and report the failures together,
+            // keeping track of what a failed resource was last applied as
+            failures = append(failures, applyFailure{resource: res, err: err})
+            if entry, ok := tracked[key]; ok {
+                managed = append(managed, entry)
+            }
+            continue
+        }
+        results.record(res, v1.ApplyResult(result), nil)
+        switch result {
+        case applyResultCreated:
+            recordResourceOperation(source.Name, res.GroupVersionKind(), operationCreated)
+            r.recordSyncEvent(ns, source, corev1.EventTypeNormal, ReasonResourceCreated,
+                "Created %s %s", res.GetKind(), res.GetName())
+        case applyResultUpdated:
+            recordResourceOperation(source.Name, res.GroupVersionKind(), operationUpdated)
+            r.recordSyncEvent(ns, source, corev1.EventTypeNormal, ReasonResourceUpdated,
+                "Updated %s %s", res.GetKind(), res.GetName())
+        }
+        if result == applyResultSkipped {
+            continue
+        }
+        if err := r.driftWatches.watch(res.GroupVersionKind()); err != nil {
+            logger.Error(err, "Failed to watch kind for drift", "kind", res.GetKind(), "apiVersion", res.GetAPIVersion())
+        }
+
+        // Keep tracking frozen resources with the hash they were last applied with
+        if result == applyResultFrozen {
+            entry := ManagedResource{
+                APIVersion:    res.GetAPIVersion(),
+                Kind:          res.GetKind(),
+                Name:          res.GetName(),
+                Hash:          tracked[key].Hash,
+                Class:         source.Name,
+                Frozen:        true,
+                ClusterScoped: isClusterScoped(res),
+                ID:            res.GetAnnotations()[ResourceIDAnnotation],
+            }
+            if entry.Hash == "" {
+                entry.Hash = resourceHash
+            }
+            managed = append(managed, entry)
+            if old, renamed := renames[key]; renamed {
+                migrated[old.key()] = res.GetName()
+            }
+            continue
+        }
 
         // Add to managed list
-        managed = append(managed, ManagedResource{
-            APIVersion: res.GetAPIVersion(),
-            Kind:       res.GetKind(),
-            Name:       res.GetName(),
-            Hash:       resourceHash,
-        })
+        entry := ManagedResource{
+            APIVersion:    res.GetAPIVersion(),
+            Kind:          res.GetKind(),
+            Name:          res.GetName(),
+            Hash:          resourceHash,
+            Class:         source.Name,
+            ClusterScoped: isClusterScoped(res),
+            ID:            res.GetAnnotations()[ResourceIDAnnotation],
+        }
+        managed = append(managed, entry)
+        if old, renamed := renames[key]; renamed {
+            migrated[old.key()] = res.GetName()
+        }
+        if result == applyResultCreated || result == applyResultUpdated {
+            applied = append(applied, appliedResource{entry: entry, result: result})
+        }
+
+        // Hold back dependent resources until generated tokens/secrets exist
+        ready, err := r.generatedDataReady(ctx, res)
+        if err != nil {
+            logger.Error(err, "Failed to check generated data", 
+                "kind", res.GetKind(), "name", res.GetName())
+            return reconcile.Result{}, err
+        }
+        if !ready {
+            logger.Info("Waiting for generated data before applying remaining resources",
+                "kind", res.GetKind(), "name", res.GetName())
+            r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonWaitingForData,
+                "Waiting for %s %s to be populated before applying the remaining resources", res.GetKind(), res.GetName())
+            waitingFor = res
+            waitingMessage = fmt.Sprintf("Waiting for %s %s to be populated", res.GetKind(), res.GetName())
+            waitingReason = v1.ReasonGeneratedDataPending
+        }
     }
 
-    // Get keys of desired resources for cleanup
-    desiredKeys := make(map[string]bool)
-    for _, res := range managed {
-        key := fmt.Sprintf("%s/%s/%s", res.APIVersion, res.Kind, res.Name)
-        desiredKeys[key] = true
+    // While switching classes, resources of the previous class are only
+    // pruned once every resource of the new class is applied and healthy, so
+    // the namespace is never left without either
+    keepPrevious := false
+    if transition != nil {
+        if waitingFor == nil && len(failures) == 0 {
+            health, _, err := r.checkHealth(ctx, desiredResources)
+            if err != nil {
+                logger.Error(err, "Failed to verify resources before pruning", "from", transition.From)
+                return reconcile.Result{}, err
+            }
+            for i, h := range health {
+                if h.Status != v1.HealthCurrent {
+                    waitingFor = desiredResources[i]
+                    waitingMessage = fmt.Sprintf("Waiting for %s %s to be ready before pruning the resources of class %s: %s",
+                        h.Kind, h.Name, transition.From, h.Message)
+                    waitingReason = v1.ReasonTransitionPending
+                    break
+                }
+            }
+        }
+        keepPrevious = waitingFor != nil || len(failures) > 0
     }
 
     // Clean up undesired resources
+    ctx = tr.startPhase("prune")
+    activeClasses := map[string]bool{className: true}
+    for _, addon := range addons {
+        activeClasses[addon.Name] = true
+    }
     for _, res := range currentManaged {
         key := fmt.Sprintf("%s/%s/%s", res.APIVersion, res.Kind, res.Name)
         if !desiredKeys[key] {
-            if err := r.deleteResource(ctx, ns.Name, res); err != nil {
-                if !errors.IsNotFound(err) {
-                    logger.Error(err, "Failed to delete resource", 
-                        "kind", res.Kind, "name", res.Name)
+            newName, wasMigrated := migrated[key]
+            if keepPrevious || (res.ID != "" && !wasMigrated && isRenameSource(renames, res)) {
+                managed = append(managed, res)
+                continue
+            }
+            if res.Class != "" && !activeClasses[res.Class] {
+                retained, err := r.retainProtected(ctx, ns, res)
+                if err != nil {
+                    logger.Error(err, "Failed to check data protection", "kind", res.Kind, "name", res.Name)
                     return reconcile.Result{}, err
                 }
+                if retained {
+                    continue
+                }
+            }
+            deleted, err := r.pruneResource(ctx, ns.Name, res)
+            if err != nil {
+                logger.Error(err, "Failed to delete resource", 
+                    "kind", res.Kind, "name", res.Name)
+                r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonPruneFailed,
+                    "Failed to delete %s %s: %v", res.Kind, res.Name, err)
+                return reconcile.Result{}, err
+            }
+            if deleted {
+                prunedFrom := className
+                if res.Class != "" {
+                    prunedFrom = res.Class
+                }
+                logger.Info("Deleted resource", "kind", res.Kind, "name", res.Name)
+                recordResourceOperation(prunedFrom, res.groupVersionKind(), operationDeleted)
+                if wasMigrated {
+                    r.recordSyncEvent(ns, nsc, corev1.EventTypeNormal, ReasonResourceRenamed,
+                        "Renamed %s %s to %s", res.Kind, res.Name, newName)
+                } else {
+                    r.recordSyncEvent(ns, nsc, corev1.EventTypeNormal, ReasonResourcePruned,
+                        "Deleted %s %s, which is no longer part of class %s", res.Kind, res.Name, prunedFrom)
+                }
             }
-            logger.Info("Deleted resource", "kind", res.Kind, "name", res.Name)
         }
     }
 
-    // Update managed resources annotation
-    if err := r.updateManagedResources(ctx, ns, managed); err != nil {
+    // Update managed resources inventory
+    ctx = tr.startPhase("status-update")
+    if err := r.updateManagedResources(ctx, ns, className, managed); err != nil {
         logger.Error(err, "Failed to update managed resources")
         return reconcile.Result{}, err
     }
+    if err := r.setInventoryMetadata(ctx, ns.Name, metadata); err != nil {
+        logger.Error(err, "Failed to update managed resources")
+        return reconcile.Result{}, err
+    }
+    if err := r.setInventoryApplyResults(ctx, ns.Name, desiredResources, results); err != nil {
+        logger.Error(err, "Failed to update inventory status")
+        return reconcile.Result{}, err
+    }
+    r.recordManagedResources(ns, className, len(managed))
+    recordDriftedResources(ns.Name, className, drifted)
+
+    // Resources that failed to apply fail the sync once everything else is
+    // applied and pruned
+    if len(failures) > 0 {
+        failuresErr := &applyFailuresError{failures: failures, total: len(desiredResources)}
+        if err := r.reportApplyFailures(ctx, ns, failuresErr); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+        if err := r.setInventoryApplyResults(ctx, ns.Name, desiredResources, results); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+        return reconcile.Result{}, failuresErr
+    }
+
+    // Once the previous class is pruned, run the post-switch hooks and
+    // complete the transition
+    if transition != nil && !keepPrevious {
+        transition.Phase = v1.TransitionPhasePostSwitch
+        hook, hookErr := r.runHooks(ctx, ns, nsc, transition.Phase, classHooks(nsc, transition.Phase))
+        if hookErr == nil && hook == nil {
+            if err := r.deleteHooks(ctx, ns, nsc); err != nil {
+                logger.Error(err, "Failed to delete hooks")
+                return reconcile.Result{}, err
+            }
+            logger.Info("Completed switch between classes", "from", transition.From)
+            transition = nil
+        }
+        if err := r.setInventoryTransition(ctx, ns.Name, transition); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+        if hookErr != nil {
+            logger.Error(hookErr, "Post-switch hook failed")
+            r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonHookFailed, "%v", hookErr)
+            return reconcile.Result{}, hookErr
+        }
+        if hook != nil {
+            waitingFor = hook
+            waitingMessage = fmt.Sprintf("Switching from class %s: waiting for post-switch hook %s %s",
+                transition.From, hook.GetKind(), hook.GetName())
+            waitingReason = v1.ReasonTransitionPending
+        }
+    }
+
+    // Once everything is applied, check the health of the resources for
+    // classes that wait for them to become ready
+    var healthReason, healthMessage string
+    if waitingFor == nil {
+        var health []v1.ResourceHealth
+        if nsc.Spec.WaitForReady {
+            var unhealthy int
+            if health, unhealthy, err = r.checkHealth(ctx, desiredResources); err != nil {
+                logger.Error(err, "Failed to check resource health")
+                return reconcile.Result{}, err
+            }
+            if unhealthy > 0 {
+                healthReason, healthMessage, err = r.unhealthyReason(ctx, ns, nsc, unhealthy, len(health))
+                if err != nil {
+                    logger.Error(err, "Failed to read inventory status")
+                    return reconcile.Result{}, err
+                }
+            }
+        }
+        if err := r.setInventoryHealth(ctx, ns.Name, health); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+    }
 
     // Update NamespaceClass status with retry
-    return reconcile.Result{}, r.updateNamespaceClassStatus(ctx, nsc, ns.Name)
+    sync := &v1.NamespaceSyncStatus{
+        Name:             ns.Name,
+        Phase:              v1.SyncPhaseSynced,
+        LastSyncTime:       metav1.Now(),
+        ObservedGeneration: nsc.Generation,
+        DesiredResources: len(desiredResources),
+        ManagedResources: len(managed),
+    }
+    switch {
+    case waitingFor != nil:
+        sync.Phase = v1.SyncPhasePending
+        sync.Message = waitingMessage
+    case healthReason == v1.ReasonReadyTimeout:
+        sync.Phase = v1.SyncPhaseFailed
+        sync.Message = healthMessage
+    case healthReason != "":
+        sync.Phase = v1.SyncPhasePending
+        sync.Message = healthMessage
+    }
+    if err := r.syncClassMembership(ctx, ns.Name, previousClass, className, sync); err != nil {
+        logger.Error(err, "Failed to update NamespaceClass status")
+        return reconcile.Result{}, err
+    }
+    if previousClass != className {
+        r.Notifications.onboarded(ctx, ns.Name, className, len(managed))
+    }
+    r.Notifications.synced(ctx, ns.Name, className, drifted)
+
+    // Only report the namespace Ready once generated data is available, all
+    // waves and dependencies are applied and, if requested, resources are healthy
+    if waitingFor != nil {
+        if err := r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+            Type:    v1.ConditionReady,
+            Status:  metav1.ConditionFalse,
+            Reason:  waitingReason,
+            Message: waitingMessage,
+        }); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+        return reconcile.Result{RequeueAfter: r.jittered(generatedDataRequeueInterval)}, nil
+    }
+    if healthReason != "" {
+        if err := r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+            Type:    v1.ConditionReady,
+            Status:  metav1.ConditionFalse,
+            Reason:  healthReason,
+            Message: healthMessage,
+        }); err != nil {
+            logger.Error(err, "Failed to update inventory status")
+            return reconcile.Result{}, err
+        }
+        return reconcile.Result{RequeueAfter: r.jittered(healthRequeueInterval)}, nil
+    }
+    if err := r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionReady,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonApplied,
+        Message: fmt.Sprintf("All resources of class %s are applied", className),
+    }); err != nil {
+        logger.Error(err, "Failed to update inventory status")
+        return reconcile.Result{}, err
+    }
+    // The central image pull secret is not watched; check it for rotated
+    // credentials now and then
+    if copiesImagePullSecret(desiredResources) {
+        return reconcile.Result{RequeueAfter: r.jittered(imagePullSecretResyncInterval)}, nil
+    }
+    return reconcile.Result{}, nil
 }
 
 // Handle namespace deletion by cleaning up resources and removing finalizer
@@ -242,7 +1104,7 @@ func (r *NamespaceClassReconciler) handleNamespaceDeletion(ctx context.Context,
     logger.Info("Namespace is being deleted, cleaning up resources")
     
     // Get managed resources
-    managed, err := r.getManagedResources(ns)
+    managed, err := r.getManagedResources(ctx, ns)
     if err != nil {
         logger.Error(err, "Failed to parse managed resources")
         return reconcile.Result{}, err
@@ -262,7 +1124,22 @@ func (r *NamespaceClassReconciler) handleNamespaceDeletion(ctx context.Context,
     
     // If any errors, retry
     if !allSucceeded {
-        return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+        return reconcile.Result{RequeueAfter: r.jittered(time.Second * 10)}, nil
+    }
+
+    // Drop the inventory so the namespace is removed from its class status
+    previousClass, err := r.inventoryClass(ctx, ns.Name)
+    if err != nil {
+        logger.Error(err, "Failed to get inventory")
+        return reconcile.Result{}, err
+    }
+    if err := r.updateManagedResources(ctx, ns, "", nil); err != nil {
+        logger.Error(err, "Failed to clear managed resources")
+        return reconcile.Result{}, err
+    }
+    if err := r.syncClassMembership(ctx, ns.Name, previousClass, "", nil); err != nil {
+        logger.Error(err, "Failed to update NamespaceClass status")
+        return reconcile.Result{}, err
     }
     
     // Remove finalizer
@@ -277,58 +1154,15 @@ func (r *NamespaceClassReconciler) handleNamespaceDeletion(ctx context.Context,
 }
 
 // Helper functions
-func (r *NamespaceClassReconciler) getManagedResources(ns *corev1.Namespace) ([]ManagedResource, error) {
-    if ns.Annotations == nil || ns.Annotations[AnnotationKey] == "" {
-        return nil, nil
-    }
-    var managed []ManagedResource
-    if err := json.Unmarshal([]byte(ns.Annotations[AnnotationKey]), &managed); err != nil {
+func (r *NamespaceClassReconciler) parseResources(ctx context.Context, nsc *v1.NamespaceClass) ([]*unstructured.Unstructured, error) {
+    resources, err := classSpecResources(nsc)
+    if err != nil {
         return nil, err
     }
-    return managed, nil
-}
-
-func (r *NamespaceClassReconciler) updateManagedResources(ctx context.Context, ns *corev1.Namespace, managed []ManagedResource) error {
-    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-        // Get latest namespace
-        if err := r.Get(ctx, types.NamespacedName{Name: ns.Name}, ns); err != nil {
-            return err
-        }
-        
-        if ns.Annotations == nil {
-            ns.Annotations = make(map[string]string)
-        }
-        
-        if managed == nil || len(managed) == 0 {
-            delete(ns.Annotations, AnnotationKey)
-        } else {
-            data, err := json.Marshal(managed)
-            if err != nil {
-                return err
-            }
-            ns.Annotations[AnnotationKey] = string(data)
-        }
-        
-        return r.Update(ctx, ns)
-    })
-}
-
-func (r *NamespaceClassReconciler) parseResources(ctx context.Context, raw []runtime.RawExtension, className string) ([]*unstructured.Unstructured, error) {
-    var result []*unstructured.Unstructured
-    for _, r := range raw {
-        var u unstructured.Unstructured
-        if err := json.Unmarshal(r.Raw, &u); err != nil {
-            return nil, err
-        }
-        
-        // Validate the resource
-        if err := validateResource(&u); err != nil {
-            return nil, fmt.Errorf("invalid resource in class %s: %v", className, err)
-        }
-        
-        result = append(result, &u)
+    if resources, err = r.replicateConfigMaps(ctx, nsc, resources); err != nil {
+        return nil, err
     }
-    return result, nil
+    return r.attachImagePullSecret(ctx, nsc, resources)
 }
 
 func validateResource(u *unstructured.Unstructured) error {
@@ -344,106 +1178,247 @@ func validateResource(u *unstructured.Unstructured) error {
     return nil
 }
 
-func calculateResourceHash(obj *unstructured.Unstructured) string {
-    // Deep copy to avoid modifying the original
-    copy := obj.DeepCopy()
-    
-    // Remove metadata fields that change frequently
-    if metaMap, ok := copy.Object["metadata"].(map[string]interface{}); ok {
-        delete(metaMap, "resourceVersion")
-        delete(metaMap, "generation")
-        delete(metaMap, "creationTimestamp")
-        delete(metaMap, "annotations")
-    }
-    
-    // Serialize the object for hashing
-    data, err := json.Marshal(copy.Object["spec"])
-    if err != nil {
-        return ""
-    }
-    
-    // Calculate hash
-    hash := sha256.Sum256(data)
-    return fmt.Sprintf("%x", hash)
-}
-
-func (r *NamespaceClassReconciler) createOrUpdateResource(ctx context.Context, desired *unstructured.Unstructured) error {
+func (r *NamespaceClassReconciler) createOrUpdateResource(ctx context.Context, desired *unstructured.Unstructured, opts applyOptions) (applyResult, error) {
     logger := log.FromContext(ctx)
+    c := opts.client
+    if c == nil {
+        c = r.Client
+    }
     
     existing := &unstructured.Unstructured{}
     existing.SetGroupVersionKind(desired.GroupVersionKind())
     
-    err := r.Get(ctx, types.NamespacedName{
+    err := c.Get(ctx, types.NamespacedName{
         Namespace: desired.GetNamespace(), 
         Name: desired.GetName(),
     }, existing)
     
     if errors.IsNotFound(err) {
+        if err := r.ApplyRateLimits.wait(ctx, desired.GroupVersionKind()); err != nil {
+            return "", err
+        }
         logger.Info("Creating resource", 
             "kind", desired.GetKind(), 
             "name", desired.GetName(),
             "namespace", desired.GetNamespace())
-        return r.Create(ctx, desired)
+        return applyResultCreated, c.Create(ctx, desired)
     } else if err != nil {
-        return err
+        return "", err
     }
     
-    // Check if update is needed by comparing hash
-    existingHash := existing.GetAnnotations()[ResourceHashAnnotation]
-    newHash := desired.GetAnnotations()[ResourceHashAnnotation]
+    // Leave objects propagated by the Hierarchical Namespace Controller alone
+    if isHNCPropagated(existing) {
+        logger.Info("Skipping resource propagated by HNC", 
+            "kind", desired.GetKind(), 
+            "name", desired.GetName(),
+            "namespace", desired.GetNamespace(),
+            "inheritedFrom", existing.GetLabels()[HNCInheritedFromLabel])
+        return applyResultSkipped, nil
+    }
     
-    if existingHash != newHash {
+    // Resolve conflicts with objects the controller did not create
+    adopted := false
+    if !isManagedByController(existing) {
+        policy := opts.conflictPolicy
+        if existing.GetAnnotations()[AdoptAnnotation] == "true" {
+            policy = v1.ConflictPolicyAdopt
+        }
+        switch policy {
+        case v1.ConflictPolicyFail:
+            r.recordEvent(existing, corev1.EventTypeWarning, ReasonResourceConflict,
+                "%s %s exists and is not managed by the controller", desired.GetKind(), desired.GetName())
+            return "", fmt.Errorf("%s %s/%s already exists and is not managed by the controller",
+                desired.GetKind(), desired.GetNamespace(), desired.GetName())
+        case v1.ConflictPolicySkip:
+            logger.Info("Skipping resource not managed by the controller", 
+                "kind", desired.GetKind(), 
+                "name", desired.GetName(),
+                "namespace", desired.GetNamespace())
+            return applyResultSkipped, nil
+        case v1.ConflictPolicyAdopt:
+            logger.Info("Adopting existing resource", 
+                "kind", desired.GetKind(), 
+                "name", desired.GetName(),
+                "namespace", desired.GetNamespace())
+            mergeExistingMetadata(existing, desired)
+            adopted = true
+        }
+    }
+    
+    // Never update an object another class created unless it is in our inventory
+    if isManagedByController(existing) && !ownsResource(existing, opts.className, opts.trackedClass) {
+        r.recordEvent(existing, corev1.EventTypeWarning, ReasonOwnershipConflict,
+            "Refusing to update %s %s: created by class %q", desired.GetKind(), desired.GetName(),
+            existing.GetAnnotations()[CreatedByClassAnnotation])
+        return "", fmt.Errorf("%s %s/%s is managed by class %q", desired.GetKind(), desired.GetNamespace(),
+            desired.GetName(), existing.GetAnnotations()[CreatedByClassAnnotation])
+    }
+    
+    // Never update a cluster-scoped object created for another namespace
+    if isManagedByController(existing) && !ownedByNamespace(existing, desired) {
+        r.recordEvent(existing, corev1.EventTypeWarning, ReasonOwnershipConflict,
+            "Refusing to update %s %s: created for namespace %q", desired.GetKind(), desired.GetName(),
+            existing.GetAnnotations()[OwnerNamespaceAnnotation])
+        return "", fmt.Errorf("%s %s is managed for namespace %q", desired.GetKind(), desired.GetName(),
+            existing.GetAnnotations()[OwnerNamespaceAnnotation])
+    }
+
+    // Leave objects a tenant froze alone if the class allows it
+    if opts.allowFreeze && isManagedByController(existing) && existing.GetAnnotations()[FrozenAnnotation] == "true" {
+        if needsUpdate(existing, desired, opts) {
+            logger.Info("Skipping update of frozen resource", 
+                "kind", desired.GetKind(), 
+                "name", desired.GetName(),
+                "namespace", desired.GetNamespace())
+        }
+        return applyResultFrozen, nil
+    }
+    
+    if !adopted && opts.drifted != nil && isDrifted(existing, desired, opts) {
+        logger.Info("Resource drifted from its class", 
+            "kind", desired.GetKind(), 
+            "name", desired.GetName(),
+            "namespace", desired.GetNamespace())
+        *opts.drifted++
+    }
+    
+    // Check if update is needed by comparing hash or live state
+    if adopted || needsUpdate(existing, desired, opts) {
         logger.Info("Updating resource", 
             "kind", desired.GetKind(), 
             "name", desired.GetName(),
             "namespace", desired.GetNamespace())
         
-        // Preserve resource version for update
+        if debug := logger.V(1); debug.Enabled() {
+            unmanaged := opts.unmanagedFields()
+            debug.Info("Resource diff", 
+                "kind", desired.GetKind(), 
+                "name", desired.GetName(),
+                "namespace", desired.GetNamespace(),
+                "liveHash", existing.GetAnnotations()[ResourceHashAnnotation],
+                "desiredHash", desired.GetAnnotations()[ResourceHashAnnotation],
+                "changes", redactChanges(desired, diffObjects(comparableObject(existing, unmanaged), comparableObject(desired, unmanaged))))
+        }
+        
+        if err := r.ApplyRateLimits.wait(ctx, desired.GroupVersionKind()); err != nil {
+            return "", err
+        }
+
+        // Preserve resource version and ignored fields for update
         desired.SetResourceVersion(existing.GetResourceVersion())
-        return r.Update(ctx, desired)
+        preserveIgnoredFields(existing, desired, opts.ignoreFields)
+        preserveCreateOnlyFields(existing, desired, opts.createOnlyFields)
+        var err error
+        if r.Features.Enabled(features.ServerSideApply) {
+            desired.SetManagedFields(nil)
+            err = c.Patch(ctx, desired, client.Apply, client.FieldOwner(ManagedByValue), client.ForceOwnership)
+        } else {
+            err = c.Update(ctx, desired)
+        }
+        if err != nil && opts.updateStrategy == UpdateStrategyRecreate && isImmutableFieldError(err) {
+            return applyResultUpdated, r.recreateResource(ctx, c, existing, desired)
+        }
+        if err == nil && adopted {
+            r.recordEvent(desired, corev1.EventTypeNormal, ReasonResourceAdopted,
+                "Adopted existing %s %s", desired.GetKind(), desired.GetName())
+        }
+        return applyResultUpdated, err
+    }
+    
+    // Objects applied by releases that hashed spec alone only get the new hash
+    if hasLegacyHash(existing, desired, opts) {
+        logger.V(1).Info("Rewriting legacy resource hash", 
+            "kind", desired.GetKind(), 
+            "name", desired.GetName(),
+            "namespace", desired.GetNamespace())
+        patch := client.MergeFrom(existing.DeepCopy())
+        annotations := existing.GetAnnotations()
+        annotations[ResourceHashAnnotation] = desired.GetAnnotations()[ResourceHashAnnotation]
+        existing.SetAnnotations(annotations)
+        return applyResultUnchanged, c.Patch(ctx, existing, patch)
     }
     
     logger.V(1).Info("No changes needed for resource", 
         "kind", desired.GetKind(), 
         "name", desired.GetName(),
         "namespace", desired.GetNamespace())
+    return applyResultUnchanged, nil
+}
+
+// recreateResource deletes and recreates a resource whose update was rejected
+// because it changes an immutable field, with the client it was applied with.
+func (r *NamespaceClassReconciler) recreateResource(ctx context.Context, c client.Client, existing, desired *unstructured.Unstructured) error {
+    logger := log.FromContext(ctx)
+    logger.Info("Recreating resource with immutable field changes", 
+        "kind", desired.GetKind(), 
+        "name", desired.GetName(),
+        "namespace", desired.GetNamespace())
+    
+    if err := c.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+        return err
+    }
+    
+    // The old object may still be terminating, for example while its
+    // finalizers run; it is created once the old object is gone
+    desired.SetResourceVersion("")
+    if err := c.Create(ctx, desired); err != nil {
+        if errors.IsAlreadyExists(err) {
+            return &recreatePendingError{kind: desired.GetKind(), name: desired.GetName()}
+        }
+        return err
+    }
+    r.recordEvent(desired, corev1.EventTypeNormal, ReasonResourceRecreated,
+        "Recreated %s %s because an immutable field changed", desired.GetKind(), desired.GetName())
     return nil
 }
 
+// recreatePendingError reports a resource that was deleted to be recreated
+// but whose old object still exists.
+type recreatePendingError struct {
+    kind string
+    name string
+}
+
+func (e *recreatePendingError) Error() string {
+    return fmt.Sprintf("waiting for %s %s to be deleted before recreating it", e.kind, e.name)
+}
+
 func (r *NamespaceClassReconciler) deleteResource(ctx context.Context, namespace string, res ManagedResource) error {
+    _, err := r.pruneResource(ctx, namespace, res)
+    return err
+}
+
+// pruneResource deletes a managed resource like deleteResource and reports
+// whether it was actually deleted.
+func (r *NamespaceClassReconciler) pruneResource(ctx context.Context, namespace string, res ManagedResource) (bool, error) {
     obj := &unstructured.Unstructured{}
     obj.SetAPIVersion(res.APIVersion)
     obj.SetKind(res.Kind)
     obj.SetName(res.Name)
-    obj.SetNamespace(namespace)
+    obj.SetNamespace(res.objectKey(namespace).Namespace)
     
-    err := r.Delete(ctx, obj)
-    if err != nil && !errors.IsNotFound(err) {
-        return err
-    }
-    return nil
-}
-
-// Update NamespaceClass status with managed namespaces
-func (r *NamespaceClassReconciler) updateNamespaceClassStatus(ctx context.Context, nsc *v1.NamespaceClass, namespace string) error {
-    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-        // Get latest NamespaceClass
-        if err := r.Get(ctx, types.NamespacedName{Name: nsc.Name}, nsc); err != nil {
-            return err
-        }
-        
-        // Check if namespace is already in the status
-        if !containsString(nsc.Status.ManagedNamespaces, namespace) {
-            nsc.Status.ManagedNamespaces = append(nsc.Status.ManagedNamespaces, namespace)
-            nsc.Status.LastUpdateTime = metav1.Now()
-            
-            if err := r.Status().Update(ctx, nsc); err != nil {
-                return err
-            }
+    // Verify the live object is still ours before pruning it; a stale inventory
+    // entry must never delete something a user created with the same name
+    if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+        if errors.IsNotFound(err) {
+            return false, nil
         }
-        
-        return nil
-    })
+        return false, err
+    }
+    if !isManagedByController(obj) || isHNCPropagated(obj) || !ownsResource(obj, res.Class, nil) ||
+        (res.ClusterScoped && obj.GetAnnotations()[OwnerNamespaceAnnotation] != namespace) {
+        log.FromContext(ctx).Info("Refusing to delete resource not owned by the controller", 
+            "kind", res.Kind, "name", res.Name, "namespace", namespace)
+        r.recordEvent(obj, corev1.EventTypeWarning, ReasonOwnershipConflict,
+            "Refusing to delete %s %s: not owned by class %q", res.Kind, res.Name, res.Class)
+        return false, nil
+    }
+    
+    err := r.Delete(ctx, obj, client.Preconditions{UID: ptr.To(obj.GetUID())})
+    if errors.IsNotFound(err) {
+        return false, nil
+    }
+    return err == nil, err
 }
 
 // Helper function to check if a string slice contains a string
@@ -472,6 +1447,10 @@ func isTransientError(err error) bool {
     if err == nil {
         return false
     }
+    var failuresErr *applyFailuresError
+    if stderrors.As(err, &failuresErr) {
+        return failuresErr.transient()
+    }
     
     if errors.IsServerTimeout(err) || errors.IsTimeout(err) || 
        errors.IsTooManyRequests(err) || errors.IsServiceUnavailable(err) {
@@ -484,37 +1463,51 @@ func isTransientError(err error) bool {
            strings.Contains(errMsg, "i/o timeout")
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *NamespaceClassReconciler) SetupWithManager(mgr manager.Manager) error {
-    // Define predicates for namespace events
-    namespacePredicate := predicate.Funcs{
-        CreateFunc: func(e event.CreateEvent) bool {
-            // Process namespace creation
-            return true
-        },
-        UpdateFunc: func(e event.UpdateEvent) bool {
-            oldNs, ok1 := e.ObjectOld.(*corev1.Namespace)
-            newNs, ok2 := e.ObjectNew.(*corev1.Namespace)
-            
-            if !ok1 || !ok2 {
-                return false
-            }
-            
-            // Process if class label changed or finalizers changed
-            oldClass, oldHasClass := oldNs.Labels[LabelKey]
-            newClass, newHasClass := newNs.Labels[LabelKey]
-            
-            finalizersChanged := !reflect.DeepEqual(oldNs.Finalizers, newNs.Finalizers)
-            
-            return oldHasClass != newHasClass || oldClass != newClass || 
-                   finalizersChanged || !newNs.DeletionTimestamp.IsZero()
-        },
-        DeleteFunc: func(e event.DeleteEvent) bool {
-            // Ignore namespace deletion - handled by finalizers
+// namespacePredicate filters namespace events down to the changes that
+// affect how the namespace is reconciled.
+var namespacePredicate = predicate.Funcs{
+    CreateFunc: func(e event.CreateEvent) bool {
+        // Process namespace creation
+        return true
+    },
+    UpdateFunc: func(e event.UpdateEvent) bool {
+        oldNs, ok1 := e.ObjectOld.(*corev1.Namespace)
+        newNs, ok2 := e.ObjectNew.(*corev1.Namespace)
+        
+        if !ok1 || !ok2 {
             return false
-        },
+        }
+        
+        // Process if class label changed or finalizers changed
+        oldClass, oldHasClass := oldNs.Labels[LabelKey]
+        newClass, newHasClass := newNs.Labels[LabelKey]
+        
+        finalizersChanged := !reflect.DeepEqual(oldNs.Finalizers, newNs.Finalizers)
+        pinChanged := oldNs.Annotations[RevisionAnnotation] != newNs.Annotations[RevisionAnnotation]
+        pauseChanged := isPaused(oldNs) != isPaused(newNs)
+        skipChanged := isSkipped(oldNs) != isSkipped(newNs) ||
+            oldNs.Annotations[OptInAnnotation] != newNs.Annotations[OptInAnnotation]
+        addonsChanged := oldNs.Annotations[ClassesAnnotation] != newNs.Annotations[ClassesAnnotation]
+        valuesChanged := oldNs.Annotations[ValuesAnnotation] != newNs.Annotations[ValuesAnnotation]
+        ttlChanged := oldNs.Annotations[TTLAnnotation] != newNs.Annotations[TTLAnnotation]
+        
+        return oldHasClass != newHasClass || oldClass != newClass || 
+               finalizersChanged || pinChanged || pauseChanged || skipChanged || addonsChanged || valuesChanged || ttlChanged || !newNs.DeletionTimestamp.IsZero()
+    },
+    DeleteFunc: func(e event.DeleteEvent) bool {
+        // Ignore namespace deletion - handled by finalizers
+        return false
+    },
+}
+
+// SetupWithManager sets up the controller with the Manager. Namespaces are
+// indexed by the classes they use, so class changes find their namespaces
+// in the cache.
+func (r *NamespaceClassReconciler) SetupWithManager(mgr manager.Manager) error {
+    if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Namespace{}, classIndexField, indexNamespaceClasses); err != nil {
+        return err
     }
-    
+
     // Define mapping function for NamespaceClass to trigger reconcile on related Namespaces
     mapFunc := func(ctx context.Context, obj client.Object) []reconcile.Request {
         namespaceCls, ok := obj.(*v1.NamespaceClass)
@@ -522,34 +1515,137 @@ func (r *NamespaceClassReconciler) SetupWithManager(mgr manager.Manager) error {
             return nil
         }
         
-        // Find all namespaces with this class
-        var nsList corev1.NamespaceList
-        if err := mgr.GetClient().List(ctx, &nsList, client.MatchingLabels{LabelKey: namespaceCls.Name}); err != nil {
+        requests, err := classNamespaceRequests(ctx, mgr.GetClient(), namespaceCls.Name)
+        if err != nil {
             log.FromContext(ctx).Error(err, "Failed to list namespaces for class", "class", namespaceCls.Name)
             return nil
         }
-        
-        // Queue reconcile requests for all affected namespaces
+        return requests
+    }
+    
+    // Reconcile the namespaces of classes that replicate a ConfigMap when it
+    // changes, and the namespace of a ConfigMap classes read values from.
+    // Only metadata is watched; unchanged data hashes to the same copy or
+    // renders the same resources, so edits that do not touch the data
+    // change nothing.
+    configMapMapFunc := func(ctx context.Context, obj client.Object) []reconcile.Request {
+        var classes v1.NamespaceClassList
+        if err := mgr.GetClient().List(ctx, &classes); err != nil {
+            log.FromContext(ctx).Error(err, "Failed to list classes for ConfigMap", "configmap", client.ObjectKeyFromObject(obj))
+            return nil
+        }
         var requests []reconcile.Request
-        for _, ns := range nsList.Items {
-            requests = append(requests, reconcile.Request{
-                NamespacedName: types.NamespacedName{Name: ns.Name},
-            })
+        readsValues := false
+        for i := range classes.Items {
+            if replicatesConfigMap(&classes.Items[i], client.ObjectKeyFromObject(obj)) {
+                requests = append(requests, mapFunc(ctx, &classes.Items[i])...)
+            }
+            readsValues = readsValues || readsValuesConfigMap(&classes.Items[i], obj.GetName())
+        }
+        if readsValues {
+            requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}})
+        }
+        return requests
+    }
+
+    // Reconcile HNC descendants when the class label of an ancestor changes
+    descendantsMapFunc := func(ctx context.Context, obj client.Object) []reconcile.Request {
+        requests, err := hncDescendantRequests(ctx, mgr.GetClient(), obj.GetName())
+        if err != nil {
+            log.FromContext(ctx).Error(err, "Failed to list HNC descendants", "namespace", obj.GetName())
+            return nil
         }
-        
         return requests
     }
+    classLabelChanged := predicate.Funcs{
+        CreateFunc:  func(e event.CreateEvent) bool { return false },
+        DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+        GenericFunc: func(e event.GenericEvent) bool { return false },
+        UpdateFunc: func(e event.UpdateEvent) bool {
+            return e.ObjectOld.GetLabels()[LabelKey] != e.ObjectNew.GetLabels()[LabelKey]
+        },
+    }
+
+    if r.WarmUp != nil {
+        r.WarmUp.scope = r.Scope
+    }
+    if r.DrainTimeout > 0 {
+        if err := mgr.Add(&shutdownFlusher{NamespaceClassReconciler: r}); err != nil {
+            return err
+        }
+    }
+    if r.OrphanScanInterval > 0 {
+        if err := mgr.Add(&orphanScanner{
+            NamespaceClassReconciler: r,
+            reader:                   mgr.GetAPIReader(),
+            interval:                 r.OrphanScanInterval,
+            deleteOrphans:            r.DeleteOrphans,
+        }); err != nil {
+            return err
+        }
+    }
+
+    // Refresh class conditions when a class changes, even if no namespace uses it
+    if err := builder.ControllerManagedBy(mgr).
+        Named("namespaceclass-status").
+        For(&v1.NamespaceClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+        Complete(&classStatusReconciler{r}); err != nil {
+        return err
+    }
+
+    // Queue the namespaces to converge after winning the leader election
+    sweepEvents := make(chan event.GenericEvent)
+    if r.StartupSweep {
+        if err := mgr.Add(&startupSweep{
+            NamespaceClassReconciler: r,
+            reader:                   mgr.GetAPIReader(),
+            events:                   sweepEvents,
+        }); err != nil {
+            return err
+        }
+    }
+
+    // Allow parallel processing
+    concurrency := r.MaxConcurrentReconciles
+    if concurrency < 1 {
+        concurrency = 5
+    }
+
+    options := controller.Options{
+        MaxConcurrentReconciles: concurrency,
+    }
+    if r.Progress != nil {
+        options.NewQueue = r.Progress.newQueue
+    }
 
     // Set up controller with the builder pattern
-    return builder.ControllerManagedBy(mgr).
+    c, err := builder.ControllerManagedBy(mgr).
         Named("namespaceclass-controller").
-        WithOptions(controller.Options{
-            MaxConcurrentReconciles: 5, // Allow parallel processing
-        }).
+        WithOptions(options).
         For(&corev1.Namespace{}, builder.WithPredicates(namespacePredicate)).
         Watches(
             &v1.NamespaceClass{},
-            handler.EnqueueRequestsFromMapFunc(mapFunc),
+            r.Fairness.handler(handler.EnqueueRequestsFromMapFunc(mapFunc)),
+            // Status updates made while syncing namespaces must not trigger another sync
+            builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+        ).
+        Watches(
+            &corev1.Namespace{},
+            handler.EnqueueRequestsFromMapFunc(descendantsMapFunc),
+            builder.WithPredicates(classLabelChanged),
         ).
-        Complete(r)
+        Watches(
+            &corev1.ConfigMap{},
+            handler.EnqueueRequestsFromMapFunc(configMapMapFunc),
+            builder.OnlyMetadata,
+        ).
+        WatchesRawSource(source.Channel(sweepEvents, &handler.EnqueueRequestForObject{})).
+        Build(r)
+    if err != nil {
+        return err
+    }
+    if r.Features.Enabled(features.DriftWatches) {
+        r.driftWatches = &driftWatches{controller: c, cache: mgr.GetCache()}
+    }
+    return nil
 }
\ No newline at end of file
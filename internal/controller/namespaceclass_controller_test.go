@@ -8,7 +8,6 @@ import (
 
     . "github.com/onsi/ginkgo/v2"
     . "github.com/onsi/gomega"
-    
     corev1 "k8s.io/api/core/v1"
     networkingv1 "k8s.io/api/networking/v1"
     "k8s.io/apimachinery/pkg/api/errors"
@@ -20,7 +19,7 @@ import (
     logf "sigs.k8s.io/controller-runtime/pkg/log"
     "sigs.k8s.io/controller-runtime/pkg/log/zap"
     "sigs.k8s.io/controller-runtime/pkg/reconcile"
-    
+
     v1 "github.com/nickleefly/namespace-class-controller/api/v1"
 )
 
@@ -35,12 +34,10 @@ var _ = Describe("NamespaceClass controller", func() {
         logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
         ctx = context.Background()
         
-        scheme := runtime.NewScheme()
-        Expect(corev1.AddToScheme(scheme)).To(Succeed())
+        scheme := newScheme()
         Expect(networkingv1.AddToScheme(scheme)).To(Succeed())
-        Expect(v1.AddToScheme(scheme)).To(Succeed())
         
-        cl = fake.NewClientBuilder().WithScheme(scheme).Build()
+        cl = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1.NamespaceClass{}).Build()
         reconciler = &NamespaceClassReconciler{
             Client: cl,
             Scheme: scheme,
@@ -170,25 +167,28 @@ var _ = Describe("NamespaceClass controller", func() {
             Expect(ns.Finalizers).NotTo(ContainElement(NamespaceFinalizer))
             
             // Test namespace deletion
-            ns.Labels[LabelKey] = "test-class" // Re-add label
+            ns.Labels = map[string]string{LabelKey: "test-class"} // Re-add label
             Expect(cl.Update(ctx, ns)).To(Succeed())
             
-            // Reconcile to add resources back
+            // Reconcile to add the finalizer and resources back
+            res, err = reconciler.Reconcile(ctx, req)
+            Expect(err).NotTo(HaveOccurred())
+            Expect(res).To(Equal(reconcile.Result{Requeue: true}))
             res, err = reconciler.Reconcile(ctx, req)
             Expect(err).NotTo(HaveOccurred())
+            Expect(cl.Get(ctx, types.NamespacedName{Namespace: "test-namespace", Name: "test-policy"}, netPolicy)).To(Succeed())
             
             // Mark namespace for deletion
-            now := metav1.Now()
-            ns.DeletionTimestamp = &now
-            Expect(cl.Update(ctx, ns)).To(Succeed())
+            Expect(cl.Get(ctx, types.NamespacedName{Name: "test-namespace"}, ns)).To(Succeed())
+            Expect(cl.Delete(ctx, ns)).To(Succeed())
             
             // Reconcile to handle deletion
             res, err = reconciler.Reconcile(ctx, req)
             Expect(err).NotTo(HaveOccurred())
             
-            // Verify resources are deleted and finalizer is removed
-            Expect(cl.Get(ctx, types.NamespacedName{Name: "test-namespace"}, ns)).To(Succeed())
-            Expect(ns.Finalizers).NotTo(ContainElement(NamespaceFinalizer))
+            // Verify resources are deleted and the finalizer released the namespace
+            Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "test-namespace", Name: "test-policy"}, netPolicy))).To(BeTrue())
+            Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "test-namespace"}, ns))).To(BeTrue())
         })
     })
 })
@@ -220,4 +220,4 @@ func createNetworkPolicyRaw(name, cidr string) runtime.RawExtension {
     
     raw, _ := json.Marshal(policy)
     return runtime.RawExtension{Raw: raw}
-}
\ No newline at end of file
+}
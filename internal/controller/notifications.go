@@ -0,0 +1,125 @@
+// internal/controller/notifications.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    "github.com/nickleefly/namespace-class-controller/internal/notify"
+)
+
+const (
+    // Consecutive failed reconciles of a namespace before a failure is notified
+    defaultFailureThreshold = 3
+
+    // How long a single notification may take
+    notifyTimeout = 10 * time.Second
+)
+
+// Notifications sends sync events to an external notifier. Failures are only
+// sent once a namespace failed FailureThreshold times in a row, and drift
+// only when more resources drifted than at the previous reconcile, so a
+// persistent problem is reported once rather than on every retry.
+type Notifications struct {
+    Notifier notify.Notifier
+
+    // FailureThreshold is the number of consecutive failures before notifying; defaults to 3
+    FailureThreshold int
+
+    mu       sync.Mutex
+    failures map[string]int
+    drifted  map[string]int
+}
+
+// onboarded notifies that a namespace was provisioned with a class it did not use before.
+func (n *Notifications) onboarded(ctx context.Context, namespace, className string, resources int) {
+    if n == nil {
+        return
+    }
+    n.send(ctx, notify.Event{
+        Type:      notify.EventOnboarded,
+        Namespace: namespace,
+        Class:     className,
+        Message:   fmt.Sprintf("Applied %d resources", resources),
+    })
+}
+
+// synced records a successful reconcile and notifies new drift.
+func (n *Notifications) synced(ctx context.Context, namespace, className string, drifted int) {
+    if n == nil {
+        return
+    }
+    n.mu.Lock()
+    if n.drifted == nil {
+        n.drifted = make(map[string]int)
+    }
+    previous := n.drifted[namespace]
+    n.drifted[namespace] = drifted
+    delete(n.failures, namespace)
+    n.mu.Unlock()
+
+    if drifted > previous {
+        n.send(ctx, notify.Event{
+            Type:      notify.EventDrifted,
+            Namespace: namespace,
+            Class:     className,
+            Message:   fmt.Sprintf("%d resources were changed outside the controller", drifted),
+        })
+    }
+}
+
+// failed records a failed reconcile and notifies once the threshold is reached.
+func (n *Notifications) failed(ctx context.Context, namespace, className string, err error) {
+    if n == nil {
+        return
+    }
+    threshold := n.FailureThreshold
+    if threshold <= 0 {
+        threshold = defaultFailureThreshold
+    }
+    n.mu.Lock()
+    if n.failures == nil {
+        n.failures = make(map[string]int)
+    }
+    n.failures[namespace]++
+    count := n.failures[namespace]
+    n.mu.Unlock()
+
+    if count == threshold {
+        n.send(ctx, notify.Event{
+            Type:      notify.EventSyncFailed,
+            Namespace: namespace,
+            Class:     className,
+            Message:   fmt.Sprintf("Failed %d times in a row: %v", count, err),
+        })
+    }
+}
+
+// forget drops the state of a namespace that left its class or was deleted.
+func (n *Notifications) forget(namespace string) {
+    if n == nil {
+        return
+    }
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    delete(n.failures, namespace)
+    delete(n.drifted, namespace)
+}
+
+// send delivers an event in the background so a slow endpoint does not hold
+// up reconciles. Delivery errors are logged.
+func (n *Notifications) send(ctx context.Context, event notify.Event) {
+    logger := log.FromContext(ctx)
+    event.Time = time.Now()
+    go func() {
+        ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+        defer cancel()
+        if err := n.Notifier.Notify(ctx, event); err != nil {
+            logger.Error(err, "Failed to send notification", "type", event.Type, "namespace", event.Namespace)
+        }
+    }()
+}
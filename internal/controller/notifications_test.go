@@ -0,0 +1,63 @@
+// internal/controller/notifications_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+
+    "github.com/nickleefly/namespace-class-controller/internal/notify"
+)
+
+// recordingNotifier collects notifications sent in the background.
+type recordingNotifier struct {
+    mu     sync.Mutex
+    events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event notify.Event) error {
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    n.events = append(n.events, event)
+    return nil
+}
+
+func (n *recordingNotifier) types() []notify.EventType {
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    var types []notify.EventType
+    for _, event := range n.events {
+        types = append(types, event.Type)
+    }
+    return types
+}
+
+var _ = Describe("Notifications", func() {
+    It("should notify persistent failures and new drift once", func() {
+        ctx := context.Background()
+        recorder := &recordingNotifier{}
+        notifications := &Notifications{Notifier: recorder, FailureThreshold: 2}
+
+        notifications.onboarded(ctx, "web", "public", 3)
+        Eventually(recorder.types).Should(HaveLen(1))
+        notifications.failed(ctx, "web", "public", fmt.Errorf("quota exceeded"))
+        Consistently(recorder.types, 100*time.Millisecond).Should(HaveLen(1))
+        notifications.failed(ctx, "web", "public", fmt.Errorf("quota exceeded"))
+        notifications.failed(ctx, "web", "public", fmt.Errorf("quota exceeded"))
+        Eventually(recorder.types).Should(Equal([]notify.EventType{notify.EventOnboarded, notify.EventSyncFailed}))
+
+        notifications.synced(ctx, "web", "public", 1)
+        notifications.synced(ctx, "web", "public", 1)
+        Eventually(recorder.types).Should(HaveLen(3))
+        Consistently(recorder.types, 100*time.Millisecond).Should(HaveLen(3))
+        Expect(recorder.types()[2]).To(Equal(notify.EventDrifted))
+
+        // A success resets the failure count
+        notifications.failed(ctx, "web", "public", fmt.Errorf("quota exceeded"))
+        Consistently(recorder.types, 100*time.Millisecond).Should(HaveLen(3))
+    })
+})
@@ -0,0 +1,231 @@
+// internal/controller/orphans.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/apimachinery/pkg/util/wait"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// orphanScanner periodically looks for objects that carry the managed-by
+// annotation but are not tracked by the inventory of their namespace, e.g.
+// because the namespace left its class while a prune failed or while the
+// controller was down. Such objects are reported and, when deleteOrphans is
+// set, garbage collected. Objects are listed as metadata through the
+// uncached reader to avoid starting an informer for every managed kind.
+type orphanScanner struct {
+    *NamespaceClassReconciler
+
+    // reader lists objects directly from the API server
+    reader   client.Reader
+    interval time.Duration
+
+    // deleteOrphans deletes orphaned objects instead of only counting them
+    deleteOrphans bool
+
+    // previous are the orphaned objects found by the last scan, by UID
+    previous map[types.UID]bool
+}
+
+// orphanedObject is a managed object that no inventory tracks.
+type orphanedObject struct {
+    gvk       schema.GroupVersionKind
+    namespace string
+    name      string
+    class     string
+    uid       types.UID
+
+    // retained is set on objects kept by data protection, which are never deleted
+    retained bool
+}
+
+// Start runs the scan until the context is cancelled.
+func (s *orphanScanner) Start(ctx context.Context) error {
+    logger := log.FromContext(ctx).WithName("orphan-scanner")
+    wait.UntilWithContext(ctx, func(ctx context.Context) {
+        if err := s.scan(ctx); err != nil {
+            logger.Error(err, "Failed to scan for orphaned resources")
+        }
+    }, s.interval)
+    return nil
+}
+
+// scan finds the orphaned objects of every kind a class or inventory refers
+// to, deletes them if enabled, and replaces the orphaned resources gauge
+// with those left.
+func (s *orphanScanner) scan(ctx context.Context) error {
+    tracked := make(map[string]bool)
+    kinds := make(map[schema.GroupVersionKind]bool)
+
+    var nsList corev1.NamespaceList
+    err := ListPages(ctx, s.reader, &nsList, func() error {
+        for i := range nsList.Items {
+            ns := &nsList.Items[i]
+            managed, err := s.getManagedResources(ctx, ns)
+            if err != nil {
+                return err
+            }
+            for _, res := range managed {
+                tracked[ns.Name+"/"+res.key()] = true
+                kinds[res.groupVersionKind()] = true
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return err
+    }
+
+    var classes v1.NamespaceClassList
+    if err := s.List(ctx, &classes); err != nil {
+        return err
+    }
+    for _, nsc := range classes.Items {
+        resources, err := s.parseResources(ctx, &nsc)
+        if err != nil {
+            continue
+        }
+        for _, res := range resources {
+            kinds[res.GroupVersionKind()] = true
+        }
+    }
+
+    var orphans []orphanedObject
+    for gvk := range kinds {
+        if err := s.scanKind(ctx, gvk, tracked, &orphans); err != nil {
+            return err
+        }
+    }
+    if s.deleteOrphans {
+        orphans = s.collectGarbage(ctx, orphans)
+    }
+
+    counts := make(map[[2]string]int)
+    for _, orphan := range orphans {
+        counts[[2]string{orphan.namespace, classLabel(orphan.class)}]++
+    }
+    orphanedResources.Reset()
+    for key, count := range counts {
+        orphanedResources.WithLabelValues(key[0], key[1]).Set(float64(count))
+    }
+    log.FromContext(ctx).V(1).Info("Scanned for orphaned resources", "kinds", len(kinds), "orphaned", len(orphans))
+    return nil
+}
+
+// scanKind adds the orphaned objects of one kind in the namespaces the
+// controller manages to orphans.
+func (s *orphanScanner) scanKind(ctx context.Context, gvk schema.GroupVersionKind, tracked map[string]bool, orphans *[]orphanedObject) error {
+    for _, namespace := range s.Scope.listNamespaces() {
+        if err := s.scanKindIn(ctx, gvk, namespace, tracked, orphans); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// scanKindIn adds the orphaned objects of one kind in a namespace to
+// orphans; the empty namespace scans all namespaces.
+func (s *orphanScanner) scanKindIn(ctx context.Context, gvk schema.GroupVersionKind, namespace string, tracked map[string]bool, orphans *[]orphanedObject) error {
+    list := &metav1.PartialObjectMetadataList{}
+    list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+    err := ListPages(ctx, s.reader, list, func() error {
+        for i := range list.Items {
+            obj := &list.Items[i]
+            if obj.Namespace == "" || obj.Annotations[ManagedByAnnotation] != ManagedByValue || isHNCPropagated(obj) {
+                continue
+            }
+            key := fmt.Sprintf("%s/%s/%s/%s", obj.Namespace, gvk.GroupVersion().String(), gvk.Kind, obj.Name)
+            if !tracked[key] {
+                *orphans = append(*orphans, orphanedObject{
+                    gvk:       gvk,
+                    namespace: obj.Namespace,
+                    name:      obj.Name,
+                    class:     obj.Annotations[CreatedByClassAnnotation],
+                    uid:       obj.UID,
+                    retained:  obj.Annotations[OrphanedAnnotation] != "",
+                })
+            }
+        }
+        return nil
+    }, client.InNamespace(namespace))
+    if meta.IsNoMatchError(err) {
+        // The kind is no longer served, so nothing of it can be left
+        return nil
+    }
+    return err
+}
+
+// collectGarbage deletes the orphaned objects that were already orphaned at
+// the previous scan, so objects whose inventory entry is about to be written
+// are not mistaken for orphans. Objects kept by data protection, objects in
+// namespaces the controller does not manage and objects of a class their
+// namespace still uses, which its next reconcile tracks again, are left
+// alone. It returns the orphaned objects that were not deleted.
+func (s *orphanScanner) collectGarbage(ctx context.Context, orphans []orphanedObject) []orphanedObject {
+    logger := log.FromContext(ctx)
+    previous := s.previous
+    s.previous = make(map[types.UID]bool, len(orphans))
+
+    var left []orphanedObject
+    for _, orphan := range orphans {
+        s.previous[orphan.uid] = true
+        if !previous[orphan.uid] || orphan.retained {
+            left = append(left, orphan)
+            continue
+        }
+        deleted, err := s.deleteOrphan(ctx, orphan)
+        if err != nil {
+            logger.Error(err, "Failed to delete orphaned resource",
+                "namespace", orphan.namespace, "kind", orphan.gvk.Kind, "name", orphan.name)
+        }
+        if !deleted {
+            left = append(left, orphan)
+        }
+    }
+    return left
+}
+
+// deleteOrphan deletes an orphaned object unless its namespace is not
+// managed or still uses the class that created it, and reports whether it
+// was deleted.
+func (s *orphanScanner) deleteOrphan(ctx context.Context, orphan orphanedObject) (bool, error) {
+    ns := &corev1.Namespace{}
+    if err := s.Get(ctx, types.NamespacedName{Name: orphan.namespace}, ns); err != nil {
+        return false, client.IgnoreNotFound(err)
+    }
+    if !ns.DeletionTimestamp.IsZero() || !s.Scope.includes(ns.Name) || !s.Scope.admits(ns) || isSkipped(ns) || isPaused(ns) {
+        return false, nil
+    }
+    className, _, err := s.resolveClass(ctx, ns)
+    if err != nil {
+        return false, err
+    }
+    if orphan.class == className || containsString(addonClassNames(ns, className), orphan.class) {
+        return false, nil
+    }
+
+    obj := &metav1.PartialObjectMetadata{}
+    obj.SetGroupVersionKind(orphan.gvk)
+    obj.SetNamespace(orphan.namespace)
+    obj.SetName(orphan.name)
+    if err := s.Delete(ctx, obj, client.Preconditions{UID: &orphan.uid}); err != nil {
+        return false, client.IgnoreNotFound(err)
+    }
+    log.FromContext(ctx).Info("Deleted orphaned resource",
+        "namespace", orphan.namespace, "kind", orphan.gvk.Kind, "name", orphan.name, "class", orphan.class)
+    recordResourceOperation(orphan.class, orphan.gvk, operationDeleted)
+    s.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonOrphanDeleted,
+        "Deleted %s %s of class %s, which no inventory tracks", orphan.gvk.Kind, orphan.name, orphan.class)
+    return true, nil
+}
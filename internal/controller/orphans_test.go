@@ -0,0 +1,136 @@
+// internal/controller/orphans_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Drift and orphans", func() {
+    It("should only report live changes the class did not ask for as drift", func() {
+        desired := &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": "v1",
+            "kind":       "ConfigMap",
+            "metadata": map[string]interface{}{
+                "name":        "settings",
+                "annotations": map[string]interface{}{ManagedByAnnotation: ManagedByValue},
+            },
+            "data": map[string]interface{}{"mode": "strict"},
+        }}
+        Expect(unstructured.SetNestedField(desired.Object, calculateResourceHash(desired, nil, v1.HashAlgorithmSHA256),
+            "metadata", "annotations", ResourceHashAnnotation)).To(Succeed())
+
+        existing := desired.DeepCopy()
+        Expect(isDrifted(existing, desired, applyOptions{})).To(BeFalse())
+
+        Expect(unstructured.SetNestedField(existing.Object, "relaxed", "data", "mode")).To(Succeed())
+        Expect(isDrifted(existing, desired, applyOptions{})).To(BeTrue())
+
+        // A class change is a rollout, not drift
+        Expect(unstructured.SetNestedField(existing.Object, "stale", "metadata", "annotations", ResourceHashAnnotation)).To(Succeed())
+        Expect(isDrifted(existing, desired, applyOptions{})).To(BeFalse())
+    })
+
+    It("should count managed objects no inventory tracks", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        managedConfigMap := func(name, class string) *corev1.ConfigMap {
+            return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+                Name:      name,
+                Namespace: "web",
+                Annotations: map[string]string{
+                    ManagedByAnnotation:      ManagedByValue,
+                    CreatedByClassAnnotation: class,
+                },
+            }}
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+            &v1.NamespaceClassInventory{
+                ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}},
+                Spec: v1.NamespaceClassInventorySpec{
+                    Namespace:     "web",
+                    SchemaVersion: InventorySchemaVersion,
+                    Resources:     []v1.InventoryEntry{{APIVersion: "v1", Kind: "ConfigMap", Name: "tracked", Class: "public"}},
+                },
+            },
+            managedConfigMap("tracked", "public"),
+            managedConfigMap("left-behind", "internal"),
+            &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "web"}},
+        ).Build()
+        scanner := &orphanScanner{
+            NamespaceClassReconciler: &NamespaceClassReconciler{Client: cl, Scheme: scheme},
+            reader:                   cl,
+        }
+
+        Expect(scanner.scan(ctx)).To(Succeed())
+        Expect(testutil.ToFloat64(orphanedResources.WithLabelValues("web", "internal"))).To(Equal(1.0))
+        Expect(testutil.CollectAndCount(orphanedResources)).To(Equal(1))
+    })
+
+    It("should delete objects orphaned at two scans in a row", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        managedConfigMap := func(name, class string, annotations ...string) *corev1.ConfigMap {
+            cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+                Name:      name,
+                Namespace: "web",
+                UID:       types.UID(name),
+                Annotations: map[string]string{
+                    ManagedByAnnotation:      ManagedByValue,
+                    CreatedByClassAnnotation: class,
+                },
+            }}
+            for i := 0; i < len(annotations); i += 2 {
+                cm.Annotations[annotations[i]] = annotations[i+1]
+            }
+            return cm
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}}},
+            &v1.NamespaceClassInventory{
+                ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}},
+                Spec: v1.NamespaceClassInventorySpec{
+                    Namespace:     "web",
+                    SchemaVersion: InventorySchemaVersion,
+                    Resources:     []v1.InventoryEntry{{APIVersion: "v1", Kind: "ConfigMap", Name: "tracked", Class: "public"}},
+                },
+            },
+            managedConfigMap("tracked", "public"),
+            managedConfigMap("left-behind", "internal"),
+            managedConfigMap("untracked", "public"),
+            managedConfigMap("kept", "internal", OrphanedAnnotation, "PersistentVolumeClaim is bound"),
+        ).Build()
+        scanner := &orphanScanner{
+            NamespaceClassReconciler: &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: record.NewFakeRecorder(10)},
+            reader:                   cl,
+            deleteOrphans:            true,
+        }
+        leftBehind := types.NamespacedName{Namespace: "web", Name: "left-behind"}
+
+        // A first sighting may be an inventory entry that is about to be written
+        Expect(scanner.scan(ctx)).To(Succeed())
+        Expect(cl.Get(ctx, leftBehind, &corev1.ConfigMap{})).To(Succeed())
+
+        Expect(scanner.scan(ctx)).To(Succeed())
+        Expect(errors.IsNotFound(cl.Get(ctx, leftBehind, &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(testutil.ToFloat64(orphanedResources.WithLabelValues("web", "internal"))).To(Equal(1.0))
+        Expect(testutil.ToFloat64(orphanedResources.WithLabelValues("web", "public"))).To(Equal(1.0))
+
+        // Retained data and resources of the class the namespace still uses are left alone
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "web", Name: "kept"}, &corev1.ConfigMap{})).To(Succeed())
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "web", Name: "untracked"}, &corev1.ConfigMap{})).To(Succeed())
+    })
+})
@@ -0,0 +1,40 @@
+// internal/controller/pagination.go
+package controller
+
+import (
+    "context"
+
+    "k8s.io/apimachinery/pkg/api/meta"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Page size used when listing objects directly from the API server
+const ListPageSize = 500
+
+// ListPages lists objects in pages of ListPageSize and calls fn after each
+// page is read into list, so that full scans of large clusters never hold
+// all objects in memory at once and no single request runs long enough to
+// time out. Each page is read into a fresh slice of items.
+//
+// The reader must read from the API server: the cache does not support
+// continuing a list and would return only the first page.
+func ListPages(ctx context.Context, c client.Reader, list client.ObjectList, fn func() error, opts ...client.ListOption) error {
+    first := append(opts[:len(opts):len(opts)], client.Limit(ListPageSize))
+    page := first
+    for {
+        if err := meta.SetList(list, nil); err != nil {
+            return err
+        }
+        if err := c.List(ctx, list, page...); err != nil {
+            return err
+        }
+        if err := fn(); err != nil {
+            return err
+        }
+        next := list.GetContinue()
+        if next == "" {
+            return nil
+        }
+        page = append(first[:len(first):len(first)], client.Continue(next))
+    }
+}
@@ -0,0 +1,51 @@
+// internal/controller/pagination_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Paginated listing", func() {
+    It("should read every page of a list", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        pages := map[string][]string{"": {"a", "b"}, "page-2": {"c"}}
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+            List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+                listOpts := &client.ListOptions{}
+                listOpts.ApplyOptions(opts)
+                Expect(listOpts.Limit).To(Equal(int64(ListPageSize)))
+                nsList := list.(*corev1.NamespaceList)
+                Expect(nsList.Items).To(BeEmpty())
+                for _, name := range pages[listOpts.Continue] {
+                    nsList.Items = append(nsList.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+                }
+                if listOpts.Continue == "" {
+                    nsList.Continue = "page-2"
+                } else {
+                    nsList.Continue = ""
+                }
+                return nil
+            },
+        }).Build()
+
+        var names []string
+        var nsList corev1.NamespaceList
+        Expect(ListPages(ctx, cl, &nsList, func() error {
+            for _, ns := range nsList.Items {
+                names = append(names, ns.Name)
+            }
+            return nil
+        })).To(Succeed())
+        Expect(names).To(Equal([]string{"a", "b", "c"}))
+    })
+})
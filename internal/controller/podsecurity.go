@@ -0,0 +1,41 @@
+// internal/controller/podsecurity.go
+package controller
+
+import (
+    "regexp"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Prefix of the labels Pod Security Admission reads from namespaces
+const podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+
+// podSecurityVersion matches the versions Pod Security Admission accepts
+var podSecurityVersion = regexp.MustCompile(`^(latest|v1\.[0-9]+)$`)
+
+// podSecurityLabels returns the Pod Security Admission labels for the levels
+// of a class, e.g. pod-security.kubernetes.io/enforce: restricted.
+func podSecurityLabels(ps *v1.PodSecurity) map[string]string {
+    if ps == nil {
+        return nil
+    }
+    labels := make(map[string]string)
+    for _, mode := range []struct {
+        name    string
+        level   v1.PodSecurityLevel
+        version string
+    }{
+        {"enforce", ps.Enforce, ps.EnforceVersion},
+        {"audit", ps.Audit, ps.AuditVersion},
+        {"warn", ps.Warn, ps.WarnVersion},
+    } {
+        if mode.level == "" {
+            continue
+        }
+        labels[podSecurityLabelPrefix+mode.name] = string(mode.level)
+        if mode.version != "" {
+            labels[podSecurityLabelPrefix+mode.name+"-version"] = mode.version
+        }
+    }
+    return labels
+}
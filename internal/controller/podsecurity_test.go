@@ -0,0 +1,73 @@
+// internal/controller/podsecurity_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Pod Security", func() {
+    It("should label namespaces with the Pod Security levels of the class", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+            Spec: v1.NamespaceClassSpec{PodSecurity: &v1.PodSecurity{
+                Enforce:        v1.PodSecurityRestricted,
+                EnforceVersion: "v1.30",
+                Warn:           v1.PodSecurityBaseline,
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "restricted"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{
+            LabelKey:                                     "restricted",
+            "pod-security.kubernetes.io/enforce":         "restricted",
+            "pod-security.kubernetes.io/enforce-version": "v1.30",
+            "pod-security.kubernetes.io/warn":            "baseline",
+        }))
+    })
+
+    It("should reject unknown levels, versions and conflicting labels", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+            Spec: v1.NamespaceClassSpec{
+                PodSecurity: &v1.PodSecurity{Enforce: "strict", Warn: v1.PodSecurityBaseline, WarnVersion: "1.30"},
+                NamespaceMetadata: &v1.NamespaceMetadata{
+                    Labels: map[string]string{"pod-security.kubernetes.io/warn": "restricted"},
+                },
+            },
+        }
+        var fields []string
+        for _, err := range ValidateClass(nsc) {
+            fields = append(fields, err.Field)
+        }
+        Expect(fields).To(ConsistOf(
+            "spec.podSecurity.enforce",
+            "spec.podSecurity.warnVersion",
+            "spec.namespaceMetadata.labels[pod-security.kubernetes.io/warn]",
+        ))
+    })
+})
@@ -0,0 +1,161 @@
+// internal/controller/preflight.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    authorizationv1 "k8s.io/api/authorization/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Verbs needed on every kind of a class to apply it
+var applyVerbs = []string{"get", "create", "update"}
+
+// How long an allowed access review is trusted before it is asked again
+const accessReviewTTL = 5 * time.Minute
+
+// PermissionPreflight checks that every resource of the classes of a
+// namespace may be applied before any of them is, so that missing
+// permissions are reported up front instead of leaving the namespace half
+// configured. Allowed reviews are remembered for a while so that syncs do
+// not review the same kinds over and over.
+type PermissionPreflight struct {
+    mu      sync.Mutex
+    allowed map[accessKey]time.Time
+}
+
+// accessKey identifies an access review: a verb on a resource in a namespace,
+// or at cluster scope for an empty namespace, by the controller or the
+// ServiceAccount of a class.
+type accessKey struct {
+    serviceAccount string
+    namespace      string
+    resource       schema.GroupResource
+    verb           string
+}
+
+// isAllowed reports whether an access review was allowed recently.
+func (p *PermissionPreflight) isAllowed(key accessKey) bool {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return time.Now().Before(p.allowed[key])
+}
+
+// remember records that an access review was allowed.
+func (p *PermissionPreflight) remember(key accessKey) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.allowed == nil {
+        p.allowed = make(map[accessKey]time.Time)
+    }
+    p.allowed[key] = time.Now().Add(accessReviewTTL)
+}
+
+// missingPermissionsError lists the permissions missing to apply the
+// resources of the classes of a namespace.
+type missingPermissionsError struct {
+    missing []string
+}
+
+func (e *missingPermissionsError) Error() string {
+    return fmt.Sprintf("missing permissions to apply class resources: %s", strings.Join(e.missing, "; "))
+}
+
+// checkPermissions reviews with SelfSubjectAccessReviews, made as whoever
+// applies them, that the resources of the classes of a namespace may be
+// read, created and updated. Kinds the cluster does not serve yet are left
+// to the apply. Missing permissions are returned as a
+// missingPermissionsError. A nil preflight checks nothing.
+func (r *NamespaceClassReconciler) checkPermissions(ctx context.Context, ns *corev1.Namespace, resources []*unstructured.Unstructured, sources resourceClasses, primary *v1.NamespaceClass) error {
+    if r.Preflight == nil {
+        return nil
+    }
+    seen := make(map[accessKey]bool)
+    var missing []string
+    for _, res := range resources {
+        gvk := res.GroupVersionKind()
+        mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+        if meta.IsNoMatchError(err) {
+            continue
+        } else if err != nil {
+            return err
+        }
+        source := sources.of(res, primary)
+        c, err := r.applyClient(source)
+        if err != nil {
+            return err
+        }
+
+        key := accessKey{serviceAccount: source.Spec.ServiceAccountName, resource: mapping.Resource.GroupResource()}
+        if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+            key.namespace = ns.Name
+        }
+        for _, verb := range applyVerbs {
+            key.verb = verb
+            if seen[key] || r.Preflight.isAllowed(key) {
+                continue
+            }
+            seen[key] = true
+            review := &authorizationv1.SelfSubjectAccessReview{
+                Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+                    ResourceAttributes: &authorizationv1.ResourceAttributes{
+                        Namespace: key.namespace,
+                        Verb:      verb,
+                        Group:     key.resource.Group,
+                        Resource:  key.resource.Resource,
+                    },
+                },
+            }
+            if err := c.Create(ctx, review); err != nil {
+                return err
+            }
+            if review.Status.Allowed {
+                r.Preflight.remember(key)
+                continue
+            }
+            missing = append(missing, describeAccess(key))
+        }
+    }
+    if len(missing) > 0 {
+        sort.Strings(missing)
+        return &missingPermissionsError{missing: missing}
+    }
+    return nil
+}
+
+// describeAccess names a denied access review for messages.
+func describeAccess(key accessKey) string {
+    description := fmt.Sprintf("cannot %s %s", key.verb, key.resource)
+    if key.namespace == "" {
+        description += " at cluster scope"
+    }
+    if key.serviceAccount != "" {
+        description += fmt.Sprintf(" as ServiceAccount %s", key.serviceAccount)
+    }
+    return description
+}
+
+// reportMissingPermissions records that the resources of the classes of a
+// namespace cannot be applied for lack of permissions, with a warning event
+// and a Degraded condition on the inventory of the namespace.
+func (r *NamespaceClassReconciler) reportMissingPermissions(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, permissionErr *missingPermissionsError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonPermissionDenied,
+        "Not applying any resources: %s", strings.Join(permissionErr.missing, "; "))
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonPermissionDenied,
+        Message: permissionErr.Error(),
+    })
+}
@@ -0,0 +1,81 @@
+// internal/controller/preflight_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    authorizationv1 "k8s.io/api/authorization/v1"
+    corev1 "k8s.io/api/core/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Permission preflight", func() {
+    It("should apply nothing while permissions to apply the class are missing", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+        Expect(authorizationv1.AddToScheme(scheme)).To(Succeed())
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}}`)},
+                {Raw: []byte(`{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "RoleBinding", "metadata": {"name": "admins"},
+                    "roleRef": {"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": "admin"}}`)},
+            }},
+        }
+        mapper := meta.NewDefaultRESTMapper(nil)
+        mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+        mapper.Add(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), meta.RESTScopeNamespace)
+        denied := map[string]bool{"create rolebindings": true}
+        var reviews int
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team-a",
+                Labels:     map[string]string{LabelKey: "team"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            // The fake client has no authorizer; answer reviews from the denied list
+            Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+                if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+                    reviews++
+                    attributes := review.Spec.ResourceAttributes
+                    review.Status.Allowed = !denied[attributes.Verb+" "+attributes.Resource]
+                    return nil
+                }
+                return c.Create(ctx, obj, opts...)
+            },
+        }).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Preflight: &PermissionPreflight{}}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).To(MatchError("missing permissions to apply class resources: cannot create rolebindings.rbac.authorization.k8s.io"))
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "settings"}, &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Reason).To(Equal(v1.ReasonPermissionDenied))
+        Expect(meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDegraded).Reason).To(Equal(v1.ReasonPermissionDenied))
+
+        // Once granted, allowed reviews are not asked again
+        delete(denied, "create rolebindings")
+        reviews = 0
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(reviews).To(Equal(1))
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "settings"}, &corev1.ConfigMap{})).To(Succeed())
+    })
+})
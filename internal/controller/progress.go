@@ -0,0 +1,98 @@
+// internal/controller/progress.go
+package controller
+
+import (
+    "fmt"
+    "net/http"
+    "sync"
+    "time"
+
+    "k8s.io/client-go/util/workqueue"
+    "sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// ReconcileProgress detects a namespace reconciler that stopped making
+// progress, e.g. because its workers are deadlocked or stuck on a request
+// that never returns. The controller counts as stalled when a reconcile has
+// been running for longer than StallTimeout, or when namespaces have been
+// waiting in the queue for that long without any reconcile starting or
+// finishing. An idle controller with an empty queue is never stalled. The
+// queue is sampled by the checks, so waiting is measured from the first
+// check that found it non-empty.
+type ReconcileProgress struct {
+    // StallTimeout is how long the controller may go without progress
+    StallTimeout time.Duration
+
+    mu           sync.Mutex
+    queue        workqueue.RateLimitingInterface
+    running      map[string]time.Time
+    lastProgress time.Time
+    waiting      time.Time
+}
+
+// newQueue creates the work queue of the controller and keeps it to check
+// for waiting namespaces.
+func (p *ReconcileProgress) newQueue(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+    q := workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{Name: controllerName})
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.queue = q
+    return q
+}
+
+// track records the start of the reconcile of a namespace and returns the
+// function recording its end. A nil progress tracks nothing.
+func (p *ReconcileProgress) track(namespace string) func() {
+    if p == nil {
+        return func() {}
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.running == nil {
+        p.running = make(map[string]time.Time)
+    }
+    p.running[namespace] = time.Now()
+    p.lastProgress = time.Now()
+    return func() {
+        p.mu.Lock()
+        defer p.mu.Unlock()
+        delete(p.running, namespace)
+        p.lastProgress = time.Now()
+    }
+}
+
+// Check is a health check failing while the controller is stalled.
+func (p *ReconcileProgress) Check(_ *http.Request) error {
+    if p == nil || p.StallTimeout <= 0 {
+        return nil
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    now := time.Now()
+    for namespace, started := range p.running {
+        if running := now.Sub(started); running > p.StallTimeout {
+            return fmt.Errorf("reconcile of namespace %s has been running for %s", namespace, running.Round(time.Second))
+        }
+    }
+    if p.queue == nil {
+        return nil
+    }
+    queued := p.queue.Len()
+    if queued == 0 {
+        p.waiting = time.Time{}
+        return nil
+    }
+    if p.waiting.IsZero() {
+        p.waiting = now
+    }
+    since := p.waiting
+    if p.lastProgress.After(since) {
+        since = p.lastProgress
+    }
+    if stalled := now.Sub(since); stalled > p.StallTimeout {
+        return fmt.Errorf("%d namespaces are queued but no reconcile started or finished for %s", queued, stalled.Round(time.Second))
+    }
+    return nil
+}
@@ -0,0 +1,40 @@
+// internal/controller/progress_test.go
+package controller
+
+import (
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/workqueue"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Reconcile progress", func() {
+    It("should report a stalled controller only while work is waiting", func() {
+        progress := &ReconcileProgress{StallTimeout: 50 * time.Millisecond}
+        q := progress.newQueue("test", workqueue.DefaultControllerRateLimiter())
+        defer q.ShutDown()
+        Expect(progress.Check(nil)).To(Succeed())
+
+        // An idle controller is healthy however long it has been idle
+        time.Sleep(100 * time.Millisecond)
+        Expect(progress.Check(nil)).To(Succeed())
+
+        q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}})
+        Expect(progress.Check(nil)).To(Succeed())
+        time.Sleep(100 * time.Millisecond)
+        Expect(progress.Check(nil)).To(MatchError(ContainSubstring("1 namespaces are queued")))
+
+        done := progress.track("web")
+        Expect(progress.Check(nil)).To(Succeed())
+        done()
+        Expect(progress.Check(nil)).To(Succeed())
+
+        // A reconcile that never returns is reported
+        progress.track("api")
+        time.Sleep(100 * time.Millisecond)
+        Expect(progress.Check(nil)).To(MatchError(ContainSubstring("namespace api")))
+    })
+})
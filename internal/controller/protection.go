@@ -0,0 +1,102 @@
+// internal/controller/protection.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Annotation set on stateful resources that were kept instead of pruned when
+// their namespace left the class that created them
+const OrphanedAnnotation = "namespaceclass.akuity.io/orphaned"
+
+// DataProtectionPolicy keeps resources that hold data from being pruned when
+// a namespace switches classes or loses its class label. Bound
+// PersistentVolumeClaims and Secrets with data are always protected.
+// Protected resources are dropped from the inventory and annotated instead,
+// so they show up as orphaned until someone deletes them.
+type DataProtectionPolicy struct {
+    // ProtectedKinds are kept whatever they contain, given as Kind or
+    // Kind.group, e.g. "Certificate.cert-manager.io"
+    ProtectedKinds []string
+}
+
+// protects describes why an object must not be pruned, or returns an empty
+// string if it may be.
+func (p *DataProtectionPolicy) protects(obj *unstructured.Unstructured) string {
+    if p == nil {
+        return ""
+    }
+    gk := obj.GroupVersionKind().GroupKind()
+    for _, kind := range p.ProtectedKinds {
+        if strings.EqualFold(kind, gk.Kind) || strings.EqualFold(kind, gk.String()) {
+            return fmt.Sprintf("%s is a protected kind", gk.Kind)
+        }
+    }
+    switch gk.String() {
+    case "PersistentVolumeClaim":
+        if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase == string(corev1.ClaimBound) {
+            return "PersistentVolumeClaim is bound to a volume"
+        }
+    case "Secret":
+        data, _, _ := unstructured.NestedMap(obj.Object, "data")
+        stringData, _, _ := unstructured.NestedMap(obj.Object, "stringData")
+        // Copies of the central image pull secret can be made again
+        if (len(data) > 0 || len(stringData) > 0) && !isImagePullSecretCopy(obj) {
+            return "Secret contains data"
+        }
+    }
+    return ""
+}
+
+// retainProtected keeps a resource of a class the namespace left if the data
+// protection policy protects it, marking it orphaned and recording a warning.
+// It reports whether the resource was kept and must not be pruned.
+func (r *NamespaceClassReconciler) retainProtected(ctx context.Context, ns *corev1.Namespace, res ManagedResource) (bool, error) {
+    if r.DataProtection == nil {
+        return false, nil
+    }
+    obj := &unstructured.Unstructured{}
+    obj.SetAPIVersion(res.APIVersion)
+    obj.SetKind(res.Kind)
+    if err := r.Get(ctx, res.objectKey(ns.Name), obj); err != nil {
+        if errors.IsNotFound(err) {
+            return false, nil
+        }
+        return false, err
+    }
+
+    // Objects that are not ours are never pruned anyway
+    if !isManagedByController(obj) || isHNCPropagated(obj) || !ownsResource(obj, res.Class, nil) ||
+        (res.ClusterScoped && obj.GetAnnotations()[OwnerNamespaceAnnotation] != ns.Name) {
+        return false, nil
+    }
+    reason := r.DataProtection.protects(obj)
+    if reason == "" {
+        return false, nil
+    }
+    if obj.GetAnnotations()[OrphanedAnnotation] != "" {
+        return true, nil
+    }
+
+    patch := client.MergeFrom(obj.DeepCopy())
+    annotations := obj.GetAnnotations()
+    annotations[OrphanedAnnotation] = reason
+    obj.SetAnnotations(annotations)
+    if err := r.Patch(ctx, obj, patch); err != nil {
+        return false, err
+    }
+    log.FromContext(ctx).Info("Kept protected resource instead of pruning it",
+        "kind", res.Kind, "name", res.Name, "class", res.Class, "reason", reason)
+    r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonResourceRetained,
+        "Kept %s %s of class %s instead of deleting it: %s. It is no longer managed; delete it once its data is no longer needed",
+        res.Kind, res.Name, res.Class, reason)
+    return true, nil
+}
@@ -0,0 +1,145 @@
+// internal/controller/protection_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Data protection", func() {
+    It("should keep resources holding data when a namespace switches classes", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        oldClass := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "stateful"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"data"},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"1Gi"}}}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"unbound"},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"1Gi"}}}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"credentials"},"data":{"password":"c2VjcmV0"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"backup"}}`)},
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+            }},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "stateful"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            oldClass,
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "stateless"}},
+        ).WithStatusSubresource(&v1.NamespaceClass{}, &corev1.PersistentVolumeClaim{}).Build()
+        recorder := record.NewFakeRecorder(50)
+        reconciler := &NamespaceClassReconciler{
+            Client:         cl,
+            Scheme:         scheme,
+            Recorder:       recorder,
+            DataProtection: &DataProtectionPolicy{ProtectedKinds: []string{"ServiceAccount"}},
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        key := func(name string) types.NamespacedName {
+            return types.NamespacedName{Namespace: "team", Name: name}
+        }
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        pvc := &corev1.PersistentVolumeClaim{}
+        Expect(cl.Get(ctx, key("data"), pvc)).To(Succeed())
+        pvc.Status.Phase = corev1.ClaimBound
+        Expect(cl.Status().Update(ctx, pvc)).To(Succeed())
+
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        ns.Labels[LabelKey] = "stateless"
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        Expect(cl.Get(ctx, key("data"), pvc)).To(Succeed())
+        Expect(pvc.Annotations[OrphanedAnnotation]).To(Equal("PersistentVolumeClaim is bound to a volume"))
+        secret := &corev1.Secret{}
+        Expect(cl.Get(ctx, key("credentials"), secret)).To(Succeed())
+        Expect(secret.Annotations[OrphanedAnnotation]).To(Equal("Secret contains data"))
+        Expect(cl.Get(ctx, key("backup"), &corev1.ServiceAccount{})).To(Succeed())
+        Expect(errors.IsNotFound(cl.Get(ctx, key("unbound"), &corev1.PersistentVolumeClaim{}))).To(BeTrue())
+        Expect(errors.IsNotFound(cl.Get(ctx, key("settings"), &corev1.ConfigMap{}))).To(BeTrue())
+
+        managed, err := reconciler.getManagedResources(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(managed).To(BeEmpty())
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring("Warning ResourceRetained Kept PersistentVolumeClaim data of class stateful")))
+    })
+
+    It("should keep the inventory when a namespace leaving its class cannot mark a resource orphaned", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        failPatch := false
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "stateful"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "stateful"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"credentials"},"data":{"password":"c2VjcmV0"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+                if failPatch && obj.GetName() == "credentials" {
+                    return errors.NewServiceUnavailable("patch failed")
+                }
+                return c.Patch(ctx, obj, patch, opts...)
+            },
+        }).Build()
+        reconciler := &NamespaceClassReconciler{
+            Client:         cl,
+            Scheme:         scheme,
+            Recorder:       record.NewFakeRecorder(50),
+            DataProtection: &DataProtectionPolicy{},
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        delete(ns.Labels, LabelKey)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        failPatch = true
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).To(HaveOccurred())
+
+        // The secret stays tracked so the orphan scan does not delete it
+        secret := &corev1.Secret{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "credentials"}, secret)).To(Succeed())
+        Expect(secret.Annotations).NotTo(HaveKey(OrphanedAnnotation))
+        managed, err := reconciler.getManagedResources(ctx, ns)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(managed).To(ContainElement(HaveField("Name", "credentials")))
+
+        failPatch = false
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "credentials"}, secret)).To(Succeed())
+        Expect(secret.Annotations[OrphanedAnnotation]).To(Equal("Secret contains data"))
+    })
+})
@@ -0,0 +1,194 @@
+// internal/controller/provisioning.go
+package controller
+
+import (
+    "context"
+    "slices"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/builder"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/predicate"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Label on a namespace created for the spec.namespaces of a class, naming
+// the class
+const ProvisionedByLabel = "namespaceclass.akuity.io/provisioned-by"
+
+// ProvisionReconciler creates the namespaces classes list in spec.namespaces
+// and removes those it created once they are no longer listed, according to
+// the namespace removal policy of the class.
+type ProvisionReconciler struct {
+    client.Client
+    Recorder record.EventRecorder
+
+    // Shard limits the controller to the classes of its shard; nil
+    // provisions the namespaces of all classes.
+    Shard *Sharding
+
+    // Scope limits the namespaces the controller creates and removes; nil
+    // allows all namespaces.
+    Scope *WatchScope
+}
+
+// Reconcile creates the missing namespaces of a class and removes the ones
+// no longer listed.
+func (r *ProvisionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+    logger := log.FromContext(ctx).WithValues("class", req.Name, "controller", "ProvisionReconciler")
+    if !r.Shard.owns(req.Name) {
+        return reconcile.Result{}, nil
+    }
+
+    nsc := &v1.NamespaceClass{}
+    if err := r.Get(ctx, req.NamespacedName, nsc); err != nil {
+        return reconcile.Result{}, client.IgnoreNotFound(err)
+    }
+    if !nsc.DeletionTimestamp.IsZero() || nsc.Spec.Suspend {
+        return reconcile.Result{}, nil
+    }
+
+    for _, name := range nsc.Spec.Namespaces {
+        if !r.Scope.includes(name) {
+            continue
+        }
+        if err := r.provisionNamespace(ctx, nsc, name); err != nil {
+            logger.Error(err, "Failed to provision namespace", "namespace", name)
+            return reconcile.Result{}, err
+        }
+    }
+
+    var nsList corev1.NamespaceList
+    if err := r.List(ctx, &nsList, client.MatchingLabels{ProvisionedByLabel: nsc.Name}); err != nil {
+        return reconcile.Result{}, err
+    }
+    for i := range nsList.Items {
+        ns := &nsList.Items[i]
+        if slices.Contains(nsc.Spec.Namespaces, ns.Name) || !ns.DeletionTimestamp.IsZero() || !r.Scope.includes(ns.Name) {
+            continue
+        }
+        if err := r.removeNamespace(ctx, nsc, ns); err != nil {
+            logger.Error(err, "Failed to remove namespace", "namespace", ns.Name)
+            return reconcile.Result{}, err
+        }
+    }
+    return reconcile.Result{}, nil
+}
+
+// provisionNamespace creates a namespace listed by a class, or labels it
+// with the class if it exists without one. Namespaces using another class
+// are left alone, and deprecated classes do not create new namespaces.
+func (r *ProvisionReconciler) provisionNamespace(ctx context.Context, nsc *v1.NamespaceClass, name string) error {
+    ns := &corev1.Namespace{}
+    err := r.Get(ctx, types.NamespacedName{Name: name}, ns)
+    switch {
+    case errors.IsNotFound(err):
+        if nsc.Spec.Deprecated {
+            return nil
+        }
+        ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name:   name,
+            Labels: map[string]string{LabelKey: nsc.Name, ProvisionedByLabel: nsc.Name},
+        }}
+        r.Scope.optIn(ns)
+        if err := r.Create(ctx, ns); err != nil {
+            return err
+        }
+        r.recordEvent(nsc, corev1.EventTypeNormal, ReasonNamespaceProvisioned, "Created namespace %s", name)
+        return nil
+    case err != nil:
+        return err
+    case !ns.DeletionTimestamp.IsZero():
+        // Created again once the namespace is gone
+        return nil
+    }
+
+    className, labeled := ns.Labels[LabelKey]
+    if className == nsc.Name || isSkipped(ns) || !r.Scope.admits(ns) {
+        return nil
+    }
+    if labeled {
+        r.recordEvent(nsc, corev1.EventTypeWarning, ReasonNamespaceConflict,
+            "Namespace %s uses class %s, not labeling it with this class", name, className)
+        return nil
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    if ns.Labels == nil {
+        ns.Labels = make(map[string]string)
+    }
+    ns.Labels[LabelKey] = nsc.Name
+    if err := r.Patch(ctx, ns, patch); err != nil {
+        return err
+    }
+    r.recordEvent(nsc, corev1.EventTypeNormal, ReasonNamespaceProvisioned, "Labeled existing namespace %s", name)
+    return nil
+}
+
+// removeNamespace deletes a namespace the class created and no longer
+// lists, or under the Orphan policy only forgets that the class created it.
+// Namespaces excluded from management or not opted in to it are left alone.
+func (r *ProvisionReconciler) removeNamespace(ctx context.Context, nsc *v1.NamespaceClass, ns *corev1.Namespace) error {
+    if isSkipped(ns) || !r.Scope.admits(ns) {
+        return nil
+    }
+    if nsc.Spec.NamespaceRemovalPolicy == v1.NamespaceRemovalPolicyOrphan {
+        patch := client.MergeFrom(ns.DeepCopy())
+        delete(ns.Labels, ProvisionedByLabel)
+        if err := r.Patch(ctx, ns, patch); err != nil {
+            return err
+        }
+        r.recordEvent(nsc, corev1.EventTypeNormal, ReasonNamespaceOrphaned, "Namespace %s is no longer listed and was orphaned", ns.Name)
+        return nil
+    }
+    if err := r.Delete(ctx, ns); client.IgnoreNotFound(err) != nil {
+        return err
+    }
+    r.recordEvent(nsc, corev1.EventTypeNormal, ReasonNamespaceRemoved, "Deleted namespace %s, which is no longer listed", ns.Name)
+    return nil
+}
+
+// recordEvent emits an event if the reconciler has a recorder configured.
+func (r *ProvisionReconciler) recordEvent(nsc *v1.NamespaceClass, eventType, reason, messageFmt string, args ...interface{}) {
+    if r.Recorder == nil {
+        return
+    }
+    r.Recorder.Eventf(nsc, eventType, reason, messageFmt, args...)
+}
+
+// SetupWithManager sets up the provisioning controller with the Manager.
+// Classes are reconciled when one of their namespaces is deleted, so that it
+// is created again.
+func (r *ProvisionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    namespaceClass := func(ctx context.Context, obj client.Object) []reconcile.Request {
+        labels := obj.GetLabels()
+        className := labels[ProvisionedByLabel]
+        if className == "" {
+            className = labels[LabelKey]
+        }
+        if className == "" {
+            return nil
+        }
+        return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: className}}}
+    }
+    namespaceDeleted := predicate.Funcs{
+        CreateFunc:  func(e event.CreateEvent) bool { return false },
+        UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+        GenericFunc: func(e event.GenericEvent) bool { return false },
+    }
+    return ctrl.NewControllerManagedBy(mgr).
+        Named("namespaceprovisioning").
+        For(&v1.NamespaceClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+        Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceClass),
+            builder.WithPredicates(namespaceDeleted)).
+        Complete(r)
+}
@@ -0,0 +1,75 @@
+// internal/controller/provisioning_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Provisioned namespaces", func() {
+    It("should create the namespaces a class lists and remove them once unlisted", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec:       v1.NamespaceClassSpec{Namespaces: []string{"team-a-dev", "team-a-prod", "shared", "legacy"}},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            nsc,
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Labels: map[string]string{LabelKey: "other"}}},
+        ).Build()
+        reconciler := &ProvisionReconciler{Client: cl}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-dev"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{LabelKey: "team", ProvisionedByLabel: "team"}))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "shared"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{LabelKey: "team"}))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "legacy"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{LabelKey: "other"}))
+
+        // Unlisted namespaces the class created are deleted, or kept when orphaned
+        Expect(cl.Get(ctx, request.NamespacedName, nsc)).To(Succeed())
+        nsc.Spec.Namespaces = []string{"team-a-prod"}
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "team-a-dev"}, ns))).To(BeTrue())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "shared"}, ns)).To(Succeed())
+
+        nsc.Spec.Namespaces = nil
+        nsc.Spec.NamespaceRemovalPolicy = v1.NamespaceRemovalPolicyOrphan
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-prod"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(Equal(map[string]string{LabelKey: "team"}))
+    })
+
+    It("should reject invalid provisioned namespaces", func() {
+        nsc := &v1.NamespaceClass{Spec: v1.NamespaceClassSpec{
+            Namespaces:             []string{"team-a", "Team_B", "team-a"},
+            NamespaceRemovalPolicy: "Keep",
+        }}
+        var fields []string
+        for _, err := range ValidateClass(nsc) {
+            fields = append(fields, err.Field)
+        }
+        Expect(fields).To(ConsistOf("spec.namespaceRemovalPolicy", "spec.namespaces[1]", "spec.namespaces[2]"))
+    })
+})
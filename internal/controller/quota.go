@@ -0,0 +1,76 @@
+// internal/controller/quota.go
+package controller
+
+import (
+    "strconv"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/resource"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// DefaultQuotaName is the name of the ResourceQuota generated from a class
+// quota that does not set one.
+const DefaultQuotaName = "namespace-quota"
+
+// quotaName returns the name of the ResourceQuota generated from a quota.
+func quotaName(quota *v1.ClassQuota) string {
+    if quota.Name != "" {
+        return quota.Name
+    }
+    return DefaultQuotaName
+}
+
+// quotaHard returns the hard limits of the ResourceQuota for a class quota.
+func quotaHard(quota *v1.ClassQuota) map[string]string {
+    hard := make(map[string]string)
+    for _, limit := range []struct {
+        name  corev1.ResourceName
+        value *resource.Quantity
+    }{
+        {corev1.ResourceRequestsCPU, quota.CPU},
+        {corev1.ResourceRequestsMemory, quota.Memory},
+        {corev1.ResourceLimitsCPU, quota.LimitsCPU},
+        {corev1.ResourceLimitsMemory, quota.LimitsMemory},
+        {corev1.ResourceRequestsStorage, quota.Storage},
+    } {
+        if limit.value != nil {
+            hard[string(limit.name)] = limit.value.String()
+        }
+    }
+    for _, limit := range []struct {
+        name  corev1.ResourceName
+        value *int64
+    }{
+        {corev1.ResourcePods, quota.Pods},
+        {corev1.ResourceServices, quota.Services},
+        {corev1.ResourceConfigMaps, quota.ConfigMaps},
+        {corev1.ResourceSecrets, quota.Secrets},
+        {corev1.ResourcePersistentVolumeClaims, quota.PersistentVolumeClaims},
+    } {
+        if limit.value != nil {
+            hard[string(limit.name)] = strconv.FormatInt(*limit.value, 10)
+        }
+    }
+    return hard
+}
+
+// quotaResource expands the quota of a class into a ResourceQuota, or
+// returns nil if the class has none.
+func quotaResource(quota *v1.ClassQuota) *unstructured.Unstructured {
+    if quota == nil {
+        return nil
+    }
+    hard := make(map[string]interface{})
+    for name, value := range quotaHard(quota) {
+        hard[name] = value
+    }
+    return &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "ResourceQuota",
+        "metadata":   map[string]interface{}{"name": quotaName(quota)},
+        "spec":       map[string]interface{}{"hard": hard},
+    }}
+}
@@ -0,0 +1,54 @@
+// internal/controller/quota_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/utils/ptr"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class quota", func() {
+    It("should expand spec.quota into a ResourceQuota", func() {
+        cpu, memory := resource.MustParse("4"), resource.MustParse("8Gi")
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+            Spec: v1.NamespaceClassSpec{Quota: &v1.ClassQuota{
+                CPU:    &cpu,
+                Memory: &memory,
+                Pods:   ptr.To(int64(20)),
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        resources, err := RenderClass(nsc, "team")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(1))
+        Expect(resources[0].GetKind()).To(Equal("ResourceQuota"))
+        Expect(resources[0].GetName()).To(Equal(DefaultQuotaName))
+        Expect(resources[0].GetNamespace()).To(Equal("team"))
+        hard, _, _ := unstructured.NestedStringMap(resources[0].Object, "spec", "hard")
+        Expect(hard).To(Equal(map[string]string{"requests.cpu": "4", "requests.memory": "8Gi", "pods": "20"}))
+    })
+
+    It("should reject empty quotas and quotas colliding with embedded ones", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+            Spec: v1.NamespaceClassSpec{
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ResourceQuota","metadata":{"name":"compute"},"spec":{"hard":{"pods":"10"}}}`)},
+                },
+                Quota: &v1.ClassQuota{Name: "compute"},
+            },
+        }
+        var fields []string
+        for _, err := range ValidateClass(nsc) {
+            fields = append(fields, err.Field)
+        }
+        Expect(fields).To(ConsistOf("spec.quota", "spec.quota.name"))
+    })
+})
@@ -0,0 +1,69 @@
+// internal/controller/rbac.go
+package controller
+
+import (
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// roleGrantName returns the name of the RoleBinding generated from a grant.
+func roleGrantName(grant v1.RoleGrant) string {
+    if grant.Name != "" {
+        return grant.Name
+    }
+    return grant.ClusterRole
+}
+
+// roleGrantSubjects returns the subjects of a grant: groups, then users,
+// then service accounts. Service accounts without a namespace are left
+// without one, which a RoleBinding resolves to its own namespace.
+func roleGrantSubjects(grant v1.RoleGrant) []interface{} {
+    var subjects []interface{}
+    for _, group := range grant.Groups {
+        subjects = append(subjects, map[string]interface{}{
+            "kind":     rbacv1.GroupKind,
+            "apiGroup": rbacv1.GroupName,
+            "name":     group,
+        })
+    }
+    for _, user := range grant.Users {
+        subjects = append(subjects, map[string]interface{}{
+            "kind":     rbacv1.UserKind,
+            "apiGroup": rbacv1.GroupName,
+            "name":     user,
+        })
+    }
+    for _, sa := range grant.ServiceAccounts {
+        subject := map[string]interface{}{
+            "kind": rbacv1.ServiceAccountKind,
+            "name": sa.Name,
+        }
+        if sa.Namespace != "" {
+            subject["namespace"] = sa.Namespace
+        }
+        subjects = append(subjects, subject)
+    }
+    return subjects
+}
+
+// rbacResources expands the grants of a class into RoleBindings to their
+// ClusterRoles.
+func rbacResources(grants []v1.RoleGrant) []*unstructured.Unstructured {
+    var resources []*unstructured.Unstructured
+    for _, grant := range grants {
+        resources = append(resources, &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": rbacv1.SchemeGroupVersion.String(),
+            "kind":       "RoleBinding",
+            "metadata":   map[string]interface{}{"name": roleGrantName(grant)},
+            "roleRef": map[string]interface{}{
+                "apiGroup": rbacv1.GroupName,
+                "kind":     "ClusterRole",
+                "name":     grant.ClusterRole,
+            },
+            "subjects": roleGrantSubjects(grant),
+        }})
+    }
+    return resources
+}
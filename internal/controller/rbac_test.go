@@ -0,0 +1,40 @@
+// internal/controller/rbac_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class RBAC grants", func() {
+    It("should expand spec.rbac into RoleBindings to ClusterRoles", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{RBAC: []v1.RoleGrant{
+                {ClusterRole: "edit", Groups: []string{"developers"}, ServiceAccounts: []v1.ServiceAccountSubject{{Name: "deployer"}}},
+                {Name: "auditors", ClusterRole: "view", Users: []string{"alice@example.com"}},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        resources, err := RenderClass(nsc, "team-a")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(2))
+        Expect(resources[0].GetKind()).To(Equal("RoleBinding"))
+        Expect(resources[0].GetName()).To(Equal("edit"))
+        Expect(resources[0].GetNamespace()).To(Equal("team-a"))
+        Expect(resources[0].Object["subjects"]).To(Equal([]interface{}{
+            map[string]interface{}{"kind": "Group", "apiGroup": "rbac.authorization.k8s.io", "name": "developers"},
+            map[string]interface{}{"kind": "ServiceAccount", "name": "deployer"},
+        }))
+        role, _, _ := unstructured.NestedString(resources[1].Object, "roleRef", "name")
+        Expect(resources[1].GetName()).To(Equal("auditors"))
+        Expect(role).To(Equal("view"))
+
+        nsc.Spec.RBAC = append(nsc.Spec.RBAC, v1.RoleGrant{ClusterRole: "edit"})
+        Expect(ValidateClass(nsc)).To(HaveLen(2))
+    })
+})
@@ -0,0 +1,123 @@
+// internal/controller/recreate_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/apimachinery/pkg/util/validation/field"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Recreate update strategy", func() {
+    var (
+        ctx        context.Context
+        cl         client.Client
+        reconciler *NamespaceClassReconciler
+        recorder   *record.FakeRecorder
+        request    reconcile.Request
+        settings   types.NamespacedName
+        // terminating makes creates of the ConfigMap fail as if its old
+        // object still existed
+        terminating bool
+    )
+
+    setData := func(mode string) {
+        nsc := &v1.NamespaceClass{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.Resources = []runtime.RawExtension{{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings",` +
+            `"annotations":{"namespaceclass.akuity.io/update-strategy":"Recreate"}},"data":{"mode":"` + mode + `"}}`)}}
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+    }
+
+    BeforeEach(func() {
+        ctx = context.Background()
+        terminating = false
+        scheme := newScheme()
+        isSettings := func(obj client.Object) bool {
+            _, isNamespace := obj.(*corev1.Namespace)
+            return !isNamespace && obj.GetName() == "settings"
+        }
+        cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+        ).WithStatusSubresource(&v1.NamespaceClass{}).WithInterceptorFuncs(interceptor.Funcs{
+            Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+                if isSettings(obj) {
+                    return errors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "settings", field.ErrorList{
+                        field.Invalid(field.NewPath("data"), nil, "field is immutable"),
+                    })
+                }
+                return c.Update(ctx, obj, opts...)
+            },
+            Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+                if terminating && isSettings(obj) {
+                    return errors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, "settings")
+                }
+                return c.Create(ctx, obj, opts...)
+            },
+        }).Build()
+        recorder = record.NewFakeRecorder(20)
+        reconciler = &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        settings = types.NamespacedName{Namespace: "team", Name: "settings"}
+
+        setData("strict")
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, settings, &corev1.ConfigMap{})).To(Succeed())
+    })
+
+    It("should delete and recreate a resource whose update changes an immutable field", func() {
+        setData("relaxed")
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        cm := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, settings, cm)).To(Succeed())
+        Expect(cm.Data).To(HaveKeyWithValue("mode", "relaxed"))
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring(ReasonResourceRecreated)))
+    })
+
+    It("should wait for the old object to be deleted before recreating a resource", func() {
+        setData("relaxed")
+        terminating = true
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring(ReasonWaitingForRecreate)))
+        inv := &v1.NamespaceClassInventory{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+        ready := meta.FindStatusCondition(inv.Status.Conditions, v1.ConditionReady)
+        Expect(ready).NotTo(BeNil())
+        Expect(ready.Reason).To(Equal(v1.ReasonRecreatePending))
+
+        // Created on the requeue once the old object is gone
+        terminating = false
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        cm := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, settings, cm)).To(Succeed())
+        Expect(cm.Data).To(HaveKeyWithValue("mode", "relaxed"))
+    })
+})
@@ -0,0 +1,107 @@
+// internal/controller/redact.go
+package controller
+
+import (
+    "encoding/base64"
+    "sort"
+    "strings"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+    // Placeholder for Secret values in logs, diffs and events
+    redactedValue = "<redacted>"
+
+    // Shorter values are not searched for in messages, since replacing them
+    // would mangle unrelated text
+    minRedactedLength = 4
+)
+
+// isSecret reports whether an object is a core Secret.
+func isSecret(obj *unstructured.Unstructured) bool {
+    gvk := obj.GroupVersionKind()
+    return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// isSecretValuePath reports whether a diff path is inside data or stringData.
+func isSecretValuePath(path string) bool {
+    for _, field := range []string{"data", "stringData"} {
+        if path == field || strings.HasPrefix(path, field+".") {
+            return true
+        }
+    }
+    return false
+}
+
+// redactChanges masks the values of Secret data in a diff. The changed keys
+// are still listed so an update can be audited.
+func redactChanges(obj *unstructured.Unstructured, changes []fieldChange) []fieldChange {
+    if !isSecret(obj) {
+        return changes
+    }
+    for i := range changes {
+        if !isSecretValuePath(changes[i].Path) {
+            continue
+        }
+        if changes[i].Old != nil {
+            changes[i].Old = redactedValue
+        }
+        if changes[i].New != nil {
+            changes[i].New = redactedValue
+        }
+    }
+    return changes
+}
+
+// secretValues returns the values of a Secret as they may appear in
+// messages: both encoded and decoded, longest first.
+func secretValues(obj *unstructured.Unstructured) []string {
+    var values []string
+    add := func(value string) {
+        if len(value) >= minRedactedLength {
+            values = append(values, value)
+        }
+    }
+    data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+    for _, encoded := range data {
+        add(encoded)
+        if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+            add(string(decoded))
+        }
+    }
+    stringData, _, _ := unstructured.NestedStringMap(obj.Object, "stringData")
+    for _, value := range stringData {
+        add(value)
+        add(base64.StdEncoding.EncodeToString([]byte(value)))
+    }
+    sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+    return values
+}
+
+// redactedError hides Secret values in the message of an error while
+// keeping the original error, e.g. its API status, reachable.
+type redactedError struct {
+    err     error
+    message string
+}
+
+func (e *redactedError) Error() string { return e.message }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactError masks the values of a Secret in an error returned while
+// applying it, since apiserver and webhook errors may echo invalid values.
+func redactError(obj *unstructured.Unstructured, err error) error {
+    if err == nil || !isSecret(obj) {
+        return err
+    }
+    message := err.Error()
+    redacted := message
+    for _, value := range secretValues(obj) {
+        redacted = strings.ReplaceAll(redacted, value, redactedValue)
+    }
+    if redacted == message {
+        return err
+    }
+    return &redactedError{err: err, message: redacted}
+}
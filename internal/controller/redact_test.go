@@ -0,0 +1,49 @@
+// internal/controller/redact_test.go
+package controller
+
+import (
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("Secret redaction", func() {
+    secret := &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "Secret",
+        "metadata":   map[string]interface{}{"name": "db"},
+        "data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+        "stringData": map[string]interface{}{"token": "s3cr3t-token"},
+    }}
+
+    It("should mask Secret values in diffs but keep the changed keys", func() {
+        changes := redactChanges(secret, []fieldChange{
+            {Path: "data.password", Old: "b2xk", New: "aHVudGVyMg=="},
+            {Path: "stringData.token", New: "s3cr3t-token"},
+            {Path: "metadata.labels.tier", Old: "a", New: "b"},
+        })
+        Expect(changes).To(Equal([]fieldChange{
+            {Path: "data.password", Old: redactedValue, New: redactedValue},
+            {Path: "stringData.token", New: redactedValue},
+            {Path: "metadata.labels.tier", Old: "a", New: "b"},
+        }))
+    })
+
+    It("should mask Secret values echoed in errors", func() {
+        invalid := errors.NewInvalid(schema.GroupKind{Kind: "Secret"}, "db", field.ErrorList{
+            field.Invalid(field.NewPath("data", "password"), "hunter2", "rejected by policy"),
+            field.Invalid(field.NewPath("stringData", "token"), "s3cr3t-token", "too short"),
+        })
+        err := redactError(secret, invalid)
+        Expect(err.Error()).NotTo(ContainSubstring("hunter2"))
+        Expect(err.Error()).NotTo(ContainSubstring("s3cr3t-token"))
+        Expect(err.Error()).To(ContainSubstring(redactedValue))
+        Expect(errors.IsInvalid(err)).To(BeTrue())
+
+        configMap := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+        Expect(redactError(configMap, invalid)).To(BeIdenticalTo(invalid))
+    })
+})
@@ -0,0 +1,117 @@
+// internal/controller/renames.go
+package controller
+
+import (
+    "context"
+
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // Annotation on an embedded resource giving it an identifier that stays
+    // the same when the resource is renamed
+    ResourceIDAnnotation = "namespaceclass.akuity.io/resource-id"
+
+    // Annotation on a renamed ConfigMap or Secret that copies the data of
+    // the old object into the new one
+    RenameCopyDataAnnotation = "namespaceclass.akuity.io/rename-copy-data"
+)
+
+// renameKey identifies a resource of a class across renames.
+type renameKey struct {
+    groupKind schema.GroupKind
+    class     string
+    id        string
+}
+
+// resourceRenames maps the resources of a namespace that were renamed in
+// their class to the inventory entries they were last applied as, by
+// resource key. A resource counts as renamed when a tracked entry of the
+// same kind and class has the same identifier but a name the class no
+// longer has.
+func resourceRenames(resources []*unstructured.Unstructured, sources resourceClasses, nsc *v1.NamespaceClass, currentManaged []ManagedResource, desiredKeys map[string]bool) map[string]ManagedResource {
+    previous := make(map[renameKey]ManagedResource)
+    for _, res := range currentManaged {
+        if res.ID != "" && !desiredKeys[res.key()] {
+            previous[renameKey{res.groupVersionKind().GroupKind(), res.Class, res.ID}] = res
+        }
+    }
+    if len(previous) == 0 {
+        return nil
+    }
+    renames := make(map[string]ManagedResource)
+    for _, res := range resources {
+        id := res.GetAnnotations()[ResourceIDAnnotation]
+        if id == "" {
+            continue
+        }
+        if old, ok := previous[renameKey{res.GroupVersionKind().GroupKind(), sources.of(res, nsc).Name, id}]; ok {
+            renames[resourceKey(res)] = old
+        }
+    }
+    return renames
+}
+
+// copyRenamedData copies the data of the object a ConfigMap or Secret was
+// renamed from into the new one before it is created, keeping the values
+// the class sets itself. It returns res unchanged unless the class asked
+// for the copy and the old object still exists.
+func (r *NamespaceClassReconciler) copyRenamedData(ctx context.Context, namespace string, res *unstructured.Unstructured, old ManagedResource) (*unstructured.Unstructured, error) {
+    if res.GetAnnotations()[RenameCopyDataAnnotation] != "true" || res.GroupVersionKind().Group != "" {
+        return res, nil
+    }
+    var fields []string
+    switch res.GetKind() {
+    case "ConfigMap":
+        fields = []string{"data", "binaryData"}
+    case "Secret":
+        fields = []string{"data"}
+    default:
+        return res, nil
+    }
+
+    live := &unstructured.Unstructured{}
+    live.SetAPIVersion(old.APIVersion)
+    live.SetKind(old.Kind)
+    if err := r.Get(ctx, old.objectKey(namespace), live); err != nil {
+        if errors.IsNotFound(err) {
+            return res, nil
+        }
+        return nil, err
+    }
+    res = res.DeepCopy()
+    for _, field := range fields {
+        data, _, _ := unstructured.NestedMap(live.Object, field)
+        if len(data) == 0 {
+            continue
+        }
+        merged, _, _ := unstructured.NestedMap(res.Object, field)
+        if merged == nil {
+            merged = make(map[string]interface{}, len(data))
+        }
+        for key, value := range data {
+            if _, set := merged[key]; !set {
+                merged[key] = value
+            }
+        }
+        if err := unstructured.SetNestedMap(res.Object, merged, field); err != nil {
+            return nil, err
+        }
+    }
+    return res, nil
+}
+
+// isRenameSource reports whether a tracked resource was renamed in its
+// class, so it must not be pruned before its new name is applied.
+func isRenameSource(renames map[string]ManagedResource, res ManagedResource) bool {
+    for _, old := range renames {
+        if old.key() == res.key() {
+            return true
+        }
+    }
+    return false
+}
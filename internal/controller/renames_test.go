@@ -0,0 +1,97 @@
+// internal/controller/renames_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Resource renames", func() {
+    It("should create the renamed resource with the old data before deleting the old one", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","annotations":{"namespaceclass.akuity.io/resource-id":"settings"}},"data":{"mode":"strict"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        recorder := record.NewFakeRecorder(20)
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        // Data written to the object at runtime is carried over
+        old := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, old)).To(Succeed())
+        old.Data["token"] = "generated"
+        Expect(cl.Update(ctx, old)).To(Succeed())
+
+        nsc := &v1.NamespaceClass{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.Resources = []runtime.RawExtension{
+            {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-settings","annotations":{"namespaceclass.akuity.io/resource-id":"settings","namespaceclass.akuity.io/rename-copy-data":"true"}},"data":{"mode":"relaxed"}}`)},
+        }
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+
+        renamed := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "app-settings"}, renamed)).To(Succeed())
+        Expect(renamed.Data).To(Equal(map[string]string{"mode": "relaxed", "token": "generated"}))
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, &corev1.ConfigMap{}))).To(BeTrue())
+        events := drainEvents(recorder)
+        Expect(events).To(ContainElement(ContainSubstring("Renamed ConfigMap settings to app-settings")))
+
+        managed, err := reconciler.getManagedResources(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(managed).To(HaveLen(1))
+        Expect(managed[0].Name).To(Equal("app-settings"))
+        Expect(managed[0].ID).To(Equal("settings"))
+    })
+
+    It("should keep the old resource until the renamed one is applied", func() {
+        renames := resourceRenames(
+            []*unstructured.Unstructured{{Object: map[string]interface{}{
+                "apiVersion": "v1", "kind": "Secret",
+                "metadata": map[string]interface{}{"name": "db-v2", "annotations": map[string]interface{}{ResourceIDAnnotation: "db"}},
+            }}},
+            nil, &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+            []ManagedResource{{APIVersion: "v1", Kind: "Secret", Name: "db", Class: "web", ID: "db"}},
+            map[string]bool{"v1/Secret/db-v2": true},
+        )
+        Expect(renames).To(HaveKeyWithValue("v1/Secret/db-v2", HaveField("Name", "db")))
+        Expect(isRenameSource(renames, ManagedResource{APIVersion: "v1", Kind: "Secret", Name: "db"})).To(BeTrue())
+        Expect(isRenameSource(renames, ManagedResource{APIVersion: "v1", Kind: "Secret", Name: "other"})).To(BeFalse())
+
+        // Identifiers must be unique per kind within a class
+        errs := ValidateClass(&v1.NamespaceClass{Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+            {Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"a","annotations":{"namespaceclass.akuity.io/resource-id":"db"}}}`)},
+            {Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"b","annotations":{"namespaceclass.akuity.io/resource-id":"db"}}}`)},
+            {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c","annotations":{"namespaceclass.akuity.io/resource-id":"db"}}}`)},
+        }}})
+        Expect(errs).To(HaveLen(1))
+        Expect(errs[0].Field).To(Equal("spec.resources[1].metadata.annotations[namespaceclass.akuity.io/resource-id]"))
+    })
+})
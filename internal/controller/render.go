@@ -0,0 +1,142 @@
+// internal/controller/render.go
+package controller
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// RenderClass returns the resources of a class as the controller applies them
+// to a namespace: with the namespace, management annotations and resource
+// hash set, in the order they are applied. Tools use it to preview a class
+// without running the controller. Templates are expanded with the default
+// values of the class.
+func RenderClass(nsc *v1.NamespaceClass, namespace string) ([]*unstructured.Unstructured, error) {
+    ns := &corev1.Namespace{}
+    ns.Name = namespace
+    return RenderNamespaceClass(context.Background(), nil, nsc, ns)
+}
+
+// RenderNamespaceClass is RenderClass for an existing namespace, expanding
+// templates with the values the namespace sets in its values annotation and
+// in the values ConfigMap of the class, which is read with c if not nil.
+func RenderNamespaceClass(ctx context.Context, c client.Reader, nsc *v1.NamespaceClass, ns *corev1.Namespace) ([]*unstructured.Unstructured, error) {
+    resources, err := classSpecResources(nsc)
+    if err != nil {
+        return nil, err
+    }
+    if err := expandValues(ctx, c, resources, ns, nil, nsc); err != nil {
+        return nil, err
+    }
+    namespace := ns.Name
+    scopeClusterResources(resources, namespace, nil, nsc)
+    if err := orderResources(resources); err != nil {
+        return nil, err
+    }
+    for _, res := range resources {
+        renderResource(res, namespace, nsc)
+    }
+    return resources, nil
+}
+
+// NeedsUpdate reports whether the controller would update a live object to
+// match a resource rendered by RenderClass.
+func NeedsUpdate(live, desired *unstructured.Unstructured, nsc *v1.NamespaceClass) bool {
+    return needsUpdate(live, desired, renderOptions(desired, nsc))
+}
+
+// ComparableObject returns the content of an object the controller compares,
+// without status, server-set metadata and fields the class does not manage.
+func ComparableObject(obj *unstructured.Unstructured, desired *unstructured.Unstructured, nsc *v1.NamespaceClass) map[string]interface{} {
+    return comparableObject(obj, renderOptions(desired, nsc).unmanagedFields())
+}
+
+// classSpecResources returns the resources of a class: those embedded in
+// spec.resources followed by the ResourceQuota generated from spec.quota,
+// the LimitRange generated from spec.limits, the RoleBindings generated
+// from spec.rbac, the ServiceAccounts of spec.serviceAccounts, the
+// Certificates of spec.certificates and the cluster-scoped resources of
+// spec.clusterResources.
+func classSpecResources(nsc *v1.NamespaceClass) ([]*unstructured.Unstructured, error) {
+    resources, err := parseClassResources(nsc.Spec.Resources, nsc.Name)
+    if err != nil {
+        return nil, err
+    }
+    if quota := quotaResource(nsc.Spec.Quota); quota != nil {
+        resources = append(resources, quota)
+    }
+    if limits := limitsResource(nsc.Spec.Limits); limits != nil {
+        resources = append(resources, limits)
+    }
+    resources = append(resources, rbacResources(nsc.Spec.RBAC)...)
+    resources = append(resources, serviceAccountResources(nsc.Spec.ServiceAccounts)...)
+    resources = append(resources, certificateResources(nsc.Spec.Certificates)...)
+    cluster, err := clusterResources(nsc)
+    if err != nil {
+        return nil, err
+    }
+    return append(resources, cluster...), nil
+}
+
+// parseClassResources decodes and validates the resources of a class.
+func parseClassResources(raw []runtime.RawExtension, className string) ([]*unstructured.Unstructured, error) {
+    var result []*unstructured.Unstructured
+    for _, r := range raw {
+        var u unstructured.Unstructured
+        if err := json.Unmarshal(r.Raw, &u); err != nil {
+            return nil, err
+        }
+
+        // Validate the resource
+        if err := validateResource(&u); err != nil {
+            return nil, fmt.Errorf("invalid resource in class %s: %v", className, err)
+        }
+
+        result = append(result, &u)
+    }
+    return result, nil
+}
+
+// renderOptions returns the apply options that follow from the class and
+// the resource alone.
+func renderOptions(res *unstructured.Unstructured, nsc *v1.NamespaceClass) applyOptions {
+    return applyOptions{
+        ignoreFields:     resourceIgnoreFields(res, nsc.Spec.IgnoreFields),
+        createOnlyFields: annotationFields(res, CreateOnlyFieldsAnnotation),
+        comparisonMode:   nsc.Spec.ComparisonMode,
+        updateStrategy:   res.GetAnnotations()[UpdateStrategyAnnotation],
+        conflictPolicy:   nsc.Spec.ConflictPolicy,
+        allowFreeze:      nsc.Spec.AllowTenantFreeze,
+        className:        nsc.Name,
+    }
+}
+
+// renderResource sets the namespace, management annotations and hash of a
+// class resource, expanding the DNS name templates of Certificates, and
+// returns the hash.
+func renderResource(res *unstructured.Unstructured, namespace string, nsc *v1.NamespaceClass) string {
+    if !isClusterScoped(res) {
+        res.SetNamespace(namespace)
+    }
+    expandCertificateNames(res, namespace, nsc.Name)
+    annotations := res.GetAnnotations()
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    annotations[ManagedByAnnotation] = ManagedByValue
+    annotations[CreatedByClassAnnotation] = nsc.Name
+
+    // Calculate resource hash, excluding fields owned by other actors
+    resourceHash := calculateResourceHash(res, renderOptions(res, nsc).unmanagedFields(), nsc.Spec.HashAlgorithm)
+    annotations[ResourceHashAnnotation] = resourceHash
+    res.SetAnnotations(annotations)
+    return resourceHash
+}
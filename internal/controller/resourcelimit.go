@@ -0,0 +1,100 @@
+// internal/controller/resourcelimit.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/util/validation/field"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// DefaultMaxResourcesPerClass is the default cap on the resources a class
+// renders for a namespace
+const DefaultMaxResourcesPerClass = 200
+
+// ResourceLimit caps how many resources a single class may render for a
+// namespace, so that pasting a whole Helm release or cluster dump into a
+// class by accident cannot flood every namespace using it.
+type ResourceLimit struct {
+    // MaxPerClass is the most resources a class may render; zero disables the limit
+    MaxPerClass int
+}
+
+// exceeded reports whether a class rendering count resources is over the
+// limit. A nil limit allows any number.
+func (l *ResourceLimit) exceeded(count int) bool {
+    return l != nil && l.MaxPerClass > 0 && count > l.MaxPerClass
+}
+
+// ValidateClass checks that a class renders no more resources than the
+// limit allows, counting its embedded and cluster-scoped resources and
+// those it generates. Resources that cannot be decoded are left to
+// ValidateClass.
+func (l *ResourceLimit) ValidateClass(nsc *v1.NamespaceClass) field.ErrorList {
+    if l == nil || l.MaxPerClass <= 0 {
+        return nil
+    }
+    resources, err := classSpecResources(nsc)
+    if err != nil || !l.exceeded(len(resources)) {
+        return nil
+    }
+    return field.ErrorList{field.TooMany(field.NewPath("spec", "resources"), len(resources), l.MaxPerClass)}
+}
+
+// tooManyResourcesError lists the classes of a namespace that render more
+// resources than the resource limit allows.
+type tooManyResourcesError struct {
+    classes []string
+}
+
+func (e *tooManyResourcesError) Error() string {
+    return fmt.Sprintf("classes render too many resources: %s", strings.Join(e.classes, "; "))
+}
+
+// checkResourceLimit returns a tooManyResourcesError if a class of a
+// namespace renders more resources than the resource limit allows.
+func (r *NamespaceClassReconciler) checkResourceLimit(resources []*unstructured.Unstructured, sources resourceClasses, nsc *v1.NamespaceClass) error {
+    if r.ResourceLimit == nil {
+        return nil
+    }
+    counts := make(map[string]int)
+    var order []string
+    for _, res := range resources {
+        className := sources.of(res, nsc).Name
+        if counts[className] == 0 {
+            order = append(order, className)
+        }
+        counts[className]++
+    }
+    var classes []string
+    for _, className := range order {
+        if r.ResourceLimit.exceeded(counts[className]) {
+            classes = append(classes, fmt.Sprintf("class %s renders %d resources, more than the limit of %d",
+                className, counts[className], r.ResourceLimit.MaxPerClass))
+        }
+    }
+    if len(classes) > 0 {
+        return &tooManyResourcesError{classes: classes}
+    }
+    return nil
+}
+
+// reportTooManyResources records that a class of a namespace renders more
+// resources than the resource limit allows, with a warning event and a
+// Degraded condition on the inventory of the namespace.
+func (r *NamespaceClassReconciler) reportTooManyResources(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, limitErr *tooManyResourcesError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonTooManyResources,
+        "Not applying any resources: %s", strings.Join(limitErr.classes, "; "))
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonTooManyResources,
+        Message: limitErr.Error(),
+    })
+}
@@ -0,0 +1,55 @@
+// internal/controller/resourcelimit_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Resource limit", func() {
+    It("should apply nothing from a class rendering more resources than allowed", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"first"}}`)},
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"second"}}`)},
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"third"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{
+            Client:        cl,
+            Scheme:        scheme,
+            Recorder:      record.NewFakeRecorder(10),
+            ResourceLimit: &ResourceLimit{MaxPerClass: 2},
+        }
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+        Expect(err).To(MatchError(ContainSubstring("class web renders 3 resources, more than the limit of 2")))
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "first"}, &corev1.ConfigMap{}))).To(BeTrue())
+
+        Expect((&ResourceLimit{}).exceeded(1000)).To(BeFalse())
+        var none *ResourceLimit
+        Expect(none.ValidateClass(&v1.NamespaceClass{})).To(BeEmpty())
+    })
+})
@@ -0,0 +1,283 @@
+// internal/controller/revisions.go
+package controller
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strconv"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "k8s.io/utils/ptr"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/builder"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/predicate"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // Annotation on a namespace pinning it to a revision of its class, so a
+    // single namespace can be rolled back while the class moves on
+    RevisionAnnotation = "namespaceclass.akuity.io/revision"
+
+    // DefaultRevisionHistoryLimit is the number of revisions kept per class
+    DefaultRevisionHistoryLimit = 10
+)
+
+// RevisionName returns the name of a revision of a class.
+func RevisionName(className string, revision int64) string {
+    return fmt.Sprintf("%s-%d", className, revision)
+}
+
+// ListRevisions returns the revisions of a class, oldest first.
+func ListRevisions(ctx context.Context, c client.Reader, className string) ([]v1.NamespaceClassRevision, error) {
+    revisions := &v1.NamespaceClassRevisionList{}
+    if err := c.List(ctx, revisions, client.MatchingLabels{LabelKey: className}); err != nil {
+        return nil, err
+    }
+    sort.Slice(revisions.Items, func(i, j int) bool {
+        return revisions.Items[i].Spec.Revision < revisions.Items[j].Spec.Revision
+    })
+    return revisions.Items, nil
+}
+
+// classSpecHash identifies the content of a class spec.
+func classSpecHash(spec *v1.NamespaceClassSpec) (string, error) {
+    data, err := json.Marshal(spec)
+    if err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(data)
+    return fmt.Sprintf("%s:%s", v1.HashAlgorithmSHA256, hex.EncodeToString(sum[:])), nil
+}
+
+// RevisionReconciler records a NamespaceClassRevision for every generation
+// of a class whose spec differs from the latest revision, and prunes
+// revisions beyond the history limit. Revisions are owned by their class
+// and garbage collected with it.
+type RevisionReconciler struct {
+    client.Client
+
+    // HistoryLimit is the number of revisions kept per class; 0 keeps
+    // DefaultRevisionHistoryLimit. Revisions namespaces are pinned to are
+    // never pruned.
+    HistoryLimit int
+
+    // Shard limits the controller to the classes of its shard; nil records
+    // revisions of all classes.
+    Shard *Sharding
+}
+
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=namespaceclassrevisions,verbs=get;list;watch;create;delete
+
+// Reconcile records the current generation of a class as a revision.
+func (r *RevisionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+    logger := log.FromContext(ctx).WithValues("class", req.Name, "controller", "RevisionReconciler")
+    if !r.Shard.owns(req.Name) {
+        return reconcile.Result{}, nil
+    }
+
+    nsc := &v1.NamespaceClass{}
+    if err := r.Get(ctx, req.NamespacedName, nsc); err != nil {
+        return reconcile.Result{}, client.IgnoreNotFound(err)
+    }
+    if !nsc.DeletionTimestamp.IsZero() {
+        return reconcile.Result{}, nil
+    }
+    hash, err := classSpecHash(&nsc.Spec)
+    if err != nil {
+        return reconcile.Result{}, err
+    }
+    revisions, err := ListRevisions(ctx, r, nsc.Name)
+    if err != nil {
+        logger.Error(err, "Failed to list revisions")
+        return reconcile.Result{}, err
+    }
+
+    if n := len(revisions); n == 0 || (revisions[n-1].Spec.Hash != hash && revisions[n-1].Spec.Revision < nsc.Generation) {
+        revision := &v1.NamespaceClassRevision{
+            ObjectMeta: metav1.ObjectMeta{
+                Name:   RevisionName(nsc.Name, nsc.Generation),
+                Labels: map[string]string{LabelKey: nsc.Name},
+                OwnerReferences: []metav1.OwnerReference{{
+                    APIVersion:         v1.GroupVersion.String(),
+                    Kind:               "NamespaceClass",
+                    Name:               nsc.Name,
+                    UID:                nsc.UID,
+                    BlockOwnerDeletion: ptr.To(false),
+                }},
+            },
+            Spec: v1.NamespaceClassRevisionSpec{
+                Class:    nsc.Name,
+                Revision: nsc.Generation,
+                Hash:     hash,
+                Template: *nsc.Spec.DeepCopy(),
+            },
+        }
+        if err := r.Create(ctx, revision); err != nil && !errors.IsAlreadyExists(err) {
+            logger.Error(err, "Failed to record revision", "revision", nsc.Generation)
+            return reconcile.Result{}, err
+        }
+        logger.Info("Recorded class revision", "revision", nsc.Generation)
+        revisions = append(revisions, *revision)
+    }
+
+    return reconcile.Result{}, r.pruneRevisions(ctx, nsc.Name, revisions)
+}
+
+// pruneRevisions deletes the oldest revisions beyond the history limit that
+// no namespace is pinned to.
+func (r *RevisionReconciler) pruneRevisions(ctx context.Context, className string, revisions []v1.NamespaceClassRevision) error {
+    limit := r.HistoryLimit
+    if limit <= 0 {
+        limit = DefaultRevisionHistoryLimit
+    }
+    if len(revisions) <= limit {
+        return nil
+    }
+
+    var nsList corev1.NamespaceList
+    if err := r.List(ctx, &nsList, client.MatchingLabels{LabelKey: className}); err != nil {
+        return err
+    }
+    pinned := make(map[string]bool)
+    for _, ns := range nsList.Items {
+        if revision := ns.Annotations[RevisionAnnotation]; revision != "" {
+            pinned[revision] = true
+        }
+    }
+
+    for _, revision := range revisions[:len(revisions)-limit] {
+        if pinned[strconv.FormatInt(revision.Spec.Revision, 10)] {
+            continue
+        }
+        if err := r.Delete(ctx, &revision); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// SetupWithManager sets up the revision controller with the Manager.
+func (r *RevisionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        Named("namespaceclassrevision").
+        For(&v1.NamespaceClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+        Owns(&v1.NamespaceClassRevision{}).
+        Complete(r)
+}
+
+// pinnedClass returns the class as of the revision a namespace is pinned
+// to. Only the spec is replaced; the metadata remains that of the class.
+func (r *NamespaceClassReconciler) pinnedClass(ctx context.Context, nsc *v1.NamespaceClass, pin string) (*v1.NamespaceClass, error) {
+    number, err := strconv.ParseInt(pin, 10, 64)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s annotation %q", RevisionAnnotation, pin)
+    }
+    revision := &v1.NamespaceClassRevision{}
+    if err := r.Get(ctx, types.NamespacedName{Name: RevisionName(nsc.Name, number)}, revision); err != nil {
+        if errors.IsNotFound(err) {
+            return nil, fmt.Errorf("revision %d of class %s does not exist", number, nsc.Name)
+        }
+        return nil, err
+    }
+    if revision.Spec.Class != nsc.Name {
+        return nil, fmt.Errorf("revision %s belongs to class %s", revision.Name, revision.Spec.Class)
+    }
+    pinned := nsc.DeepCopy()
+    pinned.Spec = *revision.Spec.Template.DeepCopy()
+    return pinned, nil
+}
+
+// RollbackClass restores the spec of a class from one of its revisions. A
+// revision of 0 selects the latest revision that differs from the current
+// spec. The rollback is recorded as a new revision of the class.
+func RollbackClass(ctx context.Context, c client.Client, className string, revision int64) (*v1.NamespaceClassRevision, error) {
+    nsc := &v1.NamespaceClass{}
+    if err := c.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        return nil, err
+    }
+    target, err := findRevision(ctx, c, nsc, revision)
+    if err != nil {
+        return nil, err
+    }
+    err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        if err := c.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+            return err
+        }
+        nsc.Spec = *target.Spec.Template.DeepCopy()
+        return c.Update(ctx, nsc)
+    })
+    return target, err
+}
+
+// PinNamespace pins a namespace to a revision of its class, or unpins it
+// when revision is 0.
+func PinNamespace(ctx context.Context, c client.Client, namespace string, revision int64) error {
+    ns := &corev1.Namespace{}
+    if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+        return err
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    if revision == 0 {
+        delete(ns.Annotations, RevisionAnnotation)
+        return c.Patch(ctx, ns, patch)
+    }
+
+    className := ns.Labels[LabelKey]
+    if className == "" {
+        return fmt.Errorf("namespace %s has no %s label", namespace, LabelKey)
+    }
+    if err := c.Get(ctx, types.NamespacedName{Name: RevisionName(className, revision)}, &v1.NamespaceClassRevision{}); err != nil {
+        if errors.IsNotFound(err) {
+            return fmt.Errorf("revision %d of class %s does not exist", revision, className)
+        }
+        return err
+    }
+    if ns.Annotations == nil {
+        ns.Annotations = make(map[string]string)
+    }
+    ns.Annotations[RevisionAnnotation] = strconv.FormatInt(revision, 10)
+    return c.Patch(ctx, ns, patch)
+}
+
+// findRevision returns the given revision of a class, or for 0 the latest
+// revision that differs from the current spec.
+func findRevision(ctx context.Context, c client.Client, nsc *v1.NamespaceClass, revision int64) (*v1.NamespaceClassRevision, error) {
+    if revision != 0 {
+        target := &v1.NamespaceClassRevision{}
+        if err := c.Get(ctx, types.NamespacedName{Name: RevisionName(nsc.Name, revision)}, target); err != nil {
+            if errors.IsNotFound(err) {
+                return nil, fmt.Errorf("revision %d of class %s does not exist", revision, nsc.Name)
+            }
+            return nil, err
+        }
+        return target, nil
+    }
+
+    current, err := classSpecHash(&nsc.Spec)
+    if err != nil {
+        return nil, err
+    }
+    revisions, err := ListRevisions(ctx, c, nsc.Name)
+    if err != nil {
+        return nil, err
+    }
+    for i := len(revisions) - 1; i >= 0; i-- {
+        if revisions[i].Spec.Hash != current {
+            return &revisions[i], nil
+        }
+    }
+    return nil, fmt.Errorf("class %s has no earlier revision", nsc.Name)
+}
@@ -0,0 +1,78 @@
+// internal/controller/revisions_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class revisions", func() {
+    It("should record revisions, roll back and pin namespaces", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        configMap := func(mode string) runtime.RawExtension {
+            return runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+                `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"mode":%q}}`, mode))}
+        }
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "public", Generation: 1},
+            Spec:       v1.NamespaceClassSpec{Resources: []runtime.RawExtension{configMap("strict")}},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nsc,
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}}},
+        ).Build()
+        revisions := &RevisionReconciler{Client: cl, HistoryLimit: 1}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "public"}}
+
+        _, err := revisions.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "public-1"}, &v1.NamespaceClassRevision{})).To(Succeed())
+
+        // Pinned revisions are kept beyond the history limit
+        Expect(PinNamespace(ctx, cl, "web", 1)).To(Succeed())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "public"}, nsc)).To(Succeed())
+        nsc.Spec.Resources = []runtime.RawExtension{configMap("relaxed")}
+        nsc.Generation = 2
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = revisions.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        history, err := ListRevisions(ctx, cl, "public")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(history).To(HaveLen(2))
+        Expect(history[1].Spec.Revision).To(Equal(int64(2)))
+
+        // The pinned namespace is rendered from its revision
+        reconciler := &NamespaceClassReconciler{Client: cl}
+        pinned, err := reconciler.pinnedClass(ctx, nsc, "1")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(string(pinned.Spec.Resources[0].Raw)).To(ContainSubstring("strict"))
+        _, err = reconciler.pinnedClass(ctx, nsc, "7")
+        Expect(err).To(HaveOccurred())
+
+        // Rolling back without a revision restores the previous spec
+        target, err := RollbackClass(ctx, cl, "public", 0)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(target.Spec.Revision).To(Equal(int64(1)))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "public"}, nsc)).To(Succeed())
+        Expect(string(nsc.Spec.Resources[0].Raw)).To(ContainSubstring("strict"))
+
+        // Unpinned revisions beyond the limit are pruned
+        Expect(PinNamespace(ctx, cl, "web", 0)).To(Succeed())
+        _, err = revisions.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        history, err = ListRevisions(ctx, cl, "public")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(history).To(HaveLen(1))
+    })
+})
@@ -0,0 +1,165 @@
+// internal/controller/rollback.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// appliedResource is a resource created or updated in the current sync of a
+// namespace, with the inventory entry it was applied as.
+type appliedResource struct {
+    entry  ManagedResource
+    result applyResult
+}
+
+// rolledBackError reports a resource of an Atomic class that failed to apply
+// after the resources applied before it in the same sync were reverted.
+type rolledBackError struct {
+    err      error
+    reverted int
+    failed   []string
+}
+
+func (e *rolledBackError) Error() string {
+    if len(e.failed) > 0 {
+        return fmt.Sprintf("%v; rolled back %d resources, but could not restore %s", e.err, e.reverted, strings.Join(e.failed, "; "))
+    }
+    return fmt.Sprintf("%v; rolled back %d resources", e.err, e.reverted)
+}
+
+func (e *rolledBackError) Unwrap() error {
+    return e.err
+}
+
+// rollBack reverts the resources applied in the current sync of a namespace
+// of an Atomic class once one of its resources failed to apply, newest
+// first, so the namespace is left as it was before the sync: created
+// resources are deleted again and updated ones are restored to the revision
+// of their class they were last applied from. Resources that were not
+// managed before the sync have no earlier version to restore and are left
+// as they are. Reverted resources are dropped from the apply results, so
+// they keep reporting their previous result. Other apply policies return
+// the apply error unchanged.
+func (r *NamespaceClassReconciler) rollBack(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, previousClass string, applied []appliedResource, currentManaged []ManagedResource, results applyResults, applyErr error) error {
+    if nsc.Spec.ApplyPolicy != v1.ApplyPolicyAtomic || len(applied) == 0 {
+        return applyErr
+    }
+    logger := log.FromContext(ctx)
+
+    tracked := make(map[string]ManagedResource, len(currentManaged))
+    for _, res := range currentManaged {
+        tracked[res.key()] = res
+    }
+    rollback := &rolledBackError{err: applyErr}
+    managed := currentManaged
+    for i := len(applied) - 1; i >= 0; i-- {
+        res := applied[i]
+        previous, wasTracked := tracked[res.entry.key()]
+        var err error
+        switch {
+        case res.result == applyResultCreated:
+            err = r.deleteResource(ctx, ns.Name, res.entry)
+        case !wasTracked:
+            err = fmt.Errorf("it was not managed before, so there is no earlier version")
+        default:
+            err = r.restoreResource(ctx, ns, res.entry, previous)
+        }
+        if err != nil {
+            logger.Error(err, "Failed to roll back resource", "kind", res.entry.Kind, "name", res.entry.Name)
+            rollback.failed = append(rollback.failed, fmt.Sprintf("%s %s: %v", res.entry.Kind, res.entry.Name, err))
+
+            // Keep tracking resources left in place, so they are not orphaned
+            if !wasTracked {
+                managed = append(managed, res.entry)
+            }
+            continue
+        }
+        rollback.reverted++
+        delete(results, res.entry.key())
+    }
+
+    logger.Info("Rolled back resources applied before a resource failed",
+        "reverted", rollback.reverted, "failed", len(rollback.failed))
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonRolledBack,
+        "Rolled back %d resources applied before the failure: %v", rollback.reverted, applyErr)
+    if len(managed) > len(currentManaged) {
+        inventoryClass := previousClass
+        if inventoryClass == "" {
+            inventoryClass = nsc.Name
+        }
+        if err := r.updateManagedResources(ctx, ns, inventoryClass, managed); err != nil {
+            logger.Error(err, "Failed to update managed resources")
+        }
+    }
+    return rollback
+}
+
+// restoreResource applies a resource again as it was rendered from the
+// revision of its class it was last applied from, identified by the hash in
+// its inventory entry.
+func (r *NamespaceClassReconciler) restoreResource(ctx context.Context, ns *corev1.Namespace, current, previous ManagedResource) error {
+    className := previous.Class
+    if className == "" {
+        className = current.Class
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := r.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        return err
+    }
+    revisions, err := ListRevisions(ctx, r.Client, className)
+    if err != nil {
+        return err
+    }
+    for i := len(revisions) - 1; i >= 0; i-- {
+        snapshot := nsc.DeepCopy()
+        snapshot.Spec = *revisions[i].Spec.Template.DeepCopy()
+        res, err := r.revisionResource(ctx, snapshot, ns, previous)
+        if err != nil {
+            return err
+        }
+        if res == nil {
+            continue
+        }
+        opts := renderOptions(res, snapshot)
+        opts.trackedClass = &current.Class
+        if opts.client, err = r.applyClient(snapshot); err != nil {
+            return err
+        }
+        _, err = r.createOrUpdateResource(ctx, res, opts)
+        return err
+    }
+    return fmt.Errorf("no revision of class %s matches the version last applied", className)
+}
+
+// revisionResource returns a resource as a revision of a class renders it
+// for a namespace, or nil if the revision does not render it with the hash
+// of the inventory entry.
+func (r *NamespaceClassReconciler) revisionResource(ctx context.Context, snapshot *v1.NamespaceClass, ns *corev1.Namespace, entry ManagedResource) (*unstructured.Unstructured, error) {
+    resources, err := r.parseResources(ctx, snapshot)
+    if err != nil {
+        return nil, err
+    }
+    if err := expandValues(ctx, r.Client, resources, ns, nil, snapshot); err != nil {
+        return nil, err
+    }
+    scopeClusterResources(resources, ns.Name, nil, snapshot)
+    for _, res := range resources {
+        if res.GetAPIVersion() != entry.APIVersion || res.GetKind() != entry.Kind || res.GetName() != entry.Name {
+            continue
+        }
+        if qualifiedHash(renderResource(res, ns.Name, snapshot)) == qualifiedHash(entry.Hash) {
+            return res, nil
+        }
+        return nil, nil
+    }
+    return nil, nil
+}
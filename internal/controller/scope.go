@@ -0,0 +1,112 @@
+// internal/controller/scope.go
+package controller
+
+import (
+    "slices"
+    "sync"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// Annotation on a namespace that opts it in to management when the
+// controller runs in opt-in mode
+const OptInAnnotation = "namespaceclass.akuity.io/opt-in"
+
+// DefaultSystemNamespaces are the namespaces of Kubernetes itself, which the
+// controller never manages.
+var DefaultSystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// WatchScope limits the controller to a fixed set of namespaces, for shared
+// clusters where the controller is only granted access to part of the
+// namespaces. Namespaced objects are only cached and managed in these
+// namespaces; cluster-scoped objects such as classes and namespaces are still
+// watched cluster-wide. Excluded namespaces are left alone even when they
+// are in scope; unlike the scope itself they can change at runtime. System
+// namespaces are always left alone, so that no class, selector or claim can
+// modify the namespaces the cluster depends on.
+type WatchScope struct {
+    // Namespaces are the namespaces the controller manages; empty manages
+    // all namespaces.
+    Namespaces []string
+
+    // System are namespaces the controller never manages, such as
+    // DefaultSystemNamespaces and the controller's own namespace.
+    System []string
+
+    // OptIn only manages namespaces annotated with OptInAnnotation, whatever
+    // their labels or the classes, claims and tenants selecting them, so
+    // that the controller can be adopted in an existing cluster one
+    // namespace at a time.
+    OptIn bool
+
+    mu       sync.RWMutex
+    excluded []string
+}
+
+// includes reports whether the controller manages a namespace. A nil or
+// empty scope includes every namespace that is not excluded.
+func (s *WatchScope) includes(namespace string) bool {
+    if s == nil {
+        return true
+    }
+    if slices.Contains(s.System, namespace) {
+        return false
+    }
+    s.mu.RLock()
+    excluded := slices.Contains(s.excluded, namespace)
+    s.mu.RUnlock()
+    if excluded {
+        return false
+    }
+    return len(s.Namespaces) == 0 || slices.Contains(s.Namespaces, namespace)
+}
+
+// admits reports whether the controller may manage an existing namespace in
+// scope: in opt-in mode only namespaces that opted in are admitted.
+func (s *WatchScope) admits(ns *corev1.Namespace) bool {
+    return s == nil || !s.OptIn || ns.Annotations[OptInAnnotation] == "true"
+}
+
+// optIn marks a namespace the controller creates as opted in, so that it is
+// managed in opt-in mode.
+func (s *WatchScope) optIn(ns *corev1.Namespace) {
+    if s == nil || !s.OptIn {
+        return
+    }
+    if ns.Annotations == nil {
+        ns.Annotations = make(map[string]string)
+    }
+    ns.Annotations[OptInAnnotation] = "true"
+}
+
+// SetExcluded replaces the namespaces the controller leaves alone. Excluded
+// namespaces keep the resources already applied to them, and namespaces
+// that are no longer excluded are synced on their next reconcile.
+func (s *WatchScope) SetExcluded(namespaces []string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.excluded = slices.Clone(namespaces)
+}
+
+// CacheNamespaces returns the namespaces the manager's cache is limited to,
+// or nil to cache all namespaces.
+func (s *WatchScope) CacheNamespaces() map[string]cache.Config {
+    if s == nil || len(s.Namespaces) == 0 {
+        return nil
+    }
+    namespaces := make(map[string]cache.Config, len(s.Namespaces))
+    for _, namespace := range s.Namespaces {
+        namespaces[namespace] = cache.Config{}
+    }
+    return namespaces
+}
+
+// listNamespaces returns the namespaces to list namespaced objects in, one
+// at a time; the empty namespace lists all of them at once.
+func (s *WatchScope) listNamespaces() []string {
+    if s == nil || len(s.Namespaces) == 0 {
+        return []string{""}
+    }
+    return s.Namespaces
+}
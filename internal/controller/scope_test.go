@@ -0,0 +1,160 @@
+// internal/controller/scope_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Watch scope", func() {
+    It("should only reconcile namespaces in the scope", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "team"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{LabelKey: "team"}}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{LabelKey: "team"}}},
+        ).Build()
+        scope := &WatchScope{Namespaces: []string{"team-a"}}
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Scope: scope}
+
+        for _, name := range []string{"team-a", "team-b"} {
+            _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+            Expect(err).NotTo(HaveOccurred())
+        }
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a"}, ns)).To(Succeed())
+        Expect(ns.Finalizers).To(ContainElement(NamespaceFinalizer))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-b"}, ns)).To(Succeed())
+        Expect(ns.Finalizers).To(BeEmpty())
+
+        Expect(scope.CacheNamespaces()).To(HaveKey("team-a"))
+        Expect((&WatchScope{}).CacheNamespaces()).To(BeNil())
+    })
+
+    It("should leave excluded namespaces alone until they are no longer excluded", func() {
+        scope := &WatchScope{}
+        scope.SetExcluded([]string{"kube-system"})
+        Expect(scope.includes("kube-system")).To(BeFalse())
+        Expect(scope.includes("team-a")).To(BeTrue())
+
+        scope.SetExcluded(nil)
+        Expect(scope.includes("kube-system")).To(BeTrue())
+    })
+
+    It("should never include system namespaces", func() {
+        scope := &WatchScope{
+            Namespaces: []string{"kube-system", "team-a"},
+            System:     append(DefaultSystemNamespaces, "namespaceclass-system"),
+        }
+        Expect(scope.includes("kube-system")).To(BeFalse())
+        Expect(scope.includes("kube-node-lease")).To(BeFalse())
+        Expect(scope.includes("namespaceclass-system")).To(BeFalse())
+        Expect(scope.includes("team-a")).To(BeTrue())
+
+        // Clearing the exclusions at runtime does not release them
+        scope.SetExcluded(nil)
+        Expect(scope.includes("kube-system")).To(BeFalse())
+    })
+
+    It("should release the finalizer of excluded and system namespaces being deleted", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        managed := func(name string) *corev1.Namespace {
+            return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       name,
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }}
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).
+            WithObjects(managed("legacy-team"), managed("controller-system")).
+            WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        scope := &WatchScope{System: []string{"controller-system"}}
+        scope.SetExcluded([]string{"legacy-team"})
+        reconciler := &NamespaceClassReconciler{
+            Client:   cl,
+            Scheme:   scheme,
+            Recorder: record.NewFakeRecorder(10),
+            Scope:    scope,
+        }
+
+        for _, name := range []string{"legacy-team", "controller-system"} {
+            ns := &corev1.Namespace{}
+            Expect(cl.Get(ctx, types.NamespacedName{Name: name}, ns)).To(Succeed())
+            Expect(cl.Delete(ctx, ns)).To(Succeed())
+
+            _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+            Expect(err).NotTo(HaveOccurred())
+            Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: name}, ns))).To(BeTrue(), name)
+        }
+    })
+})
+
+var _ = Describe("Opt-in mode", func() {
+    It("should only manage namespaces that opted in", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:   "legacy",
+                Labels: map[string]string{LabelKey: "web"},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{
+            Client:   cl,
+            Scheme:   scheme,
+            Recorder: record.NewFakeRecorder(10),
+            Scope:    &WatchScope{OptIn: true},
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "legacy"}}
+        settings := types.NamespacedName{Namespace: "legacy", Name: "settings"}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, settings, &corev1.ConfigMap{}))).To(BeTrue())
+
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "legacy"}, ns)).To(Succeed())
+        Expect(ns.Finalizers).NotTo(ContainElement(NamespaceFinalizer))
+        ns.Annotations = map[string]string{OptInAnnotation: "true"}
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        Eventually(func() error {
+            if _, err := reconciler.Reconcile(ctx, request); err != nil {
+                return err
+            }
+            return cl.Get(ctx, settings, &corev1.ConfigMap{})
+        }).Should(Succeed())
+    })
+
+    It("should mark the namespaces it creates as opted in", func() {
+        scope := &WatchScope{OptIn: true}
+        ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+        Expect(scope.admits(ns)).To(BeFalse())
+        scope.optIn(ns)
+        Expect(ns.Annotations).To(HaveKeyWithValue(OptInAnnotation, "true"))
+        Expect(scope.admits(ns)).To(BeTrue())
+
+        var all *WatchScope
+        Expect(all.admits(&corev1.Namespace{})).To(BeTrue())
+    })
+})
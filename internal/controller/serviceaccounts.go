@@ -0,0 +1,119 @@
+// internal/controller/serviceaccounts.go
+package controller
+
+import (
+    "context"
+    "encoding/base64"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation on objects copied into namespaces from a central source, such as
+// the image pull secret and replicated ConfigMaps, naming the object they were
+// copied from as namespace/name
+const CopiedFromAnnotation = "namespaceclass.akuity.io/copied-from"
+
+// How often namespaces with a copy of the central image pull secret are
+// synced again, so that rotated credentials reach them
+const imagePullSecretResyncInterval = 10 * time.Minute
+
+// ImagePullSecretSource is the central image pull secret that is copied into
+// namespaces whose classes attach it to service accounts.
+type ImagePullSecretSource struct {
+    // Reader is used to read the Secret, typically the manager's API reader
+    // so that Secrets are not cached cluster-wide
+    Reader client.Reader
+
+    // Secret identifies the central image pull secret
+    Secret types.NamespacedName
+}
+
+// serviceAccountResources expands the service accounts of a class into
+// ServiceAccounts. Image pull secrets are attached by the reconciler, which
+// can read the central Secret.
+func serviceAccountResources(serviceAccounts []v1.ClassServiceAccount) []*unstructured.Unstructured {
+    var resources []*unstructured.Unstructured
+    for _, sa := range serviceAccounts {
+        resources = append(resources, &unstructured.Unstructured{Object: map[string]interface{}{
+            "apiVersion": "v1",
+            "kind":       "ServiceAccount",
+            "metadata":   map[string]interface{}{"name": sa.Name},
+        }})
+    }
+    return resources
+}
+
+// attachImagePullSecret adds a copy of the central image pull secret to the
+// resources of a class and lists it in the imagePullSecrets of the service
+// accounts that ask for it. Resources of classes that attach no pull secret
+// are returned as they are.
+func (r *NamespaceClassReconciler) attachImagePullSecret(ctx context.Context, nsc *v1.NamespaceClass, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+    attach := make(map[string]bool)
+    for _, sa := range nsc.Spec.ServiceAccounts {
+        if sa.ImagePullSecret {
+            attach[sa.Name] = true
+        }
+    }
+    if len(attach) == 0 {
+        return resources, nil
+    }
+    if r.ImagePullSecret == nil || r.ImagePullSecret.Secret.Name == "" {
+        return nil, fmt.Errorf("class %s attaches an image pull secret, but no central image pull secret is configured", nsc.Name)
+    }
+
+    source := &corev1.Secret{}
+    if err := r.ImagePullSecret.Reader.Get(ctx, r.ImagePullSecret.Secret, source); err != nil {
+        return nil, fmt.Errorf("failed to get image pull secret %s: %w", r.ImagePullSecret.Secret, err)
+    }
+    for _, res := range resources {
+        if res.GetAPIVersion() == "v1" && res.GetKind() == "ServiceAccount" && attach[res.GetName()] {
+            res.Object["imagePullSecrets"] = []interface{}{map[string]interface{}{"name": source.Name}}
+        }
+    }
+    return append(resources, imagePullSecretCopy(source)), nil
+}
+
+// imagePullSecretCopy returns a copy of the central image pull secret to
+// apply in a namespace.
+func imagePullSecretCopy(source *corev1.Secret) *unstructured.Unstructured {
+    data := make(map[string]interface{}, len(source.Data))
+    for key, value := range source.Data {
+        data[key] = base64.StdEncoding.EncodeToString(value)
+    }
+    return &unstructured.Unstructured{Object: map[string]interface{}{
+        "apiVersion": "v1",
+        "kind":       "Secret",
+        "metadata": map[string]interface{}{
+            "name": source.Name,
+            "annotations": map[string]interface{}{
+                CopiedFromAnnotation: source.Namespace + "/" + source.Name,
+            },
+        },
+        "type": string(source.Type),
+        "data": data,
+    }}
+}
+
+// isImagePullSecretCopy reports whether a resource is a copy of the central
+// image pull secret.
+func isImagePullSecretCopy(res *unstructured.Unstructured) bool {
+    return res.GetKind() == "Secret" && res.GetAnnotations()[CopiedFromAnnotation] != ""
+}
+
+// copiesImagePullSecret reports whether the resources of a namespace include
+// a copy of the central image pull secret.
+func copiesImagePullSecret(resources []*unstructured.Unstructured) bool {
+    for _, res := range resources {
+        if isImagePullSecretCopy(res) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,53 @@
+// internal/controller/serviceaccounts_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class service accounts", func() {
+    It("should copy the central image pull secret and attach it to service accounts", func() {
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "registry"},
+            Type:       corev1.SecretTypeDockerConfigJson,
+            Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+        }).Build()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "team"},
+            Spec: v1.NamespaceClassSpec{ServiceAccounts: []v1.ClassServiceAccount{
+                {Name: "builder", ImagePullSecret: true},
+                {Name: "reader"},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+
+        r := &NamespaceClassReconciler{Client: cl}
+        _, err := r.parseResources(context.Background(), nsc)
+        Expect(err).To(MatchError(ContainSubstring("no central image pull secret is configured")))
+
+        r.ImagePullSecret = &ImagePullSecretSource{Reader: cl, Secret: types.NamespacedName{Namespace: "registry", Name: "regcred"}}
+        resources, err := r.parseResources(context.Background(), nsc)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources).To(HaveLen(3))
+        Expect(resources[0].Object["imagePullSecrets"]).To(Equal([]interface{}{map[string]interface{}{"name": "regcred"}}))
+        Expect(resources[1].Object).NotTo(HaveKey("imagePullSecrets"))
+        Expect(resources[2].GetKind()).To(Equal("Secret"))
+        Expect(resources[2].GetAnnotations()).To(HaveKeyWithValue(CopiedFromAnnotation, "registry/regcred"))
+        Expect(resources[2].Object["type"]).To(Equal(string(corev1.SecretTypeDockerConfigJson)))
+        Expect(resources[2].Object["data"]).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, "eyJhdXRocyI6e319"))
+        Expect((&DataProtectionPolicy{}).protects(resources[2])).To(BeEmpty())
+
+        nsc.Spec.ServiceAccounts = append(nsc.Spec.ServiceAccounts, v1.ClassServiceAccount{Name: "builder"})
+        Expect(ValidateClass(nsc)).To(HaveLen(1))
+    })
+})
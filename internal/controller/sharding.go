@@ -0,0 +1,59 @@
+// internal/controller/sharding.go
+package controller
+
+import (
+    "context"
+    "hash/fnv"
+
+    corev1 "k8s.io/api/core/v1"
+)
+
+// Sharding splits classes between several controller deployments by a hash
+// of the class name, for clusters with more namespaces than one controller
+// can keep up with. Each deployment runs with the same TotalShards and its
+// own Shard, and only reconciles the namespaces and classes of its shard.
+type Sharding struct {
+    // Shard is the shard of this controller, from 0 to TotalShards-1.
+    Shard int
+
+    // TotalShards is the number of controller deployments; 1 or less
+    // disables sharding.
+    TotalShards int
+}
+
+// owns reports whether a class belongs to the shard of this controller. A
+// nil or single shard owns every class. Namespaces without any class belong
+// to the shard of the empty class name.
+func (s *Sharding) owns(className string) bool {
+    if s == nil || s.TotalShards <= 1 {
+        return true
+    }
+    h := fnv.New32a()
+    h.Write([]byte(className))
+    return int(h.Sum32()%uint32(s.TotalShards)) == s.Shard
+}
+
+// Primary reports whether this controller runs the work that is not split
+// by class, such as claims and tenants; only the first shard does.
+func (s *Sharding) Primary() bool {
+    return s == nil || s.TotalShards <= 1 || s.Shard == 0
+}
+
+// ownsNamespace reports whether a namespace belongs to the shard of this
+// controller: the shard of the class it uses or, once it uses none, of the
+// class it last used, so that the same shard cleans it up.
+func (r *NamespaceClassReconciler) ownsNamespace(ctx context.Context, ns *corev1.Namespace) (bool, error) {
+    if r.Shard == nil || r.Shard.TotalShards <= 1 {
+        return true, nil
+    }
+    className, hasClass, err := r.resolveClass(ctx, ns)
+    if err != nil {
+        return false, err
+    }
+    if !hasClass {
+        if className, err = r.inventoryClass(ctx, ns.Name); err != nil {
+            return false, err
+        }
+    }
+    return r.Shard.owns(className), nil
+}
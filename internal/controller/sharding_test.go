@@ -0,0 +1,64 @@
+// internal/controller/sharding_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Sharding", func() {
+    It("should split classes between shards", func() {
+        shards := []*Sharding{{Shard: 0, TotalShards: 3}, {Shard: 1, TotalShards: 3}, {Shard: 2, TotalShards: 3}}
+        for _, className := range []string{"", "default", "team-a", "team-b", "restricted"} {
+            owners := 0
+            for _, shard := range shards {
+                if shard.owns(className) {
+                    owners++
+                }
+            }
+            Expect(owners).To(Equal(1), "class %q", className)
+        }
+        Expect((*Sharding)(nil).owns("team-a")).To(BeTrue())
+        Expect((&Sharding{TotalShards: 1}).owns("team-a")).To(BeTrue())
+        Expect((*Sharding)(nil).Primary()).To(BeTrue())
+        Expect(shards[0].Primary()).To(BeTrue())
+        Expect(shards[1].Primary()).To(BeFalse())
+    })
+
+    It("should only reconcile namespaces of classes in the shard", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        shards := []*Sharding{{Shard: 0, TotalShards: 2}, {Shard: 1, TotalShards: 2}}
+        owner := 0
+        if !shards[0].owns("team") {
+            owner = 1
+        }
+        for i, shard := range shards {
+            cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+                &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "team"}},
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{LabelKey: "team"}}},
+            ).Build()
+            reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Shard: shard}
+
+            _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}})
+            Expect(err).NotTo(HaveOccurred())
+            ns := &corev1.Namespace{}
+            Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a"}, ns)).To(Succeed())
+            if i == owner {
+                Expect(ns.Finalizers).To(ContainElement(NamespaceFinalizer))
+            } else {
+                Expect(ns.Finalizers).To(BeEmpty())
+            }
+        }
+    })
+})
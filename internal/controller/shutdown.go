@@ -0,0 +1,117 @@
+// internal/controller/shutdown.go
+package controller
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ShutdownFlushTimeout bounds the writes of collected class status results
+// on shutdown, after in-flight reconciles were drained
+const ShutdownFlushTimeout = 10 * time.Second
+
+// shutdownDrain lets the reconciles running when the controller is asked to
+// stop finish their applies instead of being cancelled halfway, so rolling
+// the controller does not leave namespaces with half-applied classes and
+// stale inventories. Once stopping, no new reconcile starts; reconciles that
+// already started keep an uncancelled context until the drain timeout.
+type shutdownDrain struct {
+    mu       sync.Mutex
+    stopping bool
+    running  int
+    idle     chan struct{}
+}
+
+// enter records the start of a reconcile. It returns false once the
+// controller is stopping, in which case the reconcile must not run.
+func (d *shutdownDrain) enter() bool {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    if d.stopping {
+        return false
+    }
+    d.running++
+    return true
+}
+
+// leave records the end of a reconcile.
+func (d *shutdownDrain) leave() {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    d.running--
+    if d.running == 0 && d.idle != nil {
+        close(d.idle)
+        d.idle = nil
+    }
+}
+
+// stop stops new reconciles from starting and returns a channel closed once
+// the running ones have finished.
+func (d *shutdownDrain) stop() <-chan struct{} {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    d.stopping = true
+    idle := make(chan struct{})
+    if d.running == 0 {
+        close(idle)
+    } else {
+        d.idle = idle
+    }
+    return idle
+}
+
+// drainContext returns the context a reconcile applies with: one that
+// outlives the controller's context by up to DrainTimeout, so a shutdown
+// lets in-flight applies finish. Without a drain timeout the context is
+// returned as it is.
+func (r *NamespaceClassReconciler) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+    if r.DrainTimeout <= 0 {
+        return ctx, func() {}
+    }
+    drained, cancel := context.WithCancel(context.WithoutCancel(ctx))
+    stop := context.AfterFunc(ctx, func() {
+        time.AfterFunc(r.DrainTimeout, cancel)
+    })
+    return drained, func() {
+        stop()
+        cancel()
+    }
+}
+
+// shutdownFlusher waits for in-flight reconciles when the manager stops and
+// then writes the class status results still collected in the status batch.
+// It runs alongside the controller, so the cache is still serving while it
+// flushes.
+type shutdownFlusher struct {
+    *NamespaceClassReconciler
+}
+
+// Start blocks until the manager stops, then drains.
+func (f *shutdownFlusher) Start(ctx context.Context) error {
+    <-ctx.Done()
+    logger := log.FromContext(ctx).WithName("shutdown")
+
+    timeout := time.NewTimer(f.DrainTimeout)
+    defer timeout.Stop()
+    logger.Info("Waiting for in-flight reconciles to finish", "timeout", f.DrainTimeout)
+    select {
+    case <-f.drain.stop():
+    case <-timeout.C:
+        logger.Info("Reconciles did not finish before the drain timeout")
+    }
+
+    flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ShutdownFlushTimeout)
+    defer cancel()
+    for className, updates := range f.statusBatch.takeAll() {
+        if err := f.writeClassStatus(flushCtx, className, updates); err != nil {
+            logger.Error(err, "Failed to update class status", "class", className, "namespaces", len(updates))
+        }
+    }
+    return nil
+}
@@ -0,0 +1,39 @@
+// internal/controller/shutdown_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Shutdown drain", func() {
+    It("should let in-flight reconciles finish and stop new ones", func() {
+        r := &NamespaceClassReconciler{DrainTimeout: 100 * time.Millisecond}
+        parent, stop := context.WithCancel(context.Background())
+        Expect(r.drain.enter()).To(BeTrue())
+        ctx, cancel := r.drainContext(parent)
+        defer cancel()
+
+        stop()
+        idle := r.drain.stop()
+        Expect(r.drain.enter()).To(BeFalse())
+        Consistently(ctx.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+        Eventually(ctx.Done(), time.Second).Should(BeClosed())
+
+        Expect(idle).NotTo(BeClosed())
+        r.drain.leave()
+        Expect(idle).To(BeClosed())
+    })
+
+    It("should reconcile nothing once the controller stopped", func() {
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+        r := &NamespaceClassReconciler{DrainTimeout: time.Second}
+        Expect(r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web"}})).To(Equal(reconcile.Result{}))
+    })
+})
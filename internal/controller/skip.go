@@ -0,0 +1,52 @@
+// internal/controller/skip.go
+package controller
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Annotation on a namespace that excludes it from all management by the
+// controller, for break-glass situations and special namespaces
+const SkipAnnotation = "namespaceclass.akuity.io/skip"
+
+// isSkipped reports whether a namespace carries the skip annotation.
+func isSkipped(ns *corev1.Namespace) bool {
+    return ns.Annotations[SkipAnnotation] == "true"
+}
+
+// releaseSkipped removes the finalizer from a namespace excluded from
+// management, so that the controller never holds up its deletion. Its
+// resources and inventory are left as they are, and are managed again once
+// the annotation is removed.
+func (r *NamespaceClassReconciler) releaseSkipped(ctx context.Context, ns *corev1.Namespace) error {
+    if !controllerutil.ContainsFinalizer(ns, NamespaceFinalizer) {
+        return nil
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    controllerutil.RemoveFinalizer(ns, NamespaceFinalizer)
+    return r.Patch(ctx, ns, patch)
+}
+
+// releaseExcluded releases a namespace outside the scope of the controller,
+// such as an excluded or system namespace, like releaseSkipped. The
+// namespace may have been managed before it was excluded, so it can still
+// carry the finalizer.
+func (r *NamespaceClassReconciler) releaseExcluded(ctx context.Context, name string) error {
+    ns := &corev1.Namespace{}
+    if err := r.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+        if errors.IsNotFound(err) {
+            return nil
+        }
+        return err
+    }
+    forgetNamespaceMetrics(name)
+    r.Notifications.forget(name)
+    r.MissingClassBackoff.reset(name)
+    return r.releaseSkipped(ctx, ns)
+}
@@ -0,0 +1,63 @@
+// internal/controller/skip_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Skip annotation", func() {
+    It("should leave skipped namespaces alone and release their finalizer", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:        "break-glass",
+                Labels:      map[string]string{LabelKey: "web"},
+                Annotations: map[string]string{SkipAnnotation: "true"},
+                Finalizers:  []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "break-glass"}}
+        settings := types.NamespacedName{Namespace: "break-glass", Name: "settings"}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "break-glass"}, ns)).To(Succeed())
+        Expect(ns.Finalizers).NotTo(ContainElement(NamespaceFinalizer))
+        Expect(errors.IsNotFound(cl.Get(ctx, settings, &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "break-glass"}, &v1.NamespaceClassInventory{}))).To(BeTrue())
+
+        // Removing the annotation resumes management
+        delete(ns.Annotations, SkipAnnotation)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        Eventually(func() error {
+            if _, err := reconciler.Reconcile(ctx, request); err != nil {
+                return err
+            }
+            return cl.Get(ctx, settings, &corev1.ConfigMap{})
+        }).Should(Succeed())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "break-glass"}, ns)).To(Succeed())
+        Expect(ns.Finalizers).To(ContainElement(NamespaceFinalizer))
+    })
+})
@@ -0,0 +1,306 @@
+// internal/controller/status.go
+package controller
+
+import (
+    "context"
+    stderrors "errors"
+    "fmt"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Sync failure messages are truncated to keep the class status small
+const maxSyncMessageLength = 256
+
+// syncClassMembership refreshes the status of the namespace's class and, if
+// the namespace switched classes or dropped its class, of the class it left.
+// sync is the result of syncing the namespace with className, if it has one.
+func (r *NamespaceClassReconciler) syncClassMembership(ctx context.Context, namespace, previousClass, className string, sync *v1.NamespaceSyncStatus) error {
+    if previousClass != "" && previousClass != className {
+        if err := r.updateNamespaceClassStatus(ctx, previousClass, namespace, nil); err != nil {
+            return err
+        }
+    }
+    if className == "" {
+        return nil
+    }
+    return r.updateNamespaceClassStatus(ctx, className, namespace, sync)
+}
+
+// recordSyncFailure reports a failed sync of a namespace in the status of its
+// class. Failures to record the failure are only logged.
+func (r *NamespaceClassReconciler) recordSyncFailure(ctx context.Context, namespace, className string, syncErr error) {
+    message := syncErr.Error()
+    if len(message) > maxSyncMessageLength {
+        message = message[:maxSyncMessageLength-3] + "..."
+    }
+    sync := &v1.NamespaceSyncStatus{
+        Name:         namespace,
+        Phase:        v1.SyncPhaseFailed,
+        LastSyncTime: metav1.Now(),
+        Message:      message,
+        Reason:       syncFailureReason(syncErr),
+    }
+    if err := r.updateNamespaceClassStatus(ctx, className, namespace, sync); err != nil {
+        log.FromContext(ctx).Error(err, "Failed to record sync failure", "class", className)
+    }
+}
+
+// syncFailureReason returns the reason of sync failures the class reports on
+// its own, or an empty string for other failures.
+func syncFailureReason(err error) string {
+    var kindErr *kindNotInstalledError
+    var permissionErr *missingPermissionsError
+    var kindsErr *forbiddenKindsError
+    var circuitErr *circuitOpenError
+    switch {
+    case stderrors.As(err, &kindErr):
+        return v1.ReasonKindNotInstalled
+    case stderrors.As(err, &permissionErr):
+        return v1.ReasonPermissionDenied
+    case stderrors.As(err, &kindsErr):
+        return v1.ReasonKindForbidden
+    case stderrors.As(err, &circuitErr):
+        return v1.ReasonCircuitOpen
+    }
+    return ""
+}
+
+// failureMessages lists the distinct messages of the namespaces whose last
+// sync failed for a reason.
+func failureMessages(results map[string]v1.NamespaceSyncStatus, failed []string, reason string) []string {
+    seen := make(map[string]bool)
+    var messages []string
+    for _, namespace := range failed {
+        result := results[namespace]
+        if result.Reason != reason || seen[result.Message] {
+            continue
+        }
+        seen[result.Message] = true
+        messages = append(messages, result.Message)
+    }
+    sort.Strings(messages)
+    return messages
+}
+
+// updateNamespaceClassStatus records the sync result of namespace in the
+// status of a class, immediately or, with a status batch window, together
+// with the other results of the class collected during the window. A nil
+// sync result means the namespace no longer uses the class, and an empty
+// namespace only refreshes the class.
+func (r *NamespaceClassReconciler) updateNamespaceClassStatus(ctx context.Context, className, namespace string, sync *v1.NamespaceSyncStatus) error {
+    if r.StatusBatchWindow > 0 {
+        r.statusBatch.add(className, namespace, sync, r.StatusBatchWindow, r.flushClassStatus)
+        return nil
+    }
+    updates := make(map[string]*v1.NamespaceSyncStatus)
+    if namespace != "" {
+        updates[namespace] = sync
+    }
+    return r.writeClassStatus(ctx, className, updates)
+}
+
+// writeClassStatus recomputes the namespaces using a class from the
+// inventories labelled with it and records the sync results of updated
+// namespaces. The list is served from the cache, which may not reflect the
+// inventories just written for them yet, so a nil sync result means the
+// namespace no longer uses the class and any other value means it does. The
+// status is only written if it changed; a result that only differs in its
+// sync time counts as a change once the previous time is
+// syncTimeRefreshInterval old.
+func (r *NamespaceClassReconciler) writeClassStatus(ctx context.Context, className string, updates map[string]*v1.NamespaceSyncStatus) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        // Get latest NamespaceClass
+        nsc := &v1.NamespaceClass{}
+        if err := r.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+            if errors.IsNotFound(err) {
+                forgetClassMetrics(className)
+            }
+            return client.IgnoreNotFound(err)
+        }
+        
+        inventories := &v1.NamespaceClassInventoryList{}
+        if err := r.List(ctx, inventories, client.MatchingLabels{LabelKey: className}); err != nil {
+            return err
+        }
+        var namespaces []string
+        for _, inv := range inventories.Items {
+            if _, updated := updates[inv.Name]; !updated && inv.DeletionTimestamp.IsZero() {
+                namespaces = append(namespaces, inv.Name)
+            }
+        }
+        for namespace, sync := range updates {
+            if sync != nil {
+                namespaces = append(namespaces, namespace)
+            }
+        }
+        sort.Strings(namespaces)
+        
+        before := nsc.Status.DeepCopy()
+        previous := make(map[string]v1.NamespaceSyncStatus, len(nsc.Status.Namespaces))
+        for _, result := range nsc.Status.Namespaces {
+            previous[result.Name] = result
+        }
+        
+        // Keep sync results of namespaces still using the class
+        var results []v1.NamespaceSyncStatus
+        for _, result := range nsc.Status.Namespaces {
+            if _, updated := updates[result.Name]; updated || !slices.Contains(namespaces, result.Name) {
+                continue
+            }
+            results = append(results, result)
+        }
+        for namespace, sync := range updates {
+            if sync == nil {
+                continue
+            }
+            result := *sync
+            last, ok := previous[namespace]
+            // A failed sync does not change what is applied in the namespace
+            if ok && result.Phase == v1.SyncPhaseFailed {
+                result.DesiredResources = last.DesiredResources
+                result.ManagedResources = last.ManagedResources
+                result.ObservedGeneration = last.ObservedGeneration
+            }
+            if ok && time.Since(last.LastSyncTime.Time) < syncTimeRefreshInterval {
+                unchanged := result
+                unchanged.LastSyncTime = last.LastSyncTime
+                if equality.Semantic.DeepEqual(unchanged, last) {
+                    result = last
+                }
+            }
+            results = append(results, result)
+        }
+        sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+        
+        if !slices.Equal(nsc.Status.ManagedNamespaces, namespaces) || nsc.Status.ManagedNamespaceCount != len(namespaces) {
+            nsc.Status.LastUpdateTime = metav1.Now()
+        }
+        nsc.Status.ManagedNamespaces = namespaces
+        nsc.Status.ManagedNamespaceCount = len(namespaces)
+        recordClassNamespaces(className, len(namespaces))
+        nsc.Status.Namespaces = results
+        setClassConditions(nsc)
+        if equality.Semantic.DeepEqual(before, &nsc.Status) {
+            return nil
+        }
+        return r.Status().Update(ctx, nsc)
+    })
+}
+
+// setClassConditions derives the Ready, Progressing, and Degraded conditions
+// of a class from the sync results of its namespaces. A namespace is still
+// progressing until it was synced with the current generation of the class.
+func setClassConditions(nsc *v1.NamespaceClass) {
+    results := make(map[string]v1.NamespaceSyncStatus, len(nsc.Status.Namespaces))
+    for _, result := range nsc.Status.Namespaces {
+        results[result.Name] = result
+    }
+    var failed, pending, suspended []string
+    for _, namespace := range nsc.Status.ManagedNamespaces {
+        result, ok := results[namespace]
+        switch {
+        case ok && result.Phase == v1.SyncPhaseFailed:
+            failed = append(failed, namespace)
+        case ok && result.Phase == v1.SyncPhaseSuspended:
+            suspended = append(suspended, namespace)
+        case !ok || result.Phase == v1.SyncPhasePending || result.ObservedGeneration < nsc.Generation:
+            pending = append(pending, namespace)
+        }
+    }
+    
+    nsc.Status.ObservedGeneration = nsc.Generation
+    condition := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+        meta.SetStatusCondition(&nsc.Status.Conditions, metav1.Condition{
+            Type:               conditionType,
+            Status:             status,
+            ObservedGeneration: nsc.Generation,
+            Reason:             reason,
+            Message:            message,
+        })
+    }
+    
+    // Failures the class can explain are reported with their own reason
+    degradedReason, degradedMessage := v1.ReasonSyncFailed, ""
+    for _, reason := range []string{v1.ReasonCircuitOpen, v1.ReasonKindForbidden, v1.ReasonKindNotInstalled, v1.ReasonPermissionDenied} {
+        if messages := failureMessages(results, failed, reason); len(messages) > 0 {
+            degradedReason, degradedMessage = reason, strings.Join(messages, "; ")+"; "
+            break
+        }
+    }
+    if len(failed) > 0 {
+        condition(v1.ConditionDegraded, metav1.ConditionTrue, degradedReason,
+            fmt.Sprintf("%s%d namespace(s) failed to sync: %s", degradedMessage, len(failed), summarizeNames(failed)))
+    } else {
+        condition(v1.ConditionDegraded, metav1.ConditionFalse, v1.ReasonNamespacesSynced, "No namespace failed to sync")
+    }
+    if len(pending) > 0 {
+        condition(v1.ConditionProgressing, metav1.ConditionTrue, v1.ReasonNamespacesPending,
+            fmt.Sprintf("%d namespace(s) not synced yet: %s", len(pending), summarizeNames(pending)))
+    } else {
+        condition(v1.ConditionProgressing, metav1.ConditionFalse, v1.ReasonNamespacesSynced, "All namespaces are synced")
+    }
+    
+    switch {
+    case len(failed) > 0:
+        condition(v1.ConditionReady, metav1.ConditionFalse, v1.ReasonSyncFailed,
+            fmt.Sprintf("%d namespace(s) failed to sync", len(failed)))
+    case len(pending) > 0:
+        condition(v1.ConditionReady, metav1.ConditionFalse, v1.ReasonNamespacesPending,
+            fmt.Sprintf("%d namespace(s) not synced yet", len(pending)))
+    case len(suspended) > 0:
+        condition(v1.ConditionReady, metav1.ConditionFalse, v1.ReasonSuspended,
+            fmt.Sprintf("%d namespace(s) suspended: %s", len(suspended), summarizeNames(suspended)))
+    default:
+        condition(v1.ConditionReady, metav1.ConditionTrue, v1.ReasonNamespacesSynced,
+            fmt.Sprintf("All %d namespace(s) are synced", len(nsc.Status.ManagedNamespaces)))
+    }
+
+    if nsc.Spec.Deprecated {
+        condition(v1.ConditionDeprecated, metav1.ConditionTrue, v1.ReasonDeprecated, DeprecationMessage(nsc))
+    } else {
+        meta.RemoveStatusCondition(&nsc.Status.Conditions, v1.ConditionDeprecated)
+    }
+}
+
+// DeprecationMessage explains that a class is deprecated and which class to
+// use instead.
+func DeprecationMessage(nsc *v1.NamespaceClass) string {
+    if nsc.Spec.Replacement != "" {
+        return fmt.Sprintf("NamespaceClass %s is deprecated; use %s instead", nsc.Name, nsc.Spec.Replacement)
+    }
+    return fmt.Sprintf("NamespaceClass %s is deprecated", nsc.Name)
+}
+
+// summarizeNames lists the first few names, keeping condition messages short.
+func summarizeNames(names []string) string {
+    const max = 5
+    if len(names) <= max {
+        return strings.Join(names, ", ")
+    }
+    return fmt.Sprintf("%s and %d more", strings.Join(names[:max], ", "), len(names)-max)
+}
+
+// classStatusReconciler refreshes the status of a class when its spec changes,
+// so classes without namespaces report their conditions too.
+type classStatusReconciler struct {
+    *NamespaceClassReconciler
+}
+
+func (r *classStatusReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+    return reconcile.Result{}, r.updateNamespaceClassStatus(ctx, req.Name, "", nil)
+}
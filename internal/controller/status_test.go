@@ -0,0 +1,79 @@
+// internal/controller/status_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class status", func() {
+    It("should remove namespaces that switched to another class", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        classes := []client.Object{
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "public"},
+                Status:     v1.NamespaceClassStatus{ManagedNamespaces: []string{"team", "web"}},
+            },
+            &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "internal"}},
+            &v1.NamespaceClassInventory{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}}},
+        }
+        reconciler := &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(classes...).
+                WithStatusSubresource(&v1.NamespaceClass{}).Build(),
+            Scheme: scheme,
+        }
+
+        Expect(reconciler.syncClassMembership(ctx, "team", "public", "internal", &v1.NamespaceSyncStatus{
+            Name: "team", Phase: v1.SyncPhaseSynced, DesiredResources: 2, ManagedResources: 2,
+        })).To(Succeed())
+
+        public := &v1.NamespaceClass{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "public"}, public)).To(Succeed())
+        Expect(public.Status.ManagedNamespaces).To(Equal([]string{"web"}))
+        Expect(public.Status.ManagedNamespaceCount).To(Equal(1))
+
+        internal := &v1.NamespaceClass{}
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "internal"}, internal)).To(Succeed())
+        Expect(internal.Status.ManagedNamespaces).To(Equal([]string{"team"}))
+        Expect(internal.Status.Namespaces).To(HaveLen(1))
+        Expect(internal.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseSynced))
+        Expect(meta.IsStatusConditionTrue(internal.Status.Conditions, v1.ConditionReady)).To(BeTrue())
+
+        reconciler.recordSyncFailure(ctx, "team", "internal", fmt.Errorf("quota exceeded"))
+        Expect(reconciler.Get(ctx, types.NamespacedName{Name: "internal"}, internal)).To(Succeed())
+        Expect(internal.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseFailed))
+        Expect(internal.Status.Namespaces[0].Message).To(Equal("quota exceeded"))
+        Expect(internal.Status.Namespaces[0].ManagedResources).To(Equal(2))
+        Expect(meta.IsStatusConditionTrue(internal.Status.Conditions, v1.ConditionDegraded)).To(BeTrue())
+        Expect(meta.IsStatusConditionFalse(internal.Status.Conditions, v1.ConditionReady)).To(BeTrue())
+    })
+})
+
+var _ = Describe("Class deprecation", func() {
+    It("should report deprecated classes with a Deprecated condition", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "legacy", Generation: 2},
+            Spec:       v1.NamespaceClassSpec{Deprecated: true, Replacement: "restricted"},
+        }
+        setClassConditions(nsc)
+        deprecated := meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDeprecated)
+        Expect(deprecated).NotTo(BeNil())
+        Expect(deprecated.Status).To(Equal(metav1.ConditionTrue))
+        Expect(deprecated.Message).To(Equal("NamespaceClass legacy is deprecated; use restricted instead"))
+
+        nsc.Spec.Deprecated = false
+        setClassConditions(nsc)
+        Expect(meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionDeprecated)).To(BeNil())
+    })
+})
@@ -0,0 +1,104 @@
+// internal/controller/statusbatch.go
+package controller
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Sync results that only differ in their time are written at most this often
+const syncTimeRefreshInterval = 5 * time.Minute
+
+// statusBatch collects the sync results of the namespaces of each class
+// during the aggregation window, so that a rollout to many namespaces
+// updates the class status once per window rather than once per namespace.
+type statusBatch struct {
+    mu      sync.Mutex
+    pending map[string]map[string]*v1.NamespaceSyncStatus
+}
+
+// add records the sync result of a namespace for the next write of its
+// class status, replacing any result recorded for it before. A nil result
+// records that the namespace no longer uses the class, and an empty
+// namespace only refreshes the class. The first result of a class schedules
+// flush once the window has passed.
+func (b *statusBatch) add(className, namespace string, sync *v1.NamespaceSyncStatus, window time.Duration, flush func(string)) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    updates := b.collect(className, window, flush)
+    if namespace != "" {
+        updates[namespace] = sync
+    }
+}
+
+// restore collects results that could not be written again, unless a newer
+// result for the same namespace was collected in the meantime.
+func (b *statusBatch) restore(className string, results map[string]*v1.NamespaceSyncStatus, window time.Duration, flush func(string)) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    updates := b.collect(className, window, flush)
+    for namespace, sync := range results {
+        if _, newer := updates[namespace]; !newer {
+            updates[namespace] = sync
+        }
+    }
+}
+
+// collect returns the results collected for a class, scheduling flush if
+// none were collected yet. The caller must hold the lock.
+func (b *statusBatch) collect(className string, window time.Duration, flush func(string)) map[string]*v1.NamespaceSyncStatus {
+    if b.pending == nil {
+        b.pending = make(map[string]map[string]*v1.NamespaceSyncStatus)
+    }
+    updates, scheduled := b.pending[className]
+    if !scheduled {
+        updates = make(map[string]*v1.NamespaceSyncStatus)
+        b.pending[className] = updates
+        time.AfterFunc(window, func() { flush(className) })
+    }
+    return updates
+}
+
+// take removes and returns the results collected for a class.
+func (b *statusBatch) take(className string) map[string]*v1.NamespaceSyncStatus {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    updates := b.pending[className]
+    delete(b.pending, className)
+    return updates
+}
+
+// takeAll removes and returns the results collected for every class.
+func (b *statusBatch) takeAll() map[string]map[string]*v1.NamespaceSyncStatus {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    pending := b.pending
+    b.pending = nil
+    return pending
+}
+
+// flushClassStatus writes the sync results collected for a class in one
+// update. Results that could not be written are retried in the next window.
+// Nothing is written if the results were already flushed, e.g. on shutdown.
+func (r *NamespaceClassReconciler) flushClassStatus(className string) {
+    logger := log.Log.WithValues("class", className, "controller", "NamespaceClassReconciler")
+    ctx := log.IntoContext(context.Background(), logger)
+
+    updates := r.statusBatch.take(className)
+    if updates == nil {
+        return
+    }
+    if err := r.writeClassStatus(ctx, className, updates); err != nil {
+        logger.Error(err, "Failed to update class status", "namespaces", len(updates))
+        r.statusBatch.restore(className, updates, r.StatusBatchWindow, r.flushClassStatus)
+    }
+}
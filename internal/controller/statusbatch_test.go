@@ -0,0 +1,66 @@
+// internal/controller/statusbatch_test.go
+package controller
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class status batching", func() {
+    It("should write the results of a class in one update per window", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        var mu sync.Mutex
+        updates := 0
+        cl := fake.NewClientBuilder().WithScheme(scheme).
+            WithObjects(&v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "team", Generation: 1}}).
+            WithStatusSubresource(&v1.NamespaceClass{}).
+            WithInterceptorFuncs(interceptor.Funcs{
+                SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+                    mu.Lock()
+                    updates++
+                    mu.Unlock()
+                    return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+                },
+            }).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, StatusBatchWindow: 50 * time.Millisecond}
+        writes := func() int {
+            mu.Lock()
+            defer mu.Unlock()
+            return updates
+        }
+
+        synced := func(name string) *v1.NamespaceSyncStatus {
+            return &v1.NamespaceSyncStatus{Name: name, Phase: v1.SyncPhaseSynced, LastSyncTime: metav1.Now(), ObservedGeneration: 1}
+        }
+        for _, name := range []string{"team-a", "team-b", "team-c"} {
+            Expect(cl.Create(ctx, &v1.NamespaceClassInventory{
+                ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{LabelKey: "team"}},
+            })).To(Succeed())
+            Expect(reconciler.updateNamespaceClassStatus(ctx, "team", name, synced(name))).To(Succeed())
+        }
+        Expect(writes()).To(BeZero())
+        nsc := &v1.NamespaceClass{}
+        Eventually(func() []string {
+            Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, nsc)).To(Succeed())
+            return nsc.Status.ManagedNamespaces
+        }).Should(Equal([]string{"team-a", "team-b", "team-c"}))
+        Expect(writes()).To(Equal(1))
+
+        // Syncing again with the same result does not write the status
+        Expect(reconciler.updateNamespaceClassStatus(ctx, "team", "team-a", synced("team-a"))).To(Succeed())
+        Consistently(writes, 200*time.Millisecond, 20*time.Millisecond).Should(Equal(1))
+    })
+})
@@ -0,0 +1,37 @@
+// internal/controller/suite_test.go
+package controller
+
+import (
+    "testing"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+func TestController(t *testing.T) {
+    RegisterFailHandler(Fail)
+    RunSpecs(t, "Controller Suite")
+}
+
+// newScheme returns a scheme with the core types and those of the
+// controller; specs needing other groups add them.
+func newScheme() *runtime.Scheme {
+    scheme := runtime.NewScheme()
+    Expect(corev1.AddToScheme(scheme)).To(Succeed())
+    Expect(v1.AddToScheme(scheme)).To(Succeed())
+    return scheme
+}
+
+// drainEvents returns the events recorded so far.
+func drainEvents(recorder *record.FakeRecorder) []string {
+    var events []string
+    for len(recorder.Events) > 0 {
+        events = append(events, <-recorder.Events)
+    }
+    return events
+}
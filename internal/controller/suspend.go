@@ -0,0 +1,137 @@
+// internal/controller/suspend.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // Annotation on a namespace that stops the controller from changing it
+    PausedAnnotation = "namespaceclass.akuity.io/paused"
+
+    // How often suspended namespaces are checked for drift
+    suspendedRequeueInterval = 5 * time.Minute
+)
+
+// isPaused reports whether a namespace carries the pause annotation.
+func isPaused(ns *corev1.Namespace) bool {
+    return ns.Annotations[PausedAnnotation] == "true"
+}
+
+// suspension describes why a namespace must not be changed, or returns an
+// empty string if it may be.
+func suspension(ns *corev1.Namespace, nsc *v1.NamespaceClass) string {
+    switch {
+    case nsc.Spec.Suspend:
+        return fmt.Sprintf("Class %s is suspended", nsc.Name)
+    case isPaused(ns):
+        return fmt.Sprintf("Namespace is paused by the %s annotation", PausedAnnotation)
+    }
+    return ""
+}
+
+// classSuspended reports whether a class exists and is suspended, so that
+// namespaces leaving it keep its resources until it is resumed.
+func (r *NamespaceClassReconciler) classSuspended(ctx context.Context, className string) (bool, error) {
+    if className == "" {
+        return false, nil
+    }
+    nsc := &v1.NamespaceClass{}
+    if err := r.Get(ctx, client.ObjectKey{Name: className}, nsc); err != nil {
+        if errors.IsNotFound(err) {
+            return false, nil
+        }
+        return false, err
+    }
+    return nsc.Spec.Suspend, nil
+}
+
+// reportSuspended checks a namespace that must not be changed for drift
+// from its class and records the result in the class status, without
+// applying or pruning anything.
+func (r *NamespaceClassReconciler) reportSuspended(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, desired []*unstructured.Unstructured, sources resourceClasses, currentManaged []ManagedResource, previousClass, reason string) (reconcile.Result, error) {
+    logger := log.FromContext(ctx)
+
+    drifted, err := r.countOutOfSync(ctx, ns, nsc, desired, sources, currentManaged)
+    if err != nil {
+        logger.Error(err, "Failed to check suspended namespace for drift")
+        return reconcile.Result{}, err
+    }
+    logger.Info("Skipping suspended namespace", "reason", reason, "driftedResources", drifted)
+    recordDriftedResources(ns.Name, nsc.Name, drifted)
+
+    sync := &v1.NamespaceSyncStatus{
+        Name:               ns.Name,
+        Phase:              v1.SyncPhaseSuspended,
+        LastSyncTime:       metav1.Now(),
+        ObservedGeneration: nsc.Generation,
+        DesiredResources:   len(desired),
+        ManagedResources:   len(currentManaged),
+        DriftedResources:   drifted,
+        Message:            fmt.Sprintf("%s; %d resource(s) out of sync", reason, drifted),
+    }
+    if err := r.syncClassMembership(ctx, ns.Name, previousClass, nsc.Name, sync); err != nil {
+        logger.Error(err, "Failed to update NamespaceClass status")
+        return reconcile.Result{}, err
+    }
+    return reconcile.Result{RequeueAfter: r.jittered(suspendedRequeueInterval)}, nil
+}
+
+// countOutOfSync counts the resources a sync would create, update or prune.
+func (r *NamespaceClassReconciler) countOutOfSync(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, desired []*unstructured.Unstructured, sources resourceClasses, currentManaged []ManagedResource) (int, error) {
+    count := 0
+    desiredKeys := make(map[string]bool)
+    for _, res := range desired {
+        source := sources.of(res, nsc)
+        renderResource(res, ns.Name, source)
+        desiredKeys[fmt.Sprintf("%s/%s/%s", res.GetAPIVersion(), res.GetKind(), res.GetName())] = true
+
+        existing := &unstructured.Unstructured{}
+        existing.SetGroupVersionKind(res.GroupVersionKind())
+        if err := r.Get(ctx, client.ObjectKeyFromObject(res), existing); err != nil {
+            if errors.IsNotFound(err) {
+                count++
+                continue
+            }
+            return 0, err
+        }
+        if isHNCPropagated(existing) {
+            continue
+        }
+        opts := renderOptions(res, source)
+        if !isManagedByController(existing) || needsUpdate(existing, res, opts) || isDrifted(existing, res, opts) {
+            count++
+        }
+    }
+
+    for _, res := range currentManaged {
+        if desiredKeys[res.key()] {
+            continue
+        }
+        obj := &unstructured.Unstructured{}
+        obj.SetAPIVersion(res.APIVersion)
+        obj.SetKind(res.Kind)
+        if err := r.Get(ctx, res.objectKey(ns.Name), obj); err != nil {
+            if errors.IsNotFound(err) {
+                continue
+            }
+            return 0, err
+        }
+        if isManagedByController(obj) {
+            count++
+        }
+    }
+    return count, nil
+}
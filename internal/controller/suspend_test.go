@@ -0,0 +1,124 @@
+// internal/controller/suspend_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Suspension", func() {
+    It("should only report drift while a class is suspended or a namespace paused", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{
+                Suspend: true,
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                },
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        settings := types.NamespacedName{Namespace: "team", Name: "settings"}
+
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(Equal(suspendedRequeueInterval))
+        Expect(errors.IsNotFound(cl.Get(ctx, settings, &corev1.ConfigMap{}))).To(BeTrue())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces).To(HaveLen(1))
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseSuspended))
+        Expect(nsc.Status.Namespaces[0].DriftedResources).To(Equal(1))
+        Expect(meta.FindStatusCondition(nsc.Status.Conditions, v1.ConditionReady).Reason).To(Equal(v1.ReasonSuspended))
+
+        // Resuming the class applies it, unless the namespace is paused
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        ns.Annotations = map[string]string{PausedAnnotation: "true"}
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        nsc.Spec.Suspend = false
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, settings, &corev1.ConfigMap{}))).To(BeTrue())
+
+        delete(ns.Annotations, PausedAnnotation)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, settings, &corev1.ConfigMap{})).To(Succeed())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        Expect(nsc.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseSynced))
+    })
+
+    It("should not prune the resources of a suspended class the namespace left", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "web"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                }},
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        settings := types.NamespacedName{Namespace: "team", Name: "settings"}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, settings, &corev1.ConfigMap{})).To(Succeed())
+
+        // Suspend the class, then remove the namespace from it
+        nsc := &v1.NamespaceClass{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.Suspend = true
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, request.NamespacedName, ns)).To(Succeed())
+        delete(ns.Labels, LabelKey)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+        Expect(cl.Get(ctx, settings, &corev1.ConfigMap{})).To(Succeed())
+
+        // Resuming the class prunes them
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "web"}, nsc)).To(Succeed())
+        nsc.Spec.Suspend = false
+        Expect(cl.Update(ctx, nsc)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, settings, &corev1.ConfigMap{}))).To(BeTrue())
+    })
+})
@@ -0,0 +1,93 @@
+// internal/controller/sweep.go
+package controller
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/util/sets"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// startupSweep queues every namespace the controller may have to converge
+// once it wins the leader election: namespaces with a class, add-on classes
+// or an HNC parent, and namespaces whose inventory still tracks resources,
+// e.g. because their label was removed while the controller was down. The
+// sweep does not rely on watch events, so changes missed while no replica
+// was leading are converged promptly. Namespaces still waiting in the queue
+// are not queued twice, and the sweep counts against the warm-up budget
+// like the initial listing.
+type startupSweep struct {
+    *NamespaceClassReconciler
+
+    // reader lists directly from the API server
+    reader client.Reader
+    events chan event.GenericEvent
+}
+
+// Start queues the namespaces once and returns.
+func (s *startupSweep) Start(ctx context.Context) error {
+    logger := log.FromContext(ctx).WithName("startup-sweep")
+    names, err := s.namespaces(ctx)
+    if err != nil {
+        // The informer still replays every namespace, so this is not fatal
+        logger.Error(err, "Failed to list namespaces to sweep")
+        return nil
+    }
+    for _, name := range names {
+        ns := &corev1.Namespace{}
+        ns.Name = name
+        select {
+        case s.events <- event.GenericEvent{Object: ns}:
+        case <-ctx.Done():
+            return nil
+        }
+    }
+    logger.Info("Queued namespaces for startup reconciliation", "namespaces", len(names))
+    return nil
+}
+
+// namespaces returns the names of the namespaces in scope that have a class
+// or an inventory tracking resources.
+func (s *startupSweep) namespaces(ctx context.Context) ([]string, error) {
+    names := sets.New[string]()
+    var nsList corev1.NamespaceList
+    err := ListPages(ctx, s.reader, &nsList, func() error {
+        for i := range nsList.Items {
+            ns := &nsList.Items[i]
+            if ns.Labels[LabelKey] != "" || ns.Annotations[ClassesAnnotation] != "" ||
+                ns.Annotations[HNCSubnamespaceOfAnnotation] != "" {
+                names.Insert(ns.Name)
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    var inventories v1.NamespaceClassInventoryList
+    err = ListPages(ctx, s.reader, &inventories, func() error {
+        for _, inv := range inventories.Items {
+            if len(inv.Spec.Resources) > 0 {
+                names.Insert(inv.Spec.Namespace)
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    var sweep []string
+    for _, name := range sets.List(names) {
+        if s.Scope.includes(name) {
+            sweep = append(sweep, name)
+        }
+    }
+    return sweep, nil
+}
@@ -0,0 +1,49 @@
+// internal/controller/sweep_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Startup sweep", func() {
+    It("should queue namespaces with a class or tracked resources", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{LabelKey: "public"}}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", Labels: map[string]string{LabelKey: "public"}}},
+            &v1.NamespaceClassInventory{
+                ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"},
+                Spec: v1.NamespaceClassInventorySpec{
+                    Namespace: "unlabeled",
+                    Resources: []v1.InventoryEntry{{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", Class: "public"}},
+                },
+            },
+        ).Build()
+        events := make(chan event.GenericEvent, 10)
+        sweep := &startupSweep{
+            NamespaceClassReconciler: &NamespaceClassReconciler{Client: cl, Scheme: scheme, Scope: &WatchScope{System: DefaultSystemNamespaces}},
+            reader:                   cl,
+            events:                   events,
+        }
+
+        Expect(sweep.Start(ctx)).To(Succeed())
+        close(events)
+        var queued []string
+        for e := range events {
+            queued = append(queued, e.Object.GetName())
+        }
+        Expect(queued).To(Equal([]string{"unlabeled", "web"}))
+    })
+})
@@ -0,0 +1,108 @@
+// internal/controller/templates.go
+package controller
+
+import (
+    "bytes"
+    "strings"
+    "text/template"
+
+    sprig "github.com/go-task/slim-sprig"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/yaml"
+)
+
+// templateData is what templates in a class can refer to.
+type templateData struct {
+    Namespace string
+    Class     string
+
+    // Values are the values of the class, overridden by the namespace
+    Values map[string]interface{}
+}
+
+// Data templates are expanded with to validate them
+var sampleTemplateData = templateData{Namespace: "namespace", Class: "class"}
+
+// templateFuncs are the functions templates can call: the sprig functions,
+// such as default, quote, b64enc, trunc and dict, without those that read the
+// environment, the clock or random numbers, so that a class renders the same
+// resources on every sync, plus toYaml and fromYaml as Helm has them.
+var templateFuncs = func() template.FuncMap {
+    funcs := sprig.HermeticTxtFuncMap()
+    funcs["toYaml"] = toYaml
+    funcs["fromYaml"] = fromYaml
+    return funcs
+}()
+
+// toYaml encodes a value as YAML without the trailing newline, so that it
+// can be indented into a larger document with nindent.
+func toYaml(value interface{}) (string, error) {
+    out, err := yaml.Marshal(value)
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// fromYaml decodes a YAML mapping.
+func fromYaml(text string) (map[string]interface{}, error) {
+    values := make(map[string]interface{})
+    if err := yaml.Unmarshal([]byte(text), &values); err != nil {
+        return nil, err
+    }
+    return values, nil
+}
+
+// expandTemplate expands a Go template in a class for a namespace. Text
+// without template actions is returned as it is. Every template gets its own
+// copy of the values, as functions such as set and unset modify maps, so
+// that templates cannot affect each other.
+func expandTemplate(text string, data templateData) (string, error) {
+    if !strings.Contains(text, "{{") {
+        return text, nil
+    }
+    if data.Values != nil {
+        data.Values = runtime.DeepCopyJSON(data.Values)
+    }
+    tmpl, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+    if err != nil {
+        return "", err
+    }
+    var out bytes.Buffer
+    if err := tmpl.Execute(&out, data); err != nil {
+        return "", err
+    }
+    return out.String(), nil
+}
+
+// expandObjectTemplates expands the templates in every string value of an
+// object in place. It returns the first error and leaves strings that fail
+// to expand as they are.
+func expandObjectTemplates(obj map[string]interface{}, data templateData) error {
+    var first error
+    var expand func(value interface{}) interface{}
+    expand = func(value interface{}) interface{} {
+        switch v := value.(type) {
+        case string:
+            expanded, err := expandTemplate(v, data)
+            if err != nil {
+                if first == nil {
+                    first = err
+                }
+                return v
+            }
+            return expanded
+        case map[string]interface{}:
+            for key, item := range v {
+                v[key] = expand(item)
+            }
+        case []interface{}:
+            for i, item := range v {
+                v[i] = expand(item)
+            }
+        }
+        return value
+    }
+    expand(obj)
+    return first
+}
@@ -0,0 +1,171 @@
+// internal/controller/tenants.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Label on a namespace naming the Tenant it belongs to
+const TenantLabel = "namespaceclass.akuity.io/tenant"
+
+// TenantReconciler assigns the class of each Tenant to its namespaces and
+// reports the namespaces of the tenant, their aggregated quota and whether
+// the tenant has more namespaces than it may have. The limit itself is
+// enforced by the namespace binding webhook.
+type TenantReconciler struct {
+    client.Client
+
+    // Scope limits the namespaces of tenants the controller manages and
+    // reports; nil includes all namespaces.
+    Scope *WatchScope
+}
+
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=tenants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=namespaceclass.akuity.io,resources=tenants/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+
+// Reconcile labels the namespaces of a tenant with its class and updates
+// its status.
+func (r *TenantReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+    logger := log.FromContext(ctx).WithValues("tenant", req.Name, "controller", "TenantReconciler")
+
+    tenant := &v1.Tenant{}
+    if err := r.Get(ctx, req.NamespacedName, tenant); err != nil {
+        return reconcile.Result{}, client.IgnoreNotFound(err)
+    }
+    if !tenant.DeletionTimestamp.IsZero() {
+        return reconcile.Result{}, nil
+    }
+
+    var nsList corev1.NamespaceList
+    if err := r.List(ctx, &nsList, client.MatchingLabels{TenantLabel: tenant.Name}); err != nil {
+        logger.Error(err, "Failed to list namespaces of tenant")
+        return reconcile.Result{}, err
+    }
+
+    status := v1.TenantStatus{
+        ClassName:          tenant.Spec.ClassName,
+        ObservedGeneration: tenant.Generation,
+        Conditions:         append([]metav1.Condition(nil), tenant.Status.Conditions...),
+    }
+    for i := range nsList.Items {
+        ns := &nsList.Items[i]
+        if !ns.DeletionTimestamp.IsZero() || !r.Scope.includes(ns.Name) || !r.Scope.admits(ns) {
+            continue
+        }
+        if err := r.assignTenantClass(ctx, tenant, ns); err != nil {
+            logger.Error(err, "Failed to assign tenant class", "namespace", ns.Name)
+            return reconcile.Result{}, err
+        }
+
+        var quotas corev1.ResourceQuotaList
+        if err := r.List(ctx, &quotas, client.InNamespace(ns.Name)); err != nil {
+            logger.Error(err, "Failed to list resource quotas", "namespace", ns.Name)
+            return reconcile.Result{}, err
+        }
+        for _, quota := range quotas.Items {
+            status.Hard = addResources(status.Hard, quota.Status.Hard)
+            status.Used = addResources(status.Used, quota.Status.Used)
+        }
+        status.Namespaces = append(status.Namespaces, ns.Name)
+    }
+    sort.Strings(status.Namespaces)
+    status.NamespaceCount = len(status.Namespaces)
+
+    limit := metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionFalse,
+        Reason:  v1.ReasonWithinNamespaceLimit,
+        Message: fmt.Sprintf("Tenant has %d namespaces", status.NamespaceCount),
+    }
+    if maxNamespaces := tenant.Spec.MaxNamespaces; maxNamespaces != nil && status.NamespaceCount > int(*maxNamespaces) {
+        limit.Status = metav1.ConditionTrue
+        limit.Reason = v1.ReasonNamespaceLimitExceeded
+        limit.Message = fmt.Sprintf("Tenant has %d namespaces, more than the %d it may have", status.NamespaceCount, *maxNamespaces)
+    }
+    limit.ObservedGeneration = tenant.Generation
+    meta.SetStatusCondition(&status.Conditions, limit)
+
+    if equality.Semantic.DeepEqual(tenant.Status, status) {
+        return reconcile.Result{}, nil
+    }
+    tenant.Status = status
+    return reconcile.Result{}, r.Status().Update(ctx, tenant)
+}
+
+// assignTenantClass labels a namespace of a tenant with the tenant's class
+// if the namespace has no class, or still has the class the tenant assigned
+// before its class changed. Namespaces that chose another class keep it.
+func (r *TenantReconciler) assignTenantClass(ctx context.Context, tenant *v1.Tenant, ns *corev1.Namespace) error {
+    className := tenant.Spec.ClassName
+    current, labeled := ns.Labels[LabelKey]
+    if className == "" || current == className {
+        return nil
+    }
+    if labeled && (tenant.Status.ClassName == "" || current != tenant.Status.ClassName) {
+        return nil
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    if ns.Labels == nil {
+        ns.Labels = make(map[string]string)
+    }
+    ns.Labels[LabelKey] = className
+    return r.Patch(ctx, ns, patch)
+}
+
+// addResources adds resource quantities to a running total.
+func addResources(total, add corev1.ResourceList) corev1.ResourceList {
+    for name, quantity := range add {
+        if total == nil {
+            total = make(corev1.ResourceList)
+        }
+        sum, ok := total[name]
+        if !ok {
+            total[name] = quantity.DeepCopy()
+            continue
+        }
+        sum.Add(quantity)
+        total[name] = sum
+    }
+    return total
+}
+
+// SetupWithManager sets up the tenant controller with the Manager. Tenants
+// are reconciled when namespaces join or leave them, and when the quotas in
+// their namespaces change.
+func (r *TenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    namespaceTenant := func(ctx context.Context, obj client.Object) []reconcile.Request {
+        if tenant := obj.GetLabels()[TenantLabel]; tenant != "" {
+            return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: tenant}}}
+        }
+        return nil
+    }
+    quotaTenant := func(ctx context.Context, obj client.Object) []reconcile.Request {
+        ns := &corev1.Namespace{}
+        if err := r.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+            return nil
+        }
+        return namespaceTenant(ctx, ns)
+    }
+    return ctrl.NewControllerManagedBy(mgr).
+        Named("tenant").
+        For(&v1.Tenant{}).
+        Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceTenant)).
+        Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(quotaTenant)).
+        Complete(r)
+}
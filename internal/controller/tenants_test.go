@@ -0,0 +1,87 @@
+// internal/controller/tenants_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/utils/ptr"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Tenants", func() {
+    It("should assign the tenant class and aggregate the quota of its namespaces", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        member := func(name, className string) *corev1.Namespace {
+            ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:   name,
+                Labels: map[string]string{TenantLabel: "team-a"},
+            }}
+            if className != "" {
+                ns.Labels[LabelKey] = className
+            }
+            return ns
+        }
+        quota := func(namespace, hard, used string) *corev1.ResourceQuota {
+            return &corev1.ResourceQuota{
+                ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "namespace-quota"},
+                Status: corev1.ResourceQuotaStatus{
+                    Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(hard)},
+                    Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(used)},
+                },
+            }
+        }
+        tenant := &v1.Tenant{
+            ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+            Spec:       v1.TenantSpec{ClassName: "standard", MaxNamespaces: ptr.To[int32](2)},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            tenant,
+            member("team-a-dev", ""),
+            member("team-a-gpu", "gpu"),
+            member("team-a-prod", ""),
+            quota("team-a-dev", "2", "500m"),
+            quota("team-a-prod", "4", "1"),
+        ).WithStatusSubresource(&v1.Tenant{}).Build()
+        reconciler := &TenantReconciler{Client: cl}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team-a"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-dev"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue(LabelKey, "standard"))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-gpu"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue(LabelKey, "gpu"))
+
+        Expect(cl.Get(ctx, request.NamespacedName, tenant)).To(Succeed())
+        Expect(tenant.Status.Namespaces).To(Equal([]string{"team-a-dev", "team-a-gpu", "team-a-prod"}))
+        Expect(tenant.Status.NamespaceCount).To(Equal(3))
+        Expect(tenant.Status.Hard.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).String()).To(Equal("6"))
+        Expect(tenant.Status.Used.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).String()).To(Equal("1500m"))
+        degraded := meta.FindStatusCondition(tenant.Status.Conditions, v1.ConditionDegraded)
+        Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+        Expect(degraded.Reason).To(Equal(v1.ReasonNamespaceLimitExceeded))
+
+        // Changing the tenant class moves the namespaces it assigned
+        tenant.Spec.ClassName = "restricted"
+        Expect(cl.Update(ctx, tenant)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-prod"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue(LabelKey, "restricted"))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team-a-gpu"}, ns)).To(Succeed())
+        Expect(ns.Labels).To(HaveKeyWithValue(LabelKey, "gpu"))
+    })
+})
@@ -0,0 +1,63 @@
+// internal/controller/tracing.go
+package controller
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifies the spans created by the controller. Without a
+// tracer provider installed, the global no-op provider makes spans free.
+const TracerName = "github.com/nickleefly/namespace-class-controller"
+
+// startSpan starts a child span of the span in ctx.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+    return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span, marking it failed if err is set.
+func endSpan(span trace.Span, err error) {
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
+
+// reconcileTrace holds the span of a reconcile and of the phase it is in.
+// Reconcile returns from many places, so the phase span is ended when the
+// next phase starts or the reconcile finishes, whichever comes first; an
+// error is attributed to the phase that was running.
+type reconcileTrace struct {
+    ctx   context.Context
+    span  trace.Span
+    phase trace.Span
+}
+
+func startReconcileTrace(ctx context.Context, namespace string) *reconcileTrace {
+    ctx, span := startSpan(ctx, "Reconcile", attribute.String("namespace", namespace))
+    return &reconcileTrace{ctx: ctx, span: span}
+}
+
+// startPhase ends the current phase and returns the context for the next.
+func (t *reconcileTrace) startPhase(name string) context.Context {
+    if t.phase != nil {
+        t.phase.End()
+    }
+    ctx, span := startSpan(t.ctx, name)
+    t.phase = span
+    return ctx
+}
+
+// end finishes the current phase and the reconcile span.
+func (t *reconcileTrace) end(className string, err error) {
+    if t.phase != nil {
+        endSpan(t.phase, err)
+    }
+    t.span.SetAttributes(attribute.String("class", className))
+    endSpan(t.span, err)
+}
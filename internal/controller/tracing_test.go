@@ -0,0 +1,59 @@
+// internal/controller/tracing_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "go.opentelemetry.io/otel"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Tracing", func() {
+    It("should record a span for each reconcile phase and applied resource", func() {
+        spans := tracetest.NewSpanRecorder()
+        previous := otel.GetTracerProvider()
+        otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+        defer otel.SetTracerProvider(previous)
+
+        ctx := context.Background()
+        scheme := newScheme()
+        reconciler := &NamespaceClassReconciler{
+            Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+                &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                    Name:       "team",
+                    Labels:     map[string]string{LabelKey: "web"},
+                    Finalizers: []string{NamespaceFinalizer},
+                }},
+                &v1.NamespaceClass{
+                    ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                    Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                        {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+                    }},
+                },
+            ).WithStatusSubresource(&v1.NamespaceClass{}).Build(),
+            Scheme:   scheme,
+            Recorder: record.NewFakeRecorder(10),
+        }
+
+        _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}})
+        Expect(err).NotTo(HaveOccurred())
+
+        var names []string
+        for _, span := range spans.Ended() {
+            names = append(names, span.Name())
+        }
+        Expect(names).To(Equal([]string{"fetch", "render", "apply ConfigMap", "apply", "prune", "status-update", "Reconcile"}))
+    })
+})
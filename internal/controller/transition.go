@@ -0,0 +1,169 @@
+// internal/controller/transition.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation set on hook resources to the transition phase they run in
+const HookAnnotation = "namespaceclass.akuity.io/hook"
+
+// classTransition returns the switch between classes a namespace is in, if
+// any, starting one when the class of the namespace differs from the class
+// in its inventory. A transition to another class than the current one is
+// replaced.
+func (r *NamespaceClassReconciler) classTransition(ctx context.Context, namespace, previousClass, className string) (*v1.ClassTransition, error) {
+    inv := &v1.NamespaceClassInventory{}
+    if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+        return nil, client.IgnoreNotFound(err)
+    }
+    if t := inv.Status.Transition; t != nil && t.To == className {
+        return t, nil
+    }
+
+    var transition *v1.ClassTransition
+    if previousClass != "" && previousClass != className {
+        transition = &v1.ClassTransition{
+            From:      previousClass,
+            To:        className,
+            Phase:     v1.TransitionPhasePreSwitch,
+            StartTime: metav1.Now(),
+        }
+    }
+    if inv.Status.Transition != nil || transition != nil {
+        if err := r.setInventoryTransition(ctx, namespace, transition); err != nil {
+            return nil, err
+        }
+    }
+    return transition, nil
+}
+
+// setInventoryTransition records the transition of a namespace in its
+// inventory status, or clears it when transition is nil.
+func (r *NamespaceClassReconciler) setInventoryTransition(ctx context.Context, namespace string, transition *v1.ClassTransition) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        inv := &v1.NamespaceClassInventory{}
+        if err := r.Get(ctx, types.NamespacedName{Name: namespace}, inv); err != nil {
+            return client.IgnoreNotFound(err)
+        }
+        if equality.Semantic.DeepEqual(inv.Status.Transition, transition) {
+            return nil
+        }
+        inv.Status.Transition = transition
+        return r.Update(ctx, inv)
+    })
+}
+
+// runHooks creates the hooks of a phase in the namespace one after another.
+// It returns the hook that has not completed yet, or nil once all have, and
+// an error if a hook failed. Hooks are complete when their health is
+// Current, i.e. Jobs have succeeded.
+func (r *NamespaceClassReconciler) runHooks(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, phase v1.TransitionPhase, raw []runtime.RawExtension) (*unstructured.Unstructured, error) {
+    hooks, err := parseClassResources(raw, nsc.Name)
+    if err != nil {
+        return nil, err
+    }
+    for _, hook := range hooks {
+        if reason := r.Kinds.forbids(hook.GroupVersionKind().GroupKind()); reason != "" {
+            return nil, fmt.Errorf("%s hook %s %s is forbidden: %s", phase, hook.GetKind(), hook.GetName(), reason)
+        }
+        hook.SetNamespace(ns.Name)
+        annotations := hook.GetAnnotations()
+        if annotations == nil {
+            annotations = make(map[string]string)
+        }
+        annotations[HookAnnotation] = string(phase)
+        hook.SetAnnotations(annotations)
+
+        live := &unstructured.Unstructured{}
+        live.SetGroupVersionKind(hook.GroupVersionKind())
+        if err := r.Get(ctx, client.ObjectKeyFromObject(hook), live); err != nil {
+            if !errors.IsNotFound(err) {
+                return nil, err
+            }
+            if err := r.Create(ctx, hook); err != nil && !errors.IsAlreadyExists(err) {
+                return nil, err
+            }
+            return hook, nil
+        }
+        switch status, message := resourceHealth(live); status {
+        case v1.HealthFailed:
+            return nil, fmt.Errorf("%s hook %s %s failed: %s", phase, hook.GetKind(), hook.GetName(), message)
+        case v1.HealthInProgress:
+            return hook, nil
+        }
+    }
+    return nil, nil
+}
+
+// deleteHooks removes the hook resources of a class from the namespace once
+// a transition is complete, so they run again on the next switch.
+func (r *NamespaceClassReconciler) deleteHooks(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass) error {
+    if nsc.Spec.Hooks == nil {
+        return nil
+    }
+    raw := append(append([]runtime.RawExtension{}, nsc.Spec.Hooks.PreSwitch...), nsc.Spec.Hooks.PostSwitch...)
+    hooks, err := parseClassResources(raw, nsc.Name)
+    if err != nil {
+        return err
+    }
+    for _, hook := range hooks {
+        hook.SetNamespace(ns.Name)
+        if err := r.Delete(ctx, hook, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// classHooks returns the hooks of a class for a transition phase.
+func classHooks(nsc *v1.NamespaceClass, phase v1.TransitionPhase) []runtime.RawExtension {
+    if nsc.Spec.Hooks == nil {
+        return nil
+    }
+    if phase == v1.TransitionPhasePreSwitch {
+        return nsc.Spec.Hooks.PreSwitch
+    }
+    return nsc.Spec.Hooks.PostSwitch
+}
+
+// waitForPreSwitchHooks reports a namespace whose pre-switch hooks are still
+// running as pending and requeues it.
+func (r *NamespaceClassReconciler) waitForPreSwitchHooks(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, transition *v1.ClassTransition, hook *unstructured.Unstructured, desired int, managed int) (reconcile.Result, error) {
+    message := fmt.Sprintf("Switching from class %s: waiting for pre-switch hook %s %s", transition.From, hook.GetKind(), hook.GetName())
+    sync := &v1.NamespaceSyncStatus{
+        Name:               ns.Name,
+        Phase:              v1.SyncPhasePending,
+        LastSyncTime:       metav1.Now(),
+        ObservedGeneration: nsc.Generation,
+        DesiredResources:   desired,
+        ManagedResources:   managed,
+        Message:            message,
+    }
+    if err := r.syncClassMembership(ctx, ns.Name, transition.From, nsc.Name, sync); err != nil {
+        return reconcile.Result{}, err
+    }
+    if err := r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionReady,
+        Status:  metav1.ConditionFalse,
+        Reason:  v1.ReasonTransitionPending,
+        Message: message,
+    }); err != nil {
+        return reconcile.Result{}, err
+    }
+    return reconcile.Result{RequeueAfter: r.jittered(healthRequeueInterval)}, nil
+}
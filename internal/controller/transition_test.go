@@ -0,0 +1,118 @@
+// internal/controller/transition_test.go
+package controller
+
+import (
+    "context"
+    "fmt"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Class transitions", func() {
+    It("should run hooks and prune the previous class only once the new one is ready", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        pod := func(name string) runtime.RawExtension {
+            return runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+                `{"apiVersion":"v1","kind":"Pod","metadata":{"name":%q},"spec":{"containers":[{"name":"main","image":"busybox"}]}}`, name))}
+        }
+        oldClass := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "old"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"old-policy"}}`)},
+            }},
+        }
+        newClass := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "new"},
+            Spec: v1.NamespaceClassSpec{
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"new-policy"}}`)},
+                    pod("app"),
+                },
+                Hooks: &v1.ClassHooks{PreSwitch: []runtime.RawExtension{pod("pre")}, PostSwitch: []runtime.RawExtension{pod("post")}},
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:       "team",
+                Labels:     map[string]string{LabelKey: "old"},
+                Finalizers: []string{NamespaceFinalizer},
+            }},
+            oldClass, newClass,
+        ).WithStatusSubresource(&v1.NamespaceClass{}, &corev1.Pod{}).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+        exists := func(kind client.Object, name string) bool {
+            err := cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, kind)
+            Expect(client.IgnoreNotFound(err)).To(Succeed())
+            return err == nil
+        }
+        complete := func(name string) {
+            p := &corev1.Pod{}
+            Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, p)).To(Succeed())
+            p.Status.Phase = corev1.PodSucceeded
+            Expect(cl.Status().Update(ctx, p)).To(Succeed())
+        }
+        transition := func() *v1.ClassTransition {
+            inv := &v1.NamespaceClassInventory{}
+            Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, inv)).To(Succeed())
+            return inv.Status.Transition
+        }
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(exists(&corev1.ConfigMap{}, "old-policy")).To(BeTrue())
+        Expect(transition()).To(BeNil())
+
+        // The pre-switch hook runs before anything of the new class is applied
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        ns.Labels[LabelKey] = "new"
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(exists(&corev1.Pod{}, "pre")).To(BeTrue())
+        Expect(exists(&corev1.ConfigMap{}, "new-policy")).To(BeFalse())
+        Expect(transition().Phase).To(Equal(v1.TransitionPhasePreSwitch))
+        Expect(transition().From).To(Equal("old"))
+
+        // The old resources are kept until the new ones are healthy
+        complete("pre")
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(exists(&corev1.ConfigMap{}, "new-policy")).To(BeTrue())
+        Expect(exists(&corev1.ConfigMap{}, "old-policy")).To(BeTrue())
+        Expect(transition().Phase).To(Equal(v1.TransitionPhaseSwitching))
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(exists(&corev1.ConfigMap{}, "old-policy")).To(BeTrue())
+
+        complete("app")
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(exists(&corev1.ConfigMap{}, "old-policy")).To(BeFalse())
+        Expect(exists(&corev1.Pod{}, "post")).To(BeTrue())
+        Expect(transition().Phase).To(Equal(v1.TransitionPhasePostSwitch))
+
+        // Completing the post-switch hook ends the transition and removes the hooks
+        complete("post")
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(transition()).To(BeNil())
+        Expect(exists(&corev1.Pod{}, "pre")).To(BeFalse())
+        Expect(exists(&corev1.Pod{}, "post")).To(BeFalse())
+        Expect(exists(&corev1.Pod{}, "app")).To(BeTrue())
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "new"}, newClass)).To(Succeed())
+        Expect(newClass.Status.Namespaces[0].Phase).To(Equal(v1.SyncPhaseSynced))
+    })
+})
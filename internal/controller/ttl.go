@@ -0,0 +1,124 @@
+// internal/controller/ttl.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+const (
+    // Annotation on a namespace deleting it once the duration given, e.g.
+    // "72h", has passed, for ephemeral namespaces such as preview
+    // environments
+    TTLAnnotation = "namespaceclass.akuity.io/ttl"
+
+    // Annotation set by the controller to the time it first saw the TTL of
+    // a namespace, from which the TTL counts
+    TTLStartAnnotation = "namespaceclass.akuity.io/ttl-start"
+)
+
+// namespaceTTL returns the duration of the TTL annotation of a namespace, or
+// 0 if it has none.
+func namespaceTTL(ns *corev1.Namespace) (time.Duration, error) {
+    value, ok := ns.Annotations[TTLAnnotation]
+    if !ok {
+        return 0, nil
+    }
+    ttl, err := time.ParseDuration(value)
+    if err != nil || ttl <= 0 {
+        return 0, fmt.Errorf("invalid %s annotation %q: must be a positive duration such as 72h", TTLAnnotation, value)
+    }
+    return ttl, nil
+}
+
+// namespaceExpiry returns when an ephemeral namespace expires: the start of
+// its TTL plus its duration. The TTL does not count from the creation of the
+// namespace, so that annotating a namespace that has existed for months does
+// not delete it right away. The zero time means the namespace does not
+// expire, or its TTL has not started yet.
+func namespaceExpiry(ns *corev1.Namespace) (time.Time, error) {
+    ttl, err := namespaceTTL(ns)
+    if err != nil || ttl == 0 {
+        return time.Time{}, err
+    }
+    start, err := time.Parse(time.RFC3339, ns.Annotations[TTLStartAnnotation])
+    if err != nil {
+        return time.Time{}, nil
+    }
+    return start.Add(ttl), nil
+}
+
+// startTTL records when the TTL of a namespace starts, the first time the
+// controller sees it, and forgets it once the TTL is removed, so that a TTL
+// added again starts over.
+func (r *NamespaceClassReconciler) startTTL(ctx context.Context, ns *corev1.Namespace) error {
+    _, hasTTL := ns.Annotations[TTLAnnotation]
+    start, hasStart := ns.Annotations[TTLStartAnnotation]
+    _, err := time.Parse(time.RFC3339, start)
+    patch := client.MergeFrom(ns.DeepCopy())
+    switch {
+    case hasTTL && err != nil:
+        metav1.SetMetaDataAnnotation(&ns.ObjectMeta, TTLStartAnnotation, time.Now().UTC().Format(time.RFC3339))
+    case !hasTTL && hasStart:
+        delete(ns.Annotations, TTLStartAnnotation)
+    default:
+        return nil
+    }
+    return r.Patch(ctx, ns, patch)
+}
+
+// expireNamespace deletes a namespace once its TTL has passed; its managed
+// resources are then cleaned up like those of any deleted namespace. It
+// reports whether the namespace was deleted and otherwise how long until it
+// expires, or 0 if it does not.
+func (r *NamespaceClassReconciler) expireNamespace(ctx context.Context, ns *corev1.Namespace) (bool, time.Duration, error) {
+    if _, err := namespaceTTL(ns); err != nil {
+        r.recordSyncEvent(ns, nil, corev1.EventTypeWarning, ReasonInvalidTTL, "Not expiring namespace: %v", err)
+        return false, 0, nil
+    }
+    if err := r.startTTL(ctx, ns); err != nil {
+        return false, 0, err
+    }
+    expiry, _ := namespaceExpiry(ns)
+    if expiry.IsZero() {
+        return false, 0, nil
+    }
+    if remaining := time.Until(expiry); remaining > 0 {
+        return false, remaining, nil
+    }
+    r.recordSyncEvent(ns, nil, corev1.EventTypeNormal, ReasonNamespaceExpired,
+        "Deleting namespace: its TTL of %s has passed", ns.Annotations[TTLAnnotation])
+    if err := r.Delete(ctx, ns); client.IgnoreNotFound(err) != nil {
+        return false, 0, err
+    }
+    return true, 0, nil
+}
+
+// requeueWithin makes a result requeue no later than after a duration.
+func requeueWithin(result reconcile.Result, d time.Duration) reconcile.Result {
+    if result.Requeue && result.RequeueAfter == 0 {
+        return result
+    }
+    if result.RequeueAfter == 0 || d < result.RequeueAfter {
+        result.RequeueAfter = d
+    }
+    return result
+}
+
+// claimExpiry returns when a claim with a TTL expires, or nil if it does
+// not.
+func claimExpiry(claim *v1.NamespaceClaim) *metav1.Time {
+    if claim.Spec.TTL == nil || claim.Spec.TTL.Duration <= 0 {
+        return nil
+    }
+    expiry := metav1.NewTime(claim.CreationTimestamp.Add(claim.Spec.TTL.Duration))
+    return &expiry
+}
@@ -0,0 +1,122 @@
+// internal/controller/ttl_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/event"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Ephemeral namespaces", func() {
+    It("should delete namespaces once their TTL has passed", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:              "preview-1",
+                CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+                Annotations: map[string]string{
+                    TTLAnnotation:      "2h",
+                    TTLStartAnnotation: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+                },
+            }},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:              "preview-2",
+                CreationTimestamp: metav1.NewTime(time.Now().Add(-3 * time.Hour)),
+                Annotations: map[string]string{
+                    TTLAnnotation:      "2h",
+                    TTLStartAnnotation: time.Now().Add(-3 * time.Hour).UTC().Format(time.RFC3339),
+                },
+            }},
+        ).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+
+        result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "preview-1"}})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "preview-1"}, &corev1.Namespace{})).To(Succeed())
+
+        _, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "preview-2"}})
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "preview-2"}, &corev1.Namespace{}))).To(BeTrue())
+    })
+
+    It("should delete claims once their TTL has passed", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        claim := &v1.NamespaceClaim{
+            ObjectMeta: metav1.ObjectMeta{Name: "preview-1", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+            Spec:       v1.NamespaceClaimSpec{ClassName: "preview", TTL: &metav1.Duration{Duration: 2 * time.Hour}},
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).
+            WithStatusSubresource(&v1.NamespaceClaim{}).Build()
+        reconciler := &ClaimReconciler{Client: cl}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "preview-1"}}
+
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+        Expect(cl.Get(ctx, request.NamespacedName, claim)).To(Succeed())
+        Expect(claim.Status.ExpirationTime.Time).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+        claim.Spec.TTL = &metav1.Duration{Duration: 30 * time.Minute}
+        Expect(cl.Update(ctx, claim)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(errors.IsNotFound(cl.Get(ctx, request.NamespacedName, claim))).To(BeTrue())
+    })
+
+    It("should count the TTL of an existing namespace from when it was annotated", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:              "payments",
+                CreationTimestamp: metav1.NewTime(time.Now().Add(-180 * 24 * time.Hour)),
+                Annotations:       map[string]string{TTLAnnotation: "72h"},
+            }},
+        ).Build()
+        reconciler := &NamespaceClassReconciler{Client: cl, Scheme: scheme}
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "payments"}}
+
+        result, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(result.RequeueAfter).To(BeNumerically("~", 72*time.Hour, time.Minute))
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, request.NamespacedName, ns)).To(Succeed())
+        start, err := time.Parse(time.RFC3339, ns.Annotations[TTLStartAnnotation])
+        Expect(err).NotTo(HaveOccurred())
+        Expect(start).To(BeTemporally("~", time.Now(), time.Minute))
+
+        // Removing the TTL forgets its start, so a new TTL starts over
+        delete(ns.Annotations, TTLAnnotation)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, request.NamespacedName, ns)).To(Succeed())
+        Expect(ns.Annotations).NotTo(HaveKey(TTLStartAnnotation))
+    })
+
+    It("should reconcile namespaces whose TTL annotation changes", func() {
+        oldNs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}}
+        newNs := oldNs.DeepCopy()
+        newNs.Annotations = map[string]string{TTLAnnotation: "72h"}
+        Expect(namespacePredicate.Update(event.UpdateEvent{ObjectOld: oldNs, ObjectNew: newNs})).To(BeTrue())
+        Expect(namespacePredicate.Update(event.UpdateEvent{ObjectOld: newNs, ObjectNew: oldNs})).To(BeTrue())
+        Expect(namespacePredicate.Update(event.UpdateEvent{ObjectOld: newNs, ObjectNew: newNs.DeepCopy()})).To(BeFalse())
+    })
+})
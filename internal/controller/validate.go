@@ -0,0 +1,577 @@
+// internal/controller/validate.go
+package controller
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+
+    "k8s.io/apimachinery/pkg/api/resource"
+    apivalidation "k8s.io/apimachinery/pkg/api/validation"
+    pathvalidation "k8s.io/apimachinery/pkg/api/validation/path"
+    metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/util/validation"
+    "k8s.io/apimachinery/pkg/util/validation/field"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// ValidateClass checks a class for the mistakes that would make the
+// controller reject it or fail to apply it. It needs no cluster.
+func ValidateClass(nsc *v1.NamespaceClass) field.ErrorList {
+    var errs field.ErrorList
+    spec := field.NewPath("spec")
+
+    switch nsc.Spec.ComparisonMode {
+    case "", v1.ComparisonModeHash, v1.ComparisonModeSemantic:
+    default:
+        errs = append(errs, field.NotSupported(spec.Child("comparisonMode"), nsc.Spec.ComparisonMode,
+            []string{string(v1.ComparisonModeHash), string(v1.ComparisonModeSemantic)}))
+    }
+    switch nsc.Spec.HashAlgorithm {
+    case "", v1.HashAlgorithmSHA256, v1.HashAlgorithmSHA512, v1.HashAlgorithmFNV64a:
+    default:
+        errs = append(errs, field.NotSupported(spec.Child("hashAlgorithm"), nsc.Spec.HashAlgorithm,
+            []string{string(v1.HashAlgorithmSHA256), string(v1.HashAlgorithmSHA512), string(v1.HashAlgorithmFNV64a)}))
+    }
+    switch nsc.Spec.ConflictPolicy {
+    case "", v1.ConflictPolicyFail, v1.ConflictPolicyOverwrite, v1.ConflictPolicyAdopt, v1.ConflictPolicySkip:
+    default:
+        errs = append(errs, field.NotSupported(spec.Child("conflictPolicy"), nsc.Spec.ConflictPolicy,
+            []string{string(v1.ConflictPolicyFail), string(v1.ConflictPolicyOverwrite), string(v1.ConflictPolicyAdopt), string(v1.ConflictPolicySkip)}))
+    }
+    switch nsc.Spec.ApplyPolicy {
+    case "", v1.ApplyPolicyStopOnError, v1.ApplyPolicyContinueOnError, v1.ApplyPolicyAtomic:
+    default:
+        errs = append(errs, field.NotSupported(spec.Child("applyPolicy"), nsc.Spec.ApplyPolicy,
+            []string{string(v1.ApplyPolicyStopOnError), string(v1.ApplyPolicyContinueOnError), string(v1.ApplyPolicyAtomic)}))
+    }
+    for i, f := range nsc.Spec.IgnoreFields {
+        if strings.Trim(f, "/.") == "" {
+            errs = append(errs, field.Invalid(spec.Child("ignoreFields").Index(i), f, "must name a field"))
+        }
+    }
+    if name := nsc.Spec.ServiceAccountName; name != "" {
+        for _, msg := range validation.IsDNS1123Subdomain(name) {
+            errs = append(errs, field.Invalid(spec.Child("serviceAccountName"), name, msg))
+        }
+    }
+
+    switch nsc.Spec.NamespaceRemovalPolicy {
+    case "", v1.NamespaceRemovalPolicyDelete, v1.NamespaceRemovalPolicyOrphan:
+    default:
+        errs = append(errs, field.NotSupported(spec.Child("namespaceRemovalPolicy"), nsc.Spec.NamespaceRemovalPolicy,
+            []string{string(v1.NamespaceRemovalPolicyDelete), string(v1.NamespaceRemovalPolicyOrphan)}))
+    }
+    namespaces := make(map[string]bool)
+    for i, name := range nsc.Spec.Namespaces {
+        path := spec.Child("namespaces").Index(i)
+        for _, msg := range validation.IsDNS1123Label(name) {
+            errs = append(errs, field.Invalid(path, name, msg))
+        }
+        if namespaces[name] {
+            errs = append(errs, field.Duplicate(path, name))
+        }
+        namespaces[name] = true
+    }
+
+    for i, window := range nsc.Spec.UpdateWindows {
+        if _, _, err := parseUpdateWindow(window); err != nil {
+            errs = append(errs, field.Invalid(spec.Child("updateWindows").Index(i), window.Schedule, err.Error()))
+        }
+    }
+
+    if hooks := nsc.Spec.Hooks; hooks != nil {
+        errs = append(errs, validateHooks(spec.Child("hooks", "preSwitch"), hooks.PreSwitch)...)
+        errs = append(errs, validateHooks(spec.Child("hooks", "postSwitch"), hooks.PostSwitch)...)
+    }
+
+    if md := nsc.Spec.NamespaceMetadata; md != nil {
+        errs = append(errs, validateNamespaceMetadata(spec.Child("namespaceMetadata"), md)...)
+    }
+
+    if ps := nsc.Spec.PodSecurity; ps != nil {
+        errs = append(errs, validatePodSecurity(spec, ps, nsc.Spec.NamespaceMetadata)...)
+    }
+
+    // Templates are only expanded in the resources of classes with values
+    templates := sampleTemplateData
+    values, err := classValues(nsc)
+    if err != nil {
+        errs = append(errs, field.Invalid(spec.Child("values"), string(nsc.Spec.Values.Raw), "must be a JSON object"))
+    }
+    templates.Values = values
+    if from := nsc.Spec.ValuesFrom; from != nil {
+        if nsc.Spec.Values == nil {
+            errs = append(errs, field.Required(spec.Child("values"), "declares the values valuesFrom may set"))
+        }
+        if from.ConfigMapRef == nil {
+            errs = append(errs, field.Required(spec.Child("valuesFrom", "configMapRef"), ""))
+        } else {
+            for _, msg := range validation.IsDNS1123Subdomain(from.ConfigMapRef.Name) {
+                errs = append(errs, field.Invalid(spec.Child("valuesFrom", "configMapRef", "name"), from.ConfigMapRef.Name, msg))
+            }
+        }
+    }
+
+    seen := make(map[string]int)
+    ids := make(map[string]int)
+    var decoded []*unstructured.Unstructured
+    for i, r := range nsc.Spec.Resources {
+        path := spec.Child("resources").Index(i)
+        var u unstructured.Unstructured
+        if err := json.Unmarshal(r.Raw, &u); err != nil {
+            errs = append(errs, field.Invalid(path, string(r.Raw), err.Error()))
+            continue
+        }
+        if u.GetAPIVersion() == "" {
+            errs = append(errs, field.Required(path.Child("apiVersion"), ""))
+        }
+        if u.GetKind() == "" {
+            errs = append(errs, field.Required(path.Child("kind"), ""))
+        }
+        if u.GetName() == "" {
+            errs = append(errs, field.Required(path.Child("metadata", "name"), ""))
+        }
+        if strategy := u.GetAnnotations()[UpdateStrategyAnnotation]; strategy != "" && strategy != UpdateStrategyRecreate {
+            errs = append(errs, field.NotSupported(path.Child("metadata", "annotations").Key(UpdateStrategyAnnotation),
+                strategy, []string{UpdateStrategyRecreate}))
+        }
+
+        if value, ok := u.GetAnnotations()[WaveAnnotation]; ok {
+            if _, err := resourceWave(&u); err != nil {
+                errs = append(errs, field.Invalid(path.Child("metadata", "annotations").Key(WaveAnnotation), value, "must be an integer"))
+            }
+        }
+
+        if id := u.GetAnnotations()[ResourceIDAnnotation]; id != "" {
+            idKey := fmt.Sprintf("%s/%s", u.GroupVersionKind().GroupKind(), id)
+            if first, ok := ids[idKey]; ok {
+                errs = append(errs, field.Duplicate(path.Child("metadata", "annotations").Key(ResourceIDAnnotation),
+                    fmt.Sprintf("%s, also used by the %s at index %d", id, u.GetKind(), first)))
+            } else {
+                ids[idKey] = i
+            }
+        }
+
+        errs = append(errs, validateCertificateNames(path.Child("spec"), &u)...)
+        if values != nil {
+            if err := expandObjectTemplates(u.DeepCopy().Object, templates); err != nil {
+                errs = append(errs, field.Invalid(path, u.GetName(), err.Error()))
+            }
+        }
+
+        key := fmt.Sprintf("%s/%s/%s", u.GetAPIVersion(), u.GetKind(), u.GetName())
+        if first, ok := seen[key]; ok {
+            errs = append(errs, field.Duplicate(path, fmt.Sprintf("%s %s, also at index %d", u.GetKind(), u.GetName(), first)))
+            continue
+        }
+        seen[key] = i
+        decoded = append(decoded, &u)
+    }
+    if quota := nsc.Spec.Quota; quota != nil {
+        errs = append(errs, validateQuota(spec.Child("quota"), quota)...)
+        if first, ok := seen["v1/ResourceQuota/"+quotaName(quota)]; ok {
+            errs = append(errs, field.Duplicate(spec.Child("quota", "name"),
+                fmt.Sprintf("ResourceQuota %s, also at spec.resources[%d]", quotaName(quota), first)))
+        }
+        decoded = append(decoded, quotaResource(quota))
+    }
+    if limits := nsc.Spec.Limits; limits != nil {
+        errs = append(errs, validateLimits(spec.Child("limits"), limits)...)
+        if first, ok := seen["v1/LimitRange/"+limitsName(limits)]; ok {
+            errs = append(errs, field.Duplicate(spec.Child("limits", "name"),
+                fmt.Sprintf("LimitRange %s, also at spec.resources[%d]", limitsName(limits), first)))
+        }
+        decoded = append(decoded, limitsResource(limits))
+    }
+    grantNames := make(map[string]int)
+    for i, grant := range nsc.Spec.RBAC {
+        path := spec.Child("rbac").Index(i)
+        errs = append(errs, validateRoleGrant(path, grant)...)
+        name := roleGrantName(grant)
+        if first, ok := grantNames[name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("RoleBinding %s, also at spec.rbac[%d]", name, first)))
+        } else if first, ok := seen["rbac.authorization.k8s.io/v1/RoleBinding/"+name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("RoleBinding %s, also at spec.resources[%d]", name, first)))
+        }
+        grantNames[name] = i
+    }
+    decoded = append(decoded, rbacResources(nsc.Spec.RBAC)...)
+    serviceAccounts := make(map[string]int)
+    for i, sa := range nsc.Spec.ServiceAccounts {
+        path := spec.Child("serviceAccounts").Index(i)
+        if sa.Name == "" {
+            errs = append(errs, field.Required(path.Child("name"), ""))
+            continue
+        }
+        for _, msg := range validation.IsDNS1123Subdomain(sa.Name) {
+            errs = append(errs, field.Invalid(path.Child("name"), sa.Name, msg))
+        }
+        if first, ok := serviceAccounts[sa.Name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("ServiceAccount %s, also at spec.serviceAccounts[%d]", sa.Name, first)))
+        } else if first, ok := seen["v1/ServiceAccount/"+sa.Name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("ServiceAccount %s, also at spec.resources[%d]", sa.Name, first)))
+        }
+        serviceAccounts[sa.Name] = i
+    }
+    decoded = append(decoded, serviceAccountResources(nsc.Spec.ServiceAccounts)...)
+    configMaps := make(map[string]int)
+    for i, replica := range nsc.Spec.ConfigMaps {
+        path := spec.Child("configMaps").Index(i)
+        if replica.Source.Namespace == "" {
+            errs = append(errs, field.Required(path.Child("source", "namespace"), ""))
+        }
+        if replica.Source.Name == "" {
+            errs = append(errs, field.Required(path.Child("source", "name"), ""))
+            continue
+        }
+        name := configMapReplicaName(replica)
+        for _, msg := range validation.IsDNS1123Subdomain(name) {
+            errs = append(errs, field.Invalid(path.Child("name"), name, msg))
+        }
+        if first, ok := configMaps[name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("ConfigMap %s, also at spec.configMaps[%d]", name, first)))
+        } else if first, ok := seen["v1/ConfigMap/"+name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("ConfigMap %s, also at spec.resources[%d]", name, first)))
+        }
+        configMaps[name] = i
+        // Resources may depend on the copy, whose data is only known in the cluster
+        copied := &unstructured.Unstructured{}
+        copied.SetAPIVersion("v1")
+        copied.SetKind("ConfigMap")
+        copied.SetName(name)
+        decoded = append(decoded, copied)
+    }
+    certificates := make(map[string]int)
+    for i, cert := range nsc.Spec.Certificates {
+        path := spec.Child("certificates").Index(i)
+        errs = append(errs, validateCertificate(path, cert)...)
+        if first, ok := certificates[cert.Name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("Certificate %s, also at spec.certificates[%d]", cert.Name, first)))
+        } else if first, ok := seen[certManagerGroup+"/v1/Certificate/"+cert.Name]; ok {
+            errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("Certificate %s, also at spec.resources[%d]", cert.Name, first)))
+        }
+        certificates[cert.Name] = i
+    }
+    decoded = append(decoded, certificateResources(nsc.Spec.Certificates)...)
+    errs = append(errs, validateClusterResources(spec.Child("clusterResources"), nsc.Spec.ClusterResources, templates)...)
+    if len(errs) == 0 {
+        errs = append(errs, validateDependencies(spec.Child("resources"), decoded)...)
+    }
+    return errs
+}
+
+// validateHooks checks that hooks can be decoded and name an object.
+func validateHooks(path *field.Path, hooks []runtime.RawExtension) field.ErrorList {
+    var errs field.ErrorList
+    for i, hook := range hooks {
+        var u unstructured.Unstructured
+        if err := json.Unmarshal(hook.Raw, &u); err != nil {
+            errs = append(errs, field.Invalid(path.Index(i), string(hook.Raw), err.Error()))
+            continue
+        }
+        if err := validateResource(&u); err != nil {
+            errs = append(errs, field.Invalid(path.Index(i), u.GetName(), err.Error()))
+        }
+    }
+    return errs
+}
+
+// validateNamespaceMetadata checks the labels and annotations a class sets
+// on its namespaces.
+func validateNamespaceMetadata(path *field.Path, md *v1.NamespaceMetadata) field.ErrorList {
+    errs := metav1validation.ValidateLabels(md.Labels, path.Child("labels"))
+    errs = append(errs, apivalidation.ValidateAnnotations(md.Annotations, path.Child("annotations"))...)
+    for _, keys := range []struct {
+        path   *field.Path
+        values map[string]string
+    }{{path.Child("labels"), md.Labels}, {path.Child("annotations"), md.Annotations}} {
+        for key := range keys.values {
+            if strings.HasPrefix(key, reservedMetadataPrefix) {
+                errs = append(errs, field.Forbidden(keys.path.Key(key), "reserved for the controller"))
+            }
+        }
+    }
+    return errs
+}
+
+// validatePodSecurity checks the Pod Security levels and versions of a
+// class and that its namespace labels do not set them differently.
+func validatePodSecurity(spec *field.Path, ps *v1.PodSecurity, md *v1.NamespaceMetadata) field.ErrorList {
+    var errs field.ErrorList
+    path := spec.Child("podSecurity")
+    levels := []string{string(v1.PodSecurityPrivileged), string(v1.PodSecurityBaseline), string(v1.PodSecurityRestricted)}
+    for _, mode := range []struct {
+        name    string
+        level   v1.PodSecurityLevel
+        version string
+    }{
+        {"enforce", ps.Enforce, ps.EnforceVersion},
+        {"audit", ps.Audit, ps.AuditVersion},
+        {"warn", ps.Warn, ps.WarnVersion},
+    } {
+        switch mode.level {
+        case "", v1.PodSecurityPrivileged, v1.PodSecurityBaseline, v1.PodSecurityRestricted:
+        default:
+            errs = append(errs, field.NotSupported(path.Child(mode.name), mode.level, levels))
+        }
+        if mode.version != "" && !podSecurityVersion.MatchString(mode.version) {
+            errs = append(errs, field.Invalid(path.Child(mode.name+"Version"), mode.version, `must be "latest" or a version such as "v1.30"`))
+        }
+    }
+    if md != nil {
+        for key, value := range podSecurityLabels(ps) {
+            if existing, ok := md.Labels[key]; ok && existing != value {
+                errs = append(errs, field.Invalid(spec.Child("namespaceMetadata", "labels").Key(key), existing,
+                    "conflicts with spec.podSecurity"))
+            }
+        }
+    }
+    return errs
+}
+
+// validateQuota checks that a quota limits something, has a valid name and
+// no negative limits.
+func validateQuota(path *field.Path, quota *v1.ClassQuota) field.ErrorList {
+    var errs field.ErrorList
+    if quota.Name != "" {
+        for _, msg := range validation.IsDNS1123Subdomain(quota.Name) {
+            errs = append(errs, field.Invalid(path.Child("name"), quota.Name, msg))
+        }
+    }
+    hard := quotaHard(quota)
+    if len(hard) == 0 {
+        errs = append(errs, field.Required(path, "must limit at least one resource"))
+    }
+    names := make([]string, 0, len(hard))
+    for name := range hard {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        if q := resource.MustParse(hard[name]); q.Sign() < 0 {
+            errs = append(errs, field.Invalid(path, hard[name], fmt.Sprintf("%s must not be negative", name)))
+        }
+    }
+    return errs
+}
+
+// validateLimits checks that limits set something, have a valid name, no
+// negative values, and that for each resource min <= defaultRequest <=
+// default <= max, as the API server requires of a LimitRange.
+func validateLimits(path *field.Path, limits *v1.ClassLimits) field.ErrorList {
+    var errs field.ErrorList
+    if limits.Name != "" {
+        for _, msg := range validation.IsDNS1123Subdomain(limits.Name) {
+            errs = append(errs, field.Invalid(path.Child("name"), limits.Name, msg))
+        }
+    }
+
+    bounds := []struct {
+        name   string
+        values *v1.ResourceValues
+    }{
+        {"min", limits.Min},
+        {"defaultRequest", limits.DefaultRequest},
+        {"default", limits.Default},
+        {"max", limits.Max},
+    }
+    empty := true
+    for _, resourceName := range []string{"cpu", "memory"} {
+        type bound struct {
+            name  string
+            value *resource.Quantity
+        }
+        var set []bound
+        for _, b := range bounds {
+            if b.values == nil {
+                continue
+            }
+            value := b.values.CPU
+            if resourceName == "memory" {
+                value = b.values.Memory
+            }
+            if value == nil {
+                continue
+            }
+            empty = false
+            if value.Sign() < 0 {
+                errs = append(errs, field.Invalid(path.Child(b.name, resourceName), value.String(), "must not be negative"))
+            }
+            set = append(set, bound{b.name, value})
+        }
+        for i := 1; i < len(set); i++ {
+            if set[i-1].value.Cmp(*set[i].value) > 0 {
+                errs = append(errs, field.Invalid(path.Child(set[i].name, resourceName), set[i].value.String(),
+                    fmt.Sprintf("must not be less than %s %s", set[i-1].name, set[i-1].value.String())))
+            }
+        }
+    }
+    if empty {
+        errs = append(errs, field.Required(path, "must set at least one default, default request, max or min"))
+    }
+    return errs
+}
+
+// validateRoleGrant checks that a grant names a ClusterRole and at least one
+// subject, and that its RoleBinding name is valid.
+func validateRoleGrant(path *field.Path, grant v1.RoleGrant) field.ErrorList {
+    var errs field.ErrorList
+    if grant.ClusterRole == "" {
+        errs = append(errs, field.Required(path.Child("clusterRole"), ""))
+    } else {
+        for _, msg := range pathvalidation.IsValidPathSegmentName(roleGrantName(grant)) {
+            errs = append(errs, field.Invalid(path.Child("name"), roleGrantName(grant), msg))
+        }
+    }
+    if len(grant.Groups) == 0 && len(grant.Users) == 0 && len(grant.ServiceAccounts) == 0 {
+        errs = append(errs, field.Required(path, "must grant the role to at least one group, user or service account"))
+    }
+    for i, sa := range grant.ServiceAccounts {
+        if sa.Name == "" {
+            errs = append(errs, field.Required(path.Child("serviceAccounts").Index(i).Child("name"), ""))
+        }
+    }
+    return errs
+}
+
+// validateClusterResources checks that cluster-scoped resources can be
+// decoded, name an object without a namespace, have templates that expand
+// and names that differ between namespaces, so that no two namespaces
+// manage the same object.
+func validateClusterResources(path *field.Path, raw []runtime.RawExtension, data templateData) field.ErrorList {
+    var errs field.ErrorList
+    seen := make(map[string]int)
+    for i, r := range raw {
+        path := path.Index(i)
+        var u unstructured.Unstructured
+        if err := json.Unmarshal(r.Raw, &u); err != nil {
+            errs = append(errs, field.Invalid(path, string(r.Raw), err.Error()))
+            continue
+        }
+        if u.GetAPIVersion() == "" {
+            errs = append(errs, field.Required(path.Child("apiVersion"), ""))
+        }
+        if u.GetKind() == "" {
+            errs = append(errs, field.Required(path.Child("kind"), ""))
+        }
+        if u.GetNamespace() != "" {
+            errs = append(errs, field.Forbidden(path.Child("metadata", "namespace"), "cluster-scoped resources have no namespace"))
+        }
+        if u.GetName() == "" {
+            errs = append(errs, field.Required(path.Child("metadata", "name"), ""))
+            continue
+        }
+
+        if err := expandObjectTemplates(u.DeepCopy().Object, data); err != nil {
+            errs = append(errs, field.Invalid(path, u.GetName(), err.Error()))
+            continue
+        }
+        other := data
+        other.Namespace = "other-" + other.Namespace
+        name, _ := expandTemplate(u.GetName(), data)
+        otherName, _ := expandTemplate(u.GetName(), other)
+        if name == otherName {
+            errs = append(errs, field.Invalid(path.Child("metadata", "name"), u.GetName(),
+                "must refer to {{ .Namespace }} so that each namespace gets its own object"))
+        }
+
+        key := fmt.Sprintf("%s/%s/%s", u.GetAPIVersion(), u.GetKind(), u.GetName())
+        if first, ok := seen[key]; ok {
+            errs = append(errs, field.Duplicate(path, fmt.Sprintf("%s %s, also at index %d", u.GetKind(), u.GetName(), first)))
+            continue
+        }
+        seen[key] = i
+    }
+    return errs
+}
+
+// validateCertificate checks that a certificate has a valid name, DNS names
+// that expand and an issuer.
+func validateCertificate(path *field.Path, cert v1.ClassCertificate) field.ErrorList {
+    var errs field.ErrorList
+    if cert.Name == "" {
+        errs = append(errs, field.Required(path.Child("name"), ""))
+    } else {
+        for _, msg := range validation.IsDNS1123Subdomain(cert.Name) {
+            errs = append(errs, field.Invalid(path.Child("name"), cert.Name, msg))
+        }
+    }
+    if len(cert.DNSNames) == 0 {
+        errs = append(errs, field.Required(path.Child("dnsNames"), ""))
+    }
+    for i, name := range cert.DNSNames {
+        if _, err := expandTemplate(name, sampleTemplateData); err != nil {
+            errs = append(errs, field.Invalid(path.Child("dnsNames").Index(i), name, err.Error()))
+        }
+    }
+    if cert.IssuerRef.Name == "" {
+        errs = append(errs, field.Required(path.Child("issuerRef", "name"), ""))
+    }
+    switch cert.IssuerRef.Kind {
+    case "", v1.CertificateIssuerKindIssuer, v1.CertificateIssuerKindClusterIssuer:
+    default:
+        errs = append(errs, field.NotSupported(path.Child("issuerRef", "kind"), cert.IssuerRef.Kind,
+            []string{string(v1.CertificateIssuerKindIssuer), string(v1.CertificateIssuerKindClusterIssuer)}))
+    }
+    return errs
+}
+
+// validateCertificateNames checks that the DNS name templates of an embedded
+// cert-manager Certificate expand.
+func validateCertificateNames(path *field.Path, res *unstructured.Unstructured) field.ErrorList {
+    if res.GroupVersionKind().Group != certManagerGroup || res.GetKind() != "Certificate" {
+        return nil
+    }
+    var errs field.ErrorList
+    names, _, _ := unstructured.NestedStringSlice(res.Object, "spec", "dnsNames")
+    for i, name := range names {
+        if _, err := expandTemplate(name, sampleTemplateData); err != nil {
+            errs = append(errs, field.Invalid(path.Child("dnsNames").Index(i), name, err.Error()))
+        }
+    }
+    if name, ok, _ := unstructured.NestedString(res.Object, "spec", "commonName"); ok {
+        if _, err := expandTemplate(name, sampleTemplateData); err != nil {
+            errs = append(errs, field.Invalid(path.Child("commonName"), name, err.Error()))
+        }
+    }
+    return errs
+}
+
+// validateDependencies checks that the depends-on references of resources
+// name resources of the class in the same or an earlier wave, and that they
+// do not form a cycle.
+func validateDependencies(path *field.Path, resources []*unstructured.Unstructured) field.ErrorList {
+    var errs field.ErrorList
+    waves := make(map[string]int, len(resources))
+    for _, res := range resources {
+        waves[resourceRef(res)], _ = resourceWave(res)
+    }
+    for i, res := range resources {
+        annotation := path.Index(i).Child("metadata", "annotations").Key(DependsOnAnnotation)
+        for _, ref := range resourceDependencies(res) {
+            wave, ok := waves[ref]
+            switch {
+            case !ok:
+                errs = append(errs, field.Invalid(annotation, ref, "must reference a resource of the class as Kind/name"))
+            case wave > waves[resourceRef(res)]:
+                errs = append(errs, field.Invalid(annotation, ref, fmt.Sprintf("references a resource in the later wave %d", wave)))
+            }
+        }
+    }
+    if len(errs) > 0 {
+        return errs
+    }
+    g, err := buildDependencyGraph(resources)
+    if err == nil {
+        _, err = g.order()
+    }
+    if err != nil {
+        errs = append(errs, field.Invalid(path, "", err.Error()))
+    }
+    return errs
+}
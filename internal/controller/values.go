@@ -0,0 +1,179 @@
+// internal/controller/values.go
+package controller
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// Annotation on a namespace overriding template values of its classes, as a
+// JSON object, e.g. {"tier":"large","host":"payments.example.com"}
+const ValuesAnnotation = "namespaceclass.akuity.io/values"
+
+// invalidValuesError reports template values of a namespace that its class
+// does not accept, or templates that do not expand with them.
+type invalidValuesError struct {
+    className string
+    err       error
+}
+
+func (e *invalidValuesError) Error() string {
+    return fmt.Sprintf("invalid values for class %s: %v", e.className, e.err)
+}
+
+func (e *invalidValuesError) Unwrap() error {
+    return e.err
+}
+
+// classValues decodes the default template values of a class. Classes
+// without values return nil.
+func classValues(nsc *v1.NamespaceClass) (map[string]interface{}, error) {
+    if nsc.Spec.Values == nil || len(nsc.Spec.Values.Raw) == 0 {
+        return nil, nil
+    }
+    var values map[string]interface{}
+    if err := json.Unmarshal(nsc.Spec.Values.Raw, &values); err != nil {
+        return nil, fmt.Errorf("values must be a JSON object: %w", err)
+    }
+    if values == nil {
+        values = make(map[string]interface{})
+    }
+    return values, nil
+}
+
+// namespaceValues returns the template values of a class for a namespace:
+// the defaults of the class overridden by the data of its values ConfigMap,
+// which are in turn overridden by the values annotation of the namespace.
+// Namespaces may only set the values the class declares, so a class decides
+// which knobs its namespaces can tune. Classes without values return nil.
+func namespaceValues(ns *corev1.Namespace, nsc *v1.NamespaceClass, configMap map[string]string) (map[string]interface{}, error) {
+    values, err := classValues(nsc)
+    if err != nil || values == nil {
+        return values, err
+    }
+    var undeclared []string
+    for name, text := range configMap {
+        def, ok := values[name]
+        if !ok {
+            undeclared = append(undeclared, name)
+            continue
+        }
+        // ConfigMap data are strings; other values are written as JSON
+        if _, isString := def.(string); isString {
+            values[name] = text
+            continue
+        }
+        var value interface{}
+        if err := json.Unmarshal([]byte(text), &value); err != nil {
+            return nil, fmt.Errorf("value %s of ConfigMap %s must be JSON: %w", name, nsc.Spec.ValuesFrom.ConfigMapRef.Name, err)
+        }
+        values[name] = value
+    }
+
+    if annotation := ns.Annotations[ValuesAnnotation]; annotation != "" {
+        var overrides map[string]interface{}
+        if err := json.Unmarshal([]byte(annotation), &overrides); err != nil {
+            return nil, fmt.Errorf("%s annotation must be a JSON object: %w", ValuesAnnotation, err)
+        }
+        for name, value := range overrides {
+            if _, ok := values[name]; !ok {
+                undeclared = append(undeclared, name)
+                continue
+            }
+            values[name] = value
+        }
+    }
+    if len(undeclared) > 0 {
+        sort.Strings(undeclared)
+        return nil, fmt.Errorf("class does not declare %s", strings.Join(undeclared, ", "))
+    }
+    return values, nil
+}
+
+// valuesConfigMap returns the data of the values ConfigMap of a class in a
+// namespace, or nil if the class names none or the namespace does not have
+// it. ConfigMaps are read as unstructured objects, which the manager does
+// not cache, so that they are not cached cluster-wide. Without a reader no
+// ConfigMap is read.
+func valuesConfigMap(ctx context.Context, c client.Reader, namespace string, nsc *v1.NamespaceClass) (map[string]string, error) {
+    if c == nil || nsc.Spec.ValuesFrom == nil || nsc.Spec.ValuesFrom.ConfigMapRef == nil {
+        return nil, nil
+    }
+    cm := &unstructured.Unstructured{}
+    cm.SetAPIVersion("v1")
+    cm.SetKind("ConfigMap")
+    key := types.NamespacedName{Namespace: namespace, Name: nsc.Spec.ValuesFrom.ConfigMapRef.Name}
+    if err := c.Get(ctx, key, cm); err != nil {
+        if errors.IsNotFound(err) {
+            return nil, nil
+        }
+        return nil, fmt.Errorf("failed to get values ConfigMap %s of class %s: %w", key, nsc.Name, err)
+    }
+    data, _, err := unstructured.NestedStringMap(cm.Object, "data")
+    return data, err
+}
+
+// readsValuesConfigMap reports whether a class reads its values from a
+// ConfigMap of the given name.
+func readsValuesConfigMap(nsc *v1.NamespaceClass, name string) bool {
+    return nsc.Spec.ValuesFrom != nil && nsc.Spec.ValuesFrom.ConfigMapRef != nil &&
+        nsc.Spec.ValuesFrom.ConfigMapRef.Name == name
+}
+
+// expandValues expands the templates in the resources of the classes of a
+// namespace that declare values, before they are rendered. Resources of
+// classes without values are left as they are, so classes whose manifests
+// contain template-like text, such as alerting rules, are unaffected.
+// Values ConfigMaps are read with c, which may be nil.
+func expandValues(ctx context.Context, c client.Reader, resources []*unstructured.Unstructured, ns *corev1.Namespace, sources resourceClasses, primary *v1.NamespaceClass) error {
+    byClass := make(map[string]map[string]interface{})
+    for _, res := range resources {
+        source := sources.of(res, primary)
+        if source.Spec.Values == nil {
+            continue
+        }
+        values, ok := byClass[source.Name]
+        if !ok {
+            configMap, err := valuesConfigMap(ctx, c, ns.Name, source)
+            if err != nil {
+                return err
+            }
+            if values, err = namespaceValues(ns, source, configMap); err != nil {
+                return &invalidValuesError{className: source.Name, err: err}
+            }
+            byClass[source.Name] = values
+        }
+        data := templateData{Namespace: ns.Name, Class: source.Name, Values: values}
+        if err := expandObjectTemplates(res.Object, data); err != nil {
+            return &invalidValuesError{className: source.Name,
+                err: fmt.Errorf("failed to expand %s %s: %w", res.GetKind(), res.GetName(), err)}
+        }
+    }
+    return nil
+}
+
+// reportInvalidValues records that the values of a namespace do not render
+// its classes, with a warning event and a Degraded condition on the
+// inventory of the namespace.
+func (r *NamespaceClassReconciler) reportInvalidValues(ctx context.Context, ns *corev1.Namespace, nsc *v1.NamespaceClass, valuesErr *invalidValuesError) error {
+    r.recordSyncEvent(ns, nsc, corev1.EventTypeWarning, ReasonInvalidValues,
+        "Not applying any resources: %v", valuesErr)
+    return r.setInventoryCondition(ctx, ns.Name, metav1.Condition{
+        Type:    v1.ConditionDegraded,
+        Status:  metav1.ConditionTrue,
+        Reason:  v1.ReasonInvalidValues,
+        Message: valuesErr.Error(),
+    })
+}
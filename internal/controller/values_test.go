@@ -0,0 +1,182 @@
+// internal/controller/values_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/tools/record"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Template values", func() {
+    It("should render class values overridden by the namespace annotation", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:        "team",
+                Labels:      map[string]string{LabelKey: "web"},
+                Annotations: map[string]string{ValuesAnnotation: `{"tier":"large"}`},
+                Finalizers:  []string{NamespaceFinalizer},
+            }},
+            &v1.NamespaceClass{
+                ObjectMeta: metav1.ObjectMeta{Name: "web"},
+                Spec: v1.NamespaceClassSpec{
+                    Values: &runtime.RawExtension{Raw: []byte(`{"tier":"small","team":"platform"}`)},
+                    Resources: []runtime.RawExtension{
+                        {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"tier":"{{ .Values.tier }}","owner":"{{ .Values.team }}-{{ .Namespace }}"}}`)},
+                    },
+                },
+            },
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{
+            Client:   cl,
+            Scheme:   scheme,
+            Recorder: record.NewFakeRecorder(10),
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        settings := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, settings)).To(Succeed())
+        Expect(settings.Data).To(Equal(map[string]string{"tier": "large", "owner": "platform-team"}))
+
+        // Namespaces may only set the values their class declares
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        ns.Annotations[ValuesAnnotation] = `{"tier":"small","replicas":3}`
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).To(MatchError(ContainSubstring("class does not declare replicas")))
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, settings)).To(Succeed())
+        Expect(settings.Data["tier"]).To(Equal("large"))
+    })
+
+    It("should merge the values ConfigMap of the namespace over the class values", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "web"},
+            Spec: v1.NamespaceClassSpec{
+                Values:     &runtime.RawExtension{Raw: []byte(`{"tier":"small","replicas":1}`)},
+                ValuesFrom: &v1.ValuesSource{ConfigMapRef: &v1.LocalConfigMapReference{Name: "team-values"}},
+                Resources: []runtime.RawExtension{
+                    {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"tier":"{{ .Values.tier }}","replicas":"{{ .Values.replicas }}"}}`)},
+                },
+            },
+        }
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+                Name:        "team",
+                Labels:      map[string]string{LabelKey: "web"},
+                Annotations: map[string]string{ValuesAnnotation: `{"tier":"large"}`},
+                Finalizers:  []string{NamespaceFinalizer},
+            }},
+            &corev1.ConfigMap{
+                ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "team-values"},
+                Data:       map[string]string{"tier": "medium", "replicas": "3"},
+            },
+            nsc,
+        ).WithStatusSubresource(&v1.NamespaceClass{}).Build()
+        reconciler := &NamespaceClassReconciler{
+            Client:   cl,
+            Scheme:   scheme,
+            Recorder: record.NewFakeRecorder(10),
+        }
+        request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "team"}}
+
+        _, err := reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        settings := &corev1.ConfigMap{}
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, settings)).To(Succeed())
+        Expect(settings.Data).To(Equal(map[string]string{"tier": "large", "replicas": "3"}))
+
+        // Without the annotation the ConfigMap decides
+        ns := &corev1.Namespace{}
+        Expect(cl.Get(ctx, types.NamespacedName{Name: "team"}, ns)).To(Succeed())
+        delete(ns.Annotations, ValuesAnnotation)
+        Expect(cl.Update(ctx, ns)).To(Succeed())
+        _, err = reconciler.Reconcile(ctx, request)
+        Expect(err).NotTo(HaveOccurred())
+        Expect(cl.Get(ctx, types.NamespacedName{Namespace: "team", Name: "settings"}, settings)).To(Succeed())
+        Expect(settings.Data["tier"]).To(Equal("medium"))
+        Expect(readsValuesConfigMap(nsc, "team-values")).To(BeTrue())
+        Expect(readsValuesConfigMap(nsc, "settings")).To(BeFalse())
+
+        // Values sources need values declaring what they may set
+        nsc.Spec.Values = nil
+        Expect(ValidateClass(nsc)).To(ContainElement(HaveField("Field", "spec.values")))
+    })
+
+    It("should offer the repeatable sprig functions to templates", func() {
+        data := templateData{Namespace: "payments", Class: "web", Values: map[string]interface{}{
+            "host": "", "port": float64(8080), "labels": map[string]interface{}{"team": "payments"},
+        }}
+        for text, expected := range map[string]string{
+            `{{ .Values.host | default (printf "%s.apps.example.com" .Namespace) }}`:    "payments.apps.example.com",
+            `{{ .Values.port | quote }}`:                                                `"8080"`,
+            `{{ .Namespace | b64enc }}`:                                                 "cGF5bWVudHM=",
+            `{{ printf "%s-%s-reader" .Namespace .Class | trunc 12 | trimSuffix "-" }}`: "payments-web",
+            `{{ set .Values.labels "tier" "gold" | toYaml }}`:                           "team: payments\ntier: gold",
+            `{{ (fromYaml "team: payments").team | upper }}`:                            "PAYMENTS",
+            `{{ dict "app" .Class | toJson }}`:                                          `{"app":"web"}`,
+        } {
+            Expect(expandTemplate(text, data)).To(Equal(expected), text)
+        }
+
+        // Functions reading the environment or the clock would render differently on every sync
+        for _, text := range []string{`{{ env "HOME" }}`, `{{ now }}`, `{{ randAlpha 4 }}`} {
+            _, err := expandTemplate(text, data)
+            Expect(err).To(MatchError(ContainSubstring("not defined")), text)
+        }
+    })
+
+    It("should not let templates modify the values other templates see", func() {
+        data := templateData{Namespace: "payments", Class: "web", Values: map[string]interface{}{
+            "labels": map[string]interface{}{"team": "payments"},
+        }}
+        obj := map[string]interface{}{"data": map[string]interface{}{
+            "gold":   `{{ set .Values.labels "tier" "gold" | toYaml }}`,
+            "labels": `{{ .Values.labels | toYaml }}`,
+            "silver": `{{ set .Values.labels "tier" "silver" | toYaml }}`,
+        }}
+        Expect(expandObjectTemplates(obj, data)).To(Succeed())
+        Expect(obj["data"]).To(Equal(map[string]interface{}{
+            "gold":   "team: payments\ntier: gold",
+            "labels": "team: payments",
+            "silver": "team: payments\ntier: silver",
+        }))
+        Expect(data.Values["labels"]).To(Equal(map[string]interface{}{"team": "payments"}))
+    })
+
+    It("should leave templates of classes without values alone", func() {
+        nsc := &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+            Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"alerts"},"data":{"summary":"{{ $labels.pod }} is down"}}`)},
+            }},
+        }
+        Expect(ValidateClass(nsc)).To(BeEmpty())
+        resources, err := RenderClass(nsc, "team")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(resources[0].Object["data"]).To(HaveKeyWithValue("summary", "{{ $labels.pod }} is down"))
+
+        // Classes with values must expand with their defaults
+        nsc.Spec.Values = &runtime.RawExtension{Raw: []byte(`{"tier":"small"}`)}
+        errs := ValidateClass(nsc)
+        Expect(errs).To(HaveLen(1))
+        Expect(errs[0].Field).To(Equal("spec.resources[0]"))
+        nsc.Spec.Values = &runtime.RawExtension{Raw: []byte(`["small"]`)}
+        Expect(ValidateClass(nsc)).To(ContainElement(HaveField("Field", "spec.values")))
+    })
+})
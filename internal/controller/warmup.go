@@ -0,0 +1,129 @@
+// internal/controller/warmup.go
+package controller
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "golang.org/x/time/rate"
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// WarmUpBudget limits how fast the namespaces that exist when the controller
+// starts are reconciled. After a restart every namespace is queued at once;
+// the budget spreads that initial pass out so a large cluster does not
+// saturate the API server. Namespaces created after startup and later
+// reconciles of already warmed-up namespaces are not limited.
+type WarmUpBudget struct {
+    // ReconcilesPerSecond is the sustained rate of warm-up reconciles; zero disables the rate limit
+    ReconcilesPerSecond float64
+
+    // Burst is the number of warm-up reconciles allowed above the sustained rate
+    Burst int
+
+    // MaxConcurrent bounds parallel warm-up reconciles; zero leaves them bounded by the controller only
+    MaxConcurrent int
+
+    mu      sync.Mutex
+    listed  bool
+    pending map[string]struct{}
+    started time.Time
+    limiter *rate.Limiter
+    slots   chan struct{}
+    scope   *WatchScope
+}
+
+// acquire blocks until a namespace from the initial listing may be
+// reconciled. The returned function must be called once the reconcile
+// finishes. Namespaces outside the initial listing return immediately.
+func (w *WarmUpBudget) acquire(ctx context.Context, c client.Reader, namespace string) (func(), error) {
+    noop := func() {}
+    if w == nil {
+        return noop, nil
+    }
+    warming, err := w.warming(ctx, c, namespace)
+    if err != nil || !warming {
+        return noop, err
+    }
+
+    if w.slots != nil {
+        select {
+        case w.slots <- struct{}{}:
+        case <-ctx.Done():
+            return noop, ctx.Err()
+        }
+    }
+    release := func() {
+        if w.slots != nil {
+            <-w.slots
+        }
+        w.finish(ctx, namespace)
+    }
+    if w.limiter != nil {
+        if err := w.limiter.Wait(ctx); err != nil {
+            if w.slots != nil {
+                <-w.slots
+            }
+            return noop, err
+        }
+    }
+    return release, nil
+}
+
+// warming reports whether the namespace is still waiting for its first
+// reconcile since startup. The initial listing is taken on first use, once
+// the manager's cache has synced.
+func (w *WarmUpBudget) warming(ctx context.Context, c client.Reader, namespace string) (bool, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if !w.listed {
+        var nsList corev1.NamespaceList
+        if err := c.List(ctx, &nsList); err != nil {
+            return false, err
+        }
+        w.pending = make(map[string]struct{}, len(nsList.Items))
+        for _, ns := range nsList.Items {
+            // Namespaces outside the scope are never reconciled
+            if w.scope.includes(ns.Name) {
+                w.pending[ns.Name] = struct{}{}
+            }
+        }
+        if w.ReconcilesPerSecond > 0 {
+            burst := w.Burst
+            if burst < 1 {
+                burst = 1
+            }
+            w.limiter = rate.NewLimiter(rate.Limit(w.ReconcilesPerSecond), burst)
+        }
+        if w.MaxConcurrent > 0 {
+            w.slots = make(chan struct{}, w.MaxConcurrent)
+        }
+        w.started = time.Now()
+        w.listed = true
+        warmUpPendingNamespaces.Set(float64(len(w.pending)))
+        log.FromContext(ctx).Info("Starting warm-up", "namespaces", len(w.pending),
+            "reconcilesPerSecond", w.ReconcilesPerSecond, "maxConcurrent", w.MaxConcurrent)
+    }
+    _, ok := w.pending[namespace]
+    return ok, nil
+}
+
+// finish marks a namespace as warmed up, whatever the result of its
+// reconcile; failures are retried with the controller's normal backoff.
+func (w *WarmUpBudget) finish(ctx context.Context, namespace string) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if _, ok := w.pending[namespace]; !ok {
+        return
+    }
+    delete(w.pending, namespace)
+    warmUpPendingNamespaces.Set(float64(len(w.pending)))
+    if len(w.pending) == 0 {
+        log.FromContext(ctx).Info("Warm-up complete", "durationSeconds", time.Since(w.started).Seconds())
+    }
+}
@@ -0,0 +1,48 @@
+// internal/controller/warmup_test.go
+package controller
+
+import (
+    "context"
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Restart warm-up", func() {
+    It("should only budget namespaces listed at startup", func() {
+        ctx := context.Background()
+        scheme := newScheme()
+        cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+            &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+        ).Build()
+        budget := &WarmUpBudget{ReconcilesPerSecond: 100, Burst: 1, MaxConcurrent: 1}
+
+        release, err := budget.acquire(ctx, cl, "a")
+        Expect(err).NotTo(HaveOccurred())
+        Expect(budget.slots).To(HaveLen(1))
+
+        // The only slot is taken, so another startup namespace has to wait
+        timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+        defer cancel()
+        _, err = budget.acquire(timeout, cl, "b")
+        Expect(err).To(MatchError(context.DeadlineExceeded))
+
+        // Namespaces created after startup are not limited
+        Expect(cl.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "c"}})).To(Succeed())
+        _, err = budget.acquire(ctx, cl, "c")
+        Expect(err).NotTo(HaveOccurred())
+
+        release()
+        Expect(budget.slots).To(BeEmpty())
+        Expect(budget.pending).To(HaveLen(1))
+        release, err = budget.acquire(ctx, cl, "b")
+        Expect(err).NotTo(HaveOccurred())
+        release()
+        Expect(budget.pending).To(BeEmpty())
+    })
+})
@@ -0,0 +1,55 @@
+// internal/controller/waves.go
+package controller
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotation on an embedded resource assigning it to a sync wave. Waves are
+// applied in ascending order, and a wave only after every resource of the
+// previous wave exists and is ready. Resources without the annotation are
+// in wave 0.
+const WaveAnnotation = "namespaceclass.akuity.io/wave"
+
+// resourceWave returns the sync wave of a resource.
+func resourceWave(obj *unstructured.Unstructured) (int, error) {
+    value, ok := obj.GetAnnotations()[WaveAnnotation]
+    if !ok {
+        return 0, nil
+    }
+    wave, err := strconv.Atoi(value)
+    if err != nil {
+        return 0, fmt.Errorf("invalid %s annotation %q on %s %s", WaveAnnotation, value, obj.GetKind(), obj.GetName())
+    }
+    return wave, nil
+}
+
+// pendingResource returns the first of the resources that does not exist
+// yet or reports a Ready or Established condition that is not true, or nil
+// if all of them are ready. CustomResourceDefinitions must be established.
+func (r *NamespaceClassReconciler) pendingResource(ctx context.Context, resources []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+    for _, res := range resources {
+        live := &unstructured.Unstructured{}
+        live.SetGroupVersionKind(res.GroupVersionKind())
+        if err := r.Get(ctx, client.ObjectKeyFromObject(res), live); err != nil {
+            if client.IgnoreNotFound(err) != nil {
+                return nil, err
+            }
+            return res, nil
+        }
+        if _, ok := definedGroupKind(res); ok && !crdEstablished(live) {
+            return res, nil
+        }
+        for _, conditionType := range []string{"Ready", "Established"} {
+            if c := findCondition(live, conditionType); c != nil && c["status"] != "True" {
+                return res, nil
+            }
+        }
+    }
+    return nil, nil
+}
@@ -0,0 +1,60 @@
+// internal/controller/waves_test.go
+package controller
+
+import (
+    "context"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Sync waves", func() {
+    It("should order resources by wave and wait for each wave to be ready", func() {
+        ctx := context.Background()
+        resource := func(kind, name, wave string) *unstructured.Unstructured {
+            u := &unstructured.Unstructured{}
+            u.SetAPIVersion("v1")
+            u.SetKind(kind)
+            u.SetName(name)
+            u.SetNamespace("team")
+            if wave != "" {
+                u.SetAnnotations(map[string]string{WaveAnnotation: wave})
+            }
+            return u
+        }
+        resources := []*unstructured.Unstructured{
+            resource("ConfigMap", "late", "1"),
+            resource("Pod", "default", ""),
+            resource("ServiceAccount", "early", "-1"),
+            resource("ConfigMap", "also-default", "0"),
+        }
+        Expect(orderResources(resources)).To(Succeed())
+        var names []string
+        for _, res := range resources {
+            names = append(names, res.GetName())
+        }
+        Expect(names).To(Equal([]string{"early", "default", "also-default", "late"}))
+        Expect(orderResources([]*unstructured.Unstructured{resource("ConfigMap", "bad", "first")})).NotTo(Succeed())
+
+        scheme := newScheme()
+        notReady := resource("Pod", "default", "")
+        Expect(unstructured.SetNestedSlice(notReady.Object, []interface{}{
+            map[string]interface{}{"type": "Ready", "status": "False"},
+        }, "status", "conditions")).To(Succeed())
+        reconciler := &NamespaceClassReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+            resource("ServiceAccount", "early", "-1"), notReady,
+        ).Build()}
+
+        pending, err := reconciler.pendingResource(ctx, resources[:1])
+        Expect(err).NotTo(HaveOccurred())
+        Expect(pending).To(BeNil())
+        pending, err = reconciler.pendingResource(ctx, resources[1:3])
+        Expect(err).NotTo(HaveOccurred())
+        Expect(pending.GetName()).To(Equal("default"))
+        pending, err = reconciler.pendingResource(ctx, resources[3:])
+        Expect(err).NotTo(HaveOccurred())
+        Expect(pending.GetName()).To(Equal("late"))
+    })
+})
@@ -0,0 +1,52 @@
+// internal/controller/windows.go
+package controller
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/robfig/cron/v3"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// updateWindowState reports whether one of the update windows is open at
+// now and, if none is, when the next one opens. Classes without windows are
+// always open.
+func updateWindowState(windows []v1.UpdateWindow, now time.Time) (bool, time.Time, error) {
+    var next time.Time
+    for _, window := range windows {
+        schedule, location, err := parseUpdateWindow(window)
+        if err != nil {
+            return false, time.Time{}, err
+        }
+        local := now.In(location)
+
+        // The window is open if it started less than its duration ago
+        if start := schedule.Next(local.Add(-window.Duration.Duration)); !start.After(local) {
+            return true, time.Time{}, nil
+        }
+        if start := schedule.Next(local); next.IsZero() || start.Before(next) {
+            next = start
+        }
+    }
+    return len(windows) == 0, next, nil
+}
+
+// parseUpdateWindow parses the schedule and time zone of a window.
+func parseUpdateWindow(window v1.UpdateWindow) (cron.Schedule, *time.Location, error) {
+    schedule, err := cron.ParseStandard(window.Schedule)
+    if err != nil {
+        return nil, nil, fmt.Errorf("invalid update window schedule %q: %w", window.Schedule, err)
+    }
+    location := time.UTC
+    if window.TimeZone != "" {
+        if location, err = time.LoadLocation(window.TimeZone); err != nil {
+            return nil, nil, fmt.Errorf("invalid update window time zone %q: %w", window.TimeZone, err)
+        }
+    }
+    if window.Duration.Duration <= 0 {
+        return nil, nil, fmt.Errorf("update window %q must have a positive duration", window.Schedule)
+    }
+    return schedule, location, nil
+}
@@ -0,0 +1,46 @@
+// internal/controller/windows_test.go
+package controller
+
+import (
+    "time"
+
+    . "github.com/onsi/ginkgo/v2"
+    . "github.com/onsi/gomega"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+var _ = Describe("Update windows", func() {
+    It("should only open during the scheduled windows", func() {
+        nightly := v1.UpdateWindow{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}
+        at := func(value string) time.Time {
+            t, err := time.Parse(time.RFC3339, value)
+            Expect(err).NotTo(HaveOccurred())
+            return t
+        }
+
+        open, _, err := updateWindowState(nil, at("2024-05-01T12:00:00Z"))
+        Expect(err).NotTo(HaveOccurred())
+        Expect(open).To(BeTrue())
+
+        open, _, err = updateWindowState([]v1.UpdateWindow{nightly}, at("2024-05-01T23:30:00Z"))
+        Expect(err).NotTo(HaveOccurred())
+        Expect(open).To(BeTrue())
+
+        open, next, err := updateWindowState([]v1.UpdateWindow{nightly}, at("2024-05-02T00:30:00Z"))
+        Expect(err).NotTo(HaveOccurred())
+        Expect(open).To(BeFalse())
+        Expect(next.UTC()).To(Equal(at("2024-05-02T22:00:00Z")))
+
+        // Schedules are evaluated in the time zone of the window
+        nightly.TimeZone = "Europe/Berlin"
+        open, next, err = updateWindowState([]v1.UpdateWindow{nightly}, at("2024-05-01T21:00:00Z"))
+        Expect(err).NotTo(HaveOccurred())
+        Expect(open).To(BeTrue())
+        Expect(next.IsZero()).To(BeTrue())
+
+        _, _, err = updateWindowState([]v1.UpdateWindow{{Schedule: "not a schedule", Duration: metav1.Duration{Duration: time.Hour}}}, time.Now())
+        Expect(err).To(HaveOccurred())
+    })
+})
@@ -0,0 +1,111 @@
+// internal/features/features.go
+package features
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Feature is the name of an experimental behavior that can be turned on or
+// off per cluster with --feature-gates.
+type Feature string
+
+const (
+    // DriftWatches watches the kinds the controller applies and reconciles a
+    // namespace as soon as one of its managed resources is changed, instead
+    // of on the next resync
+    DriftWatches Feature = "DriftWatches"
+
+    // ServerSideApply updates managed resources with server-side apply
+    // instead of replacing them, so fields set by other managers are kept
+    ServerSideApply Feature = "ServerSideApply"
+)
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+    Alpha Stage = "Alpha"
+    Beta  Stage = "Beta"
+)
+
+// Spec is the default state and maturity of a feature.
+type Spec struct {
+    Default bool
+    Stage   Stage
+}
+
+// Known features; alpha features are disabled unless turned on
+var known = map[Feature]Spec{
+    DriftWatches:    {Default: false, Stage: Alpha},
+    ServerSideApply: {Default: false, Stage: Alpha},
+}
+
+// Gates turns features on or off. It implements flag.Value for
+// --feature-gates, which takes a comma-separated list of Feature=true|false
+// pairs. A nil Gates leaves every feature at its default.
+type Gates struct {
+    enabled map[Feature]bool
+}
+
+// Enabled reports whether a feature is turned on.
+func (g *Gates) Enabled(f Feature) bool {
+    if g != nil {
+        if enabled, ok := g.enabled[f]; ok {
+            return enabled
+        }
+    }
+    return known[f].Default
+}
+
+// Set parses a comma-separated list of Feature=true|false pairs, e.g.
+// "DriftWatches=true,ServerSideApply=false". Unknown features are rejected.
+func (g *Gates) Set(value string) error {
+    enabled := make(map[Feature]bool)
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        name, state, ok := strings.Cut(item, "=")
+        if !ok {
+            return fmt.Errorf("invalid feature gate %q: expected Feature=true|false", item)
+        }
+        f := Feature(strings.TrimSpace(name))
+        if _, ok := known[f]; !ok {
+            return fmt.Errorf("unknown feature gate %q", f)
+        }
+        on, err := strconv.ParseBool(strings.TrimSpace(state))
+        if err != nil {
+            return fmt.Errorf("invalid value of feature gate %q: %w", f, err)
+        }
+        enabled[f] = on
+    }
+    g.enabled = enabled
+    return nil
+}
+
+// String returns the features set explicitly, in the format Set accepts.
+func (g *Gates) String() string {
+    if g == nil {
+        return ""
+    }
+    items := make([]string, 0, len(g.enabled))
+    for f, on := range g.enabled {
+        items = append(items, fmt.Sprintf("%s=%t", f, on))
+    }
+    sort.Strings(items)
+    return strings.Join(items, ",")
+}
+
+// Known describes the known features and their defaults for flag help.
+func Known() string {
+    items := make([]string, 0, len(known))
+    for f, spec := range known {
+        items = append(items, fmt.Sprintf("%s=true|false (%s - default=%t)", f, spec.Stage, spec.Default))
+    }
+    sort.Strings(items)
+    return strings.Join(items, ", ")
+}
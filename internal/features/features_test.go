@@ -0,0 +1,33 @@
+// internal/features/features_test.go
+package features
+
+import (
+    "flag"
+    "testing"
+)
+
+func TestGates(t *testing.T) {
+    var nilGates *Gates
+    if nilGates.Enabled(DriftWatches) || nilGates.Enabled(ServerSideApply) {
+        t.Error("alpha features must be disabled by default")
+    }
+
+    gates := &Gates{}
+    fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+    fs.Var(gates, "feature-gates", Known())
+    if err := fs.Parse([]string{"--feature-gates=DriftWatches=true, ServerSideApply=false"}); err != nil {
+        t.Fatal(err)
+    }
+    if !gates.Enabled(DriftWatches) || gates.Enabled(ServerSideApply) {
+        t.Errorf("unexpected gates %s", gates)
+    }
+    if gates.String() != "DriftWatches=true,ServerSideApply=false" {
+        t.Errorf("unexpected string %q", gates.String())
+    }
+
+    for _, value := range []string{"Unknown=true", "DriftWatches", "DriftWatches=maybe"} {
+        if err := (&Gates{}).Set(value); err == nil {
+            t.Errorf("%s: expected an error", value)
+        }
+    }
+}
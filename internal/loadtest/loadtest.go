@@ -0,0 +1,257 @@
+// internal/loadtest/loadtest.go
+package loadtest
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+    "time"
+
+    dto "github.com/prometheus/client_model/go"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/rest"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+    metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+const (
+    // Label marking objects created by the load generator, used for cleanup
+    LoadTestLabel = "namespaceclass.akuity.io/loadtest"
+
+    // Name of the controller whose metrics are reported
+    controllerName = "namespaceclass-controller"
+)
+
+// Options configures a load test.
+type Options struct {
+    Namespaces        int
+    Classes           int
+    ResourcesPerClass int
+    Timeout           time.Duration
+    Cleanup           bool
+}
+
+// Report summarizes a load test run.
+type Report struct {
+    Namespaces    int
+    Synced        int
+    Duration      time.Duration
+    Reconciles    map[string]float64
+    MaxQueueDepth float64
+    APICalls      map[string]float64
+}
+
+// Run creates synthetic classes and namespaces in the cluster, runs the
+// controller in-process until every namespace is synced or the timeout
+// expires, and reports throughput, queue depth, and API calls made.
+func Run(ctx context.Context, cfg *rest.Config, scheme *runtime.Scheme, opts Options) (*Report, error) {
+    c, err := client.New(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return nil, fmt.Errorf("failed to create client: %w", err)
+    }
+    if opts.Cleanup {
+        defer cleanup(context.Background(), c)
+    }
+    
+    for i := 0; i < opts.Classes; i++ {
+        if err := c.Create(ctx, syntheticClass(i, opts.ResourcesPerClass)); err != nil {
+            return nil, fmt.Errorf("failed to create class: %w", err)
+        }
+    }
+    
+    mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+        Scheme:                 scheme,
+        Metrics:                metricsserver.Options{BindAddress: "0"},
+        HealthProbeBindAddress: "0",
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to create manager: %w", err)
+    }
+    if err := (&controller.NamespaceClassReconciler{
+        Client: controller.NewInstrumentedClient(mgr.GetClient()),
+        Scheme: mgr.GetScheme(),
+    }).SetupWithManager(mgr); err != nil {
+        return nil, fmt.Errorf("failed to set up controller: %w", err)
+    }
+    
+    mgrCtx, stop := context.WithCancel(ctx)
+    defer stop()
+    errs := make(chan error, 1)
+    go func() { errs <- mgr.Start(mgrCtx) }()
+    if !mgr.GetCache().WaitForCacheSync(ctx) {
+        return nil, fmt.Errorf("failed to sync caches")
+    }
+    
+    before, err := metrics.Registry.Gather()
+    if err != nil {
+        return nil, err
+    }
+    start := time.Now()
+    for i := 0; i < opts.Namespaces; i++ {
+        ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+            Name: fmt.Sprintf("loadtest-%d", i),
+            Labels: map[string]string{
+                LoadTestLabel:       "true",
+                controller.LabelKey: fmt.Sprintf("loadtest-%d", i%opts.Classes),
+            },
+        }}
+        if err := c.Create(ctx, ns); err != nil {
+            return nil, fmt.Errorf("failed to create namespace: %w", err)
+        }
+    }
+    
+    // Poll the manager's cache so that progress checks make no API calls
+    report := &Report{Namespaces: opts.Namespaces}
+    deadline := time.After(opts.Timeout)
+    ticker := time.NewTicker(time.Second)
+    defer ticker.Stop()
+    for report.Synced < opts.Namespaces {
+        select {
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        case err := <-errs:
+            return nil, fmt.Errorf("manager stopped: %w", err)
+        case <-deadline:
+            return report.finish(start, before)
+        case <-ticker.C:
+        }
+        
+        synced, err := syncedNamespaces(ctx, mgr.GetCache(), opts.ResourcesPerClass)
+        if err != nil {
+            return nil, err
+        }
+        report.Synced = synced
+        if gathered, err := metrics.Registry.Gather(); err == nil {
+            depth := sumMetric(gathered, "workqueue_depth", "name", controllerName, "")[""]
+            if depth > report.MaxQueueDepth {
+                report.MaxQueueDepth = depth
+            }
+        }
+    }
+    return report.finish(start, before)
+}
+
+// finish computes the metric deltas accumulated since the namespaces were created.
+func (r *Report) finish(start time.Time, before []*dto.MetricFamily) (*Report, error) {
+    r.Duration = time.Since(start)
+    after, err := metrics.Registry.Gather()
+    if err != nil {
+        return nil, err
+    }
+    r.Reconciles = delta(
+        sumMetric(before, "controller_runtime_reconcile_total", "controller", controllerName, "result"),
+        sumMetric(after, "controller_runtime_reconcile_total", "controller", controllerName, "result"))
+    r.APICalls = delta(
+        sumMetric(before, "rest_client_requests_total", "", "", "method"),
+        sumMetric(after, "rest_client_requests_total", "", "", "method"))
+    // Do not count the namespaces created by the load generator itself
+    r.APICalls["POST"] -= float64(r.Namespaces)
+    return r, nil
+}
+
+// Print writes a human readable summary of the report.
+func (r *Report) Print(w io.Writer) {
+    fmt.Fprintf(w, "Synced %d/%d namespaces in %s (%.1f namespaces/s)\n", 
+        r.Synced, r.Namespaces, r.Duration.Round(time.Millisecond), float64(r.Synced)/r.Duration.Seconds())
+    fmt.Fprintf(w, "Max queue depth: %.0f\n", r.MaxQueueDepth)
+    fmt.Fprintf(w, "Reconciles: %s\n", formatCounts(r.Reconciles))
+    fmt.Fprintf(w, "API calls: %s\n", formatCounts(r.APICalls))
+}
+
+// syncedNamespaces counts load test namespaces whose inventory lists every
+// resource of their class.
+func syncedNamespaces(ctx context.Context, reader client.Reader, resourcesPerClass int) (int, error) {
+    inventories := &v1.NamespaceClassInventoryList{}
+    if err := reader.List(ctx, inventories); err != nil {
+        return 0, err
+    }
+    synced := 0
+    for _, inv := range inventories.Items {
+        if strings.HasPrefix(inv.Name, "loadtest-") && len(inv.Spec.Resources) == resourcesPerClass {
+            synced++
+        }
+    }
+    return synced, nil
+}
+
+// syntheticClass returns a class with the given number of ConfigMaps.
+func syntheticClass(index, resources int) *v1.NamespaceClass {
+    nsc := &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{
+        Name:   fmt.Sprintf("loadtest-%d", index),
+        Labels: map[string]string{LoadTestLabel: "true"},
+    }}
+    for i := 0; i < resources; i++ {
+        nsc.Spec.Resources = append(nsc.Spec.Resources, runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+            `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"loadtest-%d"},"data":{"class":"%d"}}`, i, index))})
+    }
+    return nsc
+}
+
+// cleanup deletes the namespaces and classes created by the load generator.
+func cleanup(ctx context.Context, c client.Client) {
+    selector := client.MatchingLabels{LoadTestLabel: "true"}
+    _ = c.DeleteAllOf(ctx, &v1.NamespaceClass{}, selector)
+    namespaces := &corev1.NamespaceList{}
+    if err := c.List(ctx, namespaces, selector); err != nil {
+        return
+    }
+    for i := range namespaces.Items {
+        _ = c.Delete(ctx, &namespaces.Items[i])
+    }
+}
+
+// sumMetric sums the samples of a counter or gauge family that match the
+// filter label, grouped by the value of the groupBy label.
+func sumMetric(families []*dto.MetricFamily, name, filterLabel, filterValue, groupBy string) map[string]float64 {
+    sums := make(map[string]float64)
+    for _, family := range families {
+        if family.GetName() != name {
+            continue
+        }
+        for _, m := range family.GetMetric() {
+            labels := make(map[string]string)
+            for _, pair := range m.GetLabel() {
+                labels[pair.GetName()] = pair.GetValue()
+            }
+            if filterLabel != "" && labels[filterLabel] != filterValue {
+                continue
+            }
+            value := m.GetCounter().GetValue()
+            if m.Gauge != nil {
+                value = m.GetGauge().GetValue()
+            }
+            sums[labels[groupBy]] += value
+        }
+    }
+    return sums
+}
+
+func delta(before, after map[string]float64) map[string]float64 {
+    out := make(map[string]float64, len(after))
+    for key, value := range after {
+        out[key] = value - before[key]
+    }
+    return out
+}
+
+func formatCounts(counts map[string]float64) string {
+    keys := make([]string, 0, len(counts))
+    for key := range counts {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    parts := make([]string, 0, len(keys))
+    for _, key := range keys {
+        parts = append(parts, fmt.Sprintf("%s=%.0f", key, counts[key]))
+    }
+    return strings.Join(parts, " ")
+}
@@ -0,0 +1,30 @@
+// internal/loadtest/loadtest_test.go
+package loadtest
+
+import (
+    "testing"
+
+    "github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSumMetric(t *testing.T) {
+    registry := prometheus.NewRegistry()
+    reconciles := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "controller_runtime_reconcile_total"},
+        []string{"controller", "result"})
+    registry.MustRegister(reconciles)
+    reconciles.WithLabelValues(controllerName, "success").Add(3)
+    reconciles.WithLabelValues(controllerName, "error").Add(1)
+    reconciles.WithLabelValues("other", "success").Add(5)
+
+    families, err := registry.Gather()
+    if err != nil {
+        t.Fatal(err)
+    }
+    sums := sumMetric(families, "controller_runtime_reconcile_total", "controller", controllerName, "result")
+    if sums["success"] != 3 || sums["error"] != 1 {
+        t.Errorf("unexpected sums: %v", sums)
+    }
+    if got := formatCounts(sums); got != "error=1 success=3" {
+        t.Errorf("formatCounts() = %q", got)
+    }
+}
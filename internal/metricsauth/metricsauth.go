@@ -0,0 +1,171 @@
+// internal/metricsauth/metricsauth.go
+package metricsauth
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/go-logr/logr"
+    authenticationv1 "k8s.io/api/authentication/v1"
+    authorizationv1 "k8s.io/api/authorization/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+    authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+    "k8s.io/client-go/rest"
+    metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// How long authentication and authorization decisions are reused
+const DefaultCacheTTL = time.Minute
+
+// Authorizer guards the metrics endpoint the way kube-rbac-proxy does:
+// requests must carry a bearer token, which is authenticated with a
+// TokenReview, and the token's user must be allowed to get the requested
+// path by a SubjectAccessReview, e.g. through a ClusterRole granting get on
+// the /metrics non-resource URL. Decisions are cached for CacheTTL so that
+// every scrape does not cost two API requests.
+type Authorizer struct {
+    TokenReviews         authenticationv1client.TokenReviewInterface
+    SubjectAccessReviews authorizationv1client.SubjectAccessReviewInterface
+
+    // CacheTTL is how long a decision is reused; zero disables the cache
+    CacheTTL time.Duration
+
+    mu        sync.Mutex
+    decisions map[string]decision
+}
+
+// decision is the cached outcome of a request.
+type decision struct {
+    status  int
+    expires time.Time
+}
+
+// FilterProvider returns a metrics server filter authenticating and
+// authorizing requests against the API server. It is meant for the
+// FilterProvider option of the metrics server; the controller needs to be
+// allowed to create TokenReviews and SubjectAccessReviews.
+func FilterProvider(c *rest.Config, httpClient *http.Client) (metricsserver.Filter, error) {
+    authn, err := authenticationv1client.NewForConfigAndClient(c, httpClient)
+    if err != nil {
+        return nil, err
+    }
+    authz, err := authorizationv1client.NewForConfigAndClient(c, httpClient)
+    if err != nil {
+        return nil, err
+    }
+    a := &Authorizer{
+        TokenReviews:         authn.TokenReviews(),
+        SubjectAccessReviews: authz.SubjectAccessReviews(),
+        CacheTTL:             DefaultCacheTTL,
+    }
+    return a.Filter, nil
+}
+
+// Filter wraps a handler of the metrics server so that it only serves
+// authorized requests. Requests without a valid token get 401 Unauthorized
+// and users without access 403 Forbidden.
+func (a *Authorizer) Filter(log logr.Logger, handler http.Handler) (http.Handler, error) {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+        if !ok || token == "" {
+            http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+            return
+        }
+        status, err := a.authorize(r.Context(), token, r.URL.Path, strings.ToLower(r.Method))
+        if err != nil {
+            log.Error(err, "Failed to authorize metrics request", "path", r.URL.Path)
+            http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+            return
+        }
+        if status != http.StatusOK {
+            http.Error(w, http.StatusText(status), status)
+            return
+        }
+        handler.ServeHTTP(w, r)
+    }), nil
+}
+
+// authorize returns the status a request with a token for a path gets:
+// http.StatusOK when it may be served.
+func (a *Authorizer) authorize(ctx context.Context, token, path, verb string) (int, error) {
+    sum := sha256.Sum256([]byte(token))
+    key := hex.EncodeToString(sum[:]) + " " + verb + " " + path
+    if status, ok := a.cached(key); ok {
+        return status, nil
+    }
+
+    review, err := a.TokenReviews.Create(ctx, &authenticationv1.TokenReview{
+        Spec: authenticationv1.TokenReviewSpec{Token: token},
+    }, metav1.CreateOptions{})
+    if err != nil {
+        return 0, err
+    }
+    if !review.Status.Authenticated {
+        return a.remember(key, http.StatusUnauthorized), nil
+    }
+
+    user := review.Status.User
+    extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+    for k, v := range user.Extra {
+        extra[k] = authorizationv1.ExtraValue(v)
+    }
+    access, err := a.SubjectAccessReviews.Create(ctx, &authorizationv1.SubjectAccessReview{
+        Spec: authorizationv1.SubjectAccessReviewSpec{
+            User:   user.Username,
+            UID:    user.UID,
+            Groups: user.Groups,
+            Extra:  extra,
+            NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+                Path: path,
+                Verb: verb,
+            },
+        },
+    }, metav1.CreateOptions{})
+    if err != nil {
+        return 0, err
+    }
+    if !access.Status.Allowed {
+        return a.remember(key, http.StatusForbidden), nil
+    }
+    return a.remember(key, http.StatusOK), nil
+}
+
+// cached returns the decision cached for a request, if it has not expired.
+func (a *Authorizer) cached(key string) (int, bool) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    d, ok := a.decisions[key]
+    if !ok || time.Now().After(d.expires) {
+        return 0, false
+    }
+    return d.status, true
+}
+
+// remember caches the decision for a request and returns its status.
+// Expired decisions are dropped along the way.
+func (a *Authorizer) remember(key string, status int) int {
+    if a.CacheTTL <= 0 {
+        return status
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    now := time.Now()
+    if a.decisions == nil {
+        a.decisions = make(map[string]decision)
+    }
+    for k, d := range a.decisions {
+        if now.After(d.expires) {
+            delete(a.decisions, k)
+        }
+    }
+    a.decisions[key] = decision{status: status, expires: now.Add(a.CacheTTL)}
+    return status
+}
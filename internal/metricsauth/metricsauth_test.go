@@ -0,0 +1,76 @@
+// internal/metricsauth/metricsauth_test.go
+package metricsauth
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/go-logr/logr"
+    authenticationv1 "k8s.io/api/authentication/v1"
+    authorizationv1 "k8s.io/api/authorization/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/kubernetes/fake"
+    k8stesting "k8s.io/client-go/testing"
+)
+
+func TestFilter(t *testing.T) {
+    clientset := fake.NewSimpleClientset()
+    reviews := 0
+    clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+        reviews++
+        review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+        switch review.Spec.Token {
+        case "prometheus":
+            review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "system:serviceaccount:monitoring:prometheus"}}
+        case "developer":
+            review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "developer"}}
+        }
+        return true, review, nil
+    })
+    clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+        review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+        attributes := review.Spec.NonResourceAttributes
+        review.Status.Allowed = review.Spec.User == "system:serviceaccount:monitoring:prometheus" &&
+            attributes.Path == "/metrics" && attributes.Verb == "get"
+        return true, review, nil
+    })
+
+    a := &Authorizer{
+        TokenReviews:         clientset.AuthenticationV1().TokenReviews(),
+        SubjectAccessReviews: clientset.AuthorizationV1().SubjectAccessReviews(),
+        CacheTTL:             time.Minute,
+    }
+    handler, err := a.Filter(logr.Discard(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+    }))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for token, want := range map[string]int{
+        "":           http.StatusUnauthorized,
+        "invalid":    http.StatusUnauthorized,
+        "developer":  http.StatusForbidden,
+        "prometheus": http.StatusOK,
+    } {
+        r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+        if token != "" {
+            r.Header.Set("Authorization", "Bearer "+token)
+        }
+        w := httptest.NewRecorder()
+        handler.ServeHTTP(w, r)
+        if w.Code != want {
+            t.Errorf("token %q: got status %d, want %d", token, w.Code, want)
+        }
+    }
+
+    // Decisions are cached
+    r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+    r.Header.Set("Authorization", "Bearer prometheus")
+    handler.ServeHTTP(httptest.NewRecorder(), r)
+    if reviews != 3 {
+        t.Errorf("got %d token reviews, want 3", reviews)
+    }
+}
@@ -0,0 +1,131 @@
+// internal/notify/notify.go
+package notify
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+)
+
+// EventType is the kind of sync event a notification is sent for.
+type EventType string
+
+const (
+    // A namespace was provisioned with a class for the first time
+    EventOnboarded EventType = "Onboarded"
+
+    // Managed resources were changed outside the controller
+    EventDrifted EventType = "Drifted"
+
+    // A namespace failed to sync repeatedly
+    EventSyncFailed EventType = "SyncFailed"
+)
+
+// Event describes something that happened to a namespace.
+type Event struct {
+    Type      EventType `json:"type"`
+    Namespace string    `json:"namespace"`
+    Class     string    `json:"class"`
+    Message   string    `json:"message"`
+    Time      time.Time `json:"time"`
+}
+
+// Notifier delivers events to an external system.
+type Notifier interface {
+    Notify(ctx context.Context, event Event) error
+}
+
+// Multi sends events to every notifier, returning all delivery errors.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, event Event) error {
+    var errs []error
+    for _, n := range m {
+        if err := n.Notify(ctx, event); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// Webhook posts events as JSON to a URL.
+type Webhook struct {
+    URL    string
+    Client *http.Client
+}
+
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+    return post(ctx, w.Client, w.URL, "application/json", event)
+}
+
+// Slack posts events to a Slack incoming webhook.
+type Slack struct {
+    URL    string
+    Client *http.Client
+}
+
+func (s *Slack) Notify(ctx context.Context, event Event) error {
+    text := fmt.Sprintf("*%s*: namespace `%s` (class `%s`): %s", event.Type, event.Namespace, event.Class, event.Message)
+    return post(ctx, s.Client, s.URL, "application/json", map[string]string{"text": text})
+}
+
+// CloudEvents posts events to a URL as CloudEvents 1.0 in structured mode.
+type CloudEvents struct {
+    URL string
+
+    // Source identifies the controller instance, e.g. the cluster name
+    Source string
+
+    Client *http.Client
+}
+
+// CloudEvent types are the event type with this prefix, e.g. "io.akuity.namespaceclass.Onboarded"
+const CloudEventTypePrefix = "io.akuity.namespaceclass."
+
+func (c *CloudEvents) Notify(ctx context.Context, event Event) error {
+    id := make([]byte, 16)
+    if _, err := rand.Read(id); err != nil {
+        return err
+    }
+    envelope := map[string]interface{}{
+        "specversion":     "1.0",
+        "id":              hex.EncodeToString(id),
+        "source":          c.Source,
+        "type":            CloudEventTypePrefix + string(event.Type),
+        "subject":         event.Namespace,
+        "time":            event.Time.UTC().Format(time.RFC3339),
+        "datacontenttype": "application/json",
+        "data":            event,
+    }
+    return post(ctx, c.Client, c.URL, "application/cloudevents+json", envelope)
+}
+
+func post(ctx context.Context, client *http.Client, url, contentType string, body interface{}) error {
+    if client == nil {
+        client = http.DefaultClient
+    }
+    data, err := json.Marshal(body)
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", contentType)
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("notification to %s failed: %s", req.URL.Host, resp.Status)
+    }
+    return nil
+}
@@ -0,0 +1,71 @@
+// internal/notify/notify_test.go
+package notify
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestNotifiers(t *testing.T) {
+    var contentTypes []string
+    var bodies []map[string]interface{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body := map[string]interface{}{}
+        if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+            t.Errorf("invalid body: %v", err)
+        }
+        contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+        bodies = append(bodies, body)
+    }))
+    defer server.Close()
+
+    event := Event{
+        Type:      EventSyncFailed,
+        Namespace: "web",
+        Class:     "public",
+        Message:   "quota exceeded",
+        Time:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+    }
+    notifier := Multi{
+        &Slack{URL: server.URL},
+        &Webhook{URL: server.URL},
+        &CloudEvents{URL: server.URL, Source: "prod-eu"},
+    }
+    if err := notifier.Notify(context.Background(), event); err != nil {
+        t.Fatal(err)
+    }
+    if len(bodies) != 3 {
+        t.Fatalf("got %d requests, want 3", len(bodies))
+    }
+
+    if text := bodies[0]["text"]; text != "*SyncFailed*: namespace `web` (class `public`): quota exceeded" {
+        t.Errorf("unexpected Slack text %q", text)
+    }
+    if bodies[1]["namespace"] != "web" || bodies[1]["type"] != "SyncFailed" {
+        t.Errorf("unexpected webhook body %v", bodies[1])
+    }
+    if contentTypes[2] != "application/cloudevents+json" {
+        t.Errorf("unexpected CloudEvents content type %q", contentTypes[2])
+    }
+    ce := bodies[2]
+    if ce["specversion"] != "1.0" || ce["type"] != "io.akuity.namespaceclass.SyncFailed" ||
+        ce["source"] != "prod-eu" || ce["subject"] != "web" || ce["time"] != "2024-05-01T12:00:00Z" || ce["id"] == "" {
+        t.Errorf("unexpected CloudEvent %v", ce)
+    }
+}
+
+func TestNotifyReportsHTTPErrors(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusForbidden)
+    }))
+    defer server.Close()
+
+    err := (&Webhook{URL: server.URL}).Notify(context.Background(), Event{Type: EventDrifted})
+    if err == nil {
+        t.Fatal("expected an error for a rejected notification")
+    }
+}
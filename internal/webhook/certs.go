@@ -0,0 +1,325 @@
+// internal/webhook/certs.go
+package webhook
+
+import (
+    "bytes"
+    "context"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "fmt"
+    "math/big"
+    "os"
+    "path/filepath"
+    "time"
+
+    admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/apimachinery/pkg/util/wait"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+    // Validity of generated certificates
+    DefaultCertValidity = 365 * 24 * time.Hour
+
+    // Certificates are replaced once they expire within this time
+    DefaultCertRefresh = 30 * 24 * time.Hour
+
+    // Key of the CA bundle in the certificate Secret
+    caBundleKey = "ca.crt"
+)
+
+// CertRotator provisions a self-signed serving certificate for the admission
+// webhooks and replaces it well before it expires, so the webhook server
+// never serves with an expired certificate and no external certificate
+// manager is needed. The certificate is kept in a Secret shared by all
+// replicas, written to the webhook server's certificate directory, which the
+// server watches for changes, and its CA is injected into the caBundle of
+// every webhook configuration pointing at the webhook Service.
+//
+// A rotation signs the new certificate with a new CA, and the CA bundle keeps
+// the previous CA until the next rotation, so replicas still serving the
+// previous certificate stay trusted until they pick up the new one.
+type CertRotator struct {
+    // Client reads and writes the Secret and webhook configurations directly
+    // from the API server; the rotator runs before the cache is started
+    Client client.Client
+
+    // Secret stores the certificate
+    Secret types.NamespacedName
+
+    // Service is the Service the webhook configurations call
+    Service types.NamespacedName
+
+    // CertDir is the certificate directory of the webhook server
+    CertDir string
+
+    // Validity is how long generated certificates are valid; zero uses DefaultCertValidity
+    Validity time.Duration
+
+    // Refresh is how long before expiry a certificate is replaced; zero uses DefaultCertRefresh
+    Refresh time.Duration
+
+    // Interval is how often the certificate is checked while running
+    Interval time.Duration
+}
+
+// Start checks the certificate every Interval until the context is cancelled.
+// It implements manager.Runnable.
+func (r *CertRotator) Start(ctx context.Context) error {
+    logger := logf.FromContext(ctx).WithName("cert-rotator")
+    wait.UntilWithContext(ctx, func(ctx context.Context) {
+        if err := r.Ensure(ctx); err != nil {
+            logger.Error(err, "Failed to check webhook serving certificate")
+        }
+    }, r.Interval)
+    return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every
+// replica serves the webhooks, so every replica keeps its files current.
+func (r *CertRotator) NeedLeaderElection() bool {
+    return false
+}
+
+// Ensure makes sure a valid certificate is stored in the Secret, written to
+// the certificate directory and trusted by the webhook configurations.
+func (r *CertRotator) Ensure(ctx context.Context) error {
+    secret, err := r.currentSecret(ctx)
+    if err != nil {
+        return err
+    }
+    if err := r.writeFiles(secret); err != nil {
+        return err
+    }
+    return r.injectCABundle(ctx, secret.Data[caBundleKey])
+}
+
+// currentSecret returns the certificate Secret, generating a certificate if
+// the Secret does not exist or its certificate is invalid or about to expire.
+// If another replica updates the Secret concurrently, its certificate is
+// used instead.
+func (r *CertRotator) currentSecret(ctx context.Context) (*corev1.Secret, error) {
+    logger := logf.FromContext(ctx)
+    secret := &corev1.Secret{}
+    err := r.Client.Get(ctx, r.Secret, secret)
+    if err != nil && !errors.IsNotFound(err) {
+        return nil, err
+    }
+    exists := err == nil
+    if exists && r.valid(secret.Data) {
+        return secret, nil
+    }
+
+    logger.Info("Generating webhook serving certificate", "secret", r.Secret.String())
+    data, err := r.generate(secret.Data[caBundleKey])
+    if err != nil {
+        return nil, err
+    }
+    if !exists {
+        secret = &corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Name: r.Secret.Name, Namespace: r.Secret.Namespace},
+            Type:       corev1.SecretTypeTLS,
+            Data:       data,
+        }
+        err = r.Client.Create(ctx, secret)
+    } else {
+        secret.Data = data
+        err = r.Client.Update(ctx, secret)
+    }
+    if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+        // Another replica rotated the certificate first
+        secret = &corev1.Secret{}
+        err = r.Client.Get(ctx, r.Secret, secret)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return secret, nil
+}
+
+// valid reports whether the certificate in a Secret is for the Service and
+// does not expire within the refresh time.
+func (r *CertRotator) valid(data map[string][]byte) bool {
+    if len(data[corev1.TLSPrivateKeyKey]) == 0 {
+        return false
+    }
+    block, _ := pem.Decode(data[corev1.TLSCertKey])
+    if block == nil {
+        return false
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return false
+    }
+    if cert.VerifyHostname(r.dnsNames()[2]) != nil {
+        return false
+    }
+    return time.Now().Add(r.refresh()).Before(cert.NotAfter)
+}
+
+// generate creates a new CA and a serving certificate signed by it. The CA
+// bundle holds the new CA followed by the first CA of the previous bundle.
+func (r *CertRotator) generate(previousBundle []byte) (map[string][]byte, error) {
+    now := time.Now()
+    notAfter := now.Add(r.validity())
+
+    caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+    caTemplate := &x509.Certificate{
+        SerialNumber:          serialNumber(),
+        Subject:               pkix.Name{CommonName: "namespaceclass-webhook-ca"},
+        NotBefore:             now.Add(-time.Hour),
+        NotAfter:              notAfter,
+        KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+    if err != nil {
+        return nil, err
+    }
+    ca, err := x509.ParseCertificate(caDER)
+    if err != nil {
+        return nil, err
+    }
+
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+    dnsNames := r.dnsNames()
+    template := &x509.Certificate{
+        SerialNumber: serialNumber(),
+        Subject:      pkix.Name{CommonName: dnsNames[2]},
+        DNSNames:     dnsNames,
+        NotBefore:    now.Add(-time.Hour),
+        NotAfter:     notAfter,
+        KeyUsage:     x509.KeyUsageDigitalSignature,
+        ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+    }
+    certDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+    if err != nil {
+        return nil, err
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        return nil, err
+    }
+
+    bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+    if previous, _ := pem.Decode(previousBundle); previous != nil {
+        bundle = append(bundle, pem.EncodeToMemory(previous)...)
+    }
+    return map[string][]byte{
+        caBundleKey:             bundle,
+        corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+        corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+    }, nil
+}
+
+// writeFiles writes the certificate and key to the certificate directory
+// unless they are already current. The key is written first, so the server's
+// file watcher never pairs the new certificate with the old key for long.
+func (r *CertRotator) writeFiles(secret *corev1.Secret) error {
+    if err := os.MkdirAll(r.CertDir, 0o700); err != nil {
+        return err
+    }
+    for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+        path := filepath.Join(r.CertDir, name)
+        if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, secret.Data[name]) {
+            continue
+        }
+        if err := os.WriteFile(path, secret.Data[name], 0o600); err != nil {
+            return fmt.Errorf("failed to write webhook certificate: %w", err)
+        }
+    }
+    return nil
+}
+
+// injectCABundle sets the CA bundle of every webhook that calls the Service.
+func (r *CertRotator) injectCABundle(ctx context.Context, bundle []byte) error {
+    validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+    if err := r.Client.List(ctx, validating); err != nil {
+        return err
+    }
+    for i := range validating.Items {
+        config := &validating.Items[i]
+        changed := false
+        for j := range config.Webhooks {
+            changed = r.setCABundle(&config.Webhooks[j].ClientConfig, bundle) || changed
+        }
+        if changed {
+            if err := r.Client.Update(ctx, config); err != nil {
+                return err
+            }
+        }
+    }
+
+    mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+    if err := r.Client.List(ctx, mutating); err != nil {
+        return err
+    }
+    for i := range mutating.Items {
+        config := &mutating.Items[i]
+        changed := false
+        for j := range config.Webhooks {
+            changed = r.setCABundle(&config.Webhooks[j].ClientConfig, bundle) || changed
+        }
+        if changed {
+            if err := r.Client.Update(ctx, config); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// setCABundle sets the CA bundle of a webhook that calls the Service and
+// reports whether it changed.
+func (r *CertRotator) setCABundle(cc *admissionregistrationv1.WebhookClientConfig, bundle []byte) bool {
+    if cc.Service == nil || cc.Service.Name != r.Service.Name || cc.Service.Namespace != r.Service.Namespace {
+        return false
+    }
+    if bytes.Equal(cc.CABundle, bundle) {
+        return false
+    }
+    cc.CABundle = bundle
+    return true
+}
+
+// dnsNames returns the names the Service is reached by, from the shortest.
+func (r *CertRotator) dnsNames() []string {
+    name := r.Service.Name + "." + r.Service.Namespace
+    return []string{r.Service.Name, name, name + ".svc", name + ".svc.cluster.local"}
+}
+
+func (r *CertRotator) validity() time.Duration {
+    if r.Validity > 0 {
+        return r.Validity
+    }
+    return DefaultCertValidity
+}
+
+func (r *CertRotator) refresh() time.Duration {
+    if r.Refresh > 0 {
+        return r.Refresh
+    }
+    return DefaultCertRefresh
+}
+
+// serialNumber returns a random certificate serial number.
+func serialNumber() *big.Int {
+    serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+    return serial
+}
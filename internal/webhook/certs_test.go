@@ -0,0 +1,119 @@
+// internal/webhook/certs_test.go
+package webhook
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCertRotator(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := admissionregistrationv1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    webhook := func(service string) admissionregistrationv1.ValidatingWebhook {
+        return admissionregistrationv1.ValidatingWebhook{
+            Name: service + ".namespaceclass.akuity.io",
+            ClientConfig: admissionregistrationv1.WebhookClientConfig{
+                Service: &admissionregistrationv1.ServiceReference{Name: service, Namespace: "default"},
+            },
+        }
+    }
+    c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &admissionregistrationv1.ValidatingWebhookConfiguration{
+            ObjectMeta: metav1.ObjectMeta{Name: "namespaceclass-validation"},
+            Webhooks:   []admissionregistrationv1.ValidatingWebhook{webhook("namespaceclass-webhook")},
+        },
+        &admissionregistrationv1.ValidatingWebhookConfiguration{
+            ObjectMeta: metav1.ObjectMeta{Name: "other"},
+            Webhooks:   []admissionregistrationv1.ValidatingWebhook{webhook("other-webhook")},
+        },
+    ).Build()
+
+    ctx := context.Background()
+    rotator := &CertRotator{
+        Client:  c,
+        Secret:  types.NamespacedName{Namespace: "default", Name: "namespaceclass-webhook-cert"},
+        Service: types.NamespacedName{Namespace: "default", Name: "namespaceclass-webhook"},
+        CertDir: t.TempDir(),
+    }
+    if err := rotator.Ensure(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    certPEM, err := os.ReadFile(filepath.Join(rotator.CertDir, corev1.TLSCertKey))
+    if err != nil {
+        t.Fatal(err)
+    }
+    keyPEM, err := os.ReadFile(filepath.Join(rotator.CertDir, corev1.TLSPrivateKeyKey))
+    if err != nil {
+        t.Fatal(err)
+    }
+    pair, err := tls.X509KeyPair(certPEM, keyPEM)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cert, err := x509.ParseCertificate(pair.Certificate[0])
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+    if err := c.Get(ctx, types.NamespacedName{Name: "namespaceclass-validation"}, config); err != nil {
+        t.Fatal(err)
+    }
+    roots := x509.NewCertPool()
+    if !roots.AppendCertsFromPEM(config.Webhooks[0].ClientConfig.CABundle) {
+        t.Fatal("CA bundle not injected")
+    }
+    if _, err := cert.Verify(x509.VerifyOptions{DNSName: "namespaceclass-webhook.default.svc", Roots: roots}); err != nil {
+        t.Errorf("certificate not trusted by the injected CA bundle: %v", err)
+    }
+    if err := c.Get(ctx, types.NamespacedName{Name: "other"}, config); err != nil {
+        t.Fatal(err)
+    }
+    if len(config.Webhooks[0].ClientConfig.CABundle) != 0 {
+        t.Error("CA bundle injected into a webhook of another service")
+    }
+
+    // A valid certificate is kept
+    if err := rotator.Ensure(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if current, _ := os.ReadFile(filepath.Join(rotator.CertDir, corev1.TLSCertKey)); !bytes.Equal(current, certPEM) {
+        t.Error("valid certificate was replaced")
+    }
+
+    // A certificate about to expire is replaced, and the previous CA stays trusted
+    rotator.Refresh = 2 * DefaultCertValidity
+    if err := rotator.Ensure(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if current, _ := os.ReadFile(filepath.Join(rotator.CertDir, corev1.TLSCertKey)); bytes.Equal(current, certPEM) {
+        t.Error("expiring certificate was not replaced")
+    }
+    if err := c.Get(ctx, types.NamespacedName{Name: "namespaceclass-validation"}, config); err != nil {
+        t.Fatal(err)
+    }
+    roots = x509.NewCertPool()
+    roots.AppendCertsFromPEM(config.Webhooks[0].ClientConfig.CABundle)
+    if _, err := cert.Verify(x509.VerifyOptions{DNSName: "namespaceclass-webhook.default.svc", Roots: roots, CurrentTime: time.Now()}); err != nil {
+        t.Errorf("previous certificate no longer trusted after rotation: %v", err)
+    }
+}
@@ -0,0 +1,92 @@
+// internal/webhook/claim.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    logf "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// ClaimPath is the path the NamespaceClaim webhook is served on.
+const ClaimPath = "/validate-namespace-claim"
+
+// ClaimValidator checks NamespaceClaims against the NamespaceClassBindings
+// of their classes and the namespace limit of their Tenant. The namespace of
+// a claim is created by the controller, so the binding webhook would check
+// the controller rather than the user; this webhook checks the user who
+// creates or changes the claim instead.
+type ClaimValidator struct {
+    Client client.Reader
+}
+
+// Handle implements admission.Handler.
+func (v *ClaimValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+    logger := logf.FromContext(ctx)
+
+    claim := &v1.NamespaceClaim{}
+    if err := json.Unmarshal(req.Object.Raw, claim); err != nil {
+        return admission.Errored(http.StatusBadRequest, err)
+    }
+    old := &corev1.Namespace{}
+    if req.Operation == admissionv1.Update {
+        oldClaim := &v1.NamespaceClaim{}
+        if err := json.Unmarshal(req.OldObject.Raw, oldClaim); err != nil {
+            return admission.Errored(http.StatusBadRequest, err)
+        }
+        old = controller.ClaimNamespace(oldClaim)
+    }
+    ns := controller.ClaimNamespace(claim)
+    bindings := &BindingValidator{Client: v.Client}
+
+    reason, err := bindings.tenantFull(ctx, old, ns)
+    if err != nil {
+        logger.Error(err, "Failed to check the namespace limit of tenant", "tenant", ns.Labels[controller.TenantLabel])
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    if reason != "" {
+        return admission.Denied(reason)
+    }
+
+    for _, name := range newClasses(old, ns) {
+        allowed, err := bindings.mayUse(ctx, req.UserInfo, ns.Name, name)
+        if err != nil {
+            logger.Error(err, "Failed to list NamespaceClassBindings", "class", name)
+            return admission.Errored(http.StatusInternalServerError, err)
+        }
+        if !allowed {
+            return admission.Denied(fmt.Sprintf("%s may not claim namespace %s with NamespaceClass %s: no NamespaceClassBinding of the class allows it",
+                req.UserInfo.Username, ns.Name, name))
+        }
+    }
+    return admission.Allowed("")
+}
+
+// claimClasses returns the classes of the NamespaceClaim a namespace is
+// created for, which the claim webhook already checked against the user who
+// made the claim, or nil if the namespace does not belong to a claim.
+func (v *BindingValidator) claimClasses(ctx context.Context, ns *corev1.Namespace) ([]string, error) {
+    owner := metav1.GetControllerOf(ns)
+    if owner == nil || owner.APIVersion != v1.GroupVersion.String() || owner.Kind != "NamespaceClaim" || owner.Name != ns.Name {
+        return nil, nil
+    }
+    claim := &v1.NamespaceClaim{}
+    if err := v.Client.Get(ctx, types.NamespacedName{Name: ns.Name}, claim); err != nil {
+        return nil, client.IgnoreNotFound(err)
+    }
+    if claim.UID != owner.UID {
+        return nil, nil
+    }
+    return append([]string{claim.Spec.ClassName}, claim.Spec.AddonClasses...), nil
+}
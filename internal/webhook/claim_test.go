@@ -0,0 +1,139 @@
+// internal/webhook/claim_test.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    authenticationv1 "k8s.io/api/authentication/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/utils/ptr"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestClaimBindings(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    validator := &ClaimValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClassBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: "high-quota-platform"},
+            Spec:       v1.NamespaceClassBindingSpec{ClassName: "high-quota", Users: []string{"alice"}},
+        },
+        &v1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}, Spec: v1.TenantSpec{MaxNamespaces: ptr.To[int32](1)}},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-dev", Labels: map[string]string{controller.TenantLabel: "team-a"}}},
+    ).Build()}
+
+    claim := func(class string, addons []string, tenant string) runtime.RawExtension {
+        c := &v1.NamespaceClaim{
+            ObjectMeta: metav1.ObjectMeta{Name: "preview"},
+            Spec:       v1.NamespaceClaimSpec{ClassName: class, AddonClasses: addons},
+        }
+        if tenant != "" {
+            c.Spec.Labels = map[string]string{controller.TenantLabel: tenant}
+        }
+        raw, err := json.Marshal(c)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return runtime.RawExtension{Raw: raw}
+    }
+
+    for _, tc := range []struct {
+        name    string
+        user    string
+        old     *runtime.RawExtension
+        new     runtime.RawExtension
+        allowed bool
+    }{
+        {"unrestricted class", "bob", nil, claim("standard", nil, ""), true},
+        {"bound user", "alice", nil, claim("high-quota", nil, ""), true},
+        {"unbound user", "bob", nil, claim("high-quota", nil, ""), false},
+        {"unbound add-on", "bob", nil, claim("standard", []string{"high-quota"}, ""), false},
+        {"unbound change", "bob", ptr.To(claim("standard", nil, "")), claim("high-quota", nil, ""), false},
+        {"unchanged class", "bob", ptr.To(claim("high-quota", nil, "")), claim("high-quota", nil, ""), true},
+        {"full tenant", "alice", nil, claim("standard", nil, "team-a"), false},
+    } {
+        req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Operation: admissionv1.Create,
+            UserInfo:  authenticationv1.UserInfo{Username: tc.user},
+            Object:    tc.new,
+        }}
+        if tc.old != nil {
+            req.Operation = admissionv1.Update
+            req.OldObject = *tc.old
+        }
+        resp := validator.Handle(context.Background(), req)
+        if resp.Allowed != tc.allowed {
+            t.Errorf("%s: got allowed=%v, want %v (%v)", tc.name, resp.Allowed, tc.allowed, resp.Result)
+        }
+    }
+}
+
+func TestClaimNamespaceSkipsBindings(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    claim := &v1.NamespaceClaim{
+        ObjectMeta: metav1.ObjectMeta{Name: "preview", UID: types.UID("claim-uid")},
+        Spec:       v1.NamespaceClaimSpec{ClassName: "high-quota"},
+    }
+    validator := &BindingValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        claim,
+        &v1.NamespaceClassBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: "high-quota-platform"},
+            Spec:       v1.NamespaceClassBindingSpec{ClassName: "high-quota", Users: []string{"alice"}},
+        },
+    ).Build()}
+
+    namespace := func(uid types.UID) runtime.RawExtension {
+        ns := controller.ClaimNamespace(claim)
+        ns.OwnerReferences = []metav1.OwnerReference{{
+            APIVersion: v1.GroupVersion.String(),
+            Kind:       "NamespaceClaim",
+            Name:       claim.Name,
+            UID:        uid,
+            Controller: ptr.To(true),
+        }}
+        raw, err := json.Marshal(ns)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return runtime.RawExtension{Raw: raw}
+    }
+
+    for _, tc := range []struct {
+        name    string
+        uid     types.UID
+        allowed bool
+    }{
+        {"created for the claim", claim.UID, true},
+        {"owned by another claim", types.UID("other-uid"), false},
+    } {
+        resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Operation: admissionv1.Create,
+            UserInfo:  authenticationv1.UserInfo{Username: "system:serviceaccount:default:namespaceclass-controller"},
+            Object:    namespace(tc.uid),
+        }})
+        if resp.Allowed != tc.allowed {
+            t.Errorf("%s: got allowed=%v, want %v (%v)", tc.name, resp.Allowed, tc.allowed, resp.Result)
+        }
+    }
+}
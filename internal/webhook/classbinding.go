@@ -0,0 +1,67 @@
+// internal/webhook/classbinding.go
+package webhook
+
+import (
+    "context"
+    "path"
+    "slices"
+
+    authenticationv1 "k8s.io/api/authentication/v1"
+    corev1 "k8s.io/api/core/v1"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// newClasses returns the classes a namespace uses, as its label or as add-on
+// classes, that the namespace did not use before the request.
+func newClasses(old, ns *corev1.Namespace) []string {
+    var classes []string
+    for _, name := range controller.NamespaceClasses(ns) {
+        if !slices.Contains(controller.NamespaceClasses(old), name) {
+            classes = append(classes, name)
+        }
+    }
+    return classes
+}
+
+// mayUse reports whether a user may make a namespace use a class. Classes
+// without NamespaceClassBindings may be used by anyone; otherwise one of
+// the bindings must name the user, one of the user's groups, or the
+// namespace.
+func (v *BindingValidator) mayUse(ctx context.Context, user authenticationv1.UserInfo, namespace, className string) (bool, error) {
+    bindings := &v1.NamespaceClassBindingList{}
+    if err := v.Client.List(ctx, bindings); err != nil {
+        return false, err
+    }
+    restricted := false
+    for _, binding := range bindings.Items {
+        if binding.Spec.ClassName != className {
+            continue
+        }
+        restricted = true
+        if bindingAllows(binding.Spec, user, namespace) {
+            return true, nil
+        }
+    }
+    return !restricted, nil
+}
+
+// bindingAllows reports whether a binding names a user, one of the user's
+// groups, or a namespace.
+func bindingAllows(binding v1.NamespaceClassBindingSpec, user authenticationv1.UserInfo, namespace string) bool {
+    if slices.Contains(binding.Users, user.Username) {
+        return true
+    }
+    for _, group := range user.Groups {
+        if slices.Contains(binding.Groups, group) {
+            return true
+        }
+    }
+    for _, pattern := range binding.Namespaces {
+        if matched, _ := path.Match(pattern, namespace); matched {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,86 @@
+// internal/webhook/classbinding_test.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    authenticationv1 "k8s.io/api/authentication/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestClassBindings(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    validator := &BindingValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "high-quota"}},
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+        &v1.NamespaceClassBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: "high-quota-platform"},
+            Spec: v1.NamespaceClassBindingSpec{
+                ClassName:  "high-quota",
+                Users:      []string{"alice"},
+                Groups:     []string{"platform"},
+                Namespaces: []string{"batch-*"},
+            },
+        },
+    ).Build()}
+
+    namespace := func(name, class, addons string) runtime.RawExtension {
+        ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+        if class != "" {
+            ns.Labels = map[string]string{controller.LabelKey: class}
+        }
+        if addons != "" {
+            ns.Annotations = map[string]string{controller.ClassesAnnotation: addons}
+        }
+        raw, err := json.Marshal(ns)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return runtime.RawExtension{Raw: raw}
+    }
+
+    for _, tc := range []struct {
+        name      string
+        user      authenticationv1.UserInfo
+        namespace string
+        old, new  string
+        addons    string
+        allowed   bool
+    }{
+        {"unrestricted class", authenticationv1.UserInfo{Username: "bob"}, "team", "", "standard", "", true},
+        {"bound user", authenticationv1.UserInfo{Username: "alice"}, "team", "", "high-quota", "", true},
+        {"bound group", authenticationv1.UserInfo{Username: "carol", Groups: []string{"platform"}}, "team", "", "high-quota", "", true},
+        {"bound namespace", authenticationv1.UserInfo{Username: "bob"}, "batch-etl", "", "high-quota", "", true},
+        {"unbound user", authenticationv1.UserInfo{Username: "bob"}, "team", "", "high-quota", "", false},
+        {"unbound relabel", authenticationv1.UserInfo{Username: "bob"}, "team", "standard", "high-quota", "", false},
+        {"unbound add-on", authenticationv1.UserInfo{Username: "bob"}, "team", "standard", "standard", "high-quota", false},
+        {"existing member", authenticationv1.UserInfo{Username: "bob"}, "team", "high-quota", "high-quota", "", true},
+    } {
+        req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Operation: admissionv1.Create,
+            UserInfo:  tc.user,
+            Object:    namespace(tc.namespace, tc.new, tc.addons),
+        }}
+        if tc.old != "" {
+            req.Operation = admissionv1.Update
+            req.OldObject = namespace(tc.namespace, tc.old, "")
+        }
+        resp := validator.Handle(context.Background(), req)
+        if resp.Allowed != tc.allowed {
+            t.Errorf("%s: got allowed=%v, want %v (%v)", tc.name, resp.Allowed, tc.allowed, resp.Result)
+        }
+    }
+}
@@ -0,0 +1,174 @@
+// internal/webhook/defaulter.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+// PresetKey is the key of a shorthand class resource that expands into the
+// resources of a built-in preset, e.g. {"preset": "default-deny-ingress"}.
+const PresetKey = "preset"
+
+// knownAPIVersions are filled in for resources that only declare their kind.
+var knownAPIVersions = map[string]string{
+    "ConfigMap":           "v1",
+    "LimitRange":          "v1",
+    "ResourceQuota":       "v1",
+    "Secret":              "v1",
+    "Service":             "v1",
+    "ServiceAccount":      "v1",
+    "Deployment":          "apps/v1",
+    "StatefulSet":         "apps/v1",
+    "CronJob":             "batch/v1",
+    "Job":                 "batch/v1",
+    "NetworkPolicy":       "networking.k8s.io/v1",
+    "PodDisruptionBudget": "policy/v1",
+    "Role":                "rbac.authorization.k8s.io/v1",
+    "RoleBinding":         "rbac.authorization.k8s.io/v1",
+}
+
+// kindOrder sorts resources so that dependencies are applied before the
+// resources using them. Kinds not listed are applied last.
+var kindOrder = []string{
+    "ResourceQuota",
+    "LimitRange",
+    "NetworkPolicy",
+    "ServiceAccount",
+    "Secret",
+    "ConfigMap",
+    "Role",
+    "RoleBinding",
+    "Service",
+    "PodDisruptionBudget",
+    "Deployment",
+    "StatefulSet",
+    "Job",
+    "CronJob",
+}
+
+// presets are the built-in resource sets that a preset entry expands into.
+var presets = map[string][]map[string]interface{}{
+    "default-deny-ingress": {{
+        "apiVersion": "networking.k8s.io/v1",
+        "kind":       "NetworkPolicy",
+        "metadata":   map[string]interface{}{"name": "default-deny-ingress"},
+        "spec": map[string]interface{}{
+            "podSelector": map[string]interface{}{},
+            "policyTypes": []interface{}{"Ingress"},
+        },
+    }},
+    "default-deny-egress": {{
+        "apiVersion": "networking.k8s.io/v1",
+        "kind":       "NetworkPolicy",
+        "metadata":   map[string]interface{}{"name": "default-deny-egress"},
+        "spec": map[string]interface{}{
+            "podSelector": map[string]interface{}{},
+            "policyTypes": []interface{}{"Egress"},
+        },
+    }},
+    "allow-same-namespace": {{
+        "apiVersion": "networking.k8s.io/v1",
+        "kind":       "NetworkPolicy",
+        "metadata":   map[string]interface{}{"name": "allow-same-namespace"},
+        "spec": map[string]interface{}{
+            "podSelector": map[string]interface{}{},
+            "ingress": []interface{}{map[string]interface{}{
+                "from": []interface{}{map[string]interface{}{"podSelector": map[string]interface{}{}}},
+            }},
+        },
+    }},
+}
+
+// +kubebuilder:webhook:path=/mutate-namespaceclass-akuity-io-v1-namespaceclass,mutating=true,failurePolicy=fail,sideEffects=None,groups=namespaceclass.akuity.io,resources=namespaceclasses,verbs=create;update,versions=v1,name=mnamespaceclass.akuity.io,admissionReviewVersions=v1
+
+// ClassDefaulter normalizes NamespaceClass specs so the stored spec is
+// canonical: presets are expanded, apiVersions of well-known kinds are filled
+// in, and resources are sorted by kind and name.
+type ClassDefaulter struct{}
+
+// SetupClassDefaulterWithManager registers the NamespaceClass defaulting webhook.
+func SetupClassDefaulterWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&v1.NamespaceClass{}).
+        WithDefaulter(&ClassDefaulter{}).
+        Complete()
+}
+
+// Default implements admission.CustomDefaulter.
+func (d *ClassDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+    nsc, ok := obj.(*v1.NamespaceClass)
+    if !ok {
+        return fmt.Errorf("expected a NamespaceClass but got %T", obj)
+    }
+    
+    var resources []map[string]interface{}
+    for i, raw := range nsc.Spec.Resources {
+        var res map[string]interface{}
+        if err := json.Unmarshal(raw.Raw, &res); err != nil {
+            return fmt.Errorf("resource %d is not a valid object: %w", i, err)
+        }
+        
+        // Expand presets into concrete resources
+        if name, ok := res[PresetKey]; ok {
+            expanded, ok := presets[fmt.Sprint(name)]
+            if !ok {
+                return fmt.Errorf("resource %d: unknown preset %q", i, name)
+            }
+            for _, preset := range expanded {
+                resources = append(resources, runtime.DeepCopyJSON(preset))
+            }
+            continue
+        }
+        
+        // Fill in the apiVersion of well-known kinds
+        if _, ok := res["apiVersion"]; !ok {
+            if apiVersion, ok := knownAPIVersions[fmt.Sprint(res["kind"])]; ok {
+                res["apiVersion"] = apiVersion
+            }
+        }
+        resources = append(resources, res)
+    }
+    
+    sort.SliceStable(resources, func(i, j int) bool {
+        ki, kj := kindRank(resources[i]), kindRank(resources[j])
+        if ki != kj {
+            return ki < kj
+        }
+        return resourceName(resources[i]) < resourceName(resources[j])
+    })
+    
+    nsc.Spec.Resources = make([]runtime.RawExtension, 0, len(resources))
+    for _, res := range resources {
+        data, err := json.Marshal(res)
+        if err != nil {
+            return err
+        }
+        nsc.Spec.Resources = append(nsc.Spec.Resources, runtime.RawExtension{Raw: data})
+    }
+    return nil
+}
+
+func kindRank(res map[string]interface{}) int {
+    kind := fmt.Sprint(res["kind"])
+    for i, k := range kindOrder {
+        if k == kind {
+            return i
+        }
+    }
+    return len(kindOrder)
+}
+
+func resourceName(res map[string]interface{}) string {
+    if metadata, ok := res["metadata"].(map[string]interface{}); ok {
+        return fmt.Sprint(metadata["name"])
+    }
+    return ""
+}
@@ -0,0 +1,48 @@
+// internal/webhook/defaulter_test.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    "k8s.io/apimachinery/pkg/runtime"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+)
+
+func TestClassDefaulter(t *testing.T) {
+    nsc := &v1.NamespaceClass{Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+        {Raw: []byte(`{"kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+        {Raw: []byte(`{"preset":"default-deny-egress"}`)},
+        {Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"ci"}}`)},
+    }}}
+    if err := (&ClassDefaulter{}).Default(context.Background(), nsc); err != nil {
+        t.Fatal(err)
+    }
+
+    var got []string
+    for _, raw := range nsc.Spec.Resources {
+        var res map[string]interface{}
+        if err := json.Unmarshal(raw.Raw, &res); err != nil {
+            t.Fatal(err)
+        }
+        got = append(got, res["apiVersion"].(string)+" "+res["kind"].(string))
+    }
+    want := []string{"networking.k8s.io/v1 NetworkPolicy", "v1 ServiceAccount", "v1 ConfigMap"}
+    if len(got) != len(want) {
+        t.Fatalf("got resources %v, want %v", got, want)
+    }
+    for i := range want {
+        if got[i] != want[i] {
+            t.Errorf("resource %d = %q, want %q", i, got[i], want[i])
+        }
+    }
+
+    unknown := &v1.NamespaceClass{Spec: v1.NamespaceClassSpec{Resources: []runtime.RawExtension{
+        {Raw: []byte(`{"preset":"does-not-exist"}`)},
+    }}}
+    if err := (&ClassDefaulter{}).Default(context.Background(), unknown); err == nil {
+        t.Error("expected an error for an unknown preset")
+    }
+}
@@ -0,0 +1,103 @@
+// internal/webhook/deprecation.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "slices"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    logf "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// BindingPath is the path the namespace binding webhook is served on.
+const BindingPath = "/validate-namespace-binding"
+
+// BindingValidator rejects namespaces that newly bind to a deprecated
+// class, by being created with its label or relabeled to it. Namespaces
+// that already use the class are allowed with a warning. It also rejects
+// namespaces that newly use a class, as their label or an add-on class, if
+// no NamespaceClassBinding of the class allows the requesting user or the
+// namespace, and namespaces that would take a Tenant beyond its namespace
+// limit. The classes of a NamespaceClaim are checked by ClaimValidator
+// instead, when the claim is made.
+type BindingValidator struct {
+    Client client.Reader
+}
+
+// Handle implements admission.Handler.
+func (v *BindingValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+    logger := logf.FromContext(ctx)
+
+    ns := &corev1.Namespace{}
+    if err := json.Unmarshal(req.Object.Raw, ns); err != nil {
+        return admission.Errored(http.StatusBadRequest, err)
+    }
+    old := &corev1.Namespace{}
+    if req.Operation == admissionv1.Update {
+        if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+            return admission.Errored(http.StatusBadRequest, err)
+        }
+    }
+
+    reason, err := v.tenantFull(ctx, old, ns)
+    if err != nil {
+        logger.Error(err, "Failed to check the namespace limit of tenant", "tenant", ns.Labels[controller.TenantLabel])
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    if reason != "" {
+        return admission.Denied(reason)
+    }
+
+    claimed, err := v.claimClasses(ctx, ns)
+    if err != nil {
+        logger.Error(err, "Failed to get NamespaceClaim", "claim", ns.Name)
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    for _, name := range newClasses(old, ns) {
+        if slices.Contains(claimed, name) {
+            continue
+        }
+        allowed, err := v.mayUse(ctx, req.UserInfo, ns.Name, name)
+        if err != nil {
+            logger.Error(err, "Failed to list NamespaceClassBindings", "class", name)
+            return admission.Errored(http.StatusInternalServerError, err)
+        }
+        if !allowed {
+            return admission.Denied(fmt.Sprintf("%s may not use NamespaceClass %s in namespace %s: no NamespaceClassBinding of the class allows it",
+                req.UserInfo.Username, name, ns.Name))
+        }
+    }
+
+    className := ns.Labels[controller.LabelKey]
+    if className == "" {
+        return admission.Allowed("")
+    }
+
+    nsc := &v1.NamespaceClass{}
+    if err := v.Client.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        if errors.IsNotFound(err) {
+            return admission.Allowed("")
+        }
+        logger.Error(err, "Failed to get NamespaceClass", "class", className)
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    if !nsc.Spec.Deprecated {
+        return admission.Allowed("")
+    }
+
+    if req.Operation == admissionv1.Update && old.Labels[controller.LabelKey] == className {
+        return admission.Allowed("").WithWarnings(controller.DeprecationMessage(nsc))
+    }
+    return admission.Denied(controller.DeprecationMessage(nsc))
+}
@@ -0,0 +1,77 @@
+// internal/webhook/deprecation_test.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestDeprecatedClassBinding(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    validator := &BindingValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+            Spec:       v1.NamespaceClassSpec{Deprecated: true, Replacement: "restricted"},
+        },
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "restricted"}},
+    ).Build()}
+
+    namespace := func(class string) runtime.RawExtension {
+        ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}}
+        if class != "" {
+            ns.Labels = map[string]string{controller.LabelKey: class}
+        }
+        raw, err := json.Marshal(ns)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return runtime.RawExtension{Raw: raw}
+    }
+
+    for _, tc := range []struct {
+        name      string
+        operation admissionv1.Operation
+        old, new  string
+        allowed   bool
+        warnings  int
+    }{
+        {"create with deprecated class", admissionv1.Create, "", "legacy", false, 0},
+        {"create with current class", admissionv1.Create, "", "restricted", true, 0},
+        {"create without class", admissionv1.Create, "", "", true, 0},
+        {"relabel to deprecated class", admissionv1.Update, "restricted", "legacy", false, 0},
+        {"update of existing member", admissionv1.Update, "legacy", "legacy", true, 1},
+        {"unknown class", admissionv1.Create, "", "missing", true, 0},
+    } {
+        req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Operation: tc.operation,
+            Object:    namespace(tc.new),
+        }}
+        if tc.operation == admissionv1.Update {
+            req.OldObject = namespace(tc.old)
+        }
+        resp := validator.Handle(context.Background(), req)
+        if resp.Allowed != tc.allowed {
+            t.Errorf("%s: got allowed=%v, want %v", tc.name, resp.Allowed, tc.allowed)
+        }
+        if len(resp.Warnings) != tc.warnings {
+            t.Errorf("%s: got %d warnings, want %d", tc.name, len(resp.Warnings), tc.warnings)
+        }
+        if !tc.allowed && resp.Result.Message != "NamespaceClass legacy is deprecated; use restricted instead" {
+            t.Errorf("%s: unexpected message %q", tc.name, resp.Result.Message)
+        }
+    }
+}
@@ -0,0 +1,52 @@
+// internal/webhook/tenant.go
+package webhook
+
+import (
+    "context"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// tenantFull describes why a namespace may not join its tenant, because the
+// tenant already has as many namespaces as it may have, or returns an empty
+// string if it may. Namespaces already in the tenant, and tenants that do
+// not exist or have no limit, are never refused.
+func (v *BindingValidator) tenantFull(ctx context.Context, old, ns *corev1.Namespace) (string, error) {
+    tenantName := ns.Labels[controller.TenantLabel]
+    if tenantName == "" || old.Labels[controller.TenantLabel] == tenantName {
+        return "", nil
+    }
+    tenant := &v1.Tenant{}
+    if err := v.Client.Get(ctx, types.NamespacedName{Name: tenantName}, tenant); err != nil {
+        if errors.IsNotFound(err) {
+            return "", nil
+        }
+        return "", err
+    }
+    if tenant.Spec.MaxNamespaces == nil {
+        return "", nil
+    }
+
+    var nsList corev1.NamespaceList
+    if err := v.Client.List(ctx, &nsList, client.MatchingLabels{controller.TenantLabel: tenantName}); err != nil {
+        return "", err
+    }
+    count := 0
+    for _, member := range nsList.Items {
+        if member.Name != ns.Name && member.DeletionTimestamp.IsZero() {
+            count++
+        }
+    }
+    if count < int(*tenant.Spec.MaxNamespaces) {
+        return "", nil
+    }
+    return fmt.Sprintf("namespace %s may not join tenant %s: the tenant already has %d namespaces, the most it may have",
+        ns.Name, tenantName, count), nil
+}
@@ -0,0 +1,137 @@
+// internal/webhook/tenant_test.go
+package webhook
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "os"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/labels"
+    "k8s.io/apimachinery/pkg/runtime"
+    utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+    "k8s.io/utils/ptr"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestTenantNamespaceLimit(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    member := func(name, tenant string) *corev1.Namespace {
+        ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+        if tenant != "" {
+            ns.Labels = map[string]string{controller.TenantLabel: tenant}
+        }
+        return ns
+    }
+    validator := &BindingValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &v1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}, Spec: v1.TenantSpec{MaxNamespaces: ptr.To[int32](2)}},
+        &v1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+        member("team-a-dev", "team-a"),
+        member("team-a-prod", "team-a"),
+    ).Build()}
+
+    raw := func(ns *corev1.Namespace) runtime.RawExtension {
+        data, err := json.Marshal(ns)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return runtime.RawExtension{Raw: data}
+    }
+
+    for _, tc := range []struct {
+        name      string
+        namespace string
+        old, new  string
+        allowed   bool
+    }{
+        {"tenant at its limit", "team-a-test", "", "team-a", false},
+        {"relabeled into a full tenant", "team-a-test", "team-b", "team-a", false},
+        {"existing member", "team-a-dev", "team-a", "team-a", true},
+        {"tenant without limit", "team-b-test", "", "team-b", true},
+        {"unknown tenant", "team-c-test", "", "team-c", true},
+        {"no tenant", "scratch", "", "", true},
+    } {
+        req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Operation: admissionv1.Create,
+            Object:    raw(member(tc.namespace, tc.new)),
+        }}
+        if tc.old != "" {
+            req.Operation = admissionv1.Update
+            req.OldObject = raw(member(tc.namespace, tc.old))
+        }
+        resp := validator.Handle(context.Background(), req)
+        if resp.Allowed != tc.allowed {
+            t.Errorf("%s: got allowed=%v, want %v (%v)", tc.name, resp.Allowed, tc.allowed, resp.Result)
+        }
+    }
+}
+
+func TestBindingWebhookSelectsTenantNamespaces(t *testing.T) {
+    f, err := os.Open("../../config/webhook/manifests.yaml")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+
+    var selectors []labels.Selector
+    decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+    for {
+        config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+        if err := decoder.Decode(config); errors.Is(err, io.EOF) {
+            break
+        } else if err != nil {
+            t.Fatal(err)
+        }
+        if config.Kind != "ValidatingWebhookConfiguration" {
+            continue
+        }
+        for _, webhook := range config.Webhooks {
+            if webhook.ClientConfig.Service == nil || webhook.ClientConfig.Service.Path == nil ||
+                *webhook.ClientConfig.Service.Path != BindingPath {
+                continue
+            }
+            selector := labels.Everything()
+            if webhook.ObjectSelector != nil {
+                if selector, err = metav1.LabelSelectorAsSelector(webhook.ObjectSelector); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            selectors = append(selectors, selector)
+        }
+    }
+
+    for _, tc := range []struct {
+        name   string
+        labels map[string]string
+    }{
+        {"class", map[string]string{controller.LabelKey: "web"}},
+        {"tenant", map[string]string{controller.TenantLabel: "team-a"}},
+        {"class and tenant", map[string]string{controller.LabelKey: "web", controller.TenantLabel: "team-a"}},
+    } {
+        matches := 0
+        for _, selector := range selectors {
+            if selector.Matches(labels.Set(tc.labels)) {
+                matches++
+            }
+        }
+        if matches != 1 {
+            t.Errorf("%s: namespace is sent to the binding webhook %d times, want once", tc.name, matches)
+        }
+    }
+}
@@ -0,0 +1,63 @@
+// internal/webhook/validator.go
+package webhook
+
+import (
+    "context"
+    "fmt"
+
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-namespaceclass-akuity-io-v1-namespaceclass,mutating=false,failurePolicy=fail,sideEffects=None,groups=namespaceclass.akuity.io,resources=namespaceclasses,verbs=create;update,versions=v1,name=vnamespaceclass.akuity.io,admissionReviewVersions=v1
+
+// ClassValidator rejects NamespaceClasses the controller could not apply,
+// using the same checks as nsclassctl validate. It runs after the
+// defaulter, so presets are already expanded. Classes that create kinds the
+// controller's kind policy forbids, or render more resources than its
+// resource limit allows, are rejected too.
+type ClassValidator struct {
+    Kinds *controller.KindPolicy
+    Limit *controller.ResourceLimit
+}
+
+// SetupClassValidatorWithManager registers the NamespaceClass validating webhook.
+func SetupClassValidatorWithManager(mgr ctrl.Manager, kinds *controller.KindPolicy, limit *controller.ResourceLimit) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&v1.NamespaceClass{}).
+        WithValidator(&ClassValidator{Kinds: kinds, Limit: limit}).
+        Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *ClassValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+    return nil, v.validateClass(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *ClassValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+    return nil, v.validateClass(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *ClassValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}
+
+func (v *ClassValidator) validateClass(obj runtime.Object) error {
+    nsc, ok := obj.(*v1.NamespaceClass)
+    if !ok {
+        return fmt.Errorf("expected a NamespaceClass but got %T", obj)
+    }
+    errs := append(controller.ValidateClass(nsc), v.Kinds.ValidateClass(nsc)...)
+    errs = append(errs, v.Limit.ValidateClass(nsc)...)
+    if len(errs) > 0 {
+        return errors.NewInvalid(v1.GroupVersion.WithKind("NamespaceClass").GroupKind(), nsc.Name, errs)
+    }
+    return nil
+}
@@ -0,0 +1,98 @@
+// internal/webhook/validator_test.go
+package webhook
+
+import (
+    "context"
+    "testing"
+
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestClassValidator(t *testing.T) {
+    small, large := resource.MustParse("100m"), resource.MustParse("2")
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+        Spec: v1.NamespaceClassSpec{Limits: &v1.ClassLimits{
+            DefaultRequest: &v1.ResourceValues{CPU: &small},
+            Max:            &v1.ResourceValues{CPU: &large},
+        }},
+    }
+    if _, err := (&ClassValidator{}).ValidateCreate(context.Background(), nsc); err != nil {
+        t.Fatalf("valid class rejected: %v", err)
+    }
+
+    // A default request above the max cannot be admitted by the API server
+    nsc.Spec.Limits.DefaultRequest, nsc.Spec.Limits.Max = nsc.Spec.Limits.Max, nsc.Spec.Limits.DefaultRequest
+    _, err := (&ClassValidator{}).ValidateUpdate(context.Background(), nsc, nsc)
+    if !errors.IsInvalid(err) {
+        t.Fatalf("got %v, want an Invalid error", err)
+    }
+    causes := err.(*errors.StatusError).ErrStatus.Details.Causes
+    if len(causes) != 1 || causes[0].Field != "spec.limits.max.cpu" {
+        t.Errorf("unexpected causes %v", causes)
+    }
+}
+
+func TestClassValidatorKinds(t *testing.T) {
+    validator := &ClassValidator{Kinds: &controller.KindPolicy{
+        Denied: []string{"ClusterRoleBinding.rbac.authorization.k8s.io", "*.admissionregistration.k8s.io", "RoleBinding"},
+    }}
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+        Spec: v1.NamespaceClassSpec{
+            Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}}`)},
+                {Raw: []byte(`{"apiVersion": "admissionregistration.k8s.io/v1", "kind": "ValidatingWebhookConfiguration", "metadata": {"name": "hook"}}`)},
+            },
+            RBAC: []v1.RoleGrant{{ClusterRole: "view", Groups: []string{"team"}}},
+        },
+    }
+    _, err := validator.ValidateCreate(context.Background(), nsc)
+    if !errors.IsInvalid(err) {
+        t.Fatalf("got %v, want an Invalid error", err)
+    }
+    var fields []string
+    for _, cause := range err.(*errors.StatusError).ErrStatus.Details.Causes {
+        fields = append(fields, cause.Field)
+    }
+    if len(fields) != 2 || fields[0] != "spec.resources[1]" || fields[1] != "spec" {
+        t.Errorf("unexpected causes %v", fields)
+    }
+
+    nsc.Spec.Resources, nsc.Spec.RBAC = nsc.Spec.Resources[:1], nil
+    if _, err := validator.ValidateUpdate(context.Background(), nsc, nsc); err != nil {
+        t.Errorf("allowed class rejected: %v", err)
+    }
+    if _, err := (&ClassValidator{Kinds: &controller.KindPolicy{Allowed: []string{"Secret"}}}).ValidateCreate(context.Background(), nsc); err == nil {
+        t.Error("class with a kind outside the allowlist admitted")
+    }
+}
+
+func TestClassValidatorResourceLimit(t *testing.T) {
+    validator := &ClassValidator{Limit: &controller.ResourceLimit{MaxPerClass: 2}}
+    nsc := &v1.NamespaceClass{
+        ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+        Spec: v1.NamespaceClassSpec{
+            Resources: []runtime.RawExtension{
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}}`)},
+                {Raw: []byte(`{"apiVersion": "v1", "kind": "ServiceAccount", "metadata": {"name": "deployer"}}`)},
+            },
+            RBAC: []v1.RoleGrant{{ClusterRole: "view", Groups: []string{"team"}}},
+        },
+    }
+    _, err := validator.ValidateCreate(context.Background(), nsc)
+    if !errors.IsInvalid(err) {
+        t.Fatalf("got %v, want an Invalid error", err)
+    }
+
+    nsc.Spec.RBAC = nil
+    if _, err := validator.ValidateUpdate(context.Background(), nsc, nsc); err != nil {
+        t.Errorf("class within the limit rejected: %v", err)
+    }
+}
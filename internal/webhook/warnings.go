@@ -0,0 +1,58 @@
+// internal/webhook/warnings.go
+package webhook
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    logf "sigs.k8s.io/controller-runtime/pkg/log"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+// WarningsPath is the path the workload warnings webhook is served on.
+const WarningsPath = "/warn-workloads"
+
+// WorkloadWarner returns the admission warnings declared by a namespace's
+// class when workloads are created in it. It never rejects a request, not
+// even when the namespace or class cannot be read: warnings are
+// informational, so an API server hiccup must not block workloads.
+type WorkloadWarner struct {
+    Client client.Reader
+}
+
+// Handle implements admission.Handler.
+func (w *WorkloadWarner) Handle(ctx context.Context, req admission.Request) admission.Response {
+    logger := logf.FromContext(ctx)
+    
+    if req.Namespace == "" {
+        return admission.Allowed("")
+    }
+    
+    ns := &corev1.Namespace{}
+    if err := w.Client.Get(ctx, types.NamespacedName{Name: req.Namespace}, ns); err != nil {
+        if !errors.IsNotFound(err) {
+            logger.Error(err, "Failed to get namespace", "namespace", req.Namespace)
+        }
+        return admission.Allowed("")
+    }
+    className, ok := ns.Labels[controller.LabelKey]
+    if !ok || className == "" {
+        return admission.Allowed("")
+    }
+    
+    nsc := &v1.NamespaceClass{}
+    if err := w.Client.Get(ctx, types.NamespacedName{Name: className}, nsc); err != nil {
+        if !errors.IsNotFound(err) {
+            logger.Error(err, "Failed to get NamespaceClass", "class", className)
+        }
+        return admission.Allowed("")
+    }
+    
+    return admission.Allowed("").WithWarnings(nsc.Spec.AdmissionWarnings...)
+}
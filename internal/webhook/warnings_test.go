@@ -0,0 +1,85 @@
+// internal/webhook/warnings_test.go
+package webhook
+
+import (
+    "context"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    v1 "github.com/nickleefly/namespace-class-controller/api/v1"
+    "github.com/nickleefly/namespace-class-controller/internal/controller"
+)
+
+func TestWorkloadWarnings(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    warner := &WorkloadWarner{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bound", Labels: map[string]string{controller.LabelKey: "restricted"}}},
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}},
+        &v1.NamespaceClass{
+            ObjectMeta: metav1.ObjectMeta{Name: "restricted"},
+            Spec:       v1.NamespaceClassSpec{AdmissionWarnings: []string{"this namespace enforces default-deny egress"}},
+        },
+    ).Build()}
+
+    for namespace, want := range map[string]int{"bound": 1, "plain": 0, "missing": 0} {
+        resp := warner.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Namespace: namespace,
+            Operation: admissionv1.Create,
+        }})
+        if !resp.Allowed {
+            t.Errorf("%s: request was denied: %v", namespace, resp.Result)
+        }
+        if len(resp.Warnings) != want {
+            t.Errorf("%s: got %d warnings, want %d", namespace, len(resp.Warnings), want)
+        }
+    }
+}
+
+func TestWorkloadWarningsReadErrors(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := corev1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := v1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    objects := []client.Object{
+        &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bound", Labels: map[string]string{controller.LabelKey: "restricted"}}},
+        &v1.NamespaceClass{ObjectMeta: metav1.ObjectMeta{Name: "restricted"}},
+    }
+
+    // Fail reading the namespace, then the class
+    for _, failing := range []string{"Namespace", "NamespaceClass"} {
+        warner := &WorkloadWarner{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+            WithInterceptorFuncs(interceptor.Funcs{
+                Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+                    if _, isNamespace := obj.(*corev1.Namespace); isNamespace == (failing == "Namespace") {
+                        return errors.NewServiceUnavailable("etcd leader changed")
+                    }
+                    return c.Get(ctx, key, obj, opts...)
+                },
+            }).Build()}
+        resp := warner.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+            Namespace: "bound",
+            Operation: admissionv1.Create,
+        }})
+        if !resp.Allowed {
+            t.Errorf("%s: request was denied after a read error: %v", failing, resp.Result)
+        }
+    }
+}